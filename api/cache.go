@@ -0,0 +1,54 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// cache keeps SOLO.ro answers for a while. Callers asking for the same key
+// at once wait for one fetch; failures are not kept
+type cache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	mu      sync.Mutex
+	value   any
+	expires time.Time
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{ttl: ttl, entries: map[string]*entry{}}
+}
+
+// get returns the value of key, calling load when it is missing or stale
+func (c *cache) get(key string, now time.Time, load func() (any, error)) (any, error) {
+	c.mu.Lock()
+	e := c.entries[key]
+	if e == nil {
+		e = &entry{}
+		c.entries[key] = e
+	}
+	c.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.value != nil && now.Before(e.expires) {
+		return e.value, nil
+	}
+	v, err := load()
+	if err != nil {
+		return nil, err
+	}
+	e.value, e.expires = v, now.Add(c.ttl)
+	return v, nil
+}
+
+// drop forgets key, e.g. after a change the cached value misses
+func (c *cache) drop(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
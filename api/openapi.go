@@ -0,0 +1,180 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"solo-cli/money"
+)
+
+// openAPI describes the routes as an OpenAPI 3.1 document. The schemas are
+// derived from the Go types the endpoints answer with, so they follow the
+// JSON the API actually sends
+func openAPI(version string) map[string]any {
+	schemas := map[string]any{
+		"Error": object(map[string]any{"error": map[string]any{"type": "string"}}),
+	}
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{"description": description, "content": jsonContent(ref("Error"))}
+	}
+
+	paths := map[string]any{}
+	for _, rt := range routes {
+		schemas[rt.result.name] = schemaOf(reflect.TypeOf(rt.result.value))
+
+		var params []any
+		for _, p := range rt.params {
+			params = append(params, map[string]any{
+				"name": p.name, "in": "query", "description": p.description, "schema": map[string]any{"type": p.typ},
+			})
+		}
+		status := "200"
+		if rt.method == http.MethodPost {
+			status = "201"
+		}
+		op := map[string]any{
+			"operationId": operationID(rt.method, rt.path),
+			"summary":     rt.summary,
+			"responses": map[string]any{
+				status: map[string]any{"description": rt.summary, "content": jsonContent(ref(rt.result.name))},
+				"400":  errorResponse("Invalid parameters"),
+				"401":  errorResponse("Missing or invalid token"),
+				"502":  errorResponse("SOLO.ro could not be reached or refused the request"),
+			},
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+		if rt.path == "/v1/uploads" {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{"multipart/form-data": map[string]any{"schema": object(map[string]any{
+					"file": map[string]any{"type": "string", "format": "binary", "description": "PDF or image of the document"},
+				}, "file")}},
+			}
+		}
+		methods, _ := paths[rt.path].(map[string]any)
+		if methods == nil {
+			methods = map[string]any{}
+			paths[rt.path] = methods
+		}
+		methods[strings.ToLower(rt.method)] = op
+	}
+
+	public := []any{}
+	paths["/healthz"] = map[string]any{"get": map[string]any{
+		"operationId": "health", "summary": "Liveness check", "security": public,
+		"responses": map[string]any{"200": map[string]any{"description": "The server is up"}},
+	}}
+	paths["/openapi.json"] = map[string]any{"get": map[string]any{
+		"operationId": "openapi", "summary": "This document", "security": public,
+		"responses": map[string]any{"200": map[string]any{"description": "OpenAPI document"}},
+	}}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "solo-cli API",
+			"version":     version,
+			"description": "SOLO.ro data through one local session. Amounts are numbers with two decimals, in RON unless the item has its own currency",
+		},
+		"security": []any{map[string][]string{"bearer": {}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{"bearer": map[string]any{"type": "http", "scheme": "bearer"}},
+			"schemas":         schemas,
+		},
+	}
+}
+
+// operationID names an operation after its route: GET /v1/summary is
+// getSummary
+func operationID(method, path string) string {
+	name := strings.TrimPrefix(path, "/v1/")
+	return strings.ToLower(method) + strings.ToUpper(name[:1]) + name[1:]
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonContent(schema any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+func object(properties map[string]any, required ...string) map[string]any {
+	o := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		o["required"] = required
+	}
+	return o
+}
+
+var (
+	moneyType = reflect.TypeOf(money.Money(0))
+	timeType  = reflect.TypeOf(time.Time{})
+)
+
+// schemaOf describes how encoding/json writes a value of type t
+func schemaOf(t reflect.Type) map[string]any {
+	switch t {
+	case moneyType:
+		return map[string]any{"type": "number"}
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		addFields(t, properties)
+		return object(properties)
+	}
+	return map[string]any{}
+}
+
+// addFields adds the JSON fields of struct t, flattening embedded structs
+// the way encoding/json does
+func addFields(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(ft, properties)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = schemaOf(f.Type)
+	}
+}
@@ -0,0 +1,441 @@
+// Package api serves SOLO.ro data over a local REST/JSON API. One logged in
+// session is shared by all callers, who authenticate with a bearer token;
+// answers are cached for a while so dashboards polling it do not each hit
+// SOLO.ro
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/money"
+	"solo-cli/taxes"
+)
+
+// maxUpload bounds an uploaded document
+const maxUpload = 32 << 20
+
+// Backend is the SOLO.ro session the server reads from
+type Backend interface {
+	Summary(year int) (*client.Summary, error)
+	Revenues() ([]client.Revenue, error)
+	Expenses() ([]client.Expense, error)
+	Queue() ([]client.QueuedExpense, error)
+	EFactura() ([]client.EFactura, error)
+	Company() (*Company, error)
+	Taxes(summary *client.Summary) (*taxes.TaxBreakdown, error)
+	Upload(path string) (string, error)
+
+	// Relogin renews the session when it expired and reports whether it
+	// did, so the failed call is worth repeating. It is called from
+	// concurrent requests and lets one of them log in at a time
+	Relogin() (bool, error)
+}
+
+// Company is the company profile with its CAEN codes
+type Company struct {
+	*client.CompanyInfo
+	CAEN []client.CAENCode `json:"CAEN"`
+}
+
+// Server answers the API requests
+type Server struct {
+	backend Backend
+	token   string
+	version string
+	cache   *cache
+	now     func() time.Time
+}
+
+// NewServer serves backend to callers presenting token, keeping answers for
+// ttl (0 disables the cache)
+func NewServer(backend Backend, token, version string, ttl time.Duration) *Server {
+	return &Server{backend: backend, token: token, version: version, cache: newCache(ttl), now: time.Now}
+}
+
+// route is one endpoint, described in the OpenAPI document
+type route struct {
+	method, path string
+	summary      string
+	params       []param
+	result       named // the answer, for its schema
+	handle       func(s *Server, w http.ResponseWriter, r *http.Request) error
+}
+
+// named is a schema name with a value of its type
+type named struct {
+	name  string
+	value any
+}
+
+type param struct {
+	name, typ, description string
+}
+
+var (
+	yearParam   = param{"year", "integer", "Calendar year, the current one by default"}
+	searchParam = param{"search", "string", "Case-insensitive text to look for"}
+	limitParam  = param{"limit", "integer", "Maximum number of items, all by default"}
+	offsetParam = param{"offset", "integer", "Items to skip, for paging"}
+)
+
+// Uploaded is the answer to an upload
+type Uploaded struct {
+	Uploaded string `json:"uploaded"` // file name in the queue
+}
+
+var routes = []route{
+	{"GET", "/v1/summary", "Yearly totals", []param{yearParam}, named{"Summary", client.Summary{}}, (*Server).summary},
+	{"GET", "/v1/revenues", "Issued invoices", []param{yearParam, searchParam, {"unpaid", "boolean", "Only unpaid invoices"}, limitParam, offsetParam},
+		named{"RevenueList", List[client.Revenue]{}}, (*Server).revenues},
+	{"GET", "/v1/expenses", "Booked expenses", []param{yearParam, searchParam, limitParam, offsetParam}, named{"ExpenseList", List[client.Expense]{}}, (*Server).expenses},
+	{"GET", "/v1/queue", "Documents waiting to be booked", []param{limitParam, offsetParam}, named{"QueueList", List[client.QueuedExpense]{}}, (*Server).queue},
+	{"GET", "/v1/efactura", "Invoices received through e-Factura", []param{yearParam, searchParam, limitParam, offsetParam},
+		named{"EFacturaList", List[client.EFactura]{}}, (*Server).efactura},
+	{"GET", "/v1/company", "Company profile and CAEN codes", nil, named{"Company", Company{}}, (*Server).company},
+	{"GET", "/v1/taxes", "CAS, CASS and income tax for a year under the local tax rules", []param{yearParam}, named{"Taxes", Taxes{}}, (*Server).taxes},
+	{"POST", "/v1/uploads", "Upload a receipt or invoice to the expense queue", nil, named{"Uploaded", Uploaded{}}, (*Server).upload},
+}
+
+// Handler routes the API, /openapi.json and /healthz. Only the API needs
+// the token
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, rt := range routes {
+		handle := rt.handle
+		mux.HandleFunc(rt.method+" "+rt.path, func(w http.ResponseWriter, r *http.Request) {
+			if !s.authorized(r) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="solo-cli"`)
+				writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+				return
+			}
+			if err := handle(s, w, r); err != nil {
+				var he *httpError
+				if errors.As(err, &he) {
+					writeError(w, he.status, he.err)
+				} else {
+					writeError(w, http.StatusBadGateway, err)
+				}
+			}
+		})
+	}
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, openAPI(s.version))
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no endpoint %s %s, see /openapi.json", r.Method, r.URL.Path))
+	})
+	return mux
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// httpError is a failure of the request itself rather than of SOLO.ro
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string { return e.err.Error() }
+
+func badRequest(format string, args ...any) error {
+	return &httpError{http.StatusBadRequest, fmt.Errorf(format, args...)}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// load returns the cached value of key or fetches it
+func load[T any](s *Server, key string, fetch func() (T, error)) (T, error) {
+	v, err := s.cache.get(key, s.now(), func() (any, error) { return retry(s, fetch) })
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+// retry calls fetch, and once more after logging in again when the session
+// expired
+func retry[T any](s *Server, fetch func() (T, error)) (T, error) {
+	v, err := fetch()
+	if err == nil {
+		return v, nil
+	}
+	if renewed, rerr := s.backend.Relogin(); rerr != nil {
+		return v, fmt.Errorf("%v (login again failed: %v)", err, rerr)
+	} else if !renewed {
+		return v, err
+	}
+	return fetch()
+}
+
+// Query parameters
+
+func intParam(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, badRequest("%s must be a non-negative number", name)
+	}
+	return n, nil
+}
+
+func (s *Server) parseYear(r *http.Request) (int, error) {
+	year, err := intParam(r, "year", s.now().Year())
+	if err == nil && (year < 2000 || year > 2100) {
+		err = badRequest("year %d is out of range", year)
+	}
+	return year, err
+}
+
+// listQuery is the filtering and paging shared by the list endpoints
+type listQuery struct {
+	year          int // 0 for all years
+	search        string
+	limit, offset int
+}
+
+func parseListQuery(r *http.Request) (listQuery, error) {
+	var q listQuery
+	var err error
+	if q.year, err = intParam(r, "year", 0); err != nil {
+		return q, err
+	}
+	if q.limit, err = intParam(r, "limit", 0); err != nil {
+		return q, err
+	}
+	if q.offset, err = intParam(r, "offset", 0); err != nil {
+		return q, err
+	}
+	q.search = strings.ToLower(r.URL.Query().Get("search"))
+	return q, nil
+}
+
+// keep reports whether an item dated date with the searchable fields
+// passes the filters
+func (q listQuery) keep(date string, fields ...string) bool {
+	if q.year != 0 && !strings.HasPrefix(date, strconv.Itoa(q.year)+"-") {
+		return false
+	}
+	if q.search == "" {
+		return true
+	}
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), q.search) {
+			return true
+		}
+	}
+	return false
+}
+
+// List is a page of items
+type List[T any] struct {
+	Items  []T `json:"items"`
+	Count  int `json:"count"`
+	Total  int `json:"total"` // matching items before paging
+	Offset int `json:"offset"`
+}
+
+func page[T any](items []T, q listQuery) List[T] {
+	total := len(items)
+	items = items[min(q.offset, total):]
+	if q.limit > 0 && len(items) > q.limit {
+		items = items[:q.limit]
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return List[T]{Items: items, Count: len(items), Total: total, Offset: q.offset}
+}
+
+// Endpoints
+
+func (s *Server) summary(w http.ResponseWriter, r *http.Request) error {
+	year, err := s.parseYear(r)
+	if err != nil {
+		return err
+	}
+	summary, err := load(s, "summary/"+strconv.Itoa(year), func() (*client.Summary, error) { return s.backend.Summary(year) })
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, summary)
+	return nil
+}
+
+func (s *Server) revenues(w http.ResponseWriter, r *http.Request) error {
+	q, err := parseListQuery(r)
+	if err != nil {
+		return err
+	}
+	unpaid := r.URL.Query().Get("unpaid") == "true"
+	items, err := load(s, "revenues", s.backend.Revenues)
+	if err != nil {
+		return err
+	}
+	var out []client.Revenue
+	for _, item := range items {
+		if q.keep(item.IssueDate, item.SerialCode, item.ClientName) && !(unpaid && item.IsPaid) {
+			out = append(out, item)
+		}
+	}
+	writeJSON(w, http.StatusOK, page(out, q))
+	return nil
+}
+
+func (s *Server) expenses(w http.ResponseWriter, r *http.Request) error {
+	q, err := parseListQuery(r)
+	if err != nil {
+		return err
+	}
+	items, err := load(s, "expenses", s.backend.Expenses)
+	if err != nil {
+		return err
+	}
+	var out []client.Expense
+	for _, item := range items {
+		if q.keep(item.PurchaseDate, item.SupplierName, item.Category) {
+			out = append(out, item)
+		}
+	}
+	writeJSON(w, http.StatusOK, page(out, q))
+	return nil
+}
+
+func (s *Server) queue(w http.ResponseWriter, r *http.Request) error {
+	q, err := parseListQuery(r)
+	if err != nil {
+		return err
+	}
+	items, err := load(s, "queue", s.backend.Queue)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, page(items, q))
+	return nil
+}
+
+func (s *Server) efactura(w http.ResponseWriter, r *http.Request) error {
+	q, err := parseListQuery(r)
+	if err != nil {
+		return err
+	}
+	items, err := load(s, "efactura", s.backend.EFactura)
+	if err != nil {
+		return err
+	}
+	var out []client.EFactura
+	for _, item := range items {
+		if q.keep(item.InvoiceDate, item.SerialCode, item.PartyName) {
+			out = append(out, item)
+		}
+	}
+	writeJSON(w, http.StatusOK, page(out, q))
+	return nil
+}
+
+func (s *Server) company(w http.ResponseWriter, r *http.Request) error {
+	company, err := load(s, "company", s.backend.Company)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, company)
+	return nil
+}
+
+// Taxes is the tax breakdown of a year with the totals it was computed on
+type Taxes struct {
+	Year                    int         `json:"Year"`
+	TotalRevenues           money.Money `json:"TotalRevenues"`
+	TotalDeductibleExpenses money.Money `json:"TotalDeductibleExpenses"`
+	*taxes.TaxBreakdown
+}
+
+func (s *Server) taxes(w http.ResponseWriter, r *http.Request) error {
+	year, err := s.parseYear(r)
+	if err != nil {
+		return err
+	}
+	summary, err := load(s, "summary/"+strconv.Itoa(year), func() (*client.Summary, error) { return s.backend.Summary(year) })
+	if err != nil {
+		return err
+	}
+	// The tax rules are local, so they are read on every request
+	breakdown, err := s.backend.Taxes(summary)
+	if err != nil {
+		return &httpError{http.StatusInternalServerError, err}
+	}
+	writeJSON(w, http.StatusOK, Taxes{summary.Year, summary.TotalRevenues, summary.TotalDeductibleExpenses, breakdown})
+	return nil
+}
+
+// upload takes the document from the multipart field "file" and queues it
+// under its own file name
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUpload)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return badRequest("expected a multipart form with the document in \"file\": %v", err)
+	}
+	defer file.Close()
+
+	name := filepath.Base(filepath.Clean("/" + header.Filename))
+	if name == "/" || name == "." {
+		return badRequest("the document has no file name")
+	}
+	dir, err := os.MkdirTemp("", "solo-upload-")
+	if err != nil {
+		return &httpError{http.StatusInternalServerError, err}
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, name)
+	out, err := os.Create(path)
+	if err != nil {
+		return &httpError{http.StatusInternalServerError, err}
+	}
+	_, err = io.Copy(out, file)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return &httpError{http.StatusInternalServerError, err}
+	}
+
+	uploaded, err := retry(s, func() (string, error) { return s.backend.Upload(path) })
+	if err != nil {
+		return err
+	}
+	// The new document shows up in the queue
+	s.cache.drop("queue")
+	writeJSON(w, http.StatusCreated, Uploaded{uploaded})
+	return nil
+}
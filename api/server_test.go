@@ -0,0 +1,330 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/money"
+	"solo-cli/taxes"
+)
+
+type fakeBackend struct {
+	calls    map[string]int
+	expired  bool // the next call fails until Relogin
+	relogins int
+	uploaded map[string]string // name -> content
+}
+
+func newFake() *fakeBackend {
+	return &fakeBackend{calls: map[string]int{}, uploaded: map[string]string{}}
+}
+
+func (f *fakeBackend) call(name string) error {
+	f.calls[name]++
+	if f.expired {
+		return errors.New("status 401: session expired")
+	}
+	return nil
+}
+
+func (f *fakeBackend) Summary(year int) (*client.Summary, error) {
+	if err := f.call("summary"); err != nil {
+		return nil, err
+	}
+	return &client.Summary{Year: year, TotalRevenues: 100000 * money.Lei, TotalDeductibleExpenses: 10000 * money.Lei}, nil
+}
+
+func (f *fakeBackend) Revenues() ([]client.Revenue, error) {
+	if err := f.call("revenues"); err != nil {
+		return nil, err
+	}
+	return []client.Revenue{
+		{SerialCode: "INV-3", ClientName: "Globex", IssueDate: "2026-02-01", Total: 300 * money.Lei},
+		{SerialCode: "INV-2", ClientName: "ACME Corp", IssueDate: "2025-12-01", Total: 200 * money.Lei, IsPaid: true},
+		{SerialCode: "INV-1", ClientName: "ACME Corp", IssueDate: "2025-06-01", Total: 100 * money.Lei},
+	}, nil
+}
+
+func (f *fakeBackend) Expenses() ([]client.Expense, error) {
+	return nil, f.call("expenses")
+}
+
+func (f *fakeBackend) Queue() ([]client.QueuedExpense, error) {
+	if err := f.call("queue"); err != nil {
+		return nil, err
+	}
+	var items []client.QueuedExpense
+	for name := range f.uploaded {
+		items = append(items, client.QueuedExpense{DocumentName: name})
+	}
+	return items, nil
+}
+
+func (f *fakeBackend) EFactura() ([]client.EFactura, error) {
+	return nil, f.call("efactura")
+}
+
+func (f *fakeBackend) Company() (*Company, error) {
+	if err := f.call("company"); err != nil {
+		return nil, err
+	}
+	return &Company{CompanyInfo: &client.CompanyInfo{Name: "Test PFA"}, CAEN: []client.CAENCode{{Code: "6201"}}}, nil
+}
+
+func (f *fakeBackend) Taxes(summary *client.Summary) (*taxes.TaxBreakdown, error) {
+	return &taxes.TaxBreakdown{NetIncome: summary.TotalRevenues - summary.TotalDeductibleExpenses}, nil
+}
+
+func (f *fakeBackend) Upload(path string) (string, error) {
+	if err := f.call("upload"); err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	f.uploaded[filepath.Base(path)] = string(data)
+	return filepath.Base(path), nil
+}
+
+func (f *fakeBackend) Relogin() (bool, error) {
+	f.relogins++
+	if !f.expired {
+		return false, nil
+	}
+	f.expired = false
+	return true, nil
+}
+
+const testToken = "secret"
+
+func get(t *testing.T, h http.Handler, path, token string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil && rec.Code != http.StatusOK {
+		t.Fatalf("%s: invalid JSON %q", path, rec.Body.String())
+	}
+	return rec.Code, body
+}
+
+func TestAuth(t *testing.T) {
+	h := NewServer(newFake(), testToken, "test", time.Minute).Handler()
+
+	for _, token := range []string{"", "wrong"} {
+		if code, body := get(t, h, "/v1/summary", token); code != http.StatusUnauthorized || body["error"] == nil {
+			t.Errorf("token %q: %d %v", token, code, body)
+		}
+	}
+	if code, _ := get(t, h, "/v1/summary", testToken); code != http.StatusOK {
+		t.Errorf("with the token: %d", code)
+	}
+	for _, path := range []string{"/healthz", "/openapi.json"} {
+		if code, _ := get(t, h, path, ""); code != http.StatusOK {
+			t.Errorf("%s needs no token, got %d", path, code)
+		}
+	}
+	if code, _ := get(t, h, "/v1/nope", testToken); code != http.StatusNotFound {
+		t.Errorf("unknown endpoint: %d", code)
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	h := NewServer(newFake(), testToken, "test", time.Minute).Handler()
+
+	serials := func(path string) []string {
+		code, body := get(t, h, path, testToken)
+		if code != http.StatusOK {
+			t.Fatalf("%s: %d %v", path, code, body)
+		}
+		var out []string
+		for _, item := range body["items"].([]any) {
+			out = append(out, item.(map[string]any)["SerialCode"].(string))
+		}
+		return out
+	}
+	for path, want := range map[string]string{
+		"/v1/revenues":                   "INV-3 INV-2 INV-1",
+		"/v1/revenues?year=2025":         "INV-2 INV-1",
+		"/v1/revenues?search=acme":       "INV-2 INV-1",
+		"/v1/revenues?unpaid=true":       "INV-3 INV-1",
+		"/v1/revenues?limit=1&offset=1":  "INV-2",
+		"/v1/revenues?offset=9":          "",
+		"/v1/revenues?year=2025&limit=1": "INV-2",
+	} {
+		if got := strings.Join(serials(path), " "); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+
+	_, body := get(t, h, "/v1/revenues?year=2025&limit=1", testToken)
+	if body["total"] != 2.0 || body["count"] != 1.0 {
+		t.Errorf("paging counts = %v", body)
+	}
+	if code, _ := get(t, h, "/v1/revenues?limit=x", testToken); code != http.StatusBadRequest {
+		t.Errorf("invalid limit: %d", code)
+	}
+	if code, _ := get(t, h, "/v1/summary?year=1800", testToken); code != http.StatusBadRequest {
+		t.Errorf("invalid year: %d", code)
+	}
+}
+
+func TestCache(t *testing.T) {
+	fake := newFake()
+	s := NewServer(fake, testToken, "test", time.Minute)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	h := s.Handler()
+
+	get(t, h, "/v1/revenues", testToken)
+	get(t, h, "/v1/revenues?year=2025", testToken)
+	get(t, h, "/v1/summary?year=2025", testToken)
+	get(t, h, "/v1/taxes?year=2025", testToken)
+	if fake.calls["revenues"] != 1 || fake.calls["summary"] != 1 {
+		t.Errorf("calls = %v, want one fetch each within the TTL", fake.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	get(t, h, "/v1/revenues", testToken)
+	if fake.calls["revenues"] != 2 {
+		t.Errorf("revenues fetched %d times, want a new fetch after the TTL", fake.calls["revenues"])
+	}
+
+	// An expired session is renewed and the call repeated
+	fake.expired = true
+	fake.relogins = 0
+	now = now.Add(2 * time.Minute)
+	if code, _ := get(t, h, "/v1/company", testToken); code != http.StatusOK || fake.relogins != 1 || fake.calls["company"] != 2 {
+		t.Errorf("after expiry: %d, %d relogins, %d calls", code, fake.relogins, fake.calls["company"])
+	}
+
+	uncached := newFake()
+	h = NewServer(uncached, testToken, "test", 0).Handler()
+	get(t, h, "/v1/revenues", testToken)
+	get(t, h, "/v1/revenues", testToken)
+	if uncached.calls["revenues"] != 2 {
+		t.Errorf("with no TTL revenues fetched %d times", uncached.calls["revenues"])
+	}
+}
+
+func TestReloginNotNeeded(t *testing.T) {
+	fake := newFake()
+	h := NewServer(&erroringBackend{fake}, testToken, "test", time.Minute).Handler()
+
+	// The session still works, so the error is passed on without a retry
+	code, body := get(t, h, "/v1/expenses", testToken)
+	if code != http.StatusBadGateway || !strings.Contains(body["error"].(string), "boom") || fake.relogins != 1 || fake.calls["expenses"] != 1 {
+		t.Errorf("backend failure: %d %v, %d relogins, %d calls", code, body, fake.relogins, fake.calls["expenses"])
+	}
+}
+
+// erroringBackend fails to list expenses while the session is fine
+type erroringBackend struct {
+	*fakeBackend
+}
+
+func (e *erroringBackend) Expenses() ([]client.Expense, error) {
+	e.calls["expenses"]++
+	return nil, errors.New("status 500: boom")
+}
+
+func TestTaxes(t *testing.T) {
+	h := NewServer(newFake(), testToken, "test", time.Minute).Handler()
+	code, body := get(t, h, "/v1/taxes?year=2025", testToken)
+	if code != http.StatusOK || body["Year"] != 2025.0 || body["TotalRevenues"] != 100000.0 || body["NetIncome"] != 90000.0 {
+		t.Errorf("taxes = %d %v", code, body)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	fake := newFake()
+	h := NewServer(fake, testToken, "test", time.Minute).Handler()
+
+	// The queue is cached before the upload
+	if _, body := get(t, h, "/v1/queue", testToken); body["count"] != 0.0 {
+		t.Fatalf("queue = %v", body)
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, _ := mw.CreateFormFile("file", "../../etc/receipt.pdf")
+	io.WriteString(fw, "%PDF-1.4 receipt")
+	mw.Close()
+	req := httptest.NewRequest("POST", "/v1/uploads", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated || !strings.Contains(rec.Body.String(), `"uploaded": "receipt.pdf"`) {
+		t.Fatalf("upload = %d %s", rec.Code, rec.Body.String())
+	}
+	if fake.uploaded["receipt.pdf"] != "%PDF-1.4 receipt" {
+		t.Errorf("uploaded = %v", fake.uploaded)
+	}
+	if _, body := get(t, h, "/v1/queue", testToken); body["count"] != 1.0 {
+		t.Errorf("queue after upload = %v, want the cache dropped", body)
+	}
+
+	req = httptest.NewRequest("POST", "/v1/uploads", strings.NewReader("not a form"))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("upload without a form = %d", rec.Code)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	h := NewServer(newFake(), testToken, "1.2.3", time.Minute).Handler()
+	_, doc := get(t, h, "/openapi.json", "")
+
+	if doc["openapi"] != "3.1.0" || doc["info"].(map[string]any)["version"] != "1.2.3" {
+		t.Errorf("header = %v %v", doc["openapi"], doc["info"])
+	}
+	paths := doc["paths"].(map[string]any)
+	for _, rt := range routes {
+		op, ok := paths[rt.path].(map[string]any)[strings.ToLower(rt.method)].(map[string]any)
+		if !ok {
+			t.Errorf("%s %s missing", rt.method, rt.path)
+			continue
+		}
+		if op["operationId"] == "" {
+			t.Errorf("%s has no operationId", rt.path)
+		}
+	}
+	if id := paths["/v1/summary"].(map[string]any)["get"].(map[string]any)["operationId"]; id != "getSummary" {
+		t.Errorf("operationId = %v", id)
+	}
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	revenue := schemas["RevenueList"].(map[string]any)["properties"].(map[string]any)["items"].(map[string]any)["items"].(map[string]any)
+	props := revenue["properties"].(map[string]any)
+	if props["SerialCode"].(map[string]any)["type"] != "string" || props["Total"].(map[string]any)["type"] != "number" {
+		t.Errorf("revenue schema = %v", props)
+	}
+	// Embedded structs are flattened like encoding/json does
+	company := schemas["Company"].(map[string]any)["properties"].(map[string]any)
+	if company["Name"] == nil || company["CAEN"] == nil {
+		t.Errorf("company schema = %v", company)
+	}
+	taxProps := schemas["Taxes"].(map[string]any)["properties"].(map[string]any)
+	if taxProps["NetIncome"] == nil || taxProps["Year"] == nil {
+		t.Errorf("taxes schema = %v", taxProps)
+	}
+}
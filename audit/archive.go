@@ -0,0 +1,209 @@
+// Package audit writes the year's accounting data as a self-describing ZIP
+// archive for auditors and accountants: JSON and CSV tables, the original
+// documents and a manifest with the SHA-256 checksum of every file
+package audit
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format identifies the archive layout in the manifest
+const Format = "solo-cli-audit/1"
+
+const (
+	ManifestName = "manifest.json"
+	// SumsName lists the checksums in the sha256sum format, so the archive
+	// can be checked with `sha256sum -c SHA256SUMS` once unpacked
+	SumsName = "SHA256SUMS"
+)
+
+// File is one archive member in the manifest
+type File struct {
+	Path        string `json:"path"`
+	Size        int    `json:"size"`
+	SHA256      string `json:"sha256"`
+	Description string `json:"description,omitempty"`
+}
+
+// Manifest describes the archive
+type Manifest struct {
+	Format      string         `json:"format"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Generator   string         `json:"generator"`
+	Year        int            `json:"year"`
+	Company     string         `json:"company,omitempty"`
+	CUI         string         `json:"cui,omitempty"`
+	Counts      map[string]int `json:"counts"`
+	Files       []File         `json:"files"`
+	// Notes records what is missing and why, e.g. documents that could not
+	// be downloaded
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Writer adds files to the archive while recording their checksums
+type Writer struct {
+	zw       *zip.Writer
+	modified time.Time
+	paths    map[string]bool
+	Manifest Manifest
+}
+
+// NewWriter starts an archive on w for year. Every member carries the
+// generation time
+func NewWriter(w io.Writer, year int, generator string, now time.Time) *Writer {
+	return &Writer{
+		zw:       zip.NewWriter(w),
+		modified: now,
+		paths:    map[string]bool{},
+		Manifest: Manifest{
+			Format:      Format,
+			GeneratedAt: now.UTC().Truncate(time.Second),
+			Generator:   generator,
+			Year:        year,
+			Counts:      map[string]int{},
+		},
+	}
+}
+
+// Add stores one file with its description for the manifest
+func (w *Writer) Add(path, description string, data []byte) error {
+	if path == ManifestName || path == SumsName {
+		return fmt.Errorf("%s is reserved", path)
+	}
+	if w.paths[path] {
+		return fmt.Errorf("%s is already in the archive", path)
+	}
+	w.paths[path] = true
+	if err := w.write(path, data); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	w.Manifest.Files = append(w.Manifest.Files, File{Path: path, Size: len(data), SHA256: hex.EncodeToString(sum[:]), Description: description})
+	return nil
+}
+
+// AddJSON stores v indented
+func (w *Writer) AddJSON(path, description string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return w.Add(path, description, append(data, '\n'))
+}
+
+// Has reports whether path was added
+func (w *Writer) Has(path string) bool {
+	return w.paths[path]
+}
+
+// Note records something missing from the archive
+func (w *Writer) Note(format string, args ...any) {
+	w.Manifest.Notes = append(w.Manifest.Notes, fmt.Sprintf(format, args...))
+}
+
+func (w *Writer) write(path string, data []byte) error {
+	f, err := w.zw.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Deflate, Modified: w.modified})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// Close writes SHA256SUMS and the manifest, then finishes the archive
+func (w *Writer) Close() error {
+	var sums bytes.Buffer
+	for _, f := range w.Manifest.Files {
+		fmt.Fprintf(&sums, "%s  %s\n", f.SHA256, f.Path)
+	}
+	if err := w.write(SumsName, sums.Bytes()); err != nil {
+		return err
+	}
+	manifest, err := json.MarshalIndent(w.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := w.write(ManifestName, append(manifest, '\n')); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// Verify checks an archive against its manifest: every listed file must be
+// present with its size and checksum, and nothing may be left unlisted. It
+// returns the manifest and the problems found
+func Verify(r io.ReaderAt, size int64) (*Manifest, []string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, err
+	}
+	members := map[string]*zip.File{}
+	for _, f := range zr.File {
+		members[f.Name] = f
+	}
+
+	mf, ok := members[ManifestName]
+	if !ok {
+		return nil, nil, fmt.Errorf("no %s in the archive", ManifestName)
+	}
+	data, err := readMember(mf)
+	if err != nil {
+		return nil, nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %w", ManifestName, err)
+	}
+	if !strings.HasPrefix(m.Format, "solo-cli-audit/") {
+		return nil, nil, fmt.Errorf("not an audit archive (format %q)", m.Format)
+	}
+
+	var problems []string
+	listed := map[string]bool{ManifestName: true, SumsName: true}
+	for _, f := range m.Files {
+		listed[f.Path] = true
+		zf, ok := members[f.Path]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: missing", f.Path))
+			continue
+		}
+		data, err := readMember(zf)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", f.Path, err))
+			continue
+		}
+		sum := sha256.Sum256(data)
+		switch {
+		case len(data) != f.Size:
+			problems = append(problems, fmt.Sprintf("%s: %d bytes, manifest says %d", f.Path, len(data), f.Size))
+		case hex.EncodeToString(sum[:]) != f.SHA256:
+			problems = append(problems, fmt.Sprintf("%s: checksum mismatch", f.Path))
+		}
+	}
+	var extra []string
+	for name := range members {
+		if !listed[name] {
+			extra = append(extra, fmt.Sprintf("%s: not in the manifest", name))
+		}
+	}
+	sort.Strings(extra)
+	return &m, append(problems, extra...), nil
+}
+
+func readMember(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
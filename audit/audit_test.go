@@ -0,0 +1,202 @@
+package audit
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+func buildArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf, 2025, "solo-cli test", time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC))
+	if err := w.AddJSON("revenues.json", "Invoices", []string{"INV-001"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add("documents/expenses/a.pdf", "", []byte("%PDF-1.4")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Add("documents/expenses/a.pdf", "", []byte("again")); err == nil {
+		t.Error("duplicate path accepted")
+	}
+	if err := w.Add(ManifestName, "", nil); err == nil {
+		t.Error("manifest path accepted")
+	}
+	w.Note("document %s: %s", "x", "status 404")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchiveVerify(t *testing.T) {
+	data := buildArchive(t)
+	m, problems, err := Verify(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) > 0 {
+		t.Errorf("problems = %v", problems)
+	}
+	if m.Year != 2025 || m.Format != Format || len(m.Files) != 2 || len(m.Notes) != 1 {
+		t.Errorf("manifest = %+v", m)
+	}
+	sum := sha256.Sum256([]byte("%PDF-1.4"))
+	if m.Files[1].SHA256 != hex.EncodeToString(sum[:]) || m.Files[1].Size != 8 {
+		t.Errorf("file = %+v", m.Files[1])
+	}
+
+	zr, _ := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	for _, f := range zr.File {
+		if f.Name != SumsName {
+			continue
+		}
+		rc, _ := f.Open()
+		sums, _ := io.ReadAll(rc)
+		rc.Close()
+		if !strings.HasSuffix(string(sums), "  documents/expenses/a.pdf\n") || strings.Count(string(sums), "\n") != 2 {
+			t.Errorf("SHA256SUMS = %q", sums)
+		}
+	}
+}
+
+// rewrite copies an archive, changing or adding members
+func rewrite(t *testing.T, data []byte, change map[string]string) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		if c, ok := change[f.Name]; ok {
+			delete(change, f.Name)
+			if c == "" {
+				continue
+			}
+			content = []byte(c)
+		}
+		w, _ := zw.Create(f.Name)
+		w.Write(content)
+	}
+	for name, c := range change {
+		w, _ := zw.Create(name)
+		w.Write([]byte(c))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestArchiveVerifyTampered(t *testing.T) {
+	data := rewrite(t, buildArchive(t), map[string]string{
+		"documents/expenses/a.pdf": "%PDF-1.5", // same size
+		"revenues.json":            "",         // removed
+		"extra.txt":                "hi",
+	})
+	_, problems, err := Verify(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"revenues.json: missing",
+		"documents/expenses/a.pdf: checksum mismatch",
+		"extra.txt: not in the manifest",
+	}
+	if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("problems =\n%s\nwant\n%s", strings.Join(problems, "\n"), strings.Join(want, "\n"))
+	}
+
+	data = rewrite(t, data, map[string]string{ManifestName: ""})
+	if _, _, err := Verify(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Error("archive without a manifest accepted")
+	}
+}
+
+func TestTables(t *testing.T) {
+	rate := 4.9771
+	code, mt := "doc/42", "application/pdf"
+	revenues := []client.Revenue{
+		{SerialCode: "INV-001", ClientName: "ACME, Corp", IssueDate: "2025-01-15", Total: money.MustParse("1000.50"), IsPaid: true},
+		{SerialCode: "INV-002", IssueDate: "2025-02-10", Total: money.MustParse("250.25"), Currency: client.Currency{ShortName: "EUR"},
+			InvoiceLocalAmount: &client.LocalAmount{Total: money.MustParse("1245.52"), ExchangeRate: &rate}},
+		{SerialCode: "INV-003", IssueDate: "2025-03-01", Total: 10 * money.Lei, Currency: client.Currency{ShortName: "EUR"}},
+		{SerialCode: "INV-OLD", IssueDate: "2024-12-31", Total: 5 * money.Lei},
+	}
+	revenues = Revenues(revenues, 2025)
+	if len(revenues) != 3 {
+		t.Fatalf("revenues in 2025 = %d", len(revenues))
+	}
+	data, err := RevenuesCSV(revenues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	for i, want := range []string{
+		`,INV-001,"ACME, Corp",2025-01-15,,true,false,1000.50,RON,1000.50,,false,`,
+		`,INV-002,,2025-02-10,,false,false,250.25,EUR,1245.52,4.9771,false,`,
+		// Foreign without a local amount: the RON value is left empty
+		`,INV-003,,2025-03-01,,false,false,10.00,EUR,,,false,`,
+	} {
+		if lines[i+1] != want {
+			t.Errorf("line %d = %s\nwant %s", i+1, lines[i+1], want)
+		}
+	}
+
+	expenses := Expenses([]client.Expense{
+		{SupplierName: "Hosting SRL", PurchaseDate: "2025-03-05T00:00:00", Category: "Servicii", Total: money.MustParse("99.99"), DocumentCode: &code, DocumentMimeType: &mt},
+		{SupplierName: "Later", PurchaseDate: "2026-01-01T00:00:00"},
+	}, 2025)
+	data, err = ExpensesCSV(expenses, map[string]string{code: "documents/expenses/x.pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ",Hosting SRL,2025-03-05T00:00:00,Servicii,99.99,RON,99.99,100,doc/42,documents/expenses/x.pdf\n"; !strings.HasSuffix(string(data), want) {
+		t.Errorf("expenses CSV = %q", data)
+	}
+
+	if got := Rejected(nil, 2025); got == nil || len(got) != 0 {
+		t.Errorf("Rejected(nil) = %#v, want an empty slice", got)
+	}
+}
+
+func TestDocuments(t *testing.T) {
+	code, jpeg := "AB12", "image/jpeg"
+	docs := Documents(
+		[]client.Expense{
+			{SupplierName: "Papetăria Ștefan S.R.L.", PurchaseDate: "2025-03-05T00:00:00", DocumentCode: &code, DocumentMimeType: &jpeg},
+			{SupplierName: "Duplicate", DocumentCode: &code},
+			{SupplierName: "No document"},
+		},
+		[]client.RejectedExpense{{DocumentCode: "R/7", DocumentName: "scan.HEIC", CreatedOn: "2025-04-01T08:00:00"}},
+	)
+	if len(docs) != 2 {
+		t.Fatalf("documents = %+v", docs)
+	}
+	tests := []struct {
+		doc         Document
+		contentType string
+		want        string
+	}{
+		{docs[0], "application/pdf", "documents/expenses/2025-03-05_Papetaria-Stefan-S-R-L_AB12.jpg"},
+		{docs[1], "", "documents/rejected/2025-04-01_R-7.heic"},
+		{Document{Dir: "expenses", Code: "x"}, "application/pdf; charset=binary", "documents/expenses/x.pdf"},
+		{Document{Dir: "expenses", Code: "x"}, "", "documents/expenses/x.bin"},
+	}
+	for _, tt := range tests {
+		if got := tt.doc.Path(tt.contentType); got != tt.want {
+			t.Errorf("Path(%+v) = %s, want %s", tt.doc, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"solo-cli/client"
+	"solo-cli/diacritics"
+	"solo-cli/money"
+)
+
+// Revenues keeps the invoices issued in year. The filters never return nil,
+// so an empty table is [] in JSON
+func Revenues(items []client.Revenue, year int) []client.Revenue {
+	out := []client.Revenue{}
+	for _, r := range items {
+		if client.InYear(r.IssueDate, year) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Expenses keeps the expenses purchased in year
+func Expenses(items []client.Expense, year int) []client.Expense {
+	out := []client.Expense{}
+	for _, e := range items {
+		if client.InYear(e.PurchaseDate, year) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Rejected keeps the documents submitted in year
+func Rejected(items []client.RejectedExpense, year int) []client.RejectedExpense {
+	out := []client.RejectedExpense{}
+	for _, r := range items {
+		if client.InYear(r.CreatedOn, year) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// EFactura keeps the e-invoices dated in year
+func EFactura(items []client.EFactura, year int) []client.EFactura {
+	out := []client.EFactura{}
+	for _, e := range items {
+		if client.InYear(e.InvoiceDate, year) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func writeCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// amount leaves the cell empty when there is no value, so a zero is never
+// invented
+func amount(m *money.Money) string {
+	if m == nil {
+		return ""
+	}
+	return m.String()
+}
+
+// RevenuesCSV is one line per invoice, amounts as plain decimals
+func RevenuesCSV(items []client.Revenue) ([]byte, error) {
+	rows := [][]string{{"unique_code", "serial_code", "client", "issue_date", "payment_date", "paid", "cancelled",
+		"total", "currency", "total_ron", "exchange_rate", "external", "e_invoice_status"}}
+	for _, r := range items {
+		var ron *money.Money
+		rate := ""
+		if r.InvoiceLocalAmount != nil {
+			ron = &r.InvoiceLocalAmount.Total
+			if r.InvoiceLocalAmount.ExchangeRate != nil {
+				rate = strconv.FormatFloat(*r.InvoiceLocalAmount.ExchangeRate, 'f', -1, 64)
+			}
+		} else if r.Currency.ISOCode() == "RON" {
+			ron = &r.Total
+		}
+		einvoice := ""
+		if r.EInvoiceStatus != nil {
+			einvoice = r.EInvoiceStatus.Name
+		}
+		rows = append(rows, []string{r.UniqueCode, r.SerialCode, r.ClientName, r.IssueDate, r.PaymentDate,
+			strconv.FormatBool(r.IsPaid), strconv.FormatBool(r.Status != nil && r.Status.IsCancelled),
+			r.Total.String(), r.Currency.ISOCode(), amount(ron), rate, strconv.FormatBool(r.IsExternalDocument), einvoice})
+	}
+	return writeCSV(rows)
+}
+
+// ExpensesCSV is one line per expense with the archived document, if any
+func ExpensesCSV(items []client.Expense, documents map[string]string) ([]byte, error) {
+	rows := [][]string{{"unique_code", "supplier", "purchase_date", "category", "total", "currency", "total_ron",
+		"deductible_percent", "document_code", "document"}}
+	for _, e := range items {
+		var ron *money.Money
+		if e.ExpenseLocalAmount != nil {
+			ron = &e.ExpenseLocalAmount.Total
+		} else if e.Currency.ISOCode() == "RON" {
+			ron = &e.Total
+		}
+		code := deref(e.DocumentCode)
+		rows = append(rows, []string{e.UniqueCode, e.SupplierName, e.PurchaseDate, e.Category, e.Total.String(),
+			e.Currency.ISOCode(), amount(ron), strconv.FormatFloat(e.DeductiblePercent(), 'f', -1, 64), code, documents[code]})
+	}
+	return writeCSV(rows)
+}
+
+// RejectedCSV is one line per rejected document
+func RejectedCSV(items []client.RejectedExpense, documents map[string]string) ([]byte, error) {
+	rows := [][]string{{"id", "document_name", "reason", "created_on", "rejected_on", "allow_resubmit", "document_code", "document"}}
+	for _, r := range items {
+		rows = append(rows, []string{strconv.Itoa(r.Id), r.DocumentName, r.Reason, r.CreatedOn, r.RejectedOn,
+			strconv.FormatBool(r.AllowResubmit), r.DocumentCode, documents[r.DocumentCode]})
+	}
+	return writeCSV(rows)
+}
+
+// EFacturaCSV is one line per e-invoice received through e-Factura
+func EFacturaCSV(items []client.EFactura) ([]byte, error) {
+	rows := [][]string{{"serial_code", "invoice_date", "party_code", "party_name", "total", "currency"}}
+	for _, e := range items {
+		rows = append(rows, []string{e.SerialCode, e.InvoiceDate, e.PartyCode1, e.PartyName, e.TotalAmount.String(), e.CurrencyCode})
+	}
+	return writeCSV(rows)
+}
+
+// knownExtensions are preferred over mime's pick, which may be .jfif or .jpe
+var knownExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/xml": ".xml",
+	"text/xml":        ".xml",
+}
+
+// extension picks the file extension for a document from its mime type,
+// falling back to the one of its original name
+func extension(mimeType, name string) string {
+	mt, _, _ := mime.ParseMediaType(mimeType)
+	if ext, ok := knownExtensions[mt]; ok {
+		return ext
+	}
+	if ext := strings.ToLower(path.Ext(name)); ext != "" && len(ext) <= 6 {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(mt); len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+// slug keeps a name readable in a file path: Romanian diacritics folded,
+// runs of anything but ASCII letters and digits turned into a dash
+func slug(s string, limit int) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range diacritics.Strip(s) {
+		if b.Len() >= limit {
+			break
+		}
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// Document is an original file of an expense or rejected submission
+type Document struct {
+	Dir      string // expenses or rejected
+	Date     string
+	Party    string
+	Code     string // DocumentCode in SOLO
+	MimeType string
+	Name     string // original file name, if known
+}
+
+// Documents lists the files to archive for the expenses and rejected
+// documents, each code once
+func Documents(expenses []client.Expense, rejected []client.RejectedExpense) []Document {
+	var docs []Document
+	seen := map[string]bool{}
+	add := func(d Document) {
+		if d.Code != "" && !seen[d.Code] {
+			seen[d.Code] = true
+			docs = append(docs, d)
+		}
+	}
+	for _, e := range expenses {
+		add(Document{Dir: "expenses", Date: e.PurchaseDate, Party: e.SupplierName, Code: deref(e.DocumentCode), MimeType: deref(e.DocumentMimeType)})
+	}
+	for _, r := range rejected {
+		add(Document{Dir: "rejected", Date: r.CreatedOn, Code: r.DocumentCode, MimeType: r.DocumentMimeType, Name: r.DocumentName})
+	}
+	return docs
+}
+
+// Path names the archived document after its date, party and code, e.g.
+// documents/expenses/2025-03-05_Hosting-SRL_ab12cd.pdf. contentType stands
+// in when SOLO did not record the mime type
+func (d Document) Path(contentType string) string {
+	mimeType := d.MimeType
+	if mimeType == "" {
+		mimeType = contentType
+	}
+	var parts []string
+	if day := client.Day(d.Date); day != "" {
+		parts = append(parts, day)
+	}
+	if p := slug(d.Party, 40); p != "" {
+		parts = append(parts, p)
+	}
+	parts = append(parts, slug(d.Code, 64))
+	return fmt.Sprintf("documents/%s/%s%s", d.Dir, strings.Join(parts, "_"), extension(mimeType, d.Name))
+}
@@ -0,0 +1,194 @@
+// Package bank reads bank statements (the CSV exports of Romanian banks,
+// MT940 and CAMT.053) and reconciles their lines with the open invoices
+// and the expenses
+package bank
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"solo-cli/money"
+)
+
+const dateLayout = "2006-01-02"
+
+// Transaction is one statement line. Amount is positive for money received
+// (credits) and negative for money paid (debits)
+type Transaction struct {
+	Date         string      `json:"date"` // booking date, YYYY-MM-DD
+	Amount       money.Money `json:"amount"`
+	Currency     string      `json:"currency"`
+	Counterparty string      `json:"counterparty,omitempty"`
+	Description  string      `json:"description,omitempty"`
+	Reference    string      `json:"reference,omitempty"`
+}
+
+// Credit reports whether the line is money received
+func (t Transaction) Credit() bool {
+	return t.Amount > 0
+}
+
+// text is everything on the line an invoice number or a name can appear in
+func (t Transaction) text() string {
+	return t.Counterparty + " " + t.Description + " " + t.Reference
+}
+
+// Statement is a parsed bank export
+type Statement struct {
+	Format       string        `json:"format"` // BT CSV, ING CSV, BCR CSV, CSV, MT940 or CAMT.053
+	Account      string        `json:"account,omitempty"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// Period is the first and last booking date, empty without transactions
+func (s *Statement) Period() (string, string) {
+	var first, last string
+	for _, t := range s.Transactions {
+		if first == "" || t.Date < first {
+			first = t.Date
+		}
+		if t.Date > last {
+			last = t.Date
+		}
+	}
+	return first, last
+}
+
+// Kind is a statement file format
+type Kind string
+
+const (
+	KindCSV     Kind = "csv"
+	KindMT940   Kind = "mt940"
+	KindCAMT053 Kind = "camt053"
+)
+
+// ParseKind accepts csv, mt940 and camt053 (with or without the dot)
+func ParseKind(s string) (Kind, error) {
+	switch strings.ReplaceAll(strings.ToLower(s), ".", "") {
+	case "csv":
+		return KindCSV, nil
+	case "mt940", "940", "sta":
+		return KindMT940, nil
+	case "camt053", "camt", "xml":
+		return KindCAMT053, nil
+	}
+	return "", fmt.Errorf("invalid statement format '%s' (want csv, mt940 or camt053)", s)
+}
+
+// DetectKind guesses the format from the content, falling back to the file
+// name: MT940 starts with a :20: tag and CAMT.053 is XML
+func DetectKind(name string, data []byte) Kind {
+	head := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	switch {
+	case bytes.HasPrefix(head, []byte("<")):
+		return KindCAMT053
+	case bytes.HasPrefix(head, []byte(":20:")), bytes.HasPrefix(head, []byte("{1:")):
+		return KindMT940
+	}
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".xml"):
+		return KindCAMT053
+	case strings.HasSuffix(lower, ".mt940"), strings.HasSuffix(lower, ".sta"), strings.HasSuffix(lower, ".940"):
+		return KindMT940
+	}
+	return KindCSV
+}
+
+// Parse reads a statement of the given kind, detected from name and data
+// when empty. csvBank forces a CSV layout (bt, ing, bcr) instead of
+// recognizing it from the header
+func Parse(name string, data []byte, kind Kind, csvBank string) (*Statement, error) {
+	if kind == "" {
+		kind = DetectKind(filepath.Base(name), data)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var s *Statement
+	var err error
+	switch kind {
+	case KindMT940:
+		s, err = parseMT940(data)
+	case KindCAMT053:
+		s, err = parseCAMT053(data)
+	default:
+		s, err = parseCSV(data, csvBank)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Transactions) == 0 {
+		return nil, fmt.Errorf("no transactions found in %s (%s)", filepath.Base(name), s.Format)
+	}
+	return s, nil
+}
+
+var romanianMonths = strings.NewReplacer(
+	"ianuarie", "January", "februarie", "February", "martie", "March", "aprilie", "April",
+	"mai", "May", "iunie", "June", "iulie", "July", "august", "August",
+	"septembrie", "September", "octombrie", "October", "noiembrie", "November", "decembrie", "December",
+)
+
+var dateLayouts = []string{
+	dateLayout, "02.01.2006", "02/01/2006", "02-01-2006", "2006.01.02", "2 January 2006", "02 Jan 2006",
+}
+
+// parseDate reads the date formats the banks use, including Romanian month
+// names (15 ianuarie 2026), and returns it as YYYY-MM-DD
+func parseDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 10 && s[4] == '-' && (s[10] == 'T' || s[10] == ' ') {
+		s = s[:10]
+	}
+	english := romanianMonths.Replace(strings.ToLower(s))
+	for _, layout := range dateLayouts {
+		for _, v := range []string{s, english} {
+			if d, err := time.Parse(layout, v); err == nil {
+				return d.Format(dateLayout), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("invalid date %q", s)
+}
+
+// parseAmount reads amounts with either decimal separator ("1.234,56",
+// "1,234.56", "-99.99"). A lone separator followed by three digits is a
+// thousands separator
+func parseAmount(s string) (money.Money, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', ' ', '\'':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+
+	comma, dot := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	switch {
+	case comma >= 0 && dot >= 0:
+		if comma > dot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case comma >= 0:
+		if strings.Count(s, ",") == 1 && len(s)-comma-1 != 3 {
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case dot >= 0:
+		if strings.Count(s, ".") > 1 || len(s)-dot-1 == 3 {
+			s = strings.ReplaceAll(s, ".", "")
+		}
+	}
+	return money.Parse(s)
+}
@@ -0,0 +1,177 @@
+package bank
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+func parseFile(t *testing.T, name string) *Statement {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Parse(path, data, "", "")
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return s
+}
+
+func TestParseStatements(t *testing.T) {
+	tests := []struct {
+		file, format, account string
+		want                  []Transaction
+	}{
+		{"bt.csv", "BT CSV", "", []Transaction{
+			{Date: "2026-01-20", Amount: money.MustParse("1000.50"), Currency: "RON", Description: "Incasare OP - ACME CORP SRL; plata fact INV-001", Reference: "BT123456"},
+			{Date: "2026-02-28", Amount: money.MustParse("-99.99"), Currency: "RON", Description: "Plata la POS HOSTING SRL BUCURESTI", Reference: "BT123457"},
+			{Date: "2026-03-02", Amount: -5 * money.Lei, Currency: "RON", Description: "Comision administrare cont", Reference: "BT123458"},
+			{Date: "2026-03-10", Amount: 500 * money.Lei, Currency: "RON", Description: "Incasare OP - INITECH SRL", Reference: "BT123459"},
+		}},
+		{"ing.csv", "ING CSV", "", []Transaction{
+			{Date: "2026-01-15", Amount: money.MustParse("1000.50"), Currency: "RON", Description: "Incasare Ordonator: ACME Corp Detalii: Factura INV 001"},
+			{Date: "2026-02-03", Amount: money.MustParse("-99.99"), Currency: "RON", Description: "Cumparare POS Terminal: HOSTING SRL"},
+		}},
+		{"bcr.csv", "BCR CSV", "", []Transaction{
+			{Date: "2026-01-20", Amount: money.MustParse("1000.50"), Currency: "RON", Counterparty: "ACME CORP SRL", Description: "Plata servicii", Reference: "FT2601200001"},
+			{Date: "2026-03-05", Amount: money.MustParse("-99.99"), Currency: "RON", Counterparty: "HOSTING SRL", Description: "Servicii hosting", Reference: "FT2603050002"},
+		}},
+		{"statement.mt940", "MT940", "RO49RNCB0082044123450001", []Transaction{
+			{Date: "2026-02-15", Amount: money.MustParse("250.25"), Currency: "EUR", Description: "INV-002 GLOBEX GMBH", Reference: "260215123456"},
+			{Date: "2026-03-01", Amount: -21 * money.Lei, Currency: "EUR", Description: "GITHUB.COM"},
+		}},
+		{"statement.camt053.xml", "CAMT.053", "RO49INGB0000999901234567", []Transaction{
+			{Date: "2026-01-20", Amount: money.MustParse("1000.50"), Currency: "RON", Counterparty: "ACME Corp SRL", Description: "Contravaloare factura INV-001", Reference: "ING-0001"},
+			{Date: "2026-03-06", Amount: money.MustParse("-99.99"), Currency: "RON", Counterparty: "Hosting SRL"},
+		}},
+	}
+	for _, tt := range tests {
+		s := parseFile(t, tt.file)
+		if s.Format != tt.format || s.Account != tt.account {
+			t.Errorf("%s: format %q account %q", tt.file, s.Format, s.Account)
+		}
+		if len(s.Transactions) != len(tt.want) {
+			t.Errorf("%s: got %d transactions, want %d: %+v", tt.file, len(s.Transactions), len(tt.want), s.Transactions)
+			continue
+		}
+		for i, want := range tt.want {
+			if got := s.Transactions[i]; got != want {
+				t.Errorf("%s line %d:\n got %+v\nwant %+v", tt.file, i, got, want)
+			}
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := map[string]money.Money{
+		"1.234,56":  money.MustParse("1234.56"),
+		"1,234.56":  money.MustParse("1234.56"),
+		"-99,99":    money.MustParse("-99.99"),
+		"1.234.567": 1234567 * money.Lei,
+		"2,000":     2000 * money.Lei,
+		"12,5":      money.MustParse("12.50"),
+		"1 500,00":  1500 * money.Lei,
+		"":          0,
+	}
+	for in, want := range tests {
+		if got, err := parseAmount(in); err != nil || got != want {
+			t.Errorf("parseAmount(%q) = %s, %v; want %s", in, got, err, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse("x.csv", []byte("a,b\n1,2\n"), "", ""); err == nil {
+		t.Error("CSV without a date column accepted")
+	}
+	if _, err := Parse("x.csv", []byte("Data,Suma\n"), "", ""); err == nil {
+		t.Error("empty statement accepted")
+	}
+	if _, err := Parse("x.csv", []byte("Data,Suma\n2026-01-01,5\n"), KindCSV, "revolut"); err == nil {
+		t.Error("unknown bank accepted")
+	}
+	if k, err := ParseKind(".MT940"); err != nil || k != KindMT940 {
+		t.Errorf("ParseKind = %q, %v", k, err)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	eur := client.Currency{ShortName: "EUR"}
+	revenues := []client.Revenue{
+		{SerialCode: "INV-001", ClientName: "ACME Corp", IssueDate: "2026-01-10", Total: money.MustParse("1000.50")},
+		{SerialCode: "INV-002", ClientName: "Globex GmbH", IssueDate: "2026-02-10", Total: money.MustParse("250.25"), Currency: eur},
+		{SerialCode: "INV-003", ClientName: "Initech SRL", IssueDate: "2026-03-01", Total: 500 * money.Lei},
+		{SerialCode: "INV-004", ClientName: "Umbrella SRL", IssueDate: "2026-03-01", Total: 500 * money.Lei},
+		{SerialCode: "INV-005", ClientName: "Hooli", IssueDate: "2026-03-01", Total: 700 * money.Lei},
+		{SerialCode: "INV-006", ClientName: "Hooli", IssueDate: "2026-03-02", Total: 700 * money.Lei},
+		{SerialCode: "INV-000", ClientName: "ACME Corp", IssueDate: "2025-12-01", Total: 10 * money.Lei, IsPaid: true},
+		{SerialCode: "INV-007", ClientName: "Later SRL", IssueDate: "2026-04-01", Total: 300 * money.Lei},
+	}
+	expenses := []client.Expense{
+		{SupplierName: "Hosting SRL", PurchaseDate: "2026-02-27", Total: money.MustParse("99.99")},
+		{SupplierName: "Other Host", PurchaseDate: "2026-02-28", Total: money.MustParse("99.99")},
+		{SupplierName: "Old", PurchaseDate: "2025-06-01", Total: 5 * money.Lei},
+	}
+	s := &Statement{Transactions: []Transaction{
+		// An amount match cannot take the invoice named on a later transfer
+		{Date: "2026-03-05", Amount: money.MustParse("250.25"), Currency: "EUR", Description: "payment"},
+		{Date: "2026-03-06", Amount: money.MustParse("250.25"), Currency: "EUR", Description: "GLOBEX inv 002"},
+		{Date: "2026-03-10", Amount: money.MustParse("1000.50"), Currency: "RON", Description: "plata INV001"},
+		{Date: "2026-03-11", Amount: 500 * money.Lei, Currency: "RON", Counterparty: "INITECH SRL"},
+		{Date: "2026-03-12", Amount: 700 * money.Lei, Currency: "RON", Description: "Hooli"},
+		{Date: "2026-03-13", Amount: 300 * money.Lei, Currency: "RON", Description: "avans"},
+		{Date: "2026-03-14", Amount: 999 * money.Lei, Currency: "RON", Description: "INV-003"},
+		{Date: "2026-02-28", Amount: money.MustParse("-99.99"), Currency: "RON", Description: "POS HOSTING SRL"},
+		{Date: "2026-03-02", Amount: -5 * money.Lei, Currency: "RON", Description: "Comision"},
+	}}
+	r := Reconcile(s, revenues, expenses)
+
+	got := map[string]string{}
+	for _, m := range r.Invoices {
+		got[m.Invoice.SerialCode] = m.Transaction.Date + " " + m.By
+	}
+	want := map[string]string{
+		"INV-001": "2026-03-10 " + ByReference,
+		"INV-002": "2026-03-06 " + ByReference,
+		"INV-003": "2026-03-11 " + ByPayer,
+	}
+	if len(got) != len(want) {
+		t.Errorf("matched invoices = %v", got)
+	}
+	for serial, w := range want {
+		if got[serial] != w {
+			t.Errorf("%s matched %q, want %q", serial, got[serial], w)
+		}
+	}
+
+	notes := map[string]string{}
+	for _, u := range r.UnmatchedCredits {
+		notes[u.Transaction.Date] = u.Note
+	}
+	for date, note := range map[string]string{
+		"2026-03-05": "no open invoice of this amount",
+		"2026-03-12": "could be INV-005, INV-006",
+		"2026-03-13": "no open invoice of this amount", // INV-007 was issued later
+		"2026-03-14": "mentions INV-003 of 500.00 RON",
+	} {
+		if notes[date] != note {
+			t.Errorf("credit %s note %q, want %q", date, notes[date], note)
+		}
+	}
+
+	if len(r.Expenses) != 1 || r.Expenses[0].Expense.SupplierName != "Hosting SRL" || r.Expenses[0].By != BySupplier {
+		t.Errorf("matched expenses = %+v", r.Expenses)
+	}
+	if len(r.UnmatchedDebits) != 1 || r.UnmatchedDebits[0].Transaction.Amount != -5*money.Lei {
+		t.Errorf("unmatched debits = %+v", r.UnmatchedDebits)
+	}
+	if len(r.StillOpen) != 4 || r.StillOpen[0].SerialCode != "INV-004" {
+		t.Errorf("still open = %+v", r.StillOpen)
+	}
+}
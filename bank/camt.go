@@ -0,0 +1,115 @@
+package bank
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// camtDocument is the part of a CAMT.053 (BkToCstmrStmt) document the
+// reconciliation needs. Namespaces are ignored so every version parses
+type camtDocument struct {
+	Statements []struct {
+		Account struct {
+			IBAN  string `xml:"Id>IBAN"`
+			Other string `xml:"Id>Othr>Id"`
+		} `xml:"Acct"`
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtEntry struct {
+	Amount    camtAmount `xml:"Amt"`
+	Indicator string     `xml:"CdtDbtInd"`
+	Reversal  bool       `xml:"RvslInd"`
+	Status    struct {
+		Value string `xml:",chardata"`
+		Code  string `xml:"Cd"` // since camt.053.001.08
+	} `xml:"Sts"`
+	BookingDate string `xml:"BookgDt>Dt"`
+	BookingTime string `xml:"BookgDt>DtTm"`
+	ValueDate   string `xml:"ValDt>Dt"`
+	Reference   string `xml:"AcctSvcrRef"`
+	Info        string `xml:"AddtlNtryInf"`
+	Details     []struct {
+		EndToEndID  string   `xml:"Refs>EndToEndId"`
+		Debtor      string   `xml:"RltdPties>Dbtr>Nm"`
+		DebtorPty   string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+		Creditor    string   `xml:"RltdPties>Cdtr>Nm"`
+		CreditorPty string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+		Remittance  []string `xml:"RmtInf>Ustrd"`
+		Structured  []string `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+// parseCAMT053 reads the booked entries of every statement in the document.
+// The counterparty is the debtor of a credit and the creditor of a debit
+func parseCAMT053(data []byte) (*Statement, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid CAMT.053: %w", err)
+	}
+	s := &Statement{Format: "CAMT.053"}
+
+	for _, st := range doc.Statements {
+		if s.Account == "" {
+			s.Account = strings.TrimSpace(st.Account.IBAN + st.Account.Other)
+		}
+		for _, e := range st.Entries {
+			// Pending entries may still change
+			if status := strings.TrimSpace(e.Status.Value + e.Status.Code); status != "" && status != "BOOK" {
+				continue
+			}
+			raw := e.BookingDate
+			if raw == "" {
+				raw = e.BookingTime
+			}
+			if raw == "" {
+				raw = e.ValueDate
+			}
+			date, err := parseDate(raw)
+			if err != nil {
+				return nil, err
+			}
+			amount, err := parseAmount(e.Amount.Value)
+			if err != nil {
+				return nil, err
+			}
+			// A reversed credit takes the money back out
+			if (e.Indicator == "DBIT") != e.Reversal {
+				amount = -amount
+			}
+
+			t := Transaction{Date: date, Amount: amount, Currency: strings.ToUpper(e.Amount.Currency), Reference: e.Reference}
+			var text []string
+			for _, d := range e.Details {
+				party := d.Debtor + d.DebtorPty
+				if amount < 0 {
+					party = d.Creditor + d.CreditorPty
+				}
+				if t.Counterparty == "" {
+					t.Counterparty = strings.TrimSpace(party)
+				}
+				if id := strings.TrimSpace(d.EndToEndID); id != "" && id != "NOTPROVIDED" && t.Reference == "" {
+					t.Reference = id
+				}
+				text = append(text, d.Remittance...)
+				text = append(text, d.Structured...)
+			}
+			if len(text) == 0 {
+				text = append(text, e.Info)
+			}
+			t.Description = strings.Join(strings.Fields(strings.Join(text, " ")), " ")
+			if t.Currency == "" {
+				t.Currency = "RON"
+			}
+			s.Transactions = append(s.Transactions, t)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,271 @@
+package bank
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"solo-cli/diacritics"
+	"solo-cli/money"
+)
+
+// csvLayout names the columns of a bank's CSV export. Each field lists the
+// accepted header names, lowercase and without diacritics
+type csvLayout struct {
+	name string
+	// marker is a header only this bank uses, to recognize the export
+	marker       string
+	date         []string
+	description  []string
+	reference    []string
+	counterparty []string
+	debit        []string
+	credit       []string
+	amount       []string // signed, for exports without debit and credit columns
+	currency     []string
+}
+
+// csvLayouts are the known exports. The generic layout accepts the union
+// of the header names, so most other banks work as well
+var csvLayouts = []csvLayout{
+	{
+		// Banca Transilvania, BT24 "Extras de cont" export
+		name:        "BT",
+		marker:      "referinta tranzactiei",
+		date:        []string{"data tranzactie"},
+		description: []string{"descriere"},
+		reference:   []string{"referinta tranzactiei"},
+		debit:       []string{"debit"},
+		credit:      []string{"credit"},
+		currency:    []string{"valuta", "moneda"},
+	},
+	{
+		// ING Home'Bank: details span several rows, only the first dated
+		name:        "ING",
+		marker:      "detalii tranzactie",
+		date:        []string{"data"},
+		description: []string{"detalii tranzactie"},
+		debit:       []string{"debit"},
+		credit:      []string{"credit"},
+		currency:    []string{"valuta", "moneda"},
+	},
+	{
+		// BCR George: one signed amount column
+		name:         "BCR",
+		marker:       "data finalizarii tranzactiei",
+		date:         []string{"data finalizarii tranzactiei"},
+		description:  []string{"descriere", "detalii"},
+		reference:    []string{"referinta"},
+		counterparty: []string{"nume partener"},
+		amount:       []string{"suma"},
+		currency:     []string{"valuta", "moneda"},
+	},
+}
+
+var genericLayout = csvLayout{
+	name:         "CSV",
+	date:         []string{"data tranzactie", "data tranzactiei", "data operatiunii", "data inregistrare", "data", "date", "booking date", "transaction date"},
+	description:  []string{"descriere", "detalii tranzactie", "detalii", "explicatii", "description", "details"},
+	reference:    []string{"referinta tranzactiei", "referinta", "reference"},
+	counterparty: []string{"nume partener", "beneficiar/ordonator", "ordonator/beneficiar", "partener", "counterparty", "payee"},
+	debit:        []string{"debit", "suma debit"},
+	credit:       []string{"credit", "suma credit"},
+	amount:       []string{"suma", "amount"},
+	currency:     []string{"valuta", "moneda", "currency"},
+}
+
+func normalizeHeader(s string) string {
+	return diacritics.Fold(strings.Trim(s, " \"\ufeff"))
+}
+
+// columns maps the layout fields to column indexes, -1 when missing
+type columns struct {
+	date, description, reference, counterparty, debit, credit, amount, currency int
+}
+
+func (l csvLayout) columns(header []string) columns {
+	find := func(names []string) int {
+		for _, name := range names {
+			for i, h := range header {
+				if h == name {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+	return columns{
+		date:         find(l.date),
+		description:  find(l.description),
+		reference:    find(l.reference),
+		counterparty: find(l.counterparty),
+		debit:        find(l.debit),
+		credit:       find(l.credit),
+		amount:       find(l.amount),
+		currency:     find(l.currency),
+	}
+}
+
+// usable reports whether a header has a date and some amount
+func (c columns) usable() bool {
+	return c.date >= 0 && (c.amount >= 0 || c.debit >= 0 || c.credit >= 0)
+}
+
+// layoutFor picks the bank layout from its marker column, or the one forced
+// by name, falling back to the generic one
+func layoutFor(header []string, force string) (csvLayout, error) {
+	if force != "" {
+		for _, l := range csvLayouts {
+			if strings.EqualFold(l.name, force) {
+				return l, nil
+			}
+		}
+		return csvLayout{}, fmt.Errorf("unknown bank '%s' (want bt, ing or bcr)", force)
+	}
+	for _, l := range csvLayouts {
+		for _, h := range header {
+			if h == l.marker {
+				return l, nil
+			}
+		}
+	}
+	return genericLayout, nil
+}
+
+// statementCurrency finds the account currency in the preamble lines some
+// banks put before the header ("Valuta: RON"), RON otherwise
+func statementCurrency(preamble [][]string) string {
+	for _, row := range preamble {
+		for i, cell := range row {
+			key, value, _ := strings.Cut(cell, ":")
+			key = normalizeHeader(key)
+			if key != "valuta" && key != "moneda" && key != "currency" {
+				continue
+			}
+			if value = strings.TrimSpace(value); value == "" && i+1 < len(row) {
+				value = strings.TrimSpace(row[i+1])
+			}
+			if len(value) == 3 {
+				return strings.ToUpper(value)
+			}
+		}
+	}
+	return "RON"
+}
+
+// parseCSV finds the header row (banks put account details above it), then
+// reads one transaction per dated row. Undated rows continue the details of
+// the row above
+func parseCSV(data []byte, force string) (*Statement, error) {
+	// Exports with decimal commas separate the columns with semicolons
+	delimiter := ','
+	if bytes.Count(data, []byte(";")) > bytes.Count(data, []byte(",")) {
+		delimiter = ';'
+	}
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	headerRow := -1
+	var layout csvLayout
+	var cols columns
+	for i, row := range rows {
+		header := make([]string, len(row))
+		for j, cell := range row {
+			header[j] = normalizeHeader(cell)
+		}
+		l, err := layoutFor(header, force)
+		if err != nil {
+			return nil, err
+		}
+		if c := l.columns(header); c.usable() {
+			headerRow, layout, cols = i, l, c
+			break
+		}
+	}
+	if headerRow < 0 {
+		return nil, fmt.Errorf("no header with a date and an amount column found")
+	}
+
+	s := &Statement{Format: layout.name + " CSV"}
+	currency := statementCurrency(rows[:headerRow])
+	cell := func(row []string, i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	for n, row := range rows[headerRow+1:] {
+		line := headerRow + n + 2
+		rawDate := cell(row, cols.date)
+		if rawDate == "" {
+			// ING continues the details on the following rows, with every
+			// other column empty
+			filled := 0
+			for _, c := range row {
+				if strings.TrimSpace(c) != "" {
+					filled++
+				}
+			}
+			if extra := cell(row, cols.description); extra != "" && filled == 1 && len(s.Transactions) > 0 {
+				t := &s.Transactions[len(s.Transactions)-1]
+				t.Description = strings.TrimSpace(t.Description + " " + extra)
+			}
+			continue
+		}
+		date, err := parseDate(rawDate)
+		if err != nil {
+			// Totals and closing balance lines below the transactions
+			continue
+		}
+
+		var amount money.Money
+		if cols.amount >= 0 && cell(row, cols.amount) != "" {
+			if amount, err = parseAmount(cell(row, cols.amount)); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		} else {
+			debit, err := parseAmount(cell(row, cols.debit))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			credit, err := parseAmount(cell(row, cols.credit))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			// Some exports sign the debits, some don't
+			amount = abs(credit) - abs(debit)
+		}
+		if amount == 0 {
+			continue
+		}
+
+		t := Transaction{
+			Date:         date,
+			Amount:       amount,
+			Currency:     currency,
+			Description:  strings.Join(strings.Fields(cell(row, cols.description)), " "),
+			Reference:    cell(row, cols.reference),
+			Counterparty: cell(row, cols.counterparty),
+		}
+		if cur := strings.ToUpper(cell(row, cols.currency)); len(cur) == 3 {
+			t.Currency = cur
+		}
+		s.Transactions = append(s.Transactions, t)
+	}
+	return s, nil
+}
+
+func abs(m money.Money) money.Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
@@ -0,0 +1,85 @@
+package bank
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	mt940Tag = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+	// :61: value date, optional entry date, debit/credit mark (R for a
+	// reversal), optional funds code, amount, transaction type and the
+	// customer // bank references
+	mt940Line = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d*)([A-Z0-9]{4})?([^/]*)(?://(.*))?`)
+	// Subfield codes (?20, ?32) some banks structure :86: with
+	mt940Subfield = regexp.MustCompile(`\?\d{2}`)
+)
+
+// parseMT940 reads the :61: statement lines with the :86: details that
+// follow them. The currency comes from the :60F: opening balance
+func parseMT940(data []byte) (*Statement, error) {
+	s := &Statement{Format: "MT940"}
+
+	// Join the continuation lines onto their tag
+	type field struct{ tag, value string }
+	var fields []field
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \r")
+		switch {
+		case line == "", line == "-", strings.HasPrefix(line, "{"), strings.HasPrefix(line, "-}"):
+			continue
+		}
+		if m := mt940Tag.FindStringSubmatch(line); m != nil {
+			fields = append(fields, field{m[1], m[2]})
+		} else if len(fields) > 0 {
+			fields[len(fields)-1].value += "\n" + line
+		}
+	}
+
+	currency := "RON"
+	for i, f := range fields {
+		switch f.tag {
+		case "25":
+			s.Account = strings.TrimSpace(f.value)
+		case "60F", "60M":
+			// C260101RON1000,00
+			if len(f.value) >= 10 {
+				currency = f.value[7:10]
+			}
+		case "61":
+			// The second line, when there is one, holds supplementary details
+			first, _, _ := strings.Cut(f.value, "\n")
+			m := mt940Line.FindStringSubmatch(first)
+			if m == nil {
+				return nil, fmt.Errorf("invalid :61: line %q", f.value)
+			}
+			date, err := parseDate("20" + m[1][:2] + "-" + m[1][2:4] + "-" + m[1][4:6])
+			if err != nil {
+				return nil, err
+			}
+			amount, err := parseAmount(m[5])
+			if err != nil {
+				return nil, err
+			}
+			if m[3] == "D" || m[3] == "RC" {
+				amount = -amount
+			}
+			ref := strings.TrimSpace(m[7])
+			if ref == "NONREF" {
+				ref = ""
+			}
+			if ref == "" {
+				ref = strings.TrimSpace(m[8])
+			}
+			s.Transactions = append(s.Transactions, Transaction{Date: date, Amount: amount, Currency: currency, Reference: ref})
+		case "86":
+			// Only the details right after a :61: belong to a transaction
+			if n := len(s.Transactions); n > 0 && i > 0 && fields[i-1].tag == "61" {
+				text := mt940Subfield.ReplaceAllString(f.value, " ")
+				s.Transactions[n-1].Description = strings.Join(strings.Fields(text), " ")
+			}
+		}
+	}
+	return s, nil
+}
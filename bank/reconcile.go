@@ -0,0 +1,260 @@
+package bank
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+// ExpenseWindow is how many days a debit may be from the purchase date of
+// the expense it pays
+const ExpenseWindow = 30
+
+// How a transaction was matched
+const (
+	ByReference = "reference"           // the invoice number is on the transfer
+	ByPayer     = "amount and payer"    // several invoices of the amount, one of the client
+	ByAmount    = "amount"              // the only open invoice or expense of the amount
+	BySupplier  = "amount and supplier" // the closest expense of the amount from the supplier
+)
+
+// InvoiceMatch is a credit that pays an invoice still open in SOLO
+type InvoiceMatch struct {
+	Transaction Transaction    `json:"transaction"`
+	Invoice     client.Revenue `json:"invoice"`
+	By          string         `json:"matched_by"`
+}
+
+// ExpenseMatch is a debit that pays an expense
+type ExpenseMatch struct {
+	Transaction Transaction    `json:"transaction"`
+	Expense     client.Expense `json:"expense"`
+	By          string         `json:"matched_by"`
+}
+
+// Unmatched is a transaction with no invoice or expense, with a hint why
+type Unmatched struct {
+	Transaction Transaction `json:"transaction"`
+	Note        string      `json:"note,omitempty"`
+}
+
+// Reconciliation pairs a statement with the open invoices and the expenses
+type Reconciliation struct {
+	Invoices         []InvoiceMatch   `json:"paid_invoices"`
+	Expenses         []ExpenseMatch   `json:"matched_expenses"`
+	UnmatchedCredits []Unmatched      `json:"unmatched_credits"`
+	UnmatchedDebits  []Unmatched      `json:"unmatched_debits"`
+	StillOpen        []client.Revenue `json:"still_unpaid"` // open invoices no credit paid
+}
+
+// normalize keeps the letters and digits, uppercased, so INV-001 matches
+// "inv 001" and "INV001"
+func normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, s)
+}
+
+// tokens splits text into normalized words
+func tokens(s string) []string {
+	var words []string
+	for _, w := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		words = append(words, strings.ToUpper(w))
+	}
+	return words
+}
+
+// mentions reports whether an invoice number appears in text. Numbers that
+// are only digits must be a whole word, so 12 does not match 2012; shorter
+// than three characters never match
+func mentions(text, serial string) bool {
+	n := normalize(serial)
+	if len(n) < 3 {
+		return false
+	}
+	if strings.IndexFunc(n, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0 {
+		return strings.Contains(normalize(text), n)
+	}
+	for _, w := range tokens(text) {
+		if strings.TrimLeft(w, "0") == strings.TrimLeft(n, "0") {
+			return true
+		}
+	}
+	return false
+}
+
+// legalForms are left out when looking for a name in a transfer
+var legalForms = map[string]bool{
+	"SRL": true, "SA": true, "PFA": true, "II": true, "IF": true, "LTD": true, "LLC": true,
+	"GMBH": true, "INC": true, "CORP": true, "AB": true, "BV": true, "SAS": true, "SPA": true,
+}
+
+// namedIn reports whether a significant word of name (four letters or more,
+// not a legal form) appears in text
+func namedIn(text, name string) bool {
+	words := map[string]bool{}
+	for _, w := range tokens(text) {
+		words[w] = true
+	}
+	for _, w := range tokens(name) {
+		if len([]rune(w)) >= 4 && !legalForms[w] && words[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// Reconcile matches the statement's credits to the invoices SOLO has as
+// unpaid and its debits to the expenses. An invoice number on the transfer
+// with the invoice amount is a match; otherwise the amount and currency
+// must single out one invoice issued by the transfer date, if need be by
+// the payer's name. Debits match an expense of the same amount within
+// ExpenseWindow days of its purchase, preferring the supplier named on the
+// transfer and then the closest date. Each invoice and expense is used once
+func Reconcile(s *Statement, revenues []client.Revenue, expenses []client.Expense) *Reconciliation {
+	r := &Reconciliation{}
+
+	var open []client.Revenue
+	for _, inv := range revenues {
+		if !inv.IsPaid && (inv.Status == nil || !inv.Status.IsCancelled) {
+			open = append(open, inv)
+		}
+	}
+	paid := make([]bool, len(open))
+	used := make([]bool, len(expenses))
+
+	var credits, debits []Transaction
+	for _, t := range s.Transactions {
+		if t.Credit() {
+			credits = append(credits, t)
+		} else {
+			debits = append(debits, t)
+		}
+	}
+
+	// Invoice numbers first, so an amount match cannot take an invoice a
+	// later transfer names
+	creditDone := make([]bool, len(credits))
+	notes := make([]string, len(credits))
+	for ci, t := range credits {
+		for i, inv := range open {
+			if paid[i] || !mentions(t.text(), inv.SerialCode) {
+				continue
+			}
+			if inv.Total == t.Amount && inv.Currency.ISOCode() == t.Currency {
+				paid[i], creditDone[ci] = true, true
+				r.Invoices = append(r.Invoices, InvoiceMatch{t, inv, ByReference})
+				break
+			}
+			notes[ci] = fmt.Sprintf("mentions %s of %s %s", inv.SerialCode, inv.Total, inv.Currency.ISOCode())
+		}
+	}
+
+	for ci, t := range credits {
+		if creditDone[ci] {
+			continue
+		}
+		day, _ := client.ParseDay(t.Date)
+		var candidates []int
+		for i, inv := range open {
+			issued, _ := client.ParseDay(inv.IssueDate)
+			if !paid[i] && inv.Total == t.Amount && inv.Currency.ISOCode() == t.Currency && !issued.After(day) {
+				candidates = append(candidates, i)
+			}
+		}
+		by := ByAmount
+		if len(candidates) > 1 {
+			var named []int
+			for _, i := range candidates {
+				if namedIn(t.text(), open[i].ClientName) {
+					named = append(named, i)
+				}
+			}
+			if len(named) > 0 {
+				candidates, by = named, ByPayer
+			}
+		}
+
+		switch len(candidates) {
+		case 1:
+			paid[candidates[0]] = true
+			r.Invoices = append(r.Invoices, InvoiceMatch{t, open[candidates[0]], by})
+		case 0:
+			if notes[ci] == "" {
+				notes[ci] = "no open invoice of this amount"
+			}
+			r.UnmatchedCredits = append(r.UnmatchedCredits, Unmatched{t, notes[ci]})
+		default:
+			var serials []string
+			for _, i := range candidates {
+				serials = append(serials, open[i].SerialCode)
+			}
+			r.UnmatchedCredits = append(r.UnmatchedCredits, Unmatched{t, "could be " + strings.Join(serials, ", ")})
+		}
+	}
+
+	for _, t := range debits {
+		day, _ := client.ParseDay(t.Date)
+		best, bestDays, tied := -1, 0, false
+		bestNamed := false
+		for i, e := range expenses {
+			if used[i] || e.Total != -t.Amount || e.Currency.ISOCode() != t.Currency {
+				continue
+			}
+			bought, _ := client.ParseDay(e.PurchaseDate)
+			days := int(day.Sub(bought).Hours() / 24)
+			if days < 0 {
+				days = -days
+			}
+			if days > ExpenseWindow {
+				continue
+			}
+			named := namedIn(t.text(), e.SupplierName)
+			switch {
+			case best < 0, named && !bestNamed, named == bestNamed && days < bestDays:
+				best, bestDays, bestNamed, tied = i, days, named, false
+			case named == bestNamed && days == bestDays:
+				tied = true
+			}
+		}
+		if best < 0 || tied {
+			note := fmt.Sprintf("no expense of this amount within %d days", ExpenseWindow)
+			if tied {
+				note = "several expenses of this amount"
+			}
+			r.UnmatchedDebits = append(r.UnmatchedDebits, Unmatched{t, note})
+			continue
+		}
+		used[best] = true
+		by := ByAmount
+		if bestNamed {
+			by = BySupplier
+		}
+		r.Expenses = append(r.Expenses, ExpenseMatch{t, expenses[best], by})
+	}
+
+	for i, inv := range open {
+		if !paid[i] {
+			r.StillOpen = append(r.StillOpen, inv)
+		}
+	}
+	sort.SliceStable(r.Invoices, func(i, j int) bool { return r.Invoices[i].Transaction.Date < r.Invoices[j].Transaction.Date })
+	sort.SliceStable(r.StillOpen, func(i, j int) bool { return r.StillOpen[i].IssueDate < r.StillOpen[j].IssueDate })
+	return r
+}
+
+// PaidRON totals the matched invoices in RON
+func (r *Reconciliation) PaidRON(ron func(client.Revenue) money.Money) money.Money {
+	var total money.Money
+	for _, m := range r.Invoices {
+		total += ron(m.Invoice)
+	}
+	return total
+}
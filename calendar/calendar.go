@@ -0,0 +1,264 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/money"
+	"solo-cli/taxes"
+)
+
+// Deadline is one dated occurrence of a deadline rule
+type Deadline struct {
+	Date       time.Time
+	Rule       config.DeadlineRule
+	IncomeYear int         // year whose income the payment settles (deadline year - 1)
+	Amount     money.Money // amount due, only meaningful when HasAmount
+	HasAmount  bool
+}
+
+// Upcoming expands the rules into dated deadlines between from (inclusive)
+// and from + months, sorted by date. breakdownFor returns the tax breakdown
+// for an income year and is only called for rules with an amount; a nil
+// result leaves the deadline without an amount
+func Upcoming(rules []config.DeadlineRule, from time.Time, months int, breakdownFor func(year int) *taxes.TaxBreakdown) []Deadline {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, months, 0)
+
+	var deadlines []Deadline
+	for _, rule := range rules {
+		for _, date := range occurrences(rule, from, to) {
+			d := Deadline{Date: date, Rule: rule, IncomeYear: date.Year() - 1}
+			if rule.Amount != "" && breakdownFor != nil {
+				if b := breakdownFor(d.IncomeYear); b != nil {
+					d.Amount = AmountFor(rule.Amount, b)
+					d.HasAmount = true
+				}
+			}
+			deadlines = append(deadlines, d)
+		}
+	}
+
+	sort.SliceStable(deadlines, func(i, j int) bool {
+		return deadlines[i].Date.Before(deadlines[j].Date)
+	})
+	return deadlines
+}
+
+// occurrences returns the rule's due dates in [from, to), shifted off
+// weekends. Nominal dates are generated from the year before from so a
+// date shifted forward into the window is not missed
+func occurrences(rule config.DeadlineRule, from, to time.Time) []time.Time {
+	var dates []time.Time
+	add := func(year int, month time.Month) {
+		d := nextWorkingDay(dueDate(year, month, rule.Day))
+		if !d.Before(from) && d.Before(to) {
+			dates = append(dates, d)
+		}
+	}
+
+	for year := from.Year() - 1; year <= to.Year(); year++ {
+		switch rule.Frequency {
+		case "yearly":
+			if rule.Month >= 1 && rule.Month <= 12 {
+				add(year, time.Month(rule.Month))
+			}
+		case "quarterly":
+			offset := rule.Month
+			if offset < 1 {
+				offset = 1
+			}
+			// Quarters end in March, June, September and December
+			for q := 3; q <= 12; q += 3 {
+				month := q + offset
+				add(year+(month-1)/12, time.Month((month-1)%12+1))
+			}
+		case "monthly":
+			for mo := time.January; mo <= time.December; mo++ {
+				add(year, mo)
+			}
+		}
+	}
+	return dates
+}
+
+// dueDate builds the nominal date, clamping the day to the month's length
+// so day 31 means the last day in shorter months
+func dueDate(year int, month time.Month, day int) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > last {
+		day = last
+	}
+	if day < 1 {
+		day = 1
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// nextWorkingDay moves a deadline falling on a weekend or a public holiday
+// to the next working day, as the Codul de procedură fiscală does for
+// non-working days
+func nextWorkingDay(d time.Time) time.Time {
+	for d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || isHoliday(d) {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// isHoliday reports the public holidays of the Codul muncii: 1-2, 6, 7 and
+// 24 January, Orthodox Good Friday, Easter and Pentecost (Sunday and
+// Monday), 1 May, 1 June, 15 August, 30 November, 1 December and 25-26
+// December. 6 and 7 January are holidays since 2024
+func isHoliday(d time.Time) bool {
+	switch d.Month() {
+	case time.January:
+		switch d.Day() {
+		case 1, 2, 24:
+			return true
+		case 6, 7:
+			return d.Year() >= 2024
+		}
+	case time.May, time.June:
+		if d.Day() == 1 {
+			return true
+		}
+	case time.August:
+		return d.Day() == 15
+	case time.November:
+		return d.Day() == 30
+	case time.December:
+		return d.Day() == 1 || d.Day() == 25 || d.Day() == 26
+	}
+	easter := orthodoxEaster(d.Year())
+	for _, offset := range []int{-2, 0, 1, 49, 50} {
+		if d.Equal(easter.AddDate(0, 0, offset)) {
+			return true
+		}
+	}
+	return false
+}
+
+// orthodoxEaster returns the Orthodox Easter Sunday of a year, computed on
+// the Julian calendar (Meeus) and moved to the Gregorian one, valid for
+// 1900-2099
+func orthodoxEaster(year int) time.Time {
+	a, b, c := year%4, year%7, year%19
+	d := (19*c + 15) % 30
+	e := (2*a + 4*b - d + 34) % 7
+	month := (d + e + 114) / 31
+	day := (d+e+114)%31 + 1
+	return time.Date(year, time.Month(month), day+13, 0, 0, 0, 0, time.UTC)
+}
+
+// YearBreakdowns returns a breakdownFor callback for Upcoming that computes
+// the tax breakdown of an income year from its summary and declared other
+// income, fetching each year once. Years that fail to load yield nil so
+// their deadlines show no amount
+func YearBreakdowns(summaryFor func(year int) (*client.Summary, error), taxCfg *config.TaxConfig, extra []config.ExtraIncome) func(int) *taxes.TaxBreakdown {
+	cache := map[int]*taxes.TaxBreakdown{}
+	return func(year int) *taxes.TaxBreakdown {
+		if b, ok := cache[year]; ok {
+			return b
+		}
+		var b *taxes.TaxBreakdown
+		if summary, err := summaryFor(year); err == nil {
+			b = taxes.CalculateWithIncome(summary.TotalRevenues, summary.TotalDeductibleExpenses, config.ExtraIncomeForYear(extra, year), taxCfg)
+		}
+		cache[year] = b
+		return b
+	}
+}
+
+// AmountFor picks the amount a rule refers to out of a tax breakdown. Tax
+// already withheld at source is not due, so it is left out
+func AmountFor(kind string, b *taxes.TaxBreakdown) money.Money {
+	switch kind {
+	case "income_tax":
+		return b.IncomeTax + b.OtherIncomeTax
+	case "cas":
+		return b.CAS.Amount
+	case "cass":
+		return b.CASS.Amount + b.OtherCASS.Amount
+	case "contributions":
+		return b.CAS.Amount + b.CASS.Amount + b.OtherCASS.Amount
+	case "total":
+		return b.TotalTaxes - b.WithheldTax
+	}
+	return 0
+}
+
+// WriteICS writes the deadlines as an iCalendar (RFC 5545) document of
+// all-day events. UIDs are derived from the rule ID and date so
+// re-importing updates events instead of duplicating them
+func WriteICS(w io.Writer, deadlines []Deadline, now time.Time) error {
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldLine(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//solo-cli//fiscal calendar//RO")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:SOLO termene fiscale")
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, d := range deadlines {
+		description := d.Rule.Description
+		if d.HasAmount {
+			description = strings.TrimSpace(fmt.Sprintf("%s\nDe plată pentru %d: %s", description, d.IncomeYear, taxes.FormatRON(d.Amount)))
+		}
+		summary := d.Rule.Title
+		if d.HasAmount {
+			summary = fmt.Sprintf("%s (%s)", summary, taxes.FormatRON(d.Amount))
+		}
+
+		line("BEGIN:VEVENT")
+		line(fmt.Sprintf("UID:%s-%s@solo-cli", d.Rule.ID, d.Date.Format("20060102")))
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + d.Date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + d.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escapeText(summary))
+		if description != "" {
+			line("DESCRIPTION:" + escapeText(description))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeText escapes an iCalendar TEXT value
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// foldLine splits content lines longer than 75 octets, continuing with a
+// leading space. Folds never split a multi-byte UTF-8 character
+func foldLine(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s
+	}
+	var b strings.Builder
+	width, max := 0, limit
+	for _, r := range s {
+		size := len(string(r))
+		if width+size > max {
+			b.WriteString("\r\n ")
+			// Continuation lines lose one octet to the leading space
+			width, max = 0, limit-1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
@@ -0,0 +1,178 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"solo-cli/config"
+	"solo-cli/money"
+	"solo-cli/taxes"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// D212 is due 25 May for the previous year's income, with the amount taken
+// from that year's breakdown
+func TestUpcomingYearlyWithAmount(t *testing.T) {
+	rules := []config.DeadlineRule{{ID: "d212", Title: "D212", Frequency: "yearly", Month: 5, Day: 25, Amount: "total"}}
+
+	var asked []int
+	got := Upcoming(rules, date(2026, 1, 10), 12, func(year int) *taxes.TaxBreakdown {
+		asked = append(asked, year)
+		return &taxes.TaxBreakdown{TotalTaxes: 5700 * money.Lei}
+	})
+
+	if len(got) != 1 {
+		t.Fatalf("got %d deadlines, want 1", len(got))
+	}
+	d := got[0]
+	// 25 May 2026 is a Monday, no shift
+	if !d.Date.Equal(date(2026, 5, 25)) {
+		t.Errorf("date = %s, want 2026-05-25", d.Date.Format("2006-01-02"))
+	}
+	if d.IncomeYear != 2025 || !d.HasAmount || d.Amount != 5700*money.Lei {
+		t.Errorf("deadline = %+v, want income year 2025 with 5700 due", d)
+	}
+	if len(asked) != 1 || asked[0] != 2025 {
+		t.Errorf("breakdown requested for %v, want [2025]", asked)
+	}
+}
+
+// Weekend deadlines move to Monday, and a deadline already passed is not listed
+func TestUpcomingWeekendShiftAndWindow(t *testing.T) {
+	rules := []config.DeadlineRule{{ID: "d212", Frequency: "yearly", Month: 5, Day: 25}}
+
+	// 25 May 2024 is a Saturday
+	got := Upcoming(rules, date(2024, 1, 1), 12, nil)
+	if len(got) != 1 || !got[0].Date.Equal(date(2024, 5, 27)) {
+		t.Fatalf("got %v, want a single 2024-05-27 deadline", got)
+	}
+
+	if got := Upcoming(rules, date(2024, 5, 28), 6, nil); len(got) != 0 {
+		t.Errorf("deadline after the window start listed: %v", got)
+	}
+}
+
+// Public holidays move deadlines like weekends do
+func TestUpcomingHolidayShift(t *testing.T) {
+	tests := []struct {
+		month, day int
+		want       time.Time
+	}{
+		{12, 25, date(2026, 12, 28)}, // Christmas Friday, then the weekend
+		{4, 10, date(2026, 4, 14)},   // Good Friday, Easter and Easter Monday
+		{6, 1, date(2026, 6, 2)},     // Children's Day and Pentecost Monday
+		{1, 6, date(2026, 1, 8)},     // Boboteaza and Sf. Ioan
+		{8, 14, date(2026, 8, 14)},   // the day before Sfânta Maria is a working day
+	}
+	for _, tt := range tests {
+		rules := []config.DeadlineRule{{ID: "r", Frequency: "yearly", Month: tt.month, Day: tt.day}}
+		got := Upcoming(rules, date(2026, 1, 1), 12, nil)
+		if len(got) != 1 || !got[0].Date.Equal(tt.want) {
+			t.Errorf("%d-%02d: got %v, want %s", tt.month, tt.day, got, tt.want.Format("2006-01-02"))
+		}
+	}
+	for year, want := range map[int]time.Time{2024: date(2024, 5, 5), 2025: date(2025, 4, 20), 2026: date(2026, 4, 12), 2027: date(2027, 5, 2)} {
+		if got := orthodoxEaster(year); !got.Equal(want) {
+			t.Errorf("orthodoxEaster(%d) = %s, want %s", year, got.Format("2006-01-02"), want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestUpcomingQuarterlyAndMonthly(t *testing.T) {
+	rules := []config.DeadlineRule{
+		{ID: "d300", Frequency: "quarterly", Month: 1, Day: 25},
+		{ID: "spv", Frequency: "monthly", Day: 31},
+	}
+
+	got := Upcoming(rules, date(2026, 1, 1), 12, nil)
+
+	var quarterly, monthly []string
+	for _, d := range got {
+		if d.Rule.ID == "d300" {
+			quarterly = append(quarterly, d.Date.Format("2006-01-02"))
+		} else {
+			monthly = append(monthly, d.Date.Format("2006-01-02"))
+		}
+	}
+	// Q4 2025 is due in January 2026, then April, July and October
+	wantQ := []string{"2026-01-26", "2026-04-27", "2026-07-27", "2026-10-26"}
+	if strings.Join(quarterly, ",") != strings.Join(wantQ, ",") {
+		t.Errorf("quarterly = %v, want %v", quarterly, wantQ)
+	}
+	if len(monthly) != 12 {
+		t.Fatalf("monthly count = %d, want 12", len(monthly))
+	}
+	// Day 31 clamps to the end of February (Saturday 28 → Monday 2 March)
+	if monthly[1] != "2026-03-02" {
+		t.Errorf("February deadline = %s, want 2026-03-02", monthly[1])
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Date.Before(got[i-1].Date) {
+			t.Fatal("deadlines not sorted by date")
+		}
+	}
+}
+
+func TestAmountFor(t *testing.T) {
+	b := &taxes.TaxBreakdown{
+		CAS:            taxes.ThresholdResult{Amount: 100 * money.Lei},
+		CASS:           taxes.ThresholdResult{Amount: 20 * money.Lei},
+		IncomeTax:      3 * money.Lei,
+		OtherCASS:      taxes.ThresholdResult{Amount: 10 * money.Lei},
+		OtherIncomeTax: 4 * money.Lei,
+		WithheldTax:    50 * money.Lei,
+		TotalTaxes:     187 * money.Lei,
+	}
+	// Withheld tax is never due through the calendar
+	for kind, want := range map[string]money.Money{
+		"income_tax": 7 * money.Lei, "cas": 100 * money.Lei, "cass": 30 * money.Lei,
+		"contributions": 130 * money.Lei, "total": 137 * money.Lei, "bogus": 0,
+	} {
+		if got := AmountFor(kind, b); got != want {
+			t.Errorf("AmountFor(%q) = %v, want %v", kind, got, want)
+		}
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	deadlines := []Deadline{{
+		Date:       date(2026, 5, 25),
+		Rule:       config.DeadlineRule{ID: "d212-plata", Title: "Plată impozit, CAS; CASS", Description: strings.Repeat("descriere lungă ", 10)},
+		IncomeYear: 2025,
+		Amount:     5700 * money.Lei,
+		HasAmount:  true,
+	}}
+
+	var b strings.Builder
+	if err := WriteICS(&b, deadlines, date(2026, 1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:d212-plata-20260525@solo-cli\r\n",
+		"DTSTART;VALUE=DATE:20260525\r\n",
+		"DTEND;VALUE=DATE:20260526\r\n",
+		`SUMMARY:Plată impozit\, CAS\; CASS (5700.00 RON)`,
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("ICS missing %q\n%s", want, out)
+		}
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line not folded (%d octets): %q", len(line), line)
+		}
+	}
+	// Unfolding must restore the description intact
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, "De plată pentru 2025: 5700.00 RON") {
+		t.Errorf("unfolded description missing amount line:\n%s", unfolded)
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"os"
+	"sync"
 	"time"
+
+	"solo-cli/money"
 )
 
 // baseURL is a var so tests can point the client at a mock server. The
@@ -78,14 +81,14 @@ func (c *Client) Login(username, password string) error {
 
 // Summary represents the dashboard summary response
 type Summary struct {
-	Year                    int     `json:"Year"`
-	DisplayCurrency         string  `json:"DisplayCurrency"`
-	TotalRevenues           float64 `json:"TotalRevenues"`
-	TotalDeductibleExpenses float64 `json:"TotalDeductibleExpenses"`
-	HasTaxes                bool    `json:"HasTaxes"`
-	Taxes                   float64 `json:"Taxes"`
-	RevenuesAwaitingReview  int     `json:"RevenuesAwaitingReview"`
-	ExpensesAwaitingReview  int     `json:"ExpensesAwaitingReview"`
+	Year                    int         `json:"Year"`
+	DisplayCurrency         string      `json:"DisplayCurrency"`
+	TotalRevenues           money.Money `json:"TotalRevenues"`
+	TotalDeductibleExpenses money.Money `json:"TotalDeductibleExpenses"`
+	HasTaxes                bool        `json:"HasTaxes"`
+	Taxes                   money.Money `json:"Taxes"`
+	RevenuesAwaitingReview  int         `json:"RevenuesAwaitingReview"`
+	ExpensesAwaitingReview  int         `json:"ExpensesAwaitingReview"`
 }
 
 // GetSummary fetches the dashboard summary for the current year
@@ -106,3 +109,26 @@ func (c *Client) GetSummaryForYear(year int) (*Summary, error) {
 	}
 	return &summary, nil
 }
+
+// GetSummariesForYears fetches the summaries of several years concurrently,
+// returned in the order of years. The first failure is returned
+func (c *Client) GetSummariesForYears(years []int) ([]*Summary, error) {
+	summaries := make([]*Summary, len(years))
+	errs := make([]error, len(years))
+	var wg sync.WaitGroup
+	for i, year := range years {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			summaries[i], errs[i] = c.GetSummaryForYear(year)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", years[i], err)
+		}
+	}
+	return summaries, nil
+}
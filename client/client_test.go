@@ -6,9 +6,13 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"solo-cli/money"
 )
 
 // newTestClient spins up a mock SOLO.ro server and points the client at it
@@ -63,8 +67,8 @@ func TestGetSummary(t *testing.T) {
 		}
 		json.NewEncoder(w).Encode(Summary{
 			Year: 2026, DisplayCurrency: "RON",
-			TotalRevenues: 50000.50, TotalDeductibleExpenses: 20000.25,
-			HasTaxes: true, Taxes: 6000.75,
+			TotalRevenues: money.MustParse("50000.50"), TotalDeductibleExpenses: money.MustParse("20000.25"),
+			HasTaxes: true, Taxes: money.MustParse("6000.75"),
 		})
 	}))
 
@@ -72,7 +76,7 @@ func TestGetSummary(t *testing.T) {
 	if err != nil {
 		t.Fatalf("GetSummary: %v", err)
 	}
-	if s.Year != 2026 || s.TotalRevenues != 50000.50 {
+	if s.Year != 2026 || s.TotalRevenues != money.MustParse("50000.50") {
 		t.Errorf("summary not parsed: %+v", s)
 	}
 }
@@ -90,6 +94,46 @@ func TestGetSummaryForYearPassesYearParam(t *testing.T) {
 	}
 }
 
+func TestGetSummariesForYears(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		year, _ := strconv.Atoi(r.URL.Query().Get("year"))
+		if year == 1999 {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		// Older years answer first, so the results arrive out of order
+		time.Sleep(time.Duration(year-2020) * 10 * time.Millisecond)
+		json.NewEncoder(w).Encode(Summary{Year: year, TotalRevenues: money.Money(year)})
+	}))
+
+	years := []int{2024, 2022, 2023}
+	summaries, err := c.GetSummariesForYears(years)
+	if err != nil {
+		t.Fatalf("GetSummariesForYears: %v", err)
+	}
+	for i, s := range summaries {
+		if s.Year != years[i] {
+			t.Errorf("summaries[%d].Year = %d, want %d", i, s.Year, years[i])
+		}
+	}
+	if maxInFlight.Load() < 2 {
+		t.Error("summaries were fetched one at a time")
+	}
+
+	if _, err := c.GetSummariesForYears([]int{2022, 1999}); err == nil || !strings.Contains(err.Error(), "1999") {
+		t.Errorf("error = %v, want the failing year", err)
+	}
+}
+
 func TestListRevenues(t *testing.T) {
 	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/proxy/accounting/revenues/list" || r.Method != "POST" {
@@ -104,7 +148,7 @@ func TestListRevenues(t *testing.T) {
 			t.Errorf("SearchText = %q, want acme", req.SearchText)
 		}
 		json.NewEncoder(w).Encode(RevenueListResponse{Items: []Revenue{
-			{SerialCode: "INV-001", ClientName: "ACME", Total: 1000, IsPaid: true},
+			{SerialCode: "INV-001", ClientName: "ACME", Total: 1000 * money.Lei, IsPaid: true},
 		}})
 	}))
 
@@ -117,12 +161,112 @@ func TestListRevenues(t *testing.T) {
 	}
 }
 
+// ListAllRevenues keeps requesting pages until TotalResults is reached
+func TestListAllRevenues(t *testing.T) {
+	total := listPageSize + 1
+	var starts []int
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req listRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		starts = append(starts, req.StartIndex)
+		n := min(req.MaxResults, total-req.StartIndex)
+		json.NewEncoder(w).Encode(RevenueListResponse{Items: make([]Revenue, n), TotalResults: &total})
+	}))
+
+	items, err := c.ListAllRevenues()
+	if err != nil {
+		t.Fatalf("ListAllRevenues: %v", err)
+	}
+	if len(items) != total || len(starts) != 2 || starts[1] != listPageSize {
+		t.Errorf("got %d items from pages starting at %v, want %d from [0 %d]", len(items), starts, total, listPageSize)
+	}
+}
+
+// The queue is not capped at one page either
+func TestListAllQueuedExpenses(t *testing.T) {
+	total := listPageSize + 1
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req listRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		n := min(req.MaxResults, total-req.StartIndex)
+		json.NewEncoder(w).Encode(QueuedExpenseResponse{Items: make([]QueuedExpense, n), TotalResults: &total})
+	}))
+
+	items, err := c.ListAllQueuedExpenses()
+	if err != nil || len(items) != total {
+		t.Errorf("ListAllQueuedExpenses = %d items, %v, want %d", len(items), err, total)
+	}
+}
+
+func TestMonthlyRevenues(t *testing.T) {
+	local := LocalAmount{Total: 5000 * money.Lei}
+	items := []Revenue{
+		{IssueDate: "2026-03-10T00:00:00+02:00", Total: 1000 * money.Lei, InvoiceLocalAmount: &local},
+		{IssueDate: "2026-03-20", Total: 200 * money.Lei},
+		{IssueDate: "2026-11-02", Total: 300 * money.Lei},
+		{IssueDate: "2025-03-20", Total: 99999 * money.Lei},                                           // other year, excluded
+		{IssueDate: "2026-03-25", Total: 7777 * money.Lei, Status: &InvoiceStatus{IsCancelled: true}}, // cancelled, excluded
+		{IssueDate: "", Total: money.Lei},
+	}
+
+	months := MonthlyRevenues(items, 2026)
+	if months[2] != 5200*money.Lei || months[10] != 300*money.Lei {
+		t.Errorf("months = %v, want 5200 in March (local RON amount + plain total) and 300 in November", months)
+	}
+}
+
+func TestDates(t *testing.T) {
+	for s, want := range map[string]string{"2026-03-15T00:00:00": "2026-03-15", "2026-03-15": "2026-03-15", "15.03.2026": "", "": ""} {
+		if got := Day(s); got != want {
+			t.Errorf("Day(%q) = %q, want %q", s, got, want)
+		}
+	}
+	if d, ok := ParseDay("2026-02-30T00:00:00"); ok {
+		t.Errorf("ParseDay accepted February 30: %s", d)
+	}
+	if d, ok := ParseDay("2026-03-15T10:20:00"); !ok || !d.Equal(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseDay = %s, %v", d, ok)
+	}
+	if !InYear("2026-01-01T00:00:00", 2026) || InYear("2025-12-31", 2026) || InYear("", 2026) {
+		t.Error("InYear misplaces dates around new year")
+	}
+}
+
+func TestCurrencyISOCode(t *testing.T) {
+	for short, want := range map[string]string{"eur": "EUR", " RON ": "RON", "": "RON"} {
+		if got := (Currency{ShortName: short}).ISOCode(); got != want {
+			t.Errorf("ISOCode(%q) = %q, want %q", short, got, want)
+		}
+	}
+}
+
+func TestExpenseDeductiblePercent(t *testing.T) {
+	tests := []struct {
+		deductibility, category string
+		want                    float64
+	}{
+		{"100%", "", 100},
+		{"50%", "", 50},
+		{" 37,5 % ", "", 37.5},
+		{"0%", "Servicii", 0},
+		{"150%", "", 100},
+		{"", "Servicii", 100},
+		{"", "Cheltuieli Nedeductibile", 0},
+	}
+	for _, tt := range tests {
+		e := Expense{Deductibility: tt.deductibility, Category: tt.category}
+		if got := e.DeductiblePercent(); got != tt.want {
+			t.Errorf("DeductiblePercent(%q, %q) = %v, want %v", tt.deductibility, tt.category, got, tt.want)
+		}
+	}
+}
+
 func TestListExpensesAndQueueAndRejected(t *testing.T) {
 	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case "/proxy/accounting/expenses/list":
 			json.NewEncoder(w).Encode(ExpenseListResponse{Items: []Expense{
-				{SupplierName: "Vendor", Total: 99.99, Category: "Software"},
+				{SupplierName: "Vendor", Total: money.MustParse("99.99"), Category: "Software"},
 			}})
 		case "/proxy/accounting/expenses/queued":
 			json.NewEncoder(w).Encode(QueuedExpenseResponse{Items: []QueuedExpense{
@@ -169,7 +313,7 @@ func TestListEFactura(t *testing.T) {
 			t.Errorf("unexpected path: %s", r.URL.Path)
 		}
 		json.NewEncoder(w).Encode(EFacturaListResponse{Items: []EFactura{
-			{SerialCode: "EF-1", TotalAmount: 500, CurrencyCode: "RON", PartyName: "Telecom"},
+			{SerialCode: "EF-1", TotalAmount: 500 * money.Lei, CurrencyCode: "RON", PartyName: "Telecom"},
 		}})
 	}))
 
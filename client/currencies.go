@@ -0,0 +1,28 @@
+package client
+
+import (
+	"sort"
+	"strings"
+
+	"solo-cli/money"
+)
+
+// FormatCurrencies lists amounts per currency, RON first then alphabetical,
+// as "1200.00 RON + 300.00 EUR"
+func FormatCurrencies(amounts map[string]money.Money) string {
+	curs := make([]string, 0, len(amounts))
+	for cur := range amounts {
+		curs = append(curs, cur)
+	}
+	sort.Slice(curs, func(i, j int) bool {
+		if (curs[i] == "RON") != (curs[j] == "RON") {
+			return curs[i] == "RON"
+		}
+		return curs[i] < curs[j]
+	})
+	parts := make([]string, 0, len(curs))
+	for _, cur := range curs {
+		parts = append(parts, amounts[cur].String()+" "+cur)
+	}
+	return strings.Join(parts, " + ")
+}
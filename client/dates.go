@@ -0,0 +1,33 @@
+package client
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is the day format at the start of the API's dates and
+// timestamps
+const DateLayout = "2006-01-02"
+
+// Day returns the date part of an API date or timestamp, so
+// 2026-03-15T00:00:00 gives 2026-03-15. Empty when s does not start with a
+// date
+func Day(s string) string {
+	if len(s) < len(DateLayout) || s[4] != '-' || s[7] != '-' {
+		return ""
+	}
+	return s[:len(DateLayout)]
+}
+
+// ParseDay parses the day of an API date or timestamp, ok is false for
+// missing or malformed dates
+func ParseDay(s string) (time.Time, bool) {
+	d, err := time.Parse(DateLayout, Day(s))
+	return d, err == nil
+}
+
+// InYear reports whether an API date or timestamp falls in year
+func InYear(s string, year int) bool {
+	return strings.HasPrefix(s, strconv.Itoa(year)+"-")
+}
@@ -2,7 +2,10 @@ package client
 
 import (
 	"fmt"
+	"math"
 	"time"
+
+	"solo-cli/money"
 )
 
 // GetDemoSummary returns mock summary data for demo mode
@@ -10,15 +13,27 @@ func GetDemoSummary() *Summary {
 	return &Summary{
 		Year:                    time.Now().Year(),
 		DisplayCurrency:         "RON",
-		TotalRevenues:           125840.50,
-		TotalDeductibleExpenses: 42350.75,
+		TotalRevenues:           money.MustParse("125840.50"),
+		TotalDeductibleExpenses: money.MustParse("42350.75"),
 		HasTaxes:                true,
-		Taxes:                   8349.88,
+		Taxes:                   money.MustParse("8349.88"),
 		RevenuesAwaitingReview:  0,
 		ExpensesAwaitingReview:  3,
 	}
 }
 
+// GetDemoSummaryForYear returns the demo summary scaled back for earlier
+// years, a business growing about 15% a year
+func GetDemoSummaryForYear(year int) *Summary {
+	s := GetDemoSummary()
+	factor := math.Pow(0.87, float64(s.Year-year))
+	s.Year = year
+	s.TotalRevenues = s.TotalRevenues.MulFloat(factor)
+	s.TotalDeductibleExpenses = s.TotalDeductibleExpenses.MulFloat(factor)
+	s.Taxes = s.Taxes.MulFloat(factor)
+	return s
+}
+
 // GetDemoCompany returns mock company data for demo mode
 func GetDemoCompany() *CompanyInfo {
 	return &CompanyInfo{
@@ -46,14 +61,14 @@ func GetDemoRevenues() *RevenueListResponse {
 	usdCurrency := Currency{Id: 3, Code: "USD", Name: "US Dollar", ShortName: "USD", IsDefault: false}
 
 	items := []Revenue{
-		{UniqueCode: "inv-001", SerialCode: "ACME-2025-001", ClientName: "Cloud Services Inc", IssueDate: fmt.Sprintf("%d-01-05", time.Now().Year()), PaymentDate: fmt.Sprintf("%d-01-10", time.Now().Year()), IsPaid: true, Total: 15000.00, Currency: eurCurrency},
-		{UniqueCode: "inv-002", SerialCode: "ACME-2025-002", ClientName: "DevTools Pro SRL", IssueDate: fmt.Sprintf("%d-01-08", time.Now().Year()), PaymentDate: "", IsPaid: false, Total: 8500.00, Currency: ronCurrency},
-		{UniqueCode: "inv-003", SerialCode: "ACME-2025-003", ClientName: "TechStart Solutions", IssueDate: fmt.Sprintf("%d-01-12", time.Now().Year()), PaymentDate: fmt.Sprintf("%d-01-15", time.Now().Year()), IsPaid: true, Total: 22400.00, Currency: ronCurrency},
-		{UniqueCode: "inv-004", SerialCode: "ACME-2025-004", ClientName: "Nordic Systems AB", IssueDate: fmt.Sprintf("%d-01-15", time.Now().Year()), PaymentDate: fmt.Sprintf("%d-01-20", time.Now().Year()), IsPaid: true, Total: 5200.00, Currency: eurCurrency},
-		{UniqueCode: "inv-005", SerialCode: "ACME-2025-005", ClientName: "DataFlow Analytics", IssueDate: fmt.Sprintf("%d-01-18", time.Now().Year()), PaymentDate: "", IsPaid: false, Total: 12750.00, Currency: usdCurrency},
-		{UniqueCode: "inv-006", SerialCode: "ACME-2025-006", ClientName: "InnovateTech GmbH", IssueDate: fmt.Sprintf("%d-01-22", time.Now().Year()), PaymentDate: fmt.Sprintf("%d-01-25", time.Now().Year()), IsPaid: true, Total: 18900.00, Currency: eurCurrency},
-		{UniqueCode: "inv-007", SerialCode: "ACME-2025-007", ClientName: "Quantum Labs SRL", IssueDate: fmt.Sprintf("%d-01-25", time.Now().Year()), PaymentDate: "", IsPaid: false, Total: 6300.00, Currency: ronCurrency},
-		{UniqueCode: "inv-008", SerialCode: "ACME-2025-008", ClientName: "ByteForge Studios", IssueDate: fmt.Sprintf("%d-01-28", time.Now().Year()), PaymentDate: fmt.Sprintf("%d-02-01", time.Now().Year()), IsPaid: true, Total: 31500.00, Currency: ronCurrency},
+		{UniqueCode: "inv-001", SerialCode: "ACME-2025-001", ClientName: "Cloud Services Inc", IssueDate: fmt.Sprintf("%d-01-05", time.Now().Year()), PaymentDate: fmt.Sprintf("%d-01-10", time.Now().Year()), IsPaid: true, Total: money.MustParse("15000.00"), Currency: eurCurrency},
+		{UniqueCode: "inv-002", SerialCode: "ACME-2025-002", ClientName: "DevTools Pro SRL", IssueDate: fmt.Sprintf("%d-01-08", time.Now().Year()), PaymentDate: "", IsPaid: false, Total: money.MustParse("8500.00"), Currency: ronCurrency},
+		{UniqueCode: "inv-003", SerialCode: "ACME-2025-003", ClientName: "TechStart Solutions", IssueDate: fmt.Sprintf("%d-01-12", time.Now().Year()), PaymentDate: fmt.Sprintf("%d-01-15", time.Now().Year()), IsPaid: true, Total: money.MustParse("22400.00"), Currency: ronCurrency},
+		{UniqueCode: "inv-004", SerialCode: "ACME-2025-004", ClientName: "Nordic Systems AB", IssueDate: fmt.Sprintf("%d-01-15", time.Now().Year()), PaymentDate: fmt.Sprintf("%d-01-20", time.Now().Year()), IsPaid: true, Total: money.MustParse("5200.00"), Currency: eurCurrency},
+		{UniqueCode: "inv-005", SerialCode: "ACME-2025-005", ClientName: "DataFlow Analytics", IssueDate: fmt.Sprintf("%d-01-18", time.Now().Year()), PaymentDate: "", IsPaid: false, Total: money.MustParse("12750.00"), Currency: usdCurrency},
+		{UniqueCode: "inv-006", SerialCode: "ACME-2025-006", ClientName: "InnovateTech GmbH", IssueDate: fmt.Sprintf("%d-01-22", time.Now().Year()), PaymentDate: fmt.Sprintf("%d-01-25", time.Now().Year()), IsPaid: true, Total: money.MustParse("18900.00"), Currency: eurCurrency},
+		{UniqueCode: "inv-007", SerialCode: "ACME-2025-007", ClientName: "Quantum Labs SRL", IssueDate: fmt.Sprintf("%d-01-25", time.Now().Year()), PaymentDate: "", IsPaid: false, Total: money.MustParse("6300.00"), Currency: ronCurrency},
+		{UniqueCode: "inv-008", SerialCode: "ACME-2025-008", ClientName: "ByteForge Studios", IssueDate: fmt.Sprintf("%d-01-28", time.Now().Year()), PaymentDate: fmt.Sprintf("%d-02-01", time.Now().Year()), IsPaid: true, Total: money.MustParse("31500.00"), Currency: ronCurrency},
 	}
 
 	total := len(items)
@@ -67,16 +82,18 @@ func GetDemoRevenues() *RevenueListResponse {
 func GetDemoExpenses() *ExpenseListResponse {
 	ronCurrency := Currency{Id: 1, Code: "RON", Name: "Romanian Leu", ShortName: "RON", IsDefault: true}
 	eurCurrency := Currency{Id: 2, Code: "EUR", Name: "Euro", ShortName: "EUR", IsDefault: false}
+	// Dated in the current year like the demo invoices
+	year := time.Now().Year()
 
 	items := []Expense{
-		{UniqueCode: "exp-001", SupplierName: "Adobe Systems", PurchaseDate: "2025-01-03", Category: "Software & Subscriptions", PrimaryCategory: "Operating", Total: 450.00, Currency: eurCurrency, Deductibility: "100%"},
-		{UniqueCode: "exp-002", SupplierName: "DigitalOcean", PurchaseDate: "2025-01-05", Category: "Cloud Hosting", PrimaryCategory: "Operating", Total: 1250.00, Currency: ronCurrency, Deductibility: "100%"},
-		{UniqueCode: "exp-003", SupplierName: "Petrom", PurchaseDate: "2025-01-08", Category: "Cheltuieli auto - Nedeductibilă", PrimaryCategory: "Transport", Total: 380.50, Currency: ronCurrency, Deductibility: "0%"},
-		{UniqueCode: "exp-004", SupplierName: "eMAG", PurchaseDate: "2025-01-10", Category: "Office Equipment", PrimaryCategory: "Equipment", Total: 2890.00, Currency: ronCurrency, Deductibility: "100%"},
-		{UniqueCode: "exp-005", SupplierName: "GitHub Enterprise", PurchaseDate: "2025-01-12", Category: "Software & Subscriptions", PrimaryCategory: "Operating", Total: 210.00, Currency: usdCurrency()},
-		{UniqueCode: "exp-006", SupplierName: "Restaurant La Mama", PurchaseDate: "2025-01-15", Category: "Cheltuieli protocol - Nedeductibilă", PrimaryCategory: "Entertainment", Total: 520.00, Currency: ronCurrency, Deductibility: "0%"},
-		{UniqueCode: "exp-007", SupplierName: "Telekom Romania", PurchaseDate: "2025-01-18", Category: "Telecommunications", PrimaryCategory: "Operating", Total: 189.00, Currency: ronCurrency, Deductibility: "100%"},
-		{UniqueCode: "exp-008", SupplierName: "JetBrains", PurchaseDate: "2025-01-20", Category: "Software & Subscriptions", PrimaryCategory: "Operating", Total: 649.00, Currency: eurCurrency, Deductibility: "100%"},
+		{UniqueCode: "exp-001", SupplierName: "Adobe Systems", PurchaseDate: fmt.Sprintf("%d-01-03", year), Category: "Software & Subscriptions", PrimaryCategory: "Operating", Total: money.MustParse("450.00"), Currency: eurCurrency, Deductibility: "100%"},
+		{UniqueCode: "exp-002", SupplierName: "DigitalOcean", PurchaseDate: fmt.Sprintf("%d-01-05", year), Category: "Cloud Hosting", PrimaryCategory: "Operating", Total: money.MustParse("1250.00"), Currency: ronCurrency, Deductibility: "100%"},
+		{UniqueCode: "exp-003", SupplierName: "Petrom", PurchaseDate: fmt.Sprintf("%d-01-08", year), Category: "Cheltuieli auto - Nedeductibilă", PrimaryCategory: "Transport", Total: money.MustParse("380.50"), Currency: ronCurrency, Deductibility: "0%"},
+		{UniqueCode: "exp-004", SupplierName: "eMAG", PurchaseDate: fmt.Sprintf("%d-01-10", year), Category: "Office Equipment", PrimaryCategory: "Equipment", Total: money.MustParse("2890.00"), Currency: ronCurrency, Deductibility: "100%"},
+		{UniqueCode: "exp-005", SupplierName: "GitHub Enterprise", PurchaseDate: fmt.Sprintf("%d-01-12", year), Category: "Software & Subscriptions", PrimaryCategory: "Operating", Total: money.MustParse("210.00"), Currency: usdCurrency()},
+		{UniqueCode: "exp-006", SupplierName: "Restaurant La Mama", PurchaseDate: fmt.Sprintf("%d-01-15", year), Category: "Cheltuieli protocol - Nedeductibilă", PrimaryCategory: "Entertainment", Total: money.MustParse("520.00"), Currency: ronCurrency, Deductibility: "0%"},
+		{UniqueCode: "exp-007", SupplierName: "Telekom Romania", PurchaseDate: fmt.Sprintf("%d-01-18", year), Category: "Telecommunications", PrimaryCategory: "Operating", Total: money.MustParse("189.00"), Currency: ronCurrency, Deductibility: "100%"},
+		{UniqueCode: "exp-008", SupplierName: "JetBrains", PurchaseDate: fmt.Sprintf("%d-01-20", year), Category: "Software & Subscriptions", PrimaryCategory: "Operating", Total: money.MustParse("649.00"), Currency: eurCurrency, Deductibility: "100%"},
 	}
 
 	total := len(items)
@@ -122,11 +139,11 @@ func GetDemoRejectedExpenses() *RejectedExpenseResponse {
 // GetDemoEFactura returns mock e-factura data for demo mode
 func GetDemoEFactura() *EFacturaListResponse {
 	items := []EFactura{
-		{SerialCode: "EF-2025-00142", TotalAmount: 1890.50, CurrencyCode: "RON", InvoiceDate: "2025-01-22", PartyCode1: "RO9876543", PartyName: "Supplier Alpha SRL"},
-		{SerialCode: "EF-2025-00138", TotalAmount: 4250.00, CurrencyCode: "RON", InvoiceDate: "2025-01-20", PartyCode1: "RO1122334", PartyName: "Tech Imports SA"},
-		{SerialCode: "EF-2025-00125", TotalAmount: 780.00, CurrencyCode: "EUR", InvoiceDate: "2025-01-18", PartyCode1: "RO5544332", PartyName: "Office Supplies Pro"},
-		{SerialCode: "EF-2025-00119", TotalAmount: 2340.75, CurrencyCode: "RON", InvoiceDate: "2025-01-15", PartyCode1: "RO6677889", PartyName: "Logistics Express SRL"},
-		{SerialCode: "EF-2025-00108", TotalAmount: 560.00, CurrencyCode: "RON", InvoiceDate: "2025-01-12", PartyCode1: "RO3344556", PartyName: "Cleaning Services Pro"},
+		{SerialCode: "EF-2025-00142", TotalAmount: money.MustParse("1890.50"), CurrencyCode: "RON", InvoiceDate: "2025-01-22", PartyCode1: "RO9876543", PartyName: "Supplier Alpha SRL"},
+		{SerialCode: "EF-2025-00138", TotalAmount: money.MustParse("4250.00"), CurrencyCode: "RON", InvoiceDate: "2025-01-20", PartyCode1: "RO1122334", PartyName: "Tech Imports SA"},
+		{SerialCode: "EF-2025-00125", TotalAmount: money.MustParse("780.00"), CurrencyCode: "EUR", InvoiceDate: "2025-01-18", PartyCode1: "RO5544332", PartyName: "Office Supplies Pro"},
+		{SerialCode: "EF-2025-00119", TotalAmount: money.MustParse("2340.75"), CurrencyCode: "RON", InvoiceDate: "2025-01-15", PartyCode1: "RO6677889", PartyName: "Logistics Express SRL"},
+		{SerialCode: "EF-2025-00108", TotalAmount: money.MustParse("560.00"), CurrencyCode: "RON", InvoiceDate: "2025-01-12", PartyCode1: "RO3344556", PartyName: "Cleaning Services Pro"},
 	}
 
 	total := len(items)
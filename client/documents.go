@@ -0,0 +1,45 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// maxDocumentSize caps a downloaded document, receipts and invoices are far
+// smaller
+const maxDocumentSize = 50 << 20
+
+// DownloadDocument fetches the original file of an expense or rejected
+// document by its DocumentCode, with its content type
+func (c *Client) DownloadDocument(code string) ([]byte, string, error) {
+	if code == "" {
+		return nil, "", fmt.Errorf("document has no code")
+	}
+	req, err := http.NewRequest("GET", baseURL+"/api/financial-documents/download/"+url.PathEscape(code), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Referer", baseURL+"/expenses")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download document %s: %w", code, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download document %s: status %d", code, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download document %s: %w", code, err)
+	}
+	if len(data) > maxDocumentSize {
+		return nil, "", fmt.Errorf("document %s is larger than %d MB", code, maxDocumentSize>>20)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
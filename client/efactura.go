@@ -1,15 +1,19 @@
 package client
 
-import "fmt"
+import (
+	"fmt"
+
+	"solo-cli/money"
+)
 
 // EFactura represents an e-invoice from the national e-Factura system
 type EFactura struct {
-	SerialCode   string  `json:"SerialCode"`
-	TotalAmount  float64 `json:"TotalAmount"`
-	CurrencyCode string  `json:"CurrencyCode"`
-	InvoiceDate  string  `json:"InvoiceDate"`
-	PartyCode1   string  `json:"PartyCode1"`
-	PartyName    string  `json:"PartyName"`
+	SerialCode   string      `json:"SerialCode"`
+	TotalAmount  money.Money `json:"TotalAmount"`
+	CurrencyCode string      `json:"CurrencyCode"`
+	InvoiceDate  string      `json:"InvoiceDate"`
+	PartyCode1   string      `json:"PartyCode1"`
+	PartyName    string      `json:"PartyName"`
 }
 
 // EFacturaListResponse represents response from e-invoice list endpoint
@@ -27,3 +31,14 @@ func (c *Client) ListEFactura(startIndex, maxResults int, search string) (*EFact
 	}
 	return &result, nil
 }
+
+// ListAllEFactura pages through the complete e-invoice list
+func (c *Client) ListAllEFactura() ([]EFactura, error) {
+	return listAll(func(start, size int) ([]EFactura, *int, error) {
+		resp, err := c.ListEFactura(start, size, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		return resp.Items, resp.TotalResults, nil
+	})
+}
@@ -1,6 +1,12 @@
 package client
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"solo-cli/money"
+)
 
 // Expense represents a single expense item
 type Expense struct {
@@ -12,16 +18,39 @@ type Expense struct {
 	Category           string              `json:"Category"`
 	PrimaryCategory    string              `json:"PrimaryCategory"`
 	CategoryCount      int                 `json:"CategoryCount"`
-	Total              float64             `json:"Total"`
+	Total              money.Money         `json:"Total"`
 	Deductibility      string              `json:"Deductibility"`
 	Currency           Currency            `json:"Currency"`
 	ExpenseLocalAmount *ExpenseLocalAmount `json:"ExpenseLocalAmount"`
 }
 
+// TotalRON returns the expense value in RON, preferring the local amount
+// for foreign currency expenses
+func (e Expense) TotalRON() money.Money {
+	if e.ExpenseLocalAmount != nil {
+		return e.ExpenseLocalAmount.Total
+	}
+	return e.Total
+}
+
+// DeductiblePercent parses Deductibility ("100%", "50%", "0%"). When the
+// API leaves it empty the category decides: SOLO marks non-deductible
+// categories with "Nedeductibil"
+func (e Expense) DeductiblePercent() float64 {
+	s := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(e.Deductibility), "%"))
+	if pct, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64); err == nil {
+		return min(max(pct, 0), 100)
+	}
+	if strings.Contains(strings.ToLower(e.Category), "nedeductibil") {
+		return 0
+	}
+	return 100
+}
+
 // ExpenseLocalAmount represents expense amount in local currency
 type ExpenseLocalAmount struct {
-	Total    float64  `json:"Total"`
-	Currency Currency `json:"Currency"`
+	Total    money.Money `json:"Total"`
+	Currency Currency    `json:"Currency"`
 }
 
 // ExpenseListResponse represents response from expense list endpoint
@@ -85,6 +114,17 @@ func (c *Client) ListExpenses(startIndex, maxResults int, search string) (*Expen
 	return &result, nil
 }
 
+// ListAllExpenses pages through the complete expense list
+func (c *Client) ListAllExpenses() ([]Expense, error) {
+	return listAll(func(start, size int) ([]Expense, *int, error) {
+		resp, err := c.ListExpenses(start, size, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		return resp.Items, resp.TotalResults, nil
+	})
+}
+
 // GetExpenseCounts fetches expense document counts for a given year
 func (c *Client) GetExpenseCounts(year int) (*ExpenseCounts, error) {
 	path := "/proxy/accounting/expenses/summary"
@@ -109,6 +149,17 @@ func (c *Client) ListQueuedExpenses(startIndex, maxResults int, search string) (
 	return &result, nil
 }
 
+// ListAllQueuedExpenses pages through every document in the queue
+func (c *Client) ListAllQueuedExpenses() ([]QueuedExpense, error) {
+	return listAll(func(start, size int) ([]QueuedExpense, *int, error) {
+		resp, err := c.ListQueuedExpenses(start, size, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		return resp.Items, resp.TotalResults, nil
+	})
+}
+
 // ListRejectedExpenses fetches expenses that were rejected
 func (c *Client) ListRejectedExpenses(startIndex, maxResults int) (*RejectedExpenseResponse, error) {
 	var result RejectedExpenseResponse
@@ -118,6 +169,17 @@ func (c *Client) ListRejectedExpenses(startIndex, maxResults int) (*RejectedExpe
 	return &result, nil
 }
 
+// ListAllRejectedExpenses pages through every rejected expense
+func (c *Client) ListAllRejectedExpenses() ([]RejectedExpense, error) {
+	return listAll(func(start, size int) ([]RejectedExpense, *int, error) {
+		resp, err := c.ListRejectedExpenses(start, size)
+		if err != nil {
+			return nil, nil, err
+		}
+		return resp.Items, resp.TotalResults, nil
+	})
+}
+
 // DeleteExpense deletes an expense by ID
 func (c *Client) DeleteExpense(id int) error {
 	path := fmt.Sprintf("/proxy/accounting/expenses/%d", id)
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"solo-cli/money"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// testdata/revenues.json holds 360 invoices with amounts in bani, foreign
+// ones with their RON local amount. The golden monthly totals were computed
+// independently with decimal arithmetic and must match to the ban
+func TestMonthlyRevenuesGolden(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "revenues.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp RevenueListResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	months := MonthlyRevenues(resp.Items, 2026)
+	var b strings.Builder
+	for i, v := range months {
+		fmt.Fprintf(&b, "2026-%02d %s\n", i+1, v)
+	}
+	fmt.Fprintf(&b, "total %s\n", money.Sum(months[:]...))
+
+	checkGolden(t, filepath.Join("testdata", "monthly_revenues.golden"), b.String())
+}
+
+// checkGolden compares got with the golden file, rewriting it with -update
+func checkGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch:\n got:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -1,6 +1,12 @@
 package client
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"solo-cli/money"
+)
 
 // Revenue represents a single revenue/invoice item
 type Revenue struct {
@@ -10,7 +16,7 @@ type Revenue struct {
 	IssueDate          string          `json:"IssueDate"`
 	PaymentDate        string          `json:"PaymentDate"`
 	IsPaid             bool            `json:"IsPaid"`
-	Total              float64         `json:"Total"`
+	Total              money.Money     `json:"Total"`
 	Currency           Currency        `json:"Currency"`
 	InvoiceLocalAmount *LocalAmount    `json:"InvoiceLocalAmount"`
 	IsExternalDocument bool            `json:"IsExternalDocument"`
@@ -18,6 +24,39 @@ type Revenue struct {
 	EInvoiceStatus     *EInvoiceStatus `json:"EInvoiceStatus"`
 }
 
+// TotalRON returns the invoice value in RON, preferring the local amount
+// for foreign currency invoices
+func (r Revenue) TotalRON() money.Money {
+	if r.InvoiceLocalAmount != nil {
+		return r.InvoiceLocalAmount.Total
+	}
+	return r.Total
+}
+
+// MonthlyRevenues sums the RON value of the invoices issued in the given
+// year by issue month, exactly to the ban
+func MonthlyRevenues(items []Revenue, year int) [12]money.Money {
+	return MonthlyRevenuesBy(items, year, Revenue.TotalRON)
+}
+
+// MonthlyRevenuesBy sums value(invoice) for the invoices issued in the given
+// year by issue month. IssueDate is ISO formatted so the year and month are
+// a prefix. Cancelled invoices are left out
+func MonthlyRevenuesBy(items []Revenue, year int, value func(Revenue) money.Money) [12]money.Money {
+	var months [12]money.Money
+	for _, r := range items {
+		if !InYear(r.IssueDate, year) || len(r.IssueDate) < 7 || (r.Status != nil && r.Status.IsCancelled) {
+			continue
+		}
+		mo, err := strconv.Atoi(r.IssueDate[5:7])
+		if err != nil || mo < 1 || mo > 12 {
+			continue
+		}
+		months[mo-1] += value(r)
+	}
+	return months
+}
+
 // Currency represents a currency type
 type Currency struct {
 	Id        int    `json:"Id"`
@@ -27,13 +66,22 @@ type Currency struct {
 	IsDefault bool   `json:"IsDefault"`
 }
 
+// ISOCode returns the currency's code, such as EUR, treating an unset one
+// as RON
+func (c Currency) ISOCode() string {
+	if cur := strings.ToUpper(strings.TrimSpace(c.ShortName)); cur != "" {
+		return cur
+	}
+	return "RON"
+}
+
 // LocalAmount represents amount in local currency
 type LocalAmount struct {
-	Amount       float64  `json:"Amount"`
-	VAT          float64  `json:"VAT"`
-	Total        float64  `json:"Total"`
-	Currency     Currency `json:"Currency"`
-	ExchangeRate *float64 `json:"ExchangeRate"`
+	Amount       money.Money `json:"Amount"`
+	VAT          money.Money `json:"VAT"`
+	Total        money.Money `json:"Total"`
+	Currency     Currency    `json:"Currency"`
+	ExchangeRate *float64    `json:"ExchangeRate"`
 }
 
 // InvoiceStatus represents invoice status
@@ -84,6 +132,36 @@ func (c *Client) ListRevenues(startIndex, maxResults int, search string) (*Reven
 	return &result, nil
 }
 
+// listPageSize is the page size listAll requests
+const listPageSize = 100
+
+// listAll requests pages of listPageSize items from start 0 until a short
+// page or TotalResults items
+func listAll[T any](page func(start, size int) ([]T, *int, error)) ([]T, error) {
+	var items []T
+	for {
+		got, total, err := page(len(items), listPageSize)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, got...)
+		if len(got) < listPageSize || (total != nil && len(items) >= *total) {
+			return items, nil
+		}
+	}
+}
+
+// ListAllRevenues pages through the complete revenue list
+func (c *Client) ListAllRevenues() ([]Revenue, error) {
+	return listAll(func(start, size int) ([]Revenue, *int, error) {
+		resp, err := c.ListRevenues(start, size, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		return resp.Items, resp.TotalResults, nil
+	})
+}
+
 // GetRevenueCounts fetches revenue document counts for a given year
 func (c *Client) GetRevenueCounts(year int) (*RevenueCounts, error) {
 	path := "/proxy/accounting/revenues/summary"
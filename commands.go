@@ -3,9 +3,17 @@ package main
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"solo-cli/client"
 	"solo-cli/config"
+	"solo-cli/money"
+	"solo-cli/rates"
+	"solo-cli/report"
 	"solo-cli/taxes"
 )
 
@@ -22,12 +30,133 @@ func parseYearArg(args []string) int {
 	return year
 }
 
+// maxCompareYears bounds summary --years so a typo does not fire off
+// hundreds of requests
+const maxCompareYears = 20
+
+// parseYearsArg reads the years of summary --years: a range (2022-2026), a
+// list (2022,2024) or a count of years up to current (5)
+func parseYearsArg(spec string, current int) ([]int, error) {
+	var years []int
+	if from, to, ok := strings.Cut(spec, "-"); ok {
+		a, errA := strconv.Atoi(from)
+		b, errB := strconv.Atoi(to)
+		if errA != nil || errB != nil || a > b {
+			return nil, fmt.Errorf("invalid year range '%s' (want e.g. 2022-2026)", spec)
+		}
+		for y := a; y <= b && len(years) <= maxCompareYears; y++ {
+			years = append(years, y)
+		}
+	} else {
+		for _, part := range strings.Split(spec, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid years '%s' (want a range, a list or a count)", spec)
+			}
+			years = append(years, n)
+		}
+		// A lone small number is a count of years ending this year
+		if len(years) == 1 && years[0] <= maxCompareYears {
+			n := years[0]
+			years = years[:0]
+			for y := current - n + 1; y <= current; y++ {
+				years = append(years, y)
+			}
+		}
+	}
+	if len(years) > maxCompareYears {
+		return nil, fmt.Errorf("too many years in '%s' (at most %d)", spec, maxCompareYears)
+	}
+	slices.Sort(years)
+	return slices.Compact(years), nil
+}
+
+// parseIncomeFlags pulls repeated --income category=amount flags out of
+// args, returning the remaining args and the declared income
+func parseIncomeFlags(args []string, taxCfg *config.TaxConfig) ([]string, []config.ExtraIncome) {
+	var rest []string
+	var extra []config.ExtraIncome
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--income" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			fmt.Fprintln(os.Stderr, "Error: --income requires a category=amount argument")
+			os.Exit(1)
+		}
+		i++
+		key, value, ok := strings.Cut(args[i], "=")
+		amount, err := money.Parse(value)
+		if !ok || err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --income '%s' (want category=amount)\n", args[i])
+			os.Exit(1)
+		}
+		if taxCfg.IncomeCategory(key) == nil {
+			var keys []string
+			for _, c := range taxCfg.IncomeCategories {
+				keys = append(keys, c.Key)
+			}
+			fmt.Fprintf(os.Stderr, "Error: unknown income category '%s' (known: %s)\n", key, strings.Join(keys, ", "))
+			os.Exit(1)
+		}
+		extra = append(extra, config.ExtraIncome{Category: key, Amount: amount})
+	}
+	return rest, extra
+}
+
+// loadExtraIncome returns the income declared in income.json for year plus
+// the --income flag amounts
+func loadExtraIncome(year int, flagged []config.ExtraIncome) []config.ExtraIncome {
+	entries, err := config.LoadExtraIncome()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading income.json: %v\n", err)
+		os.Exit(1)
+	}
+	extra := config.ExtraIncomeForYear(entries, year)
+	for _, e := range flagged {
+		e.Year = year
+		extra = append(extra, e)
+	}
+	return extra
+}
+
 func runSummary(c *client.Client, args []string) {
+	showVAT := false
+	var rest []string
+	for _, arg := range args {
+		if arg == "--vat" {
+			showVAT = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	args = rest
+	for i, arg := range args {
+		if arg != "--years" {
+			continue
+		}
+		if i+1 >= len(args) {
+			fmt.Fprintln(os.Stderr, "Error: --years requires a value (e.g. 2022-2026)")
+			os.Exit(1)
+		}
+		years, err := parseYearsArg(args[i+1], time.Now().Year())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		runSummaryYears(c, years)
+		return
+	}
+
 	summary, err := c.GetSummaryForYear(parseYearArg(args))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if printTemplate(summary) {
+		return
+	}
 
 	fmt.Printf("Year: %d\n", summary.Year)
 	fmt.Printf("Revenues: %.2f %s\n", summary.TotalRevenues, summary.DisplayCurrency)
@@ -35,6 +164,99 @@ func runSummary(c *client.Client, args []string) {
 	if summary.HasTaxes {
 		fmt.Printf("Taxes: %.2f %s\n", summary.Taxes, summary.DisplayCurrency)
 	}
+	if !showVAT {
+		return
+	}
+
+	// The VAT tracker is extra information, don't fail the summary over it
+	taxCfg, err := config.LoadTaxes()
+	if err != nil {
+		printTaxesConfigError(err)
+		return
+	}
+	revenues, err := c.ListAllRevenues()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not read the invoices for the VAT turnover: %v\n", err)
+		return
+	}
+	store := loadRates()
+	months := client.MonthlyRevenuesBy(revenues, summary.Year, store.RevenueRON)
+	vat := taxes.TrackVAT(months, summary.Year, time.Now(), taxCfg)
+	if vat == nil {
+		return
+	}
+	fmt.Printf("VAT Turnover: %.2f / %.2f RON (%.1f%%)\n", vat.Turnover, vat.Threshold, vat.UsedPercent)
+	fmt.Printf("VAT Headroom: %.2f RON\n", vat.Headroom)
+	if vat.CrossingMonth != 0 {
+		verb := "exceeded in"
+		if vat.Projected {
+			verb = "projected for"
+		}
+		fmt.Printf("VAT Crossing: %s %s %d\n", verb, vat.CrossingMonth, vat.Year)
+	}
+	printVATWarning(vat)
+}
+
+// runSummaryYears compares several years side by side, fetching their
+// summaries concurrently
+func runSummaryYears(c *client.Client, years []int) {
+	summaries, err := c.GetSummariesForYears(years)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if outputTemplate != nil {
+		for _, s := range summaries {
+			printTemplate(s)
+		}
+		return
+	}
+
+	// Taxes are extra information, the comparison works without them
+	taxCfg, err := config.LoadTaxes()
+	if err != nil {
+		printTaxesConfigError(err)
+		taxCfg = nil
+	}
+	extra, err := config.LoadExtraIncome()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading income.json: %v\n", err)
+		os.Exit(1)
+	}
+	comparison := report.CompareYears(summaries, taxCfg, extra)
+
+	fmt.Printf("Year-over-Year Comparison (%d-%d, RON)\n", years[0], years[len(years)-1])
+	fmt.Printf("══════════════════════════════════════════\n")
+	fmt.Printf("%-6s %13s %8s %13s %8s %13s %8s %13s %8s\n",
+		"Year", "Revenues", "Growth", "Deductible", "Growth", "Net income", "Growth", "Taxes", "Growth")
+	for _, row := range comparison.Rows {
+		taxCell := fmt.Sprintf("%13s", row.Taxes)
+		if taxCfg == nil {
+			taxCell = fmt.Sprintf("%13s", "n/a")
+		}
+		fmt.Printf("%-6d %13s %8s %13s %8s %13s %8s %s %8s\n", row.Year,
+			row.Revenue, report.FormatGrowth(row.RevenueGrowth),
+			row.Deductible, report.FormatGrowth(row.DeductibleGrowth),
+			row.Net, report.FormatGrowth(row.NetGrowth),
+			taxCell, report.FormatGrowth(row.TaxesGrowth))
+	}
+	if comparison.RevenueCAGR != nil {
+		fmt.Println()
+		fmt.Printf("Revenue growth: %s per year on average\n", report.FormatGrowth(comparison.RevenueCAGR))
+	}
+}
+
+// printVATWarning warns on stderr when the turnover is past or close to the
+// VAT registration threshold
+func printVATWarning(vat *taxes.VATStatus) {
+	switch {
+	case vat.Exceeded:
+		fmt.Fprintf(os.Stderr, "⚠️  VAT threshold of %s exceeded in %s %d: register for VAT within 10 days of the end of that month\n",
+			taxes.FormatRON(vat.Threshold), vat.CrossingMonth, vat.Year)
+	case vat.Warning:
+		fmt.Fprintf(os.Stderr, "⚠️  VAT threshold: only %s (%.1f%%) left before %s\n",
+			taxes.FormatRON(vat.Headroom), 100-vat.UsedPercent, taxes.FormatRON(vat.Threshold))
+	}
 }
 
 func runRevenues(c *client.Client) {
@@ -43,13 +265,30 @@ func runRevenues(c *client.Client) {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	var store *rates.Store
+	if reportCurrency != "" {
+		store = loadRatesOrExit()
+	}
+	missing := 0
 	for _, r := range revenues.Items {
+		if printTemplate(r) {
+			continue
+		}
 		paid := "UNPAID"
 		if r.IsPaid {
 			paid = "PAID"
 		}
-		fmt.Printf("%s\t%.2f %s\t%s\t%s\n", r.SerialCode, r.Total, r.Currency.ShortName, paid, r.ClientName)
+		amount, currency := r.Total, r.Currency.ShortName
+		if store != nil {
+			if v, err := store.RevenueIn(r, reportCurrency); err == nil {
+				amount, currency = v, reportCurrency
+			} else {
+				missing++
+			}
+		}
+		fmt.Printf("%s\t%.2f %s\t%s\t%s\n", r.SerialCode, amount, currency, paid, r.ClientName)
 	}
+	warnUnconverted(missing)
 }
 
 func runExpenses(c *client.Client) {
@@ -68,9 +307,26 @@ func runExpenses(c *client.Client) {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	var store *rates.Store
+	if reportCurrency != "" {
+		store = loadRatesOrExit()
+	}
+	missing := 0
 	for _, e := range expenses.Items {
-		fmt.Printf("%.2f %s\t%s\t%s\n", e.Total, e.Currency.ShortName, e.Category, e.SupplierName)
+		if printTemplate(e) {
+			continue
+		}
+		amount, currency := e.Total, e.Currency.ShortName
+		if store != nil {
+			if v, err := store.ExpenseIn(e, reportCurrency); err == nil {
+				amount, currency = v, reportCurrency
+			} else {
+				missing++
+			}
+		}
+		fmt.Printf("%.2f %s\t%s\t%s\n", amount, currency, e.Category, e.SupplierName)
 	}
+	warnUnconverted(missing)
 }
 
 func runQueue(c *client.Client, args []string) {
@@ -105,6 +361,9 @@ func runQueue(c *client.Client, args []string) {
 		os.Exit(1)
 	}
 	for _, q := range queue.Items {
+		if printTemplate(q) {
+			continue
+		}
 		overdue := ""
 		if q.IsOverdue {
 			overdue = "OVERDUE"
@@ -120,6 +379,9 @@ func runEFactura(c *client.Client) {
 		os.Exit(1)
 	}
 	for _, e := range efactura.Items {
+		if printTemplate(e) {
+			continue
+		}
 		fmt.Printf("%s\t%.2f %s\t%s\t%s\n", e.SerialCode, e.TotalAmount, e.CurrencyCode, e.InvoiceDate, e.PartyName)
 	}
 }
@@ -134,6 +396,9 @@ func runCompany(c *client.Client) {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if printTemplate(company) {
+		return
+	}
 	fmt.Printf("Name: %s\n", company.Name)
 	fmt.Printf("CUI: %s\n", company.Code1)
 	fmt.Printf("Reg: %s\n", company.Code2)
@@ -151,20 +416,33 @@ func runCompany(c *client.Client) {
 }
 
 func runTaxes(c *client.Client, args []string) {
+	if len(args) > 0 && (args[0] == "optimize" || args[0] == "compare") {
+		templateUnsupported("'taxes " + args[0] + "'")
+	}
+	if len(args) > 0 && args[0] == "optimize" {
+		runTaxesOptimize(c, args[1:])
+		return
+	}
+	if len(args) > 0 && args[0] == "compare" {
+		runTaxesCompare(c, args[1:])
+		return
+	}
+
+	taxCfg := loadTaxesOrExit()
+
+	args, flagged := parseIncomeFlags(args, taxCfg)
 	summary, err := c.GetSummaryForYear(parseYearArg(args))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	taxCfg, err := config.LoadTaxes()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading taxes config: %v\n", err)
-		os.Exit(1)
+	extra := loadExtraIncome(summary.Year, flagged)
+	result := taxes.CalculateWithIncome(summary.TotalRevenues, summary.TotalDeductibleExpenses, extra, taxCfg)
+	if printTemplate(result) {
+		return
 	}
 
-	result := taxes.Calculate(summary.TotalRevenues, summary.TotalDeductibleExpenses, taxCfg)
-
 	fmt.Printf("Tax Breakdown (%d)\n", summary.Year)
 	fmt.Printf("══════════════════════════════════════════\n")
 	fmt.Printf("Total Revenues:       %s\n", taxes.FormatRON(summary.TotalRevenues))
@@ -187,12 +465,141 @@ func runTaxes(c *client.Client, args []string) {
 	fmt.Printf("  Base: Net Income - CAS - CASS = %s\n", taxes.FormatRON(result.NetIncome-result.CAS.Amount-result.CASS.Amount))
 	fmt.Println()
 
+	if len(result.OtherIncome) > 0 {
+		fmt.Printf("Other Income:         %s\n", taxes.FormatRON(result.OtherIncomeTotal))
+		for _, o := range result.OtherIncome {
+			withheld := ""
+			if o.Category.WithheldAtSource {
+				withheld = ", withheld at source"
+			}
+			fmt.Printf("  %s: %s → Tax (%.0f%% of %s%s): %s\n", o.Category.Label, taxes.FormatRON(o.Amount),
+				o.Category.IncomeTaxPercent, taxes.FormatRON(o.Taxable), withheld, taxes.FormatRON(o.Tax))
+		}
+		fmt.Printf("CASS on other income (%.0f%%): %s\n", result.OtherCASS.Percentage, result.OtherCASS.Label)
+		fmt.Printf("  Base: %s → Amount: %s\n", taxes.FormatRON(result.OtherCASS.Base), taxes.FormatRON(result.OtherCASS.Amount))
+		printThresholdHint(result.OtherCASS)
+		fmt.Println()
+	}
+
 	fmt.Printf("══════════════════════════════════════════\n")
 	fmt.Printf("Total Taxes:          %s\n", taxes.FormatRON(result.TotalTaxes))
+	if result.WithheldTax > 0 {
+		fmt.Printf("  Withheld at source: %s\n", taxes.FormatRON(result.WithheldTax))
+	}
 	fmt.Printf("Net After Tax:        %s\n", taxes.FormatRON(result.NetAfterTax))
 	fmt.Printf("Effective Tax Rate:   %.1f%%\n", result.EffectiveRate)
 }
 
+func runTaxesOptimize(c *client.Client, args []string) {
+	summary, err := c.GetSummaryForYear(parseYearArg(args))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	taxCfg := loadTaxesOrExit()
+
+	opt := taxes.Optimize(summary.TotalRevenues, summary.TotalDeductibleExpenses, taxCfg)
+
+	fmt.Printf("Expense Optimizer (%d)\n", summary.Year)
+	fmt.Printf("══════════════════════════════════════════\n")
+	fmt.Printf("%14s %14s %14s %14s %12s\n", "Extra Exp.", "Net Income", "Total Taxes", "Net After Tax", "Gain")
+	for _, p := range opt.Candidates {
+		fmt.Printf("%14.2f %14.2f %14.2f %14.2f %12.2f  %s / %s\n",
+			p.ExtraExpenses, p.NetIncome, p.TotalTaxes, p.NetAfterTax, p.Gain, p.CASLabel, p.CASSLabel)
+	}
+	fmt.Println()
+
+	if opt.Best.ExtraExpenses > 0 {
+		fmt.Printf("Best: spend %s more on deductible expenses, net after tax rises by %s\n",
+			taxes.FormatRON(opt.Best.ExtraExpenses), taxes.FormatRON(opt.Best.Gain))
+	} else {
+		fmt.Println("Best: no extra deductible expense pays for itself")
+	}
+	fmt.Println()
+
+	fmt.Println("Marginal Tax Rate")
+	fmt.Printf("══════════════════════════════════════════\n")
+	fmt.Printf("%14s %14s %10s %10s\n", "Net Income", "Total Taxes", "Effective", "Marginal")
+	for _, p := range opt.Curve {
+		fmt.Printf("%14.2f %14.2f %9.1f%% %9.1f%%\n", p.NetIncome, p.TotalTaxes, p.EffectiveRate, p.MarginalRate)
+	}
+}
+
+func runTaxesCompare(c *client.Client, args []string) {
+	summary, err := c.GetSummaryForYear(parseYearArg(args))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	taxCfg := loadTaxesOrExit()
+
+	primaryCAEN := ""
+	if codes, err := c.GetCAENCodes(c.CompanyID); err == nil {
+		for _, code := range codes {
+			if code.IsPrimary {
+				primaryCAEN = code.Code
+			}
+		}
+	}
+
+	results := taxes.Compare(summary.TotalRevenues, summary.TotalDeductibleExpenses, primaryCAEN, taxCfg)
+
+	fmt.Printf("Tax Regime Comparison (%d)\n", summary.Year)
+	fmt.Printf("══════════════════════════════════════════\n")
+	fmt.Printf("Total Revenues:       %s\n", taxes.FormatRON(summary.TotalRevenues))
+	fmt.Printf("Deductible Expenses:  %s\n", taxes.FormatRON(summary.TotalDeductibleExpenses))
+	fmt.Println()
+
+	fmt.Printf("%-22s", "")
+	for _, r := range results {
+		// Pad by runes, regime names carry diacritics
+		fmt.Printf(" %s%s", strings.Repeat(" ", max(20-utf8.RuneCountInString(r.Name), 0)), r.Name)
+	}
+	fmt.Println()
+
+	rows := []struct {
+		label string
+		value func(taxes.RegimeResult) money.Money
+	}{
+		{"Tax base", func(r taxes.RegimeResult) money.Money { return r.TaxBase }},
+		{"Income/turnover tax", func(r taxes.RegimeResult) money.Money { return r.IncomeTax }},
+		{"CAS", func(r taxes.RegimeResult) money.Money { return r.CAS }},
+		{"CASS", func(r taxes.RegimeResult) money.Money { return r.CASS }},
+		{"Dividend tax", func(r taxes.RegimeResult) money.Money { return r.DividendTax }},
+		{"Total taxes", func(r taxes.RegimeResult) money.Money { return r.TotalTaxes }},
+		{"Net in pocket", func(r taxes.RegimeResult) money.Money { return r.NetInPocket }},
+	}
+	for _, row := range rows {
+		fmt.Printf("%-22s", row.label)
+		for _, r := range results {
+			if !r.Available {
+				fmt.Printf(" %20s", "n/a")
+				continue
+			}
+			fmt.Printf(" %20s", row.value(r))
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+
+	best := -1
+	for i, r := range results {
+		if r.Available && (best < 0 || r.NetInPocket > results[best].NetInPocket) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		fmt.Printf("Best: %s (%s in pocket)\n", results[best].Name, taxes.FormatRON(results[best].NetInPocket))
+	}
+	for _, r := range results {
+		if r.Note != "" {
+			fmt.Printf("  %s: %s\n", r.Name, r.Note)
+		}
+	}
+}
+
 func printThresholdHint(t taxes.ThresholdResult) {
 	if t.PrevLabel != "" {
 		fmt.Printf("  %s (→ %s)\n", taxes.FormatExpensesHint(t.ExpensesToPrev), t.PrevLabel)
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"solo-cli/audit"
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/taxes"
+)
+
+// runExportAudit writes the year's company profile, revenues, expenses,
+// rejected documents, e-Factura entries, tax breakdown and original
+// documents as a ZIP with a checksum manifest. Documents that cannot be
+// downloaded are listed in the manifest instead of failing the export
+func runExportAudit(c *client.Client, args []string) {
+	documents := true
+	var rest []string
+	for _, arg := range args {
+		if arg == "--no-documents" {
+			documents = false
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	opts := parseExportArgs(rest)
+	if opts.output == "" {
+		opts.output = fmt.Sprintf("solo-audit-%d.zip", opts.year)
+	}
+
+	revenues, err := c.ListAllRevenues()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	expenses, err := c.ListAllExpenses()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	rejected, err := c.ListAllRejectedExpenses()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	efactura, err := c.ListAllEFactura()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	summary, err := c.GetSummaryForYear(opts.year)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	taxCfg := loadTaxesOrExit()
+	extra := loadExtraIncome(opts.year, nil)
+
+	revenues = audit.Revenues(revenues, opts.year)
+	expenses = audit.Expenses(expenses, opts.year)
+	rejected = audit.Rejected(rejected, opts.year)
+	efactura = audit.EFactura(efactura, opts.year)
+
+	// Written next to the target and renamed, so a failed export never
+	// leaves a truncated archive behind
+	tmp := opts.output + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fail := func(err error) {
+		f.Close()
+		os.Remove(tmp)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	zw := audit.NewWriter(f, opts.year, "solo-cli "+version, time.Now())
+	m := &zw.Manifest
+	m.Counts["revenues"] = len(revenues)
+	m.Counts["expenses"] = len(expenses)
+	m.Counts["rejected"] = len(rejected)
+	m.Counts["efactura"] = len(efactura)
+
+	if c.CompanyID == "" {
+		zw.Note("company profile: could not determine company ID")
+	} else {
+		company, err := c.GetCompanyInfo(c.CompanyID)
+		if err != nil {
+			zw.Note("company profile: %v", err)
+		}
+		codes, err := c.GetCAENCodes(c.CompanyID)
+		if err != nil {
+			zw.Note("CAEN codes: %v", err)
+		}
+		if company != nil {
+			m.Company, m.CUI = company.Name, company.Code1
+		}
+		if err := zw.AddJSON("company.json", "Company profile and CAEN codes", struct {
+			Company   *client.CompanyInfo `json:"company"`
+			CAENCodes []client.CAENCode   `json:"caen_codes"`
+		}{company, codes}); err != nil {
+			fail(err)
+		}
+	}
+
+	// Documents first, so the tables can name the archived files
+	paths := map[string]string{}
+	if documents {
+		missing := 0
+		for _, d := range audit.Documents(expenses, rejected) {
+			data, contentType, err := c.DownloadDocument(d.Code)
+			if err != nil {
+				missing++
+				zw.Note("document %s: %v", d.Code, err)
+				continue
+			}
+			path := d.Path(contentType)
+			if err := zw.Add(path, "Original document "+d.Code, data); err != nil {
+				fail(err)
+			}
+			paths[d.Code] = path
+		}
+		m.Counts["documents"] = len(paths)
+		m.Counts["missing_documents"] = missing
+	} else {
+		zw.Note("documents: left out with --no-documents")
+	}
+
+	tables := []struct {
+		name, description string
+		items             any
+		csv               func() ([]byte, error)
+	}{
+		{"revenues", "Invoices issued in the year", revenues, func() ([]byte, error) { return audit.RevenuesCSV(revenues) }},
+		{"expenses", "Expenses purchased in the year", expenses, func() ([]byte, error) { return audit.ExpensesCSV(expenses, paths) }},
+		{"rejected", "Documents submitted in the year and rejected", rejected, func() ([]byte, error) { return audit.RejectedCSV(rejected, paths) }},
+		{"efactura", "Supplier invoices received through e-Factura", efactura, func() ([]byte, error) { return audit.EFacturaCSV(efactura) }},
+	}
+	for _, t := range tables {
+		if err := zw.AddJSON(t.name+".json", t.description, t.items); err != nil {
+			fail(err)
+		}
+		data, err := t.csv()
+		if err != nil {
+			fail(err)
+		}
+		if err := zw.Add(t.name+".csv", t.description, data); err != nil {
+			fail(err)
+		}
+	}
+
+	breakdown := taxes.CalculateWithIncome(summary.TotalRevenues, summary.TotalDeductibleExpenses, extra, taxCfg)
+	if err := zw.AddJSON("taxes.json", "Summary totals and the tax breakdown under the configured rules", struct {
+		Summary   *client.Summary      `json:"summary"`
+		Income    []config.ExtraIncome `json:"extra_income,omitempty"`
+		Breakdown *taxes.TaxBreakdown  `json:"breakdown"`
+	}{summary, extra, breakdown}); err != nil {
+		fail(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		fail(err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.Rename(tmp, opts.output); err != nil {
+		os.Remove(tmp)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %s: %d revenues, %d expenses, %d rejected, %d e-Factura, %d documents\n",
+		opts.output, len(revenues), len(expenses), len(rejected), len(efactura), len(paths))
+	for _, note := range m.Notes {
+		fmt.Fprintf(os.Stderr, "  note: %s\n", note)
+	}
+}
+
+// runExportVerify checks an audit archive against its manifest
+func runExportVerify(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: solo-cli export verify <archive.zip>")
+		os.Exit(1)
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	m, problems, err := audit.Verify(f, info.Size())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	company := ""
+	if m.Company != "" {
+		company = " for " + m.Company
+	}
+	fmt.Printf("Audit archive %d%s, generated %s by %s\n", m.Year, company, m.GeneratedAt.Local().Format("2006-01-02 15:04"), m.Generator)
+	for _, p := range problems {
+		fmt.Printf("  FAILED %s\n", p)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problem(s) in %d files\n", len(problems), len(m.Files))
+		os.Exit(1)
+	}
+	fmt.Printf("All %d files match their SHA-256 checksums\n", len(m.Files))
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"solo-cli/bank"
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+const bankUsage = `Usage: solo-cli bank import <statement> [--format csv|mt940|camt053] [--bank bt|ing|bcr] [--json]
+  Matches the statement's credits to the unpaid invoices and its debits to
+  the expenses, listing what is left unmatched`
+
+// bankTextWidth is the width of the client, supplier and details columns
+const bankTextWidth = 28
+
+func runBank(c *client.Client, args []string) {
+	if len(args) == 0 || args[0] != "import" {
+		fmt.Fprintln(os.Stderr, bankUsage)
+		os.Exit(1)
+	}
+	runBankImport(c, args[1:])
+}
+
+// runBankImport parses a bank statement and reconciles it with the open
+// invoices and the expenses
+func runBankImport(c *client.Client, args []string) {
+	var path, csvBank string
+	var kind bank.Kind
+	asJSON := false
+	value := func(i int) string {
+		if i+1 >= len(args) {
+			fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+			fmt.Fprintln(os.Stderr, bankUsage)
+			os.Exit(1)
+		}
+		return args[i+1]
+	}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format", "-f":
+			k, err := bank.ParseKind(value(i))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			kind = k
+			i++
+		case "--bank":
+			csvBank = value(i)
+			i++
+		case "--json":
+			asJSON = true
+		default:
+			if strings.HasPrefix(args[i], "-") || path != "" {
+				fmt.Fprintf(os.Stderr, "Unknown option: %s\n", args[i])
+				fmt.Fprintln(os.Stderr, bankUsage)
+				os.Exit(1)
+			}
+			path = args[i]
+		}
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "Error: missing statement file")
+		fmt.Fprintln(os.Stderr, bankUsage)
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	stmt, err := bank.Parse(path, data, kind, csvBank)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	revenues, err := c.ListAllRevenues()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	expenses, err := c.ListAllExpenses()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	rec := bank.Reconcile(stmt, revenues, expenses)
+
+	if asJSON {
+		printJSON(struct {
+			Statement *bank.Statement `json:"statement"`
+			*bank.Reconciliation
+		}{stmt, rec})
+		return
+	}
+	printReconciliation(stmt, rec)
+}
+
+func printReconciliation(stmt *bank.Statement, rec *bank.Reconciliation) {
+	store := loadRates()
+
+	first, last := stmt.Period()
+	account := ""
+	if stmt.Account != "" {
+		account = ", " + stmt.Account
+	}
+	fmt.Printf("Bank statement: %s%s, %d transactions (%s to %s)\n", stmt.Format, account, len(stmt.Transactions), first, last)
+	fmt.Printf("══════════════════════════════════════════\n")
+
+	amount := func(t bank.Transaction) string {
+		return fmt.Sprintf("%12s %s", t.Amount, t.Currency)
+	}
+
+	fmt.Printf("\nPaid invoices still open in SOLO (%d, %.2f RON)\n", len(rec.Invoices), rec.PaidRON(store.RevenueRON))
+	if len(rec.Invoices) > 0 {
+		fmt.Printf("%-10s  %-16s %-*s %16s  %s\n", "Date", "Invoice", bankTextWidth, "Client", "Amount", "Matched by")
+	}
+	for _, m := range rec.Invoices {
+		fmt.Printf("%-10s  %-16s %-*s %16s  %s\n", m.Transaction.Date, fitColumn(m.Invoice.SerialCode, 16),
+			bankTextWidth, fitColumn(m.Invoice.ClientName, bankTextWidth), amount(m.Transaction), m.By)
+	}
+
+	fmt.Printf("\nExpenses paid (%d)\n", len(rec.Expenses))
+	if len(rec.Expenses) > 0 {
+		fmt.Printf("%-10s  %-10s %-*s %16s  %s\n", "Date", "Purchased", bankTextWidth, "Supplier", "Amount", "Matched by")
+	}
+	for _, m := range rec.Expenses {
+		fmt.Printf("%-10s  %-10s %-*s %16s  %s\n", m.Transaction.Date, client.Day(m.Expense.PurchaseDate),
+			bankTextWidth, fitColumn(m.Expense.SupplierName, bankTextWidth), amount(m.Transaction), m.By)
+	}
+
+	for _, section := range []struct {
+		title string
+		lines []bank.Unmatched
+	}{
+		{"Unmatched credits", rec.UnmatchedCredits},
+		{"Unmatched debits", rec.UnmatchedDebits},
+	} {
+		fmt.Printf("\n%s (%d)\n", section.title, len(section.lines))
+		if len(section.lines) > 0 {
+			fmt.Printf("%-10s  %16s  %-*s  %s\n", "Date", "Amount", bankTextWidth, "Details", "Note")
+		}
+		for _, u := range section.lines {
+			t := u.Transaction
+			details := strings.TrimSpace(t.Counterparty + " " + t.Description)
+			if details == "" {
+				details = t.Reference
+			}
+			fmt.Printf("%-10s  %16s  %-*s  %s\n", t.Date, amount(t), bankTextWidth, fitColumn(details, bankTextWidth), u.Note)
+		}
+	}
+
+	var open money.Money
+	for _, inv := range rec.StillOpen {
+		open += store.RevenueRON(inv)
+	}
+	fmt.Printf("\nStill unpaid: %d invoice(s), %.2f RON\n", len(rec.StillOpen), open)
+	if len(rec.Invoices) > 0 {
+		fmt.Fprintf(os.Stderr, "%d invoice(s) were paid but are still open in SOLO, mark them as paid there\n", len(rec.Invoices))
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"solo-cli/calendar"
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/taxes"
+)
+
+// calendarMonths is how far ahead the calendar looks
+const calendarMonths = 12
+
+func runCalendar(c *client.Client, args []string) {
+	calCfg, err := config.LoadCalendar()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading calendar config: %v\n", err)
+		os.Exit(1)
+	}
+	taxCfg := loadTaxesOrExit()
+
+	extra, err := config.LoadExtraIncome()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading income.json: %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	deadlines := calendar.Upcoming(calCfg.Rules, now, calendarMonths, calendar.YearBreakdowns(c.GetSummaryForYear, taxCfg, extra))
+
+	if len(args) > 0 && args[0] == "ics" {
+		out := os.Stdout
+		if len(args) > 1 {
+			f, err := os.Create(args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			out = f
+		}
+		if err := calendar.WriteICS(out, deadlines, now); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(args) > 1 {
+			fmt.Fprintf(os.Stderr, "Wrote %d deadline(s) to %s\n", len(deadlines), args[1])
+		}
+		return
+	}
+
+	for _, d := range deadlines {
+		amount := ""
+		if d.HasAmount {
+			amount = taxes.FormatRON(d.Amount)
+		}
+		fmt.Printf("%s\t%s\t%s\n", d.Date.Format("2006-01-02"), d.Rule.Title, amount)
+	}
+}
@@ -0,0 +1,555 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"solo-cli/config"
+	"solo-cli/daemon"
+	"solo-cli/notify"
+)
+
+const daemonUsage = `Usage: solo-cli daemon [--log json|text] [--log-file file]
+       solo-cli daemon status [--json]
+       solo-cli daemon run <job>
+
+Runs the jobs of the "jobs" section of config.json on their cron schedules
+until SIGINT or SIGTERM. Job kinds: sync (JSON copy of the data into dir),
+upload (documents dropped into the dir watch folder), notify, report, export
+and command (any solo-cli command, in args). status shows the last runs,
+run starts one job right away.`
+
+// jobKinds are the values of a job's "run"
+var jobKinds = []string{"sync", "upload", "notify", "report", "export", "command"}
+
+// jobOutputLimit bounds the command output kept in the logs
+const jobOutputLimit = 2000
+
+// uploadSettle is how long a file in the watch folder must stay unchanged
+// before it is uploaded, so files still being written are left alone
+const uploadSettle = 5 * time.Second
+
+// jobRunner holds what the jobs share: one session for the built-in kinds
+// and the binary the command kinds run
+type jobRunner struct {
+	b          *sessionBackend
+	dispatcher *notify.Dispatcher
+	exe        string
+	configPath string
+}
+
+// buildJobs checks the job configs and builds the jobs. The session is only
+// set up when a built-in kind needs it
+func buildJobs(cfg *config.Config) ([]*daemon.Job, error) {
+	if len(cfg.Jobs) == 0 {
+		return nil, errors.New(`no jobs configured, add a "jobs" section to config.json`)
+	}
+	r := &jobRunner{}
+	var problems []error
+	var jobs []*daemon.Job
+	seen := map[string]bool{}
+	needsSession, needsNotify := false, false
+	for i, jc := range cfg.Jobs {
+		fail := func(format string, args ...any) {
+			problems = append(problems, fmt.Errorf("job %d (%s): %s", i+1, jc.Name, fmt.Sprintf(format, args...)))
+		}
+		switch {
+		case jc.Name == "":
+			fail("name is empty")
+		case seen[jc.Name]:
+			fail("duplicate name")
+		}
+		seen[jc.Name] = true
+		schedule, err := daemon.ParseSchedule(jc.Schedule)
+		if err != nil {
+			fail("%v", err)
+		}
+		timeout := 30 * time.Minute
+		if jc.Timeout != "" {
+			if timeout, err = time.ParseDuration(jc.Timeout); err != nil || timeout <= 0 {
+				fail("invalid timeout %q (e.g. 10m)", jc.Timeout)
+			}
+		}
+		switch jc.Run {
+		case "sync", "upload", "notify":
+			needsSession = true
+			needsNotify = needsNotify || jc.Run == "notify"
+			if jc.Run == "upload" && jc.Dir == "" {
+				fail("upload needs the dir to watch")
+			}
+		case "report", "export", "command":
+			if len(jc.Args) == 0 {
+				fail("%s needs args", jc.Run)
+			}
+		default:
+			fail("unknown run %q (%s)", jc.Run, strings.Join(jobKinds, ", "))
+		}
+		jobs = append(jobs, &daemon.Job{Name: jc.Name, Spec: jc.Schedule, Schedule: schedule, Timeout: timeout, Run: r.runner(jc)})
+	}
+	if needsNotify {
+		d, err := notify.NewDispatcher(cfg.Notify)
+		if err != nil {
+			problems = append(problems, err)
+		}
+		r.dispatcher = d
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid jobs config: %w", errors.Join(problems...))
+	}
+
+	var err error
+	if r.exe, err = os.Executable(); err != nil {
+		return nil, err
+	}
+	if path, err := config.GetConfigPath(); err == nil {
+		r.configPath, _ = filepath.Abs(path)
+	}
+	if needsSession {
+		c, cfg := setupClient()
+		r.b = &sessionBackend{c: c, cfg: cfg}
+	}
+	return jobs, nil
+}
+
+// runner returns the function that runs a job of the given config
+func (r *jobRunner) runner(jc config.JobConfig) func(context.Context, *slog.Logger) error {
+	switch jc.Run {
+	case "sync":
+		return func(ctx context.Context, log *slog.Logger) error { return r.sync(jc.Dir, log) }
+	case "upload":
+		return func(ctx context.Context, log *slog.Logger) error { return r.upload(ctx, expandHome(jc.Dir), log) }
+	case "notify":
+		return r.notify
+	case "report", "export":
+		return func(ctx context.Context, log *slog.Logger) error {
+			return r.command(ctx, append([]string{jc.Run}, jc.Args...), log)
+		}
+	}
+	return func(ctx context.Context, log *slog.Logger) error { return r.command(ctx, jc.Args, log) }
+}
+
+// sync writes the year's summary and the lists as JSON files into dir,
+// ~/.config/solo-cli/sync by default
+func (r *jobRunner) sync(dir string, log *slog.Logger) error {
+	if dir == "" {
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(configDir, "sync")
+	}
+	dir = expandHome(dir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	files := map[string]func() (any, error){
+		"summary.json":  func() (any, error) { return r.b.Summary(0) },
+		"revenues.json": func() (any, error) { return r.b.Revenues() },
+		"expenses.json": func() (any, error) { return r.b.Expenses() },
+		"queue.json":    func() (any, error) { return r.b.Queue() },
+		"rejected.json": func() (any, error) { return r.b.Rejected() },
+		"efactura.json": func() (any, error) { return r.b.EFactura() },
+	}
+	for name, read := range files {
+		var data any
+		err := withRelogin(r.b, func() (err error) {
+			data, err = read()
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := writeJSONFile(filepath.Join(dir, name), data); err != nil {
+			return err
+		}
+	}
+	log.Info("synced", "dir", dir, "files", len(files))
+	return nil
+}
+
+// writeJSONFile replaces a file with indented JSON through a temporary file
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// upload sends the documents in the watch folder to the expense queue and
+// moves each uploaded one into its "uploaded" subfolder. Hidden files,
+// partial downloads and files changed in the last seconds are left alone
+func (r *jobRunner) upload(ctx context.Context, dir string, log *slog.Logger) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	done := filepath.Join(dir, "uploaded")
+	uploaded, failed := 0, 0
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || strings.HasPrefix(name, ".") || isPartialDownload(name) {
+			continue
+		}
+		if info, err := e.Info(); err != nil || time.Since(info.ModTime()) < uploadSettle {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		path := filepath.Join(dir, name)
+		err := withRelogin(r.b, func() error {
+			_, err := r.b.Upload(path)
+			return err
+		})
+		if err != nil {
+			failed++
+			log.Error("upload failed", "file", name, "error", err.Error())
+			continue
+		}
+		if err := os.MkdirAll(done, 0755); err != nil {
+			return err
+		}
+		dest := freePath(filepath.Join(done, name))
+		if err := os.Rename(path, dest); err != nil {
+			return fmt.Errorf("%s was uploaded but could not be moved, it will be uploaded again: %w", name, err)
+		}
+		uploaded++
+		log.Info("uploaded", "file", name, "moved_to", dest)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d documents failed to upload", failed, failed+uploaded)
+	}
+	return nil
+}
+
+// isPartialDownload reports files browsers and sync tools are still writing
+func isPartialDownload(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".part", ".crdownload", ".download", ".tmp":
+		return true
+	}
+	return false
+}
+
+// freePath returns path, or path with a number before the extension when a
+// file by that name exists
+func freePath(path string) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~"); ok && (rest == "" || rest[0] == '/' || rest[0] == filepath.Separator) {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// notify polls for events once, logging every delivery
+func (r *jobRunner) notify(ctx context.Context, log *slog.Logger) error {
+	deliveries, recorded, err := notifyPoll(ctx, r.b, r.dispatcher)
+	failed := 0
+	for _, del := range deliveries {
+		if del.Err != nil {
+			failed++
+			log.Error("notification failed", "event", del.Event.ID, "notifier", del.Notifier, "error", del.Err.Error())
+			continue
+		}
+		log.Info("notified", "event", del.Event.ID, "notifier", del.Notifier, "title", del.Event.Title)
+	}
+	if recorded > 0 {
+		log.Info("recorded current events", "events", recorded)
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d notifications failed, retrying on the next run", failed)
+	}
+	return nil
+}
+
+// command runs solo-cli with args as a child process, so a command that
+// exits cannot take the daemon down. Its output goes to the log
+func (r *jobRunner) command(ctx context.Context, args []string, log *slog.Logger) error {
+	if r.configPath != "" {
+		args = append([]string{"--config", r.configPath}, args...)
+	}
+	cmd := exec.CommandContext(ctx, r.exe, args...)
+	cmd.WaitDelay = 10 * time.Second
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	output := strings.TrimSpace(out.String())
+	if len(output) > jobOutputLimit {
+		output = "..." + output[len(output)-jobOutputLimit:]
+	}
+	if err != nil {
+		if output != "" {
+			return fmt.Errorf("%w: %s", err, output)
+		}
+		return err
+	}
+	if output != "" {
+		log.Info("output", "text", output)
+	}
+	return nil
+}
+
+func runDaemon(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "status":
+			runDaemonStatus(args[1:])
+			return
+		case "run":
+			runDaemonJob(args[1:])
+			return
+		}
+	}
+	format, logFile := "json", ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--log", "--log-file":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+				os.Exit(1)
+			}
+			if args[i] == "--log" {
+				format = args[i+1]
+			} else {
+				logFile = args[i+1]
+			}
+			i++
+		case "--help", "-h":
+			fmt.Println(daemonUsage)
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown argument '%s'\n", args[i])
+			fmt.Fprintln(os.Stderr, daemonUsage)
+			os.Exit(1)
+		}
+	}
+
+	var out io.Writer = os.Stderr
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(out, nil)
+	case "text":
+		handler = slog.NewTextHandler(out, nil)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid --log '%s' (json or text)\n", format)
+		os.Exit(1)
+	}
+	logger := slog.New(handler)
+
+	// Lock before logging in, so a second daemon stops right away
+	lockPath, err := daemon.GetLockPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	lock, err := daemon.AcquireLock(lockPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer lock.Release()
+	jobs, err := buildJobs(loadConfigOrExit())
+	if err != nil {
+		lock.Release()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	s := &daemon.Scheduler{
+		Jobs:  jobs,
+		Log:   logger,
+		Grace: 30 * time.Second,
+		Save: func(st *daemon.Status) {
+			if err := daemon.SaveStatus(st); err != nil {
+				logger.Warn("could not save status", "error", err.Error())
+			}
+		},
+	}
+	s.Run(ctx)
+}
+
+// loadConfigOrExit reads config.json for commands that need its settings
+// before logging in
+func loadConfigOrExit() *config.Config {
+	if err := config.EnsureExists(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating config file: %v\n", err)
+		os.Exit(1)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// runDaemonJob runs one configured job right away, logging as text
+func runDaemonJob(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Error: name the job to run")
+		fmt.Fprintln(os.Stderr, "Usage: solo-cli daemon run <job>")
+		os.Exit(1)
+	}
+	cfg := loadConfigOrExit()
+	found := false
+	for _, jc := range cfg.Jobs {
+		found = found || jc.Name == args[0]
+	}
+	if !found {
+		fmt.Fprintf(os.Stderr, "Error: no job named '%s' in config.json\n", args[0])
+		os.Exit(1)
+	}
+	// A run next to the daemon's could send or upload the same thing twice
+	if lockPath, err := daemon.GetLockPath(); err == nil {
+		if pid, running := daemon.LockHolder(lockPath); running {
+			fmt.Fprintf(os.Stderr, "Error: the daemon is running (pid %d) and runs '%s' on its schedule, stop it first\n", pid, args[0])
+			os.Exit(1)
+		}
+	}
+	jobs, err := buildJobs(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, j := range jobs {
+		if j.Name != args[0] {
+			continue
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if j.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, j.Timeout)
+			defer cancel()
+		}
+		log := slog.New(slog.NewTextHandler(os.Stderr, nil)).With("job", j.Name)
+		if err := j.Run(ctx, log); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func runDaemonStatus(args []string) {
+	asJSON := false
+	for _, a := range args {
+		if a != "--json" {
+			fmt.Fprintf(os.Stderr, "Error: unknown argument '%s'\n", a)
+			fmt.Fprintln(os.Stderr, "Usage: solo-cli daemon status [--json]")
+			os.Exit(1)
+		}
+		asJSON = true
+	}
+	lockPath, err := daemon.GetLockPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	st, err := daemon.LoadStatus()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	pid, running := daemon.LockHolder(lockPath)
+
+	if asJSON {
+		printJSON(struct {
+			Running bool `json:"running"`
+			*daemon.Status
+		}{running, st})
+		return
+	}
+
+	switch {
+	case running:
+		since := ""
+		if st != nil && st.PID == pid {
+			since = " since " + st.Started.Format("2006-01-02 15:04")
+		}
+		fmt.Printf("Daemon running (pid %d)%s\n", pid, since)
+	case st == nil:
+		fmt.Println("Daemon has not run yet")
+		return
+	case st.Stopped.IsZero():
+		fmt.Printf("Daemon not running (pid %d ended without shutting down)\n", st.PID)
+	default:
+		fmt.Printf("Daemon not running (stopped %s)\n", st.Stopped.Format("2006-01-02 15:04"))
+	}
+	if st == nil || len(st.Jobs) == 0 {
+		return
+	}
+	fmt.Printf("══════════════════════════════════════════\n")
+	fmt.Printf("%-16s %-16s %-16s %-16s %s\n", "Job", "Schedule", "Next run", "Last run", "Result")
+	for _, j := range st.Jobs {
+		next, last, result := "-", "-", "-"
+		if running && !j.Next.IsZero() {
+			next = j.Next.Format("2006-01-02 15:04")
+		}
+		if j.Last != nil {
+			last = j.Last.Started.Format("2006-01-02 15:04")
+			result = fmt.Sprintf("ok in %.1fs", j.Last.Duration)
+			if j.Last.Error != "" {
+				result = "failed: " + firstLine(j.Last.Error)
+			}
+		}
+		if running && j.Running {
+			result = "running"
+		}
+		fmt.Printf("%-16s %-16s %-16s %-16s %s\n", fitColumn(j.Name, 16), fitColumn(j.Schedule, 16), next, last, result)
+		if j.Failures > 0 || j.Skipped > 0 {
+			fmt.Printf("%-16s %d runs, %d failed, %d skipped while still running\n", "", j.Runs, j.Failures, j.Skipped)
+		}
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/journal"
+	"solo-cli/money"
+)
+
+const exportUsage = `Usage: solo-cli export <format> [--year Y] [--output file]
+  ledger, hledger, beancount             Invoices, payments and expenses as a
+                                         plain-text accounting journal
+  audit [--no-documents]                 ZIP archive of the year's data and
+                                         documents with a SHA-256 manifest
+  verify <archive.zip>                   Check an audit archive's checksums
+Expense categories map to accounts in ~/.config/solo-cli/accounts.json`
+
+// exportOptions are the flags of the export subcommands
+type exportOptions struct {
+	year   int
+	output string // stdout when empty
+}
+
+func parseExportArgs(args []string) exportOptions {
+	opts := exportOptions{year: time.Now().Year()}
+	value := func(i int) string {
+		if i+1 >= len(args) {
+			fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+			fmt.Fprintln(os.Stderr, exportUsage)
+			os.Exit(1)
+		}
+		return args[i+1]
+	}
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--year", "-y":
+			opts.year = parseYearArg([]string{value(i)})
+			i++
+		case "--output", "-o":
+			opts.output = value(i)
+			i++
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				fmt.Fprintf(os.Stderr, "Unknown option: %s\n", args[i])
+				fmt.Fprintln(os.Stderr, exportUsage)
+				os.Exit(1)
+			}
+			opts.year = parseYearArg(args[i:])
+		}
+	}
+	return opts
+}
+
+func runExport(c *client.Client, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, exportUsage)
+		os.Exit(1)
+	}
+	if args[0] == "audit" {
+		runExportAudit(c, args[1:])
+		return
+	}
+
+	format, err := journal.ParseFormat(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unknown export: %s\n", args[0])
+		fmt.Fprintln(os.Stderr, exportUsage)
+		os.Exit(1)
+	}
+	runExportJournal(c, format, args[1:])
+}
+
+// runExportJournal writes the year's invoices, payments and expenses as a
+// ledger or beancount journal. The output only changes when the data does
+func runExportJournal(c *client.Client, format journal.Format, args []string) {
+	opts := parseExportArgs(args)
+
+	accounts, err := config.LoadAccounts()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading accounts.json: %v\n", err)
+		os.Exit(1)
+	}
+	revenues, err := c.ListAllRevenues()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	expenses, err := c.ListAllExpenses()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	store := loadRates()
+	txns := journal.Build(revenues, expenses, opts.year, accounts,
+		func(r client.Revenue) (money.Money, error) { return store.RevenueIn(r, "RON") },
+		func(e client.Expense) (money.Money, error) { return store.ExpenseIn(e, "RON") })
+
+	var buf bytes.Buffer
+	if err := journal.Write(&buf, txns, opts.year, format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := os.WriteFile(opts.output, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d transactions to %s\n", len(txns), opts.output)
+}
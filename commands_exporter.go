@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"solo-cli/metrics"
+)
+
+const exporterUsage = `Usage: solo-cli exporter [--listen 127.0.0.1:9787] [--interval 5m]
+
+Polls SOLO.ro every --interval and serves the figures as Prometheus gauges on
+/metrics: revenues, deductible expenses and estimated taxes of the year, document
+counts, queued and overdue documents, rejected documents, unpaid receivables
+and polling errors.`
+
+func runExporter(args []string) {
+	listen, interval := "127.0.0.1:9787", 5*time.Minute
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--listen", "-l", "--interval":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+				os.Exit(1)
+			}
+			v := args[i+1]
+			i++
+			if args[i-1] == "--interval" {
+				d, err := time.ParseDuration(v)
+				if err != nil || d < time.Second {
+					fmt.Fprintf(os.Stderr, "Error: invalid --interval '%s' (e.g. 30s, 5m, at least 1s)\n", v)
+					os.Exit(1)
+				}
+				interval = d
+			} else {
+				listen = v
+			}
+		case "--help", "-h":
+			fmt.Println(exporterUsage)
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown argument '%s'\n", args[i])
+			fmt.Fprintln(os.Stderr, exporterUsage)
+			os.Exit(1)
+		}
+	}
+
+	c, cfg := setupClient()
+	store := loadRates()
+	collector := metrics.NewCollector(&sessionBackend{c: c, cfg: cfg}, store.RevenueRON)
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if host, _, _ := net.SplitHostPort(ln.Addr().String()); !net.ParseIP(host).IsLoopback() {
+		fmt.Fprintf(os.Stderr, "⚠️  Listening on %s, reachable from other machines: anyone can read your revenue and tax totals\n", host)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", collector)
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "solo-cli exporter, metrics at /metrics")
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go collector.Run(ctx, interval)
+
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Listening on http://%s/metrics, polling SOLO.ro every %s\n", ln.Addr(), interval)
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	<-drained
+	fmt.Fprintln(os.Stderr, "Stopped")
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/mcp"
+	"solo-cli/money"
+	"solo-cli/taxes"
+)
+
+// mcpListLimit is how many items the list tools return unless asked
+const mcpListLimit = 100
+
+const mcpInstructions = `SOLO.ro accounting data of a Romanian PFA (sole trader). Amounts are numbers in the item's currency with two decimals; dates are ISO. calculate_taxes follows the user's taxes.json and income.json. delete_queued_expense needs the user's approval: call it with "confirm": true only after they agreed.`
+
+var (
+	yearProperty   = mcp.Property{Type: "integer", Description: "Calendar year, e.g. 2025. Defaults to the current year"}
+	searchProperty = mcp.Property{Type: "string", Description: "Text to look for in the names, case-insensitive"}
+	limitProperty  = mcp.Property{Type: "integer", Description: "Maximum number of items, 100 by default"}
+)
+
+// readOnly are the annotations of the tools that only read SOLO.ro data
+var readOnly = mcp.Annotations{ReadOnlyHint: true, IdempotentHint: true, OpenWorldHint: true}
+
+// listArgs are the arguments shared by the list tools
+type listArgs struct {
+	Year   int    `json:"year"`
+	Search string `json:"search"`
+	Limit  int    `json:"limit"`
+}
+
+func (a listArgs) limit() int {
+	if a.Limit <= 0 {
+		return mcpListLimit
+	}
+	return a.Limit
+}
+
+// listResult is the structured result of the list tools
+type listResult[T any] struct {
+	Items []T `json:"items"`
+	Count int `json:"count"`
+	Total int `json:"total"` // before the limit
+}
+
+func newListResult[T any](items []T, limit int) listResult[T] {
+	total := len(items)
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return listResult[T]{Items: items, Count: len(items), Total: total}
+}
+
+// matches does a case-insensitive search over a few fields
+func matches(search string, fields ...string) bool {
+	search = strings.ToLower(search)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// mcpTools are the tools offered by 'solo-cli mcp'
+func mcpTools(b *sessionBackend) []*mcp.Tool {
+	return []*mcp.Tool{
+		{
+			Name:        "get_summary",
+			Description: "Yearly totals from SOLO.ro: revenues, deductible expenses and the taxes SOLO computed, in RON",
+			Properties:  map[string]mcp.Property{"year": yearProperty},
+			Annotations: readOnly,
+			Handler: func(raw json.RawMessage) (any, error) {
+				var args struct {
+					Year int `json:"year"`
+				}
+				if err := mcp.Args(raw, &args); err != nil {
+					return nil, err
+				}
+				return b.Summary(args.Year)
+			},
+		},
+		{
+			Name:        "list_revenues",
+			Description: "Issued invoices, newest first, with client, amount, currency, RON amount and whether they are paid",
+			Properties: map[string]mcp.Property{
+				"year": {Type: "integer", Description: "Only invoices issued in this year"}, "search": searchProperty, "limit": limitProperty,
+				"unpaid": {Type: "boolean", Description: "Only invoices not yet paid"},
+			},
+			Annotations: readOnly,
+			Handler: func(raw json.RawMessage) (any, error) {
+				var args struct {
+					listArgs
+					Unpaid bool `json:"unpaid"`
+				}
+				if err := mcp.Args(raw, &args); err != nil {
+					return nil, err
+				}
+				items, err := b.Revenues()
+				if err != nil {
+					return nil, err
+				}
+				var out []client.Revenue
+				for _, r := range items {
+					if (args.Year != 0 && !client.InYear(r.IssueDate, args.Year)) || (args.Unpaid && r.IsPaid) ||
+						(args.Search != "" && !matches(args.Search, r.SerialCode, r.ClientName)) {
+						continue
+					}
+					out = append(out, r)
+				}
+				return newListResult(out, args.limit()), nil
+			},
+		},
+		{
+			Name:        "list_expenses",
+			Description: "Booked expenses, newest first, with supplier, category, amount and deductibility",
+			Properties: map[string]mcp.Property{
+				"year": {Type: "integer", Description: "Only expenses bought in this year"}, "search": searchProperty, "limit": limitProperty,
+			},
+			Annotations: readOnly,
+			Handler: func(raw json.RawMessage) (any, error) {
+				var args listArgs
+				if err := mcp.Args(raw, &args); err != nil {
+					return nil, err
+				}
+				items, err := b.Expenses()
+				if err != nil {
+					return nil, err
+				}
+				var out []client.Expense
+				for _, e := range items {
+					if (args.Year != 0 && !client.InYear(e.PurchaseDate, args.Year)) ||
+						(args.Search != "" && !matches(args.Search, e.SupplierName, e.Category)) {
+						continue
+					}
+					out = append(out, e)
+				}
+				return newListResult(out, args.limit()), nil
+			},
+		},
+		{
+			Name:        "list_queue",
+			Description: "Uploaded documents waiting to be booked by the accountant, with their id and whether they are overdue",
+			Properties:  map[string]mcp.Property{"limit": limitProperty},
+			Annotations: readOnly,
+			Handler: func(raw json.RawMessage) (any, error) {
+				var args listArgs
+				if err := mcp.Args(raw, &args); err != nil {
+					return nil, err
+				}
+				items, err := b.Queue()
+				if err != nil {
+					return nil, err
+				}
+				return newListResult(items, args.limit()), nil
+			},
+		},
+		{
+			Name:        "list_efactura",
+			Description: "Invoices received through e-Factura, the national electronic invoicing system",
+			Properties: map[string]mcp.Property{
+				"year": {Type: "integer", Description: "Only invoices dated in this year"}, "search": searchProperty, "limit": limitProperty,
+			},
+			Annotations: readOnly,
+			Handler: func(raw json.RawMessage) (any, error) {
+				var args listArgs
+				if err := mcp.Args(raw, &args); err != nil {
+					return nil, err
+				}
+				items, err := b.EFactura()
+				if err != nil {
+					return nil, err
+				}
+				var out []client.EFactura
+				for _, e := range items {
+					if (args.Year != 0 && !client.InYear(e.InvoiceDate, args.Year)) ||
+						(args.Search != "" && !matches(args.Search, e.SerialCode, e.PartyName)) {
+						continue
+					}
+					out = append(out, e)
+				}
+				return newListResult(out, args.limit()), nil
+			},
+		},
+		{
+			Name:        "get_company",
+			Description: "The company profile: name, CUI, registration number, address and CAEN activity codes",
+			Annotations: readOnly,
+			Handler: func(raw json.RawMessage) (any, error) {
+				if err := mcp.Args(raw, &struct{}{}); err != nil {
+					return nil, err
+				}
+				return b.Company()
+			},
+		},
+		{
+			Name: "calculate_taxes",
+			Description: "CAS, CASS and income tax for a year under the user's tax rules, from the SOLO.ro totals. " +
+				"Give revenue and deductible_expenses (RON) to compute a what-if instead",
+			Properties: map[string]mcp.Property{
+				"year":                yearProperty,
+				"revenue":             {Type: "number", Description: "Revenue in RON to use instead of the SOLO.ro total"},
+				"deductible_expenses": {Type: "number", Description: "Deductible expenses in RON to use instead of the SOLO.ro total"},
+			},
+			Annotations: readOnly,
+			Handler: func(raw json.RawMessage) (any, error) {
+				var args struct {
+					Year       int          `json:"year"`
+					Revenue    *money.Money `json:"revenue"`
+					Deductible *money.Money `json:"deductible_expenses"`
+				}
+				if err := mcp.Args(raw, &args); err != nil {
+					return nil, err
+				}
+				taxCfg, err := config.LoadTaxes()
+				if err != nil {
+					return nil, fmt.Errorf("taxes.json: %w", err)
+				}
+				year := args.Year
+				if year == 0 {
+					year = time.Now().Year()
+				}
+				revenue, deductible := money.Money(0), money.Money(0)
+				if args.Revenue == nil || args.Deductible == nil {
+					summary, err := b.Summary(year)
+					if err != nil {
+						return nil, err
+					}
+					revenue, deductible = summary.TotalRevenues, summary.TotalDeductibleExpenses
+				}
+				if args.Revenue != nil {
+					revenue = *args.Revenue
+				}
+				if args.Deductible != nil {
+					deductible = *args.Deductible
+				}
+				entries, err := config.LoadExtraIncome()
+				if err != nil {
+					return nil, fmt.Errorf("income.json: %w", err)
+				}
+				result := taxes.CalculateWithIncome(revenue, deductible, config.ExtraIncomeForYear(entries, year), taxCfg)
+				return struct {
+					Year               int         `json:"Year"`
+					Revenue            money.Money `json:"Revenue"`
+					DeductibleExpenses money.Money `json:"DeductibleExpenses"`
+					*taxes.TaxBreakdown
+				}{year, revenue, deductible, result}, nil
+			},
+		},
+		{
+			Name:        "upload_document",
+			Description: "Upload a receipt or invoice (PDF or image) from a local path to the expense queue for the accountant",
+			Properties:  map[string]mcp.Property{"path": {Type: "string", Description: "Path of the file on this computer"}},
+			Required:    []string{"path"},
+			Annotations: mcp.Annotations{OpenWorldHint: true},
+			Handler: func(raw json.RawMessage) (any, error) {
+				var args struct {
+					Path string `json:"path"`
+				}
+				if err := mcp.Args(raw, &args); err != nil {
+					return nil, err
+				}
+				if _, err := os.Stat(args.Path); err != nil {
+					return nil, err
+				}
+				name, err := b.Upload(args.Path)
+				if err != nil {
+					return nil, err
+				}
+				return map[string]string{"uploaded": name}, nil
+			},
+		},
+		{
+			Name:        "delete_queued_expense",
+			Description: "Delete a document from the expense queue by its id (see list_queue). This cannot be undone",
+			Properties:  map[string]mcp.Property{"id": {Type: "integer", Description: "Id of the queued document"}},
+			Required:    []string{"id"},
+			Annotations: mcp.Annotations{DestructiveHint: true, IdempotentHint: true, OpenWorldHint: true},
+			Confirm:     true,
+			Handler: func(raw json.RawMessage) (any, error) {
+				var args struct {
+					ID int `json:"id"`
+				}
+				if err := mcp.Args(raw, &args); err != nil {
+					return nil, err
+				}
+				if err := b.c.DeleteExpense(args.ID); err != nil {
+					return nil, err
+				}
+				return map[string]int{"deleted": args.ID}, nil
+			},
+		},
+	}
+}
+
+// runMCP serves the tools over stdio until the agent closes stdin. Stdout
+// carries only protocol messages, everything else goes to stderr
+func runMCP(args []string) {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected argument '%s'\n", args[0])
+		fmt.Fprintln(os.Stderr, "Usage: solo-cli mcp")
+		os.Exit(1)
+	}
+	c, cfg := setupClient()
+	b := &sessionBackend{c: c, cfg: cfg}
+	server := mcp.NewServer("solo-cli", version)
+	server.Instructions = mcpInstructions
+	for _, t := range mcpTools(b) {
+		t.Handler = reloginHandler(b, t.Handler)
+		server.Add(t)
+	}
+	fmt.Fprintln(os.Stderr, "solo-cli MCP server ready on stdio")
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// reloginHandler runs a tool once more after logging in again when SOLO.ro
+// ended the session, as the agent may keep the server open for hours
+func reloginHandler(b *sessionBackend, h mcp.Handler) mcp.Handler {
+	return func(raw json.RawMessage) (result any, err error) {
+		err = withRelogin(b, func() (err error) {
+			result, err = h(raw)
+			return err
+		})
+		return result, err
+	}
+}
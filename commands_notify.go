@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/daemon"
+	"solo-cli/notify"
+)
+
+const notifyUsage = `Usage: solo-cli notify [--once] [--dry-run] [--interval 5m]
+       solo-cli notify test [notifier...]
+
+Polls SOLO.ro and notifies when a document is rejected, a queued document
+becomes overdue or an invoice is paid, by the rules in the "notify" section
+of config.json. Each event is sent once; the first run only records what is
+already there. test sends a test notification to the named notifiers (all by
+default).`
+
+// notifyRead fetches what events are detected from, logging in again once
+// when SOLO.ro ended the session
+func notifyRead(b *sessionBackend) ([]notify.Event, error) {
+	var queue []client.QueuedExpense
+	var rejected []client.RejectedExpense
+	var revenues []client.Revenue
+	err := withRelogin(b, func() (err error) {
+		if queue, err = b.Queue(); err != nil {
+			return err
+		}
+		if rejected, err = b.Rejected(); err != nil {
+			return err
+		}
+		revenues, err = b.Revenues()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return notify.Detect(queue, rejected, revenues, time.Now()), nil
+}
+
+// notifyPoll sends the events not delivered yet and saves the state.
+// recorded is the number of events a first run only recorded
+func notifyPoll(ctx context.Context, b *sessionBackend, d *notify.Dispatcher) (deliveries []notify.Delivery, recorded int, err error) {
+	events, err := notifyRead(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	lockPath, err := notify.GetLockPath()
+	if err != nil {
+		return nil, 0, fmt.Errorf("notification state: %w", err)
+	}
+	lock, err := daemon.AcquireLock(lockPath)
+	var locked *daemon.LockedError
+	if errors.As(err, &locked) {
+		return nil, 0, fmt.Errorf("notifications are being sent by another process (pid %d)", locked.PID)
+	} else if err != nil {
+		return nil, 0, fmt.Errorf("notification state: %w", err)
+	}
+	defer lock.Release()
+	state, err := notify.LoadState()
+	if err != nil {
+		return nil, 0, fmt.Errorf("notification state: %w", err)
+	}
+	if state.Fresh() {
+		recorded = len(events)
+	}
+	deliveries = d.Dispatch(ctx, state, events, time.Now())
+	if err := notify.SaveState(state); err != nil {
+		return deliveries, recorded, fmt.Errorf("notification state: %w", err)
+	}
+	return deliveries, recorded, nil
+}
+
+// printNotifyPoll polls once, printing what was sent
+func printNotifyPoll(ctx context.Context, b *sessionBackend, d *notify.Dispatcher) error {
+	deliveries, recorded, err := notifyPoll(ctx, b, d)
+	failed := 0
+	for _, del := range deliveries {
+		if del.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Error: %s -> %s: %v\n", del.Event.ID, del.Notifier, del.Err)
+			continue
+		}
+		fmt.Printf("%s: %s -> %s\n", del.Event.ID, del.Event.Title, del.Notifier)
+	}
+	if recorded > 0 {
+		fmt.Fprintf(os.Stderr, "Recorded %d current events, only new ones will be notified\n", recorded)
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d notifications failed, retrying on the next poll", failed)
+	}
+	return nil
+}
+
+// printNotifyDryRun prints the events the next poll would send
+func printNotifyDryRun(b *sessionBackend, d *notify.Dispatcher) error {
+	events, err := notifyRead(b)
+	if err != nil {
+		return err
+	}
+	state, err := notify.LoadState()
+	if err != nil {
+		return fmt.Errorf("notification state: %w", err)
+	}
+	for _, e := range events {
+		if _, seen := state.Seen[e.ID]; seen && state.Pending[e.ID] == nil {
+			continue
+		}
+		if state.Fresh() {
+			fmt.Printf("%s: %s (recorded on the first run, not sent)\n", e.ID, e.Title)
+			continue
+		}
+		targets := state.Pending[e.ID]
+		if targets == nil {
+			targets = d.Targets(e)
+		}
+		fmt.Printf("%s: %s -> %s\n", e.ID, e.Title, strings.Join(targets, ", "))
+	}
+	return nil
+}
+
+// loadNotifyConfig reads config.json and builds its notifiers, exiting on
+// errors
+func loadNotifyConfig(cfg *config.Config) (*notify.Dispatcher, time.Duration) {
+	d, err := notify.NewDispatcher(cfg.Notify)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	interval := 5 * time.Minute
+	if cfg.Notify.Interval != "" {
+		interval, err = time.ParseDuration(cfg.Notify.Interval)
+		if err != nil || interval < time.Minute {
+			fmt.Fprintf(os.Stderr, "Error: invalid notify interval '%s' in config.json (e.g. 5m, at least 1m)\n", cfg.Notify.Interval)
+			os.Exit(1)
+		}
+	}
+	return d, interval
+}
+
+func runNotify(args []string) {
+	if len(args) > 0 && args[0] == "test" {
+		runNotifyTest(args[1:])
+		return
+	}
+	once, dryRun := false, false
+	var interval time.Duration
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--once":
+			once = true
+		case "--dry-run", "-n":
+			dryRun = true
+		case "--interval":
+			if i+1 >= len(args) {
+				fmt.Fprintln(os.Stderr, "Error: --interval requires a value")
+				os.Exit(1)
+			}
+			d, err := time.ParseDuration(args[i+1])
+			if err != nil || d < time.Minute {
+				fmt.Fprintf(os.Stderr, "Error: invalid --interval '%s' (e.g. 5m, at least 1m)\n", args[i+1])
+				os.Exit(1)
+			}
+			interval = d
+			i++
+		case "--help", "-h":
+			fmt.Println(notifyUsage)
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown argument '%s'\n", args[i])
+			fmt.Fprintln(os.Stderr, notifyUsage)
+			os.Exit(1)
+		}
+	}
+
+	c, cfg := setupClient()
+	d, configured := loadNotifyConfig(cfg)
+	if interval == 0 {
+		interval = configured
+	}
+	b := &sessionBackend{c: c, cfg: cfg}
+
+	if once || dryRun {
+		poll := func() error { return printNotifyPoll(context.Background(), b, d) }
+		if dryRun {
+			poll = func() error { return printNotifyDryRun(b, d) }
+		}
+		if err := poll(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "Polling SOLO.ro every %s\n", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := printNotifyPoll(ctx, b, d); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(os.Stderr, "Stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNotifyTest sends a test event, to check the notifier settings without
+// waiting for a real one
+func runNotifyTest(names []string) {
+	if err := config.EnsureExists(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating config file: %v\n", err)
+		os.Exit(1)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	d, _ := loadNotifyConfig(cfg)
+	if len(names) == 0 {
+		for name := range d.Notifiers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	now := time.Now()
+	e := notify.Event{Kind: "test", ID: "test:" + now.Format("20060102T150405"), Time: now,
+		Title: "solo-cli test notification", Message: "Notifications from solo-cli arrive here"}
+	failed := false
+	for _, name := range names {
+		n, ok := d.Notifiers[name]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown notifier '%s'\n", name)
+			failed = true
+			continue
+		}
+		if err := n.Notify(context.Background(), e); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Sent: %s\n", name)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
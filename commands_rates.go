@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"solo-cli/rates"
+)
+
+// reportCurrency is the --currency flag: listed amounts are converted to it
+// at the BNR rate of their own date. Empty keeps each item's currency
+var reportCurrency string
+
+// parseCurrencyFlag pulls the global --currency CUR flag out of args
+func parseCurrencyFlag(args []string) []string {
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--currency" {
+			continue
+		}
+		if i+1 >= len(args) || len(args[i+1]) != 3 {
+			fmt.Fprintln(os.Stderr, "Error: --currency requires a currency code (e.g. RON, EUR)")
+			os.Exit(1)
+		}
+		reportCurrency = strings.ToUpper(args[i+1])
+		return append(args[:i], args[i+2:]...)
+	}
+	return args
+}
+
+// loadRatesOrExit loads the local exchange rate store
+func loadRatesOrExit() *rates.Store {
+	store, err := rates.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading exchange rates: %v\n", err)
+		os.Exit(1)
+	}
+	return store
+}
+
+// loadRates loads the local exchange rate store that values foreign items
+// without a local RON amount. An unreadable store is reported on stderr and
+// left empty
+func loadRates() *rates.Store {
+	store, err := rates.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not load the exchange rates, foreign amounts without a RON value are not converted: %v\n", err)
+	}
+	return store
+}
+
+// warnUnconverted reports on stderr how many amounts kept their own currency
+// because no rate was available
+func warnUnconverted(missing int) {
+	if missing == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "⚠️  %d amount(s) could not be converted to %s and are shown in their own currency. Import the BNR rates with 'solo-cli rates import <nbrfxrates.xml>'\n", missing, reportCurrency)
+}
+
+func runRates(args []string) {
+	sub := "show"
+	if len(args) > 0 {
+		sub = args[0]
+		args = args[1:]
+	}
+
+	switch sub {
+	case "import":
+		runRatesImport(args)
+	case "show":
+		runRatesShow(args)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown rates subcommand: %s\n", sub)
+		fmt.Fprintln(os.Stderr, "Usage: solo-cli rates [show [YYYY-MM-DD]|import <nbrfxrates.xml>...]")
+		os.Exit(1)
+	}
+}
+
+func runRatesImport(files []string) {
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: missing file")
+		fmt.Fprintln(os.Stderr, "Usage: solo-cli rates import <nbrfxrates.xml>...")
+		os.Exit(1)
+	}
+
+	store := loadRatesOrExit()
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		days, err := rates.ParseBNR(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", file, err)
+			os.Exit(1)
+		}
+		added := store.Add(days)
+		fmt.Printf("Imported %d day(s) from %s (%d new)\n", len(days), file, added)
+	}
+
+	if err := rates.Save(store); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving exchange rates: %v\n", err)
+		os.Exit(1)
+	}
+	first, last := store.Range()
+	fmt.Printf("Rates available from %s to %s\n", first, last)
+}
+
+func runRatesShow(args []string) {
+	date := time.Now()
+	if len(args) > 0 {
+		d, err := time.Parse("2006-01-02", args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid date '%s' (want YYYY-MM-DD)\n", args[0])
+			os.Exit(1)
+		}
+		date = d
+	}
+
+	store := loadRatesOrExit()
+	day, fixing, ok := store.Fixing(date)
+	if !ok {
+		first, last := store.Range()
+		if first == "" {
+			fmt.Fprintln(os.Stderr, "No exchange rates imported. Download nbrfxrates.xml from bnr.ro and run 'solo-cli rates import <file>'")
+		} else {
+			fmt.Fprintf(os.Stderr, "No BNR rates for %s (rates available from %s to %s)\n", date.Format("2006-01-02"), first, last)
+		}
+		os.Exit(1)
+	}
+
+	currencies := make([]string, 0, len(fixing))
+	for cur := range fixing {
+		currencies = append(currencies, cur)
+	}
+	sort.Strings(currencies)
+
+	fmt.Printf("BNR rates of %s (RON per unit)\n", day)
+	for _, cur := range currencies {
+		fmt.Printf("%s\t%g\n", cur, fixing[cur])
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/report"
+)
+
+// receivablesClientWidth is the width of the client column
+const receivablesClientWidth = 24
+
+// runReceivables ages the unpaid invoices by client so it is clear who to
+// chase: RON owed per 0-30/31-60/61-90/90+ days bucket, the amounts in the
+// invoice currencies and how long each client usually takes to pay
+func runReceivables(c *client.Client) {
+	revenues, err := c.ListAllRevenues()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	store := loadRates()
+	rep := report.AgeReceivables(revenues, time.Now(), store.RevenueRON)
+	if outputTemplate != nil {
+		for _, cr := range rep.Clients {
+			printTemplate(cr)
+		}
+		return
+	}
+
+	fmt.Printf("Receivables (as of %s)\n", rep.AsOf.Format("2006-01-02"))
+	fmt.Printf("══════════════════════════════════════════\n")
+	if len(rep.Clients) == 0 {
+		fmt.Println("No unpaid invoices")
+		return
+	}
+
+	b := report.AgingBuckets
+	fmt.Printf("%-*s %8s %12s %12s %12s %12s %13s %8s  %s\n", receivablesClientWidth,
+		"Client", "Invoices", b[0], b[1], b[2], b[3], "Total (RON)", "Pays in", "Owed")
+	for _, cr := range rep.Clients {
+		paysIn := "n/a"
+		if cr.PaidInvoices > 0 {
+			paysIn = fmt.Sprintf("%.0fd", cr.AvgDaysToPay)
+		}
+		fmt.Printf("%-*s %8d %12s %12s %12s %12s %13s %8s  %s\n", receivablesClientWidth,
+			fitColumn(cr.Client, receivablesClientWidth), cr.Invoices,
+			cr.Buckets[0], cr.Buckets[1], cr.Buckets[2], cr.Buckets[3], cr.Total,
+			paysIn, client.FormatCurrencies(cr.ByCurrency))
+	}
+	fmt.Printf("%-*s %8d %12s %12s %12s %12s %13s %8s  %s\n", receivablesClientWidth,
+		"Total", rep.Invoices, rep.Buckets[0], rep.Buckets[1], rep.Buckets[2], rep.Buckets[3], rep.Total,
+		"", client.FormatCurrencies(rep.ByCurrency))
+
+	if overdue := rep.Buckets[3]; overdue > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  %.2f RON outstanding for more than 90 days\n", overdue)
+	}
+}
+
+// fitColumn truncates s to width runes, marking the cut with "..."
+func fitColumn(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width-3]) + "..."
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/report"
+)
+
+const reportUsage = `Usage: solo-cli report <report> [year] [options]
+  currency [year]                        Invoicing split by currency
+  pnl [--year Y] [--by month|quarter]    Profit and loss per period
+  expenses [--year Y] [--group-by category|supplier|deductibility] [--top N]
+                                         Where the money goes, vs last year
+  clients [--year Y] [--top N]           Revenue per client and concentration
+  annual [--year Y] --html F | --pdf F   Yearly report with company, P&L,
+                                         taxes, top clients and charts
+Options: --format table|csv|json (pnl, expenses, clients)`
+
+// defaultReportTop is how many groups or clients the breakdowns list before
+// combining the rest
+const defaultReportTop = 10
+
+// reportOptions are the flags shared by the report subcommands
+type reportOptions struct {
+	year    int
+	by      string
+	groupBy string
+	top     int    // 0 lists every group
+	format  string // table, csv or json
+}
+
+// parseReportArgs reads the year (positional or --year) and the --by,
+// --group-by, --top and --format flags. The year defaults to the current one
+func parseReportArgs(args []string) reportOptions {
+	opts := reportOptions{year: time.Now().Year(), top: defaultReportTop, format: "table"}
+	value := func(i int) string {
+		if i+1 >= len(args) {
+			fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+			fmt.Fprintln(os.Stderr, reportUsage)
+			os.Exit(1)
+		}
+		return args[i+1]
+	}
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--year", "-y":
+			opts.year = parseYearArg([]string{value(i)})
+			i++
+		case "--by":
+			opts.by = value(i)
+			i++
+		case "--group-by", "-g":
+			opts.groupBy = value(i)
+			i++
+		case "--top", "-n":
+			n, err := strconv.Atoi(value(i))
+			if err != nil || n < 0 {
+				fmt.Fprintf(os.Stderr, "Error: invalid --top '%s' (want a number, 0 for all)\n", args[i+1])
+				os.Exit(1)
+			}
+			opts.top = n
+			i++
+		case "--format", "-f":
+			opts.format = strings.ToLower(value(i))
+			i++
+		case "--csv":
+			opts.format = "csv"
+		case "--json":
+			opts.format = "json"
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				fmt.Fprintf(os.Stderr, "Unknown option: %s\n", args[i])
+				fmt.Fprintln(os.Stderr, reportUsage)
+				os.Exit(1)
+			}
+			opts.year = parseYearArg(args[i:])
+		}
+	}
+
+	switch opts.format {
+	case "table", "csv", "json":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid format '%s' (want table, csv or json)\n", opts.format)
+		os.Exit(1)
+	}
+	return opts
+}
+
+// printJSON writes v indented to stdout
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runReport(c *client.Client, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, reportUsage)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "currency", "currencies", "fx":
+		runReportCurrency(c, args[1:])
+	case "pnl", "p&l", "profit":
+		runReportPnL(c, args[1:])
+	case "expenses", "expense", "exp":
+		runReportExpenses(c, args[1:])
+	case "clients", "client", "customers":
+		runReportClients(c, args[1:])
+	case "annual", "yearly":
+		templateUnsupported("'report annual'")
+		runReportAnnual(c, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown report: %s\n", args[0])
+		fmt.Fprintln(os.Stderr, reportUsage)
+		os.Exit(1)
+	}
+}
+
+// runReportCurrency shows how a year's invoicing splits by currency, valued
+// in the --currency (RON by default) at the BNR rate of each issue date
+func runReportCurrency(c *client.Client, args []string) {
+	year := parseReportArgs(args).year
+	currency := reportCurrency
+	if currency == "" {
+		currency = "RON"
+	}
+
+	revenues, err := c.ListAllRevenues()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	exp := loadRatesOrExit().CurrencyExposure(revenues, year, currency)
+	if outputTemplate != nil {
+		for _, row := range exp.Rows {
+			printTemplate(row)
+		}
+		return
+	}
+
+	fmt.Printf("Currency Exposure (%d, values in %s)\n", year, currency)
+	fmt.Printf("══════════════════════════════════════════\n")
+	if len(exp.Rows) == 0 {
+		fmt.Printf("No invoices issued in %d\n", year)
+		return
+	}
+
+	valueLabel := "Value (" + currency + ")"
+	fmt.Printf("%-8s %8s %18s %18s %14s %7s\n", "Currency", "Invoices", "Invoiced", "Unpaid", valueLabel, "Share")
+	invoices := 0
+	for _, row := range exp.Rows {
+		invoices += row.Invoices
+		value := fmt.Sprintf("%14s", row.Value)
+		if row.Missing == row.Invoices {
+			value = fmt.Sprintf("%14s", "n/a")
+		}
+		fmt.Printf("%-8s %8d %18s %18s %s %6.1f%%\n", row.Currency, row.Invoices,
+			row.Amount.String()+" "+row.Currency, row.Unpaid.String()+" "+row.Currency, value, row.Share)
+	}
+	fmt.Printf("%-8s %8d %18s %18s %14s\n", "Total", invoices, "", "", exp.Total)
+	fmt.Println()
+	fmt.Printf("Foreign currency: %.1f%% of invoicing\n", exp.Foreign)
+
+	if exp.Missing > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  %d invoice(s) without a BNR rate are left out of the values. Import the rates with 'solo-cli rates import <nbrfxrates.xml>'\n", exp.Missing)
+	}
+}
+
+// runReportPnL prints the profit and loss of a year per month or quarter,
+// from the complete revenue and expense lists in RON
+func runReportPnL(c *client.Client, args []string) {
+	opts := parseReportArgs(args)
+	by := report.ByMonth
+	if opts.by != "" {
+		var err error
+		if by, err = report.ParsePeriod(opts.by); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	revenues, err := c.ListAllRevenues()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	expenses, err := c.ListAllExpenses()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	store := loadRates()
+	pnl := report.BuildPnL(revenues, expenses, opts.year, by, store.RevenueRON, store.ExpenseRON)
+	if outputTemplate != nil {
+		for _, row := range pnl.Rows {
+			printTemplate(row)
+		}
+		return
+	}
+
+	switch opts.format {
+	case "json":
+		printJSON(pnl)
+		return
+	case "csv":
+		if err := pnl.WriteCSV(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Printf("Profit and Loss %d by %s (RON)\n", pnl.Year, pnl.By)
+	fmt.Printf("══════════════════════════════════════════\n")
+	fmt.Printf("%-10s %14s %14s %16s %14s %14s\n", "Period", "Revenue", "Deductible", "Non-deductible", "Net income", "Net YTD")
+	for _, row := range append(pnl.Rows, pnl.Total) {
+		fmt.Printf("%-10s %14s %14s %16s %14s %14s\n", row.Period, row.Revenue, row.Deductible, row.NonDeductible, row.Net, row.YTD)
+	}
+}
+
+// runReportExpenses breaks a year's expenses down by category, supplier or
+// deductibility, with each group's share and change from the year before
+func runReportExpenses(c *client.Client, args []string) {
+	opts := parseReportArgs(args)
+	by := report.GroupCategory
+	if opts.groupBy != "" {
+		var err error
+		if by, err = report.ParseGroupBy(opts.groupBy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	expenses, err := c.ListAllExpenses()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	store := loadRates()
+	breakdown := report.BuildExpenseBreakdown(expenses, opts.year, by, opts.top, store.ExpenseRON)
+	if outputTemplate != nil {
+		for _, g := range breakdown.Groups {
+			printTemplate(g)
+		}
+		if breakdown.Other != nil {
+			printTemplate(*breakdown.Other)
+		}
+		return
+	}
+
+	switch opts.format {
+	case "json":
+		printJSON(breakdown)
+		return
+	case "csv":
+		if err := breakdown.WriteCSV(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Printf("Expenses by %s %d (RON)\n", by, opts.year)
+	fmt.Printf("══════════════════════════════════════════\n")
+	if breakdown.Total.Count == 0 {
+		fmt.Printf("No expenses in %d\n", opts.year)
+		return
+	}
+
+	fmt.Printf("%-28s %6s %14s %7s %14s %8s\n", by.Title(), "Count", "Amount", "Share", strconv.Itoa(opts.year-1), "Change")
+	rows := slices.Clip(breakdown.Groups)
+	if breakdown.Other != nil {
+		rows = append(rows, *breakdown.Other)
+	}
+	for _, g := range append(rows, breakdown.Total) {
+		fmt.Printf("%-28s %6d %14s %6.1f%% %14s %8s\n", fitColumn(g.Name, 28), g.Count, g.Amount,
+			g.Share, g.Previous, report.FormatChange(g.Change))
+	}
+	fmt.Println()
+	fmt.Printf("Non-deductible: %s RON (%.1f%% of expenses)\n", breakdown.Total.NonDeductible, breakdown.NonDeductibleShare())
+}
+
+// runReportClients shows how dependent a year's revenue is on single
+// clients: revenue and share per client, the Herfindahl index and the new
+// vs returning clients of every year
+func runReportClients(c *client.Client, args []string) {
+	opts := parseReportArgs(args)
+
+	revenues, err := c.ListAllRevenues()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	store := loadRates()
+	rep := report.BuildClientReport(revenues, opts.year, opts.top, store.RevenueRON)
+	if outputTemplate != nil {
+		for _, cr := range rep.Clients {
+			printTemplate(cr)
+		}
+		if rep.Other != nil {
+			printTemplate(*rep.Other)
+		}
+		return
+	}
+
+	switch opts.format {
+	case "json":
+		printJSON(rep)
+		return
+	case "csv":
+		if err := rep.WriteCSV(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Printf("Revenue by Client %d (RON)\n", opts.year)
+	fmt.Printf("══════════════════════════════════════════\n")
+	if rep.Invoices == 0 {
+		fmt.Printf("No invoices issued in %d\n", opts.year)
+		return
+	}
+
+	fmt.Printf("%-28s %8s %14s %7s %12s %6s\n", "Client", "Invoices", "Revenue", "Share", "Avg invoice", "Since")
+	rows := slices.Clip(rep.Clients)
+	if rep.Other != nil {
+		rows = append(rows, *rep.Other)
+	}
+	for _, cr := range rows {
+		since := ""
+		if cr.Since != 0 {
+			since = strconv.Itoa(cr.Since)
+		}
+		fmt.Printf("%-28s %8d %14s %6.1f%% %12s %6s\n", fitColumn(cr.Name, 28), cr.Invoices, cr.Revenue, cr.Share, cr.AvgInvoice, since)
+	}
+	fmt.Printf("%-28s %8d %14s %6.1f%% %12s\n", "Total", rep.Invoices, rep.Revenue, rep.TotalShare(), rep.AvgInvoice)
+	fmt.Println()
+
+	this := rep.ThisYear()
+	fmt.Printf("Concentration:   HHI %.0f (%s), largest client %.1f%% of revenue\n", rep.HHI, rep.Concentration(), rep.TopShare)
+	fmt.Printf("Clients:         %d (%d new, %d returning)\n", this.Clients, this.New, this.Returning)
+	fmt.Printf("Average invoice: %s RON\n", rep.AvgInvoice)
+
+	if len(rep.ByYear) > 1 {
+		fmt.Println()
+		fmt.Println("New vs returning clients")
+		fmt.Printf("%-6s %8s %6s %10s %14s\n", "Year", "Clients", "New", "Returning", "Revenue")
+		for _, y := range rep.ByYear {
+			fmt.Printf("%-6d %8d %6d %10d %14s\n", y.Year, y.Clients, y.New, y.Returning, y.Revenue)
+		}
+	}
+}
+
+// runReportAnnual writes the yearly report for a partner or a bank as an
+// HTML page and/or a PDF, combining the summary, taxes, monthly profit and
+// loss and the largest clients and suppliers
+func runReportAnnual(c *client.Client, args []string) {
+	var htmlPath, pdfPath string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--html", "--pdf":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a file\n", args[i])
+				os.Exit(1)
+			}
+			if args[i] == "--html" {
+				htmlPath = args[i+1]
+			} else {
+				pdfPath = args[i+1]
+			}
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	opts := parseReportArgs(rest)
+	if htmlPath == "" && pdfPath == "" && opts.format != "json" {
+		fmt.Fprintln(os.Stderr, "Error: choose an output with --html <file> and/or --pdf <file>")
+		fmt.Fprintln(os.Stderr, reportUsage)
+		os.Exit(1)
+	}
+
+	taxCfg := loadTaxesOrExit()
+	summary, err := c.GetSummaryForYear(opts.year)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	revenues, err := c.ListAllRevenues()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	expenses, err := c.ListAllExpenses()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	store := loadRates()
+	annual := report.BuildAnnual(summary, revenues, expenses, loadExtraIncome(summary.Year, nil), taxCfg, store.RevenueRON, store.ExpenseRON)
+	annual.Generated = time.Now()
+	annual.Generator = "solo-cli " + version
+
+	// The report still makes sense without the header, so a failure only warns
+	if c.CompanyID != "" {
+		if info, err := c.GetCompanyInfo(c.CompanyID); err == nil {
+			codes, _ := c.GetCAENCodes(c.CompanyID)
+			annual.Company = report.NewAnnualCompany(info, codes)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: report without company header: %v\n", err)
+		}
+	}
+
+	if opts.format == "json" {
+		printJSON(annual)
+	}
+	for _, out := range []struct {
+		path   string
+		render func(io.Writer) error
+	}{{htmlPath, annual.HTML}, {pdfPath, annual.PDF}} {
+		if out.path == "" {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := out.render(&buf); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(out.path, buf.Bytes(), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", out.path)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"solo-cli/api"
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/taxes"
+)
+
+const serveUsage = `Usage: solo-cli serve [--listen 127.0.0.1:8787] [--token T] [--cache-ttl 5m]
+
+Serves SOLO.ro data as a local REST/JSON API through one logged in session.
+Callers send "Authorization: Bearer <token>"; the token is --token or the one
+generated in ~/.config/solo-cli/api-token. The endpoints are described at
+/openapi.json.`
+
+// sessionBackend reads from the logged in client and logs in again when
+// SOLO.ro ends the session
+type sessionBackend struct {
+	c   *client.Client
+	cfg *config.Config
+
+	companyMu sync.Mutex // guards c.CompanyID
+	reloginMu sync.Mutex // one login at a time
+}
+
+func (b *sessionBackend) Summary(year int) (*client.Summary, error) {
+	return b.c.GetSummaryForYear(year)
+}
+
+func (b *sessionBackend) Revenues() ([]client.Revenue, error) {
+	return b.c.ListAllRevenues()
+}
+
+func (b *sessionBackend) Expenses() ([]client.Expense, error) {
+	return b.c.ListAllExpenses()
+}
+
+func (b *sessionBackend) EFactura() ([]client.EFactura, error) {
+	return b.c.ListAllEFactura()
+}
+
+func (b *sessionBackend) Queue() ([]client.QueuedExpense, error) {
+	return b.c.ListAllQueuedExpenses()
+}
+
+func (b *sessionBackend) Rejected() ([]client.RejectedExpense, error) {
+	return b.c.ListAllRejectedExpenses()
+}
+
+func (b *sessionBackend) RevenueCounts(year int) (*client.RevenueCounts, error) {
+	return b.c.GetRevenueCounts(year)
+}
+
+func (b *sessionBackend) ExpenseCounts(year int) (*client.ExpenseCounts, error) {
+	return b.c.GetExpenseCounts(year)
+}
+
+// companyID is the ID found at login, looked up again if that failed
+func (b *sessionBackend) companyID() string {
+	b.companyMu.Lock()
+	defer b.companyMu.Unlock()
+	if b.c.CompanyID == "" {
+		if id, err := b.c.DiscoverCompanyID(); err == nil {
+			b.c.CompanyID = id
+		}
+	}
+	return b.c.CompanyID
+}
+
+func (b *sessionBackend) Company() (*api.Company, error) {
+	id := b.companyID()
+	if id == "" {
+		return nil, errors.New("could not determine company ID")
+	}
+	info, err := b.c.GetCompanyInfo(id)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := b.c.GetCAENCodes(id)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Company{CompanyInfo: info, CAEN: codes}, nil
+}
+
+// Taxes reads taxes.json and income.json on every call, so edits apply
+// without a restart
+func (b *sessionBackend) Taxes(summary *client.Summary) (*taxes.TaxBreakdown, error) {
+	taxCfg, err := config.LoadTaxes()
+	if err != nil {
+		return nil, fmt.Errorf("taxes.json: %w", err)
+	}
+	entries, err := config.LoadExtraIncome()
+	if err != nil {
+		return nil, fmt.Errorf("income.json: %w", err)
+	}
+	extra := config.ExtraIncomeForYear(entries, summary.Year)
+	return taxes.CalculateWithIncome(summary.TotalRevenues, summary.TotalDeductibleExpenses, extra, taxCfg), nil
+}
+
+func (b *sessionBackend) Upload(path string) (string, error) {
+	return b.c.UploadDocument(path)
+}
+
+// Relogin logs in again unless the session still works, in which case the
+// failure had another cause. Callers that failed together wait for the first
+// login and then find the session working
+func (b *sessionBackend) Relogin() (bool, error) {
+	b.reloginMu.Lock()
+	defer b.reloginMu.Unlock()
+	if _, err := b.c.GetSummary(); err == nil {
+		return false, nil
+	}
+	fmt.Fprintln(os.Stderr, "Session expired, logging in to SOLO.ro again...")
+	if err := b.c.Login(b.cfg.Username, b.cfg.Password); err != nil {
+		return false, err
+	}
+	if err := b.c.SaveCookies(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save session: %v\n", err)
+	}
+	return true, nil
+}
+
+// withRelogin runs fn, and once more after logging in again when it failed
+// because SOLO.ro ended the session
+func withRelogin(b *sessionBackend, fn func() error) error {
+	err := fn()
+	if err == nil {
+		return nil
+	}
+	if again, lerr := b.Relogin(); lerr != nil || !again {
+		return err
+	}
+	return fn()
+}
+
+func runServe(args []string) {
+	listen, token, ttl := "127.0.0.1:8787", "", 5*time.Minute
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--listen", "-l", "--token", "--cache-ttl":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+				os.Exit(1)
+			}
+			v := args[i+1]
+			i++
+			switch args[i-1] {
+			case "--token":
+				token = v
+			case "--cache-ttl":
+				d, err := time.ParseDuration(v)
+				if err != nil || d < 0 {
+					fmt.Fprintf(os.Stderr, "Error: invalid --cache-ttl '%s' (e.g. 30s, 5m, 0 to disable)\n", v)
+					os.Exit(1)
+				}
+				ttl = d
+			default:
+				listen = v
+			}
+		case "--help", "-h":
+			fmt.Println(serveUsage)
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown argument '%s'\n", args[i])
+			fmt.Fprintln(os.Stderr, serveUsage)
+			os.Exit(1)
+		}
+	}
+
+	if token == "" {
+		var created bool
+		var err error
+		token, created, err = config.LoadToken()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading API token: %v\n", err)
+			os.Exit(1)
+		}
+		if created {
+			path, _ := config.GetTokenPath()
+			fmt.Fprintf(os.Stderr, "Generated an API token in %s\n", path)
+		}
+	}
+
+	c, cfg := setupClient()
+	server := api.NewServer(&sessionBackend{c: c, cfg: cfg}, token, version, ttl)
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if host, _, _ := net.SplitHostPort(ln.Addr().String()); !net.ParseIP(host).IsLoopback() {
+		fmt.Fprintf(os.Stderr, "⚠️  Listening on %s, reachable from other machines: anyone with the token can read your accounting data\n", host)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{Handler: server.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	// On SIGINT or SIGTERM, stop accepting and let running requests finish
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Listening on http://%s (API description at /openapi.json)\n", ln.Addr())
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	<-drained
+	fmt.Fprintln(os.Stderr, "Stopped")
+}
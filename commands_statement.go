@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/statement"
+)
+
+// defaultPaymentTerms is how many days after issue an invoice falls due
+// when --terms is not given
+const defaultPaymentTerms = 30
+
+const statementUsage = "Usage: solo-cli statement <client> [--format txt|md|html] [--output file] [--terms days] [--reminder [file.eml]] [--to address]"
+
+// statementOptions are the flags of the statement command
+type statementOptions struct {
+	client   string
+	format   string
+	output   string
+	terms    int
+	reminder bool
+	emlPath  string
+	to       string
+}
+
+func parseStatementArgs(args []string) statementOptions {
+	opts := statementOptions{terms: defaultPaymentTerms}
+	var name []string
+	value := func(i int) string {
+		if i+1 >= len(args) {
+			fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+			fmt.Fprintln(os.Stderr, statementUsage)
+			os.Exit(1)
+		}
+		return args[i+1]
+	}
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format", "-f":
+			opts.format = strings.ToLower(value(i))
+			i++
+		case "--output", "-o":
+			opts.output = value(i)
+			i++
+		case "--terms":
+			days, err := strconv.Atoi(value(i))
+			if err != nil || days < 0 {
+				fmt.Fprintf(os.Stderr, "Error: invalid payment terms '%s' (want days)\n", args[i+1])
+				os.Exit(1)
+			}
+			opts.terms = days
+			i++
+		case "--to":
+			opts.to = value(i)
+			i++
+		case "--reminder":
+			opts.reminder = true
+			// The draft path is optional
+			if i+1 < len(args) && strings.HasSuffix(strings.ToLower(args[i+1]), ".eml") {
+				opts.emlPath = args[i+1]
+				i++
+			}
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				fmt.Fprintf(os.Stderr, "Unknown option: %s\n", args[i])
+				fmt.Fprintln(os.Stderr, statementUsage)
+				os.Exit(1)
+			}
+			name = append(name, args[i])
+		}
+	}
+	opts.client = strings.Join(name, " ")
+
+	if opts.format == "" {
+		switch strings.ToLower(filepath.Ext(opts.output)) {
+		case ".md", ".markdown":
+			opts.format = "md"
+		case ".html", ".htm":
+			opts.format = "html"
+		default:
+			opts.format = "txt"
+		}
+	}
+	return opts
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// reminderFileName names the draft after the client and day
+func reminderFileName(clientName string, day time.Time) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(clientName), "-"), "-")
+	if slug == "" {
+		slug = "client"
+	}
+	return fmt.Sprintf("reminder-%s-%s.eml", slug, day.Format("2006-01-02"))
+}
+
+// runStatement renders the account statement of one client and, with
+// --reminder, an email draft asking for the overdue invoices
+func runStatement(c *client.Client, args []string) {
+	opts := parseStatementArgs(args)
+	if opts.client == "" {
+		fmt.Fprintln(os.Stderr, "Error: missing client name")
+		fmt.Fprintln(os.Stderr, statementUsage)
+		os.Exit(1)
+	}
+
+	revenues, err := c.ListAllRevenues()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	name, err := statement.MatchClient(revenues, opts.client)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	store := loadRates()
+	now := time.Now()
+	st := statement.Build(revenues, name, now, opts.terms, store.RevenueRON)
+	if c.CompanyID != "" {
+		if company, err := c.GetCompanyInfo(c.CompanyID); err == nil {
+			st.Issuer = company.Name
+		}
+	}
+
+	out := os.Stdout
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := st.Render(out, opts.format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.output != "" {
+		fmt.Fprintf(os.Stderr, "Wrote the statement of %s to %s\n", name, opts.output)
+	}
+
+	if opts.reminder {
+		writeReminder(st, opts, now)
+	}
+}
+
+// writeReminder writes the reminder email draft for the overdue invoices
+func writeReminder(st *statement.Statement, opts statementOptions, now time.Time) {
+	overdue := st.Overdue()
+	if len(overdue) == 0 {
+		fmt.Fprintf(os.Stderr, "No overdue invoices for %s, no reminder written\n", st.Client)
+		return
+	}
+
+	tmpl, err := config.LoadReminderTemplate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading reminder template: %v\n", err)
+		os.Exit(1)
+	}
+
+	path := opts.emlPath
+	if path == "" {
+		path = reminderFileName(st.Client, now)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	if err := st.Reminder(f, tmpl, opts.to, now); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote a reminder for %d overdue invoice(s) to %s\n", len(overdue), path)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"solo-cli/config"
+)
+
+// taxesConfigKeys maps the keys accepted by `taxes config set` to the
+// fields they change. Both the short names and the JSON names work
+var taxesConfigKeys = map[string]func(*config.TaxConfig) *float64{
+	"smb":                 func(c *config.TaxConfig) *float64 { return &c.SalariuMinimBrut },
+	"salariu_minim_brut":  func(c *config.TaxConfig) *float64 { return &c.SalariuMinimBrut },
+	"income_tax":          func(c *config.TaxConfig) *float64 { return &c.IncomeTaxPercent },
+	"income_tax_percent":  func(c *config.TaxConfig) *float64 { return &c.IncomeTaxPercent },
+	"cas":                 func(c *config.TaxConfig) *float64 { return &c.CASPercent },
+	"cas_percent":         func(c *config.TaxConfig) *float64 { return &c.CASPercent },
+	"cass":                func(c *config.TaxConfig) *float64 { return &c.CASSPercent },
+	"cass_percent":        func(c *config.TaxConfig) *float64 { return &c.CASSPercent },
+	"cass_cap":            func(c *config.TaxConfig) *float64 { return &c.CASSCapSalaries },
+	"cass_cap_salaries":   func(c *config.TaxConfig) *float64 { return &c.CASSCapSalaries },
+	"vat":                 func(c *config.TaxConfig) *float64 { return &c.VATThreshold },
+	"vat_threshold":       func(c *config.TaxConfig) *float64 { return &c.VATThreshold },
+	"vat_warning":         func(c *config.TaxConfig) *float64 { return &c.VATWarningPercent },
+	"vat_warning_percent": func(c *config.TaxConfig) *float64 { return &c.VATWarningPercent },
+}
+
+// loadTaxesOrExit loads and validates taxes.json, exiting with every
+// problem listed when it cannot be used
+func loadTaxesOrExit() *config.TaxConfig {
+	taxCfg, err := config.LoadTaxes()
+	if err != nil {
+		printTaxesConfigError(err)
+		os.Exit(1)
+	}
+	return taxCfg
+}
+
+// printTaxesConfigError reports a taxes.json load error on stderr, one
+// validation problem per line with how to fix them
+func printTaxesConfigError(err error) {
+	taxesPath, _ := config.GetTaxesConfigPath()
+	var invalid *config.ValidationError
+	if !errors.As(err, &invalid) {
+		fmt.Fprintf(os.Stderr, "Error loading taxes config: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: %s is invalid:\n", taxesPath)
+	for _, p := range invalid.Problems {
+		fmt.Fprintf(os.Stderr, "  - %s\n", p)
+	}
+	fmt.Fprintln(os.Stderr, "Please edit it and run 'solo-cli taxes config validate', or restore the defaults with 'solo-cli taxes config reset'.")
+}
+
+func runTaxesConfig(args []string) {
+	sub := "show"
+	if len(args) > 0 {
+		sub = args[0]
+	}
+
+	switch sub {
+	case "show":
+		taxCfg, err := config.LoadTaxesUnchecked()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading taxes config: %v\n", err)
+			os.Exit(1)
+		}
+		data, _ := json.MarshalIndent(taxCfg, "", "  ")
+		fmt.Println(string(data))
+		if err := taxCfg.Validate(); err != nil {
+			printTaxesConfigError(err)
+		}
+	case "validate":
+		taxCfg, err := config.LoadTaxesUnchecked()
+		if err == nil {
+			err = taxCfg.Validate()
+		}
+		if err != nil {
+			printTaxesConfigError(err)
+			os.Exit(1)
+		}
+		taxesPath, _ := config.GetTaxesConfigPath()
+		fmt.Printf("%s is valid\n", taxesPath)
+	case "reset":
+		runTaxesConfigReset()
+	case "set":
+		runTaxesConfigSet(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown taxes config subcommand: %s\n", sub)
+		fmt.Fprintln(os.Stderr, "Usage: solo-cli taxes config [show|validate|reset|set <key> <value>]")
+		os.Exit(1)
+	}
+}
+
+// runTaxesConfigReset writes the default config, keeping the previous file
+// next to it as taxes.json.bak
+func runTaxesConfigReset() {
+	taxesPath, err := config.GetTaxesConfigPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if data, err := os.ReadFile(taxesPath); err == nil {
+		if err := os.WriteFile(taxesPath+".bak", data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error backing up %s: %v\n", taxesPath, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Previous config saved to %s.bak\n", taxesPath)
+	}
+
+	defaults := config.DefaultTaxConfig()
+	if err := config.SaveTaxes(defaults); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", taxesPath, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Reset %s to the %d defaults\n", taxesPath, defaults.Year)
+}
+
+func runTaxesConfigSet(args []string) {
+	if len(args) != 2 {
+		keys := make([]string, 0, len(taxesConfigKeys))
+		for k := range taxesConfigKeys {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintln(os.Stderr, "Usage: solo-cli taxes config set <key> <value>")
+		fmt.Fprintf(os.Stderr, "Keys: year, %s\n", strings.Join(keys, ", "))
+		os.Exit(1)
+	}
+	key, raw := args[0], args[1]
+
+	taxCfg, err := config.LoadTaxesUnchecked()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading taxes config: %v\n", err)
+		os.Exit(1)
+	}
+
+	if key == "year" {
+		year, err := strconv.Atoi(raw)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid year '%s'\n", raw)
+			os.Exit(1)
+		}
+		taxCfg.Year = year
+	} else {
+		field, ok := taxesConfigKeys[key]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown key '%s'\n", key)
+			os.Exit(1)
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid number '%s'\n", raw)
+			os.Exit(1)
+		}
+		*field(taxCfg) = value
+	}
+
+	// Never write a config the other commands would refuse to load
+	if err := taxCfg.Validate(); err != nil {
+		printTaxesConfigError(err)
+		fmt.Fprintln(os.Stderr, "Nothing was saved.")
+		os.Exit(1)
+	}
+	if err := config.SaveTaxes(taxCfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving taxes config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Set %s = %s\n", key, raw)
+}
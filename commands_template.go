@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"solo-cli/client"
+	"solo-cli/money"
+	"solo-cli/taxes"
+)
+
+// outputTemplate is set by --template or --template-file and replaces the
+// output of the list and detail commands
+var outputTemplate *template.Template
+
+// templateCommands are the commands that print through outputTemplate
+var templateCommands = map[string]bool{
+	"summary": true, "revenues": true, "revenue": true, "rev": true,
+	"expenses": true, "expense": true, "exp": true, "queue": true, "q": true,
+	"efactura": true, "einvoice": true, "ei": true, "company": true, "taxes": true, "tax": true,
+	"receivables": true, "ar": true, "report": true,
+}
+
+// templateFuncs are the helpers available to output templates
+var templateFuncs = template.FuncMap{
+	"ron":   taxes.FormatRON,
+	"money": func(m money.Money) string { return m.String() },
+	"date":  formatTemplateDate,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"pad":   func(width int, s string) string { return padTemplate(width, s, false) },
+	"lpad":  func(width int, s string) string { return padTemplate(width, s, true) },
+}
+
+// formatTemplateDate formats an API date ("2026-01-15" or a timestamp) or a
+// time with a Go layout, leaving anything it cannot parse as it is
+func formatTemplateDate(layout string, v any) string {
+	switch d := v.(type) {
+	case time.Time:
+		return d.Format(layout)
+	case string:
+		if t, ok := client.ParseDay(d); ok {
+			return t.Format(layout)
+		}
+		return d
+	}
+	return fmt.Sprint(v)
+}
+
+// padTemplate pads s with spaces to width characters, on the left for
+// right-aligned columns
+func padTemplate(width int, s string, left bool) string {
+	n := width - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return s
+	}
+	if left {
+		return strings.Repeat(" ", n) + s
+	}
+	return s + strings.Repeat(" ", n)
+}
+
+// parseTemplateFlags removes --template and --template-file from args and
+// compiles the template
+func parseTemplateFlags(args []string) []string {
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--template" && args[i] != "--template-file" {
+			continue
+		}
+		if i+1 >= len(args) {
+			fmt.Fprintf(os.Stderr, "Error: %s requires a value\n", args[i])
+			os.Exit(1)
+		}
+		if outputTemplate != nil {
+			fmt.Fprintln(os.Stderr, "Error: use either --template or --template-file")
+			os.Exit(1)
+		}
+		text := args[i+1]
+		if args[i] == "--template-file" {
+			data, err := os.ReadFile(text)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading template: %v\n", err)
+				os.Exit(1)
+			}
+			text = string(data)
+		}
+		tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid template: %v\n", err)
+			os.Exit(1)
+		}
+		outputTemplate = tmpl
+		args = append(args[:i], args[i+2:]...)
+		i--
+	}
+	return args
+}
+
+// printTemplate renders one item through outputTemplate and reports whether
+// it did. Each item ends its own line; an item rendering to nothing prints
+// nothing, so templates can filter
+func printTemplate(item any) bool {
+	if outputTemplate == nil {
+		return false
+	}
+	var buf bytes.Buffer
+	if err := outputTemplate.Execute(&buf, item); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: template: %v\n", err)
+		os.Exit(1)
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	os.Stdout.Write(buf.Bytes())
+	return true
+}
+
+// templateUnsupported exits when a template was given to a command that
+// does not print through it
+func templateUnsupported(what string) {
+	if outputTemplate != nil {
+		fmt.Fprintf(os.Stderr, "Error: --template is not supported by %s\n", what)
+		os.Exit(1)
+	}
+}
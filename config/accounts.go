@@ -0,0 +1,113 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const accountsFileName = "accounts.json"
+
+// AccountsConfig maps invoices and expenses to the accounts of a
+// plain-text accounting journal
+type AccountsConfig struct {
+	// Receivable is debited with each invoice until it is paid
+	Receivable string `json:"receivable"`
+	// Income is credited with each invoice
+	Income string `json:"income"`
+	// Bank receives the invoice payments and pays the expenses
+	Bank string `json:"bank"`
+	// Expense is the account of the expenses whose category is not mapped.
+	// Empty uses "Expenses:" followed by the category name
+	Expense string `json:"expense"`
+	// Categories maps an expense category (or primary category) to its
+	// account, matched ignoring case and diacritics
+	Categories map[string]string `json:"categories"`
+}
+
+// DefaultAccountsConfig returns accounts that suit a PFA: the categories
+// SOLO uses most often get an account, the rest are named after their
+// category
+func DefaultAccountsConfig() *AccountsConfig {
+	return &AccountsConfig{
+		Receivable: "Assets:Receivables",
+		Income:     "Income:Services",
+		Bank:       "Assets:Bank",
+		Categories: map[string]string{
+			"Servicii":              "Expenses:Services",
+			"Chirie":                "Expenses:Rent",
+			"Utilitati":             "Expenses:Utilities",
+			"Telecomunicatii":       "Expenses:Telecom",
+			"Echipamente":           "Expenses:Equipment",
+			"Software":              "Expenses:Software",
+			"Cheltuieli auto":       "Expenses:Car",
+			"Cheltuieli protocol":   "Expenses:Entertainment",
+			"Contributii sociale":   "Expenses:Taxes:Contributions",
+			"Materiale consumabile": "Expenses:Supplies",
+		},
+	}
+}
+
+// GetAccountsPath returns the full path to the journal accounts file
+func GetAccountsPath() (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), accountsFileName), nil
+}
+
+// EnsureAccountsExists creates a default accounts.json if it doesn't
+// exist, so there is a mapping to edit
+func EnsureAccountsExists() error {
+	path, err := GetAccountsPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		data, err := json.MarshalIndent(DefaultAccountsConfig(), "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0644)
+	}
+
+	return nil
+}
+
+// LoadAccounts reads the journal accounts file. Accounts left empty fall
+// back to the defaults
+func LoadAccounts() (*AccountsConfig, error) {
+	if err := EnsureAccountsExists(); err != nil {
+		return nil, err
+	}
+
+	path, err := GetAccountsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg AccountsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	defaults := DefaultAccountsConfig()
+	if strings.TrimSpace(cfg.Receivable) == "" {
+		cfg.Receivable = defaults.Receivable
+	}
+	if strings.TrimSpace(cfg.Income) == "" {
+		cfg.Income = defaults.Income
+	}
+	if strings.TrimSpace(cfg.Bank) == "" {
+		cfg.Bank = defaults.Bank
+	}
+	return &cfg, nil
+}
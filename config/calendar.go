@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const calendarFileName = "calendar.json"
+
+// DeadlineRule defines a recurring fiscal deadline
+type DeadlineRule struct {
+	// ID identifies the rule, it is also the stable part of the iCalendar UID
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Frequency is "yearly", "quarterly" or "monthly"
+	Frequency string `json:"frequency"`
+	// Month is the due month for yearly rules (1-12). For quarterly rules it
+	// is the month after the quarter end (1 = first month after the quarter).
+	// Ignored for monthly rules
+	Month int `json:"month"`
+	// Day is the due day of the month
+	Day int `json:"day"`
+	// Amount selects what is due from the previous year's tax breakdown:
+	// "" (nothing), "income_tax", "cas", "cass", "contributions" or "total"
+	Amount string `json:"amount"`
+}
+
+// CalendarConfig holds the fiscal deadline rules
+type CalendarConfig struct {
+	Rules []DeadlineRule `json:"rules"`
+}
+
+// DefaultCalendarConfig returns the default deadline rules for a SOLO PFA
+//
+// The declarația unică (D212) is filed and paid by 25 May for the previous
+// year's income. Deadlines landing on a weekend move to the next working day
+func DefaultCalendarConfig() *CalendarConfig {
+	return &CalendarConfig{
+		Rules: []DeadlineRule{
+			{
+				ID:          "d212-depunere",
+				Title:       "Depunere D212 (declarația unică)",
+				Description: "Declarația unică pentru veniturile anului anterior",
+				Frequency:   "yearly",
+				Month:       5,
+				Day:         25,
+			},
+			{
+				ID:          "d212-plata",
+				Title:       "Plată impozit pe venit și CAS/CASS",
+				Description: "Impozitul pe venit, CAS și CASS datorate pentru anul anterior",
+				Frequency:   "yearly",
+				Month:       5,
+				Day:         25,
+				Amount:      "total",
+			},
+			{
+				ID:          "efactura-spv",
+				Title:       "e-Factura: verificare facturi transmise în SPV",
+				Description: "Facturile emise se transmit în SPV în cel mult 5 zile calendaristice de la emitere",
+				Frequency:   "monthly",
+				Day:         5,
+			},
+		},
+	}
+}
+
+// GetCalendarConfigPath returns the full path to the deadline rules file
+func GetCalendarConfigPath() (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), calendarFileName), nil
+}
+
+// EnsureCalendarExists creates a default calendar.json if it doesn't exist
+func EnsureCalendarExists() error {
+	calendarPath, err := GetCalendarConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(calendarPath); os.IsNotExist(err) {
+		data, err := json.MarshalIndent(DefaultCalendarConfig(), "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(calendarPath, data, 0644)
+	}
+
+	return nil
+}
+
+// LoadCalendar reads and parses the deadline rules file
+func LoadCalendar() (*CalendarConfig, error) {
+	if err := EnsureCalendarExists(); err != nil {
+		return nil, err
+	}
+
+	calendarPath, err := GetCalendarConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(calendarPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg CalendarConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
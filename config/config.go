@@ -22,6 +22,9 @@ type Config struct {
 	Password  string `json:"password"`
 	PageSize  int    `json:"page_size"`
 	UserAgent string `json:"user_agent"`
+
+	Notify *NotifyConfig `json:"notify,omitempty"`
+	Jobs   []JobConfig   `json:"jobs,omitempty"`
 }
 
 // ErrCredentialsMissing is returned when username or password is empty
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"solo-cli/money"
 )
 
 // useTempConfig points the package at a temp config file and restores after
@@ -114,3 +118,209 @@ func TestDefaultThresholdsAreContiguous(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDefaults(t *testing.T) {
+	if err := DefaultTaxConfig().Validate(); err != nil {
+		t.Errorf("defaults invalid: %v", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := DefaultTaxConfig()
+	cfg.SalariuMinimBrut = 0
+	cfg.CASPercent = 125
+	cfg.CASThresholds = []TaxThreshold{
+		{MinSalaries: 0, MaxSalaries: 12, BaseSalaries: 0},
+		{MinSalaries: 14, MaxSalaries: 24, BaseSalaries: 12}, // gap 12-14
+		{MinSalaries: 20, MaxSalaries: 0, BaseSalaries: -2},  // overlap 20-24, bad base
+	}
+	cfg.CASSThresholds = []TaxThreshold{
+		{MinSalaries: 6, MaxSalaries: 72, BaseSalaries: -1},
+		{MinSalaries: 0, MaxSalaries: 6, BaseSalaries: 6}, // unsorted, doesn't end unbounded
+	}
+
+	err := cfg.Validate()
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Validate = %v, want a *ValidationError", err)
+	}
+	want := []string{
+		"salariu_minim_brut must be positive, got 0",
+		"cas_percent must be between 0 and 100, got 125",
+		"cas_thresholds[1]: gap between 12 and 14 salarii",
+		"cas_thresholds[2]: overlaps cas_thresholds[1] between 20 and 24 salarii",
+		"cas_thresholds[2]: invalid base_salaries -2",
+		"cass_thresholds[0]: starts at min_salaries 6, must start at 0",
+		"cass_thresholds[1]: min_salaries 0 comes after 6, sort the thresholds by min_salaries",
+		"cass_thresholds[1]: income above 6 salarii is not covered",
+	}
+	all := strings.Join(invalid.Problems, "\n")
+	for _, w := range want {
+		if !strings.Contains(all, w) {
+			t.Errorf("problems missing %q:\n%s", w, all)
+		}
+	}
+	if len(invalid.Problems) != len(want) {
+		t.Errorf("got %d problems, want %d:\n%s", len(invalid.Problems), len(want), all)
+	}
+}
+
+// Problems come in field order, so cas_thresholds[2] is before [10]
+func TestValidateFieldOrder(t *testing.T) {
+	cfg := DefaultTaxConfig()
+	cfg.CASThresholds = nil
+	for i := 0; i < 12; i++ {
+		th := TaxThreshold{MinSalaries: float64(i), MaxSalaries: float64(i + 1)}
+		if i == 2 || i == 10 {
+			th.BaseSalaries = -3
+		}
+		cfg.CASThresholds = append(cfg.CASThresholds, th)
+	}
+	cfg.CASThresholds[11].MaxSalaries = 0
+	cfg.CASSPercent = -1
+
+	var invalid *ValidationError
+	if !errors.As(cfg.Validate(), &invalid) {
+		t.Fatal("Validate accepted the config")
+	}
+	want := []string{"cass_percent", "cas_thresholds[2]", "cas_thresholds[10]"}
+	if len(invalid.Problems) != len(want) {
+		t.Fatalf("problems = %q", invalid.Problems)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(invalid.Problems[i], w) {
+			t.Errorf("problem %d = %q, want %s first", i, invalid.Problems[i], w)
+		}
+	}
+}
+
+// LoadTaxes refuses an invalid file, LoadTaxesUnchecked still reads it
+func TestLoadTaxesValidates(t *testing.T) {
+	path := useTempConfig(t)
+	taxesPath := filepath.Join(filepath.Dir(path), "taxes.json")
+	if err := os.WriteFile(taxesPath, []byte(`{"salariu_minim_brut":0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var invalid *ValidationError
+	if _, err := LoadTaxes(); !errors.As(err, &invalid) || !strings.Contains(err.Error(), taxesPath) {
+		t.Errorf("LoadTaxes = %v, want a validation error naming the file", err)
+	}
+	cfg, err := LoadTaxesUnchecked()
+	if err != nil || cfg.SalariuMinimBrut != 0 {
+		t.Errorf("LoadTaxesUnchecked = %+v, %v", cfg, err)
+	}
+
+	cfg.SalariuMinimBrut = 4325
+	if err := SaveTaxes(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := LoadTaxes(); err != nil || cfg.SalariuMinimBrut != 4325 {
+		t.Errorf("after SaveTaxes: %+v, %v", cfg, err)
+	}
+}
+
+func TestEnsureCalendarExistsAndLoad(t *testing.T) {
+	useTempConfig(t)
+
+	cfg, err := LoadCalendar()
+	if err != nil {
+		t.Fatalf("LoadCalendar: %v", err)
+	}
+	if len(cfg.Rules) != len(DefaultCalendarConfig().Rules) {
+		t.Errorf("rules count = %d, want %d", len(cfg.Rules), len(DefaultCalendarConfig().Rules))
+	}
+	for _, r := range cfg.Rules {
+		if r.ID == "" || r.Day == 0 {
+			t.Errorf("default rule without ID or day: %+v", r)
+		}
+	}
+}
+
+// taxes.json files written before a field existed get its default
+func TestLoadTaxesFillsMissingFields(t *testing.T) {
+	path := useTempConfig(t)
+	taxesPath := filepath.Join(filepath.Dir(path), "taxes.json")
+	if err := os.WriteFile(taxesPath, []byte(`{"year":2025,"salariu_minim_brut":4050}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadTaxes()
+	if err != nil {
+		t.Fatalf("LoadTaxes: %v", err)
+	}
+	if cfg.Year != 2025 {
+		t.Errorf("Year = %d, want the file's 2025", cfg.Year)
+	}
+	if cfg.IncomeCategory("dividends") == nil || len(cfg.OtherCASSThresholds) == 0 || cfg.CASSCapSalaries != 72 {
+		t.Errorf("missing fields not defaulted: %+v", cfg)
+	}
+}
+
+// A list in the file replaces the default list, entries do not inherit the
+// fields they omit from the default entry at the same index
+func TestLoadTaxesPartialCategory(t *testing.T) {
+	path := useTempConfig(t)
+	taxesPath := filepath.Join(filepath.Dir(path), "taxes.json")
+	data := `{"salariu_minim_brut":4050,"income_categories":[{"key":"royalties","income_tax_percent":10}]}`
+	if err := os.WriteFile(taxesPath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadTaxes()
+	if err != nil {
+		t.Fatalf("LoadTaxes: %v", err)
+	}
+	want := IncomeCategory{Key: "royalties", IncomeTaxPercent: 10}
+	if len(cfg.IncomeCategories) != 1 || cfg.IncomeCategories[0] != want {
+		t.Errorf("IncomeCategories = %+v, want only %+v", cfg.IncomeCategories, want)
+	}
+	if len(cfg.CASThresholds) != len(DefaultTaxConfig().CASThresholds) {
+		t.Errorf("missing cas_thresholds not defaulted: %+v", cfg.CASThresholds)
+	}
+}
+
+func TestLoadExtraIncome(t *testing.T) {
+	path := useTempConfig(t)
+
+	entries, err := LoadExtraIncome()
+	if err != nil || entries != nil {
+		t.Fatalf("missing income.json: entries %v, err %v, want nothing", entries, err)
+	}
+
+	data := `[{"year":2025,"category":"rent","amount":1000},{"year":2026,"category":"dividends","amount":2000}]`
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "income.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = LoadExtraIncome()
+	if err != nil {
+		t.Fatalf("LoadExtraIncome: %v", err)
+	}
+	got := ExtraIncomeForYear(entries, 2026)
+	if len(got) != 1 || got[0].Category != "dividends" || got[0].Amount != 2000*money.Lei {
+		t.Errorf("ExtraIncomeForYear(2026) = %+v", got)
+	}
+}
+
+func TestLoadToken(t *testing.T) {
+	useTempConfig(t)
+
+	token, created, err := LoadToken()
+	if err != nil || !created || len(token) != 64 {
+		t.Fatalf("LoadToken = %q, %v, %v", token, created, err)
+	}
+	path, _ := GetTokenPath()
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("token file: %v %v", info, err)
+	}
+
+	again, created, err := LoadToken()
+	if err != nil || created || again != token {
+		t.Errorf("second LoadToken = %q, %v, %v, want the stored token", again, created, err)
+	}
+
+	os.WriteFile(path, []byte("  my-token\n"), 0600)
+	if token, _, _ := LoadToken(); token != "my-token" {
+		t.Errorf("edited token = %q", token)
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"solo-cli/money"
+)
+
+const incomeFileName = "income.json"
+
+// ExtraIncome is a manually declared non-PFA income amount for a year
+type ExtraIncome struct {
+	Year int `json:"year"`
+	// Category is an IncomeCategory key from taxes.json
+	Category    string      `json:"category"`
+	Amount      money.Money `json:"amount"`
+	Description string      `json:"description"`
+}
+
+// GetIncomePath returns the full path to the declared income file
+func GetIncomePath() (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), incomeFileName), nil
+}
+
+// LoadExtraIncome reads the declared non-PFA income. A missing file simply
+// means nothing was declared
+func LoadExtraIncome() ([]ExtraIncome, error) {
+	incomePath, err := GetIncomePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(incomePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []ExtraIncome
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ExtraIncomeForYear filters declared income down to one year
+func ExtraIncomeForYear(entries []ExtraIncome, year int) []ExtraIncome {
+	var out []ExtraIncome
+	for _, e := range entries {
+		if e.Year == year {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,17 @@
+package config
+
+// JobConfig is one entry of the "jobs" section of config.json, run by
+// solo-cli daemon on a cron schedule
+type JobConfig struct {
+	Name string `json:"name"`
+	// Schedule is a cron expression: minute hour day-of-month month
+	// day-of-week, or @hourly, @daily, @weekly, @monthly, @yearly
+	Schedule string `json:"schedule"`
+	// Run is sync, upload, notify, report, export or command
+	Run  string   `json:"run"`
+	Args []string `json:"args,omitempty"`
+	// Dir is where sync writes and the folder upload watches
+	Dir string `json:"dir,omitempty"`
+	// Timeout bounds a run, a Go duration. Empty is 30m
+	Timeout string `json:"timeout,omitempty"`
+}
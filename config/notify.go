@@ -0,0 +1,38 @@
+package config
+
+// NotifyConfig is the "notify" section of config.json: where notifications
+// go and which events each destination gets
+type NotifyConfig struct {
+	// Interval between polls of SOLO.ro, a Go duration. Empty is 5m
+	Interval string `json:"interval,omitempty"`
+	// Notifiers are the destinations, by a name the rules refer to
+	Notifiers map[string]NotifierConfig `json:"notifiers"`
+	Rules     []NotifyRule              `json:"rules"`
+}
+
+// NotifierConfig is one destination. Type picks which fields apply:
+// webhook (url, headers), exec (command), email (smtp, username, password,
+// from, to) or desktop
+type NotifierConfig struct {
+	Type string `json:"type"`
+
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Command is the program and its arguments, run without a shell
+	Command []string `json:"command,omitempty"`
+
+	// SMTP is the server's host:port
+	SMTP     string   `json:"smtp,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+}
+
+// NotifyRule sends the listed events to the named notifiers
+type NotifyRule struct {
+	// Events are rejected, overdue and paid. Empty matches all of them
+	Events []string `json:"events,omitempty"`
+	Notify []string `json:"notify"`
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const reminderFileName = "reminder.tmpl"
+
+// DefaultReminderTemplate is the payment reminder email, a text/template
+// rendered with the client statement. Header lines up to the first blank
+// line (Subject) become email headers, the rest is the body
+const DefaultReminderTemplate = `Subject: Reamintire plată facturi restante{{with .Issuer}} - {{.}}{{end}}
+
+Bună ziua,
+
+Vă reamintim că următoarele facturi emise către {{.Client}} sunt restante la data de {{date .AsOf}}:
+
+{{range .Overdue}}- {{.Number}} din {{date .IssueDate}}, scadentă la {{date .DueDate}}: {{money .Total}} {{.Currency}} ({{.DaysOverdue}} zile întârziere)
+{{end}}
+Total restant: {{currencies .OverdueByCurrency}}
+
+Vă rugăm să efectuați plata în cel mai scurt timp. Dacă plata a fost deja efectuată, vă rugăm să ignorați acest mesaj.
+
+Cu stimă,
+{{.Issuer}}
+`
+
+// GetReminderTemplatePath returns the full path to the reminder email template
+func GetReminderTemplatePath() (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), reminderFileName), nil
+}
+
+// EnsureReminderTemplateExists creates the default reminder.tmpl if it
+// doesn't exist, so there is a file to customize
+func EnsureReminderTemplateExists() error {
+	path, err := GetReminderTemplatePath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return os.WriteFile(path, []byte(DefaultReminderTemplate), 0644)
+	}
+
+	return nil
+}
+
+// LoadReminderTemplate reads the reminder email template
+func LoadReminderTemplate() (string, error) {
+	if err := EnsureReminderTemplateExists(); err != nil {
+		return "", err
+	}
+
+	path, err := GetReminderTemplatePath()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
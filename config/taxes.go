@@ -2,8 +2,11 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 )
 
 const taxesFileName = "taxes.json"
@@ -20,6 +23,47 @@ type TaxThreshold struct {
 	Label string `json:"label"`
 }
 
+// IncomeCategory defines the income tax rules of a non-PFA income source
+type IncomeCategory struct {
+	// Key identifies the category in income.json and --income flags
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	// IncomeTaxPercent is applied to the amount left after the deduction
+	IncomeTaxPercent float64 `json:"income_tax_percent"`
+	// DeductionPercent is the flat-rate deduction from the gross amount
+	// before income tax (cheltuială forfetară, e.g. 20% for rent)
+	DeductionPercent float64 `json:"deduction_percent"`
+	// WithheldAtSource means the payer already withheld the income tax, so
+	// it is not paid through the declarația unică
+	WithheldAtSource bool `json:"withheld_at_source"`
+	// CASS marks income that counts toward the CASS base on other income
+	CASS bool `json:"cass"`
+}
+
+// RegimeConfig holds the parameters of the alternative tax regimes used by
+// the regime comparison
+type RegimeConfig struct {
+	// NormaVenit maps a CAEN code to its annual norma de venit. The norms
+	// are set per county, so there are no defaults
+	NormaVenit map[string]float64 `json:"norma_venit"`
+	Micro      MicroConfig        `json:"micro"`
+}
+
+// MicroConfig holds the micro-enterprise (SRL) parameters
+type MicroConfig struct {
+	// TurnoverTaxPercent is the impozit pe veniturile microîntreprinderilor
+	TurnoverTaxPercent float64 `json:"turnover_tax_percent"`
+	// HighTurnoverTaxPercent applies instead when the primary CAEN code is
+	// listed in HighRateCAEN
+	HighTurnoverTaxPercent float64  `json:"high_turnover_tax_percent"`
+	HighRateCAEN           []string `json:"high_rate_caen"`
+	// DividendTaxPercent is withheld when the profit is paid out
+	DividendTaxPercent float64 `json:"dividend_tax_percent"`
+	// AnnualFixedCosts are the yearly SRL running costs on top of the PFA
+	// expenses (accounting, the mandatory employee...)
+	AnnualFixedCosts float64 `json:"annual_fixed_costs"`
+}
+
 // TaxConfig holds all configurable tax parameters
 type TaxConfig struct {
 	Year             int            `json:"year"`
@@ -29,6 +73,32 @@ type TaxConfig struct {
 	CASThresholds    []TaxThreshold `json:"cas_thresholds"`
 	CASSPercent      float64        `json:"cass_percent"`
 	CASSThresholds   []TaxThreshold `json:"cass_thresholds"`
+	// IncomeCategories are the non-PFA income sources (dividends, rent...)
+	IncomeCategories []IncomeCategory `json:"income_categories"`
+	// OtherCASSThresholds are the CASS brackets over the combined non-PFA
+	// income (6/12/24 salarii)
+	OtherCASSThresholds []TaxThreshold `json:"other_cass_thresholds"`
+	// CASSCapSalaries caps the combined PFA and other income CASS base, in
+	// multiples of SMB (0 = no cap)
+	CASSCapSalaries float64 `json:"cass_cap_salaries"`
+	// Regimes parameterizes the regime comparison (normă de venit, micro)
+	Regimes RegimeConfig `json:"regimes"`
+	// VATThreshold is the yearly turnover above which a PFA must register
+	// for VAT (plafonul de scutire), in RON
+	VATThreshold float64 `json:"vat_threshold"`
+	// VATWarningPercent warns once the headroom to the VAT threshold drops
+	// to this share of the threshold
+	VATWarningPercent float64 `json:"vat_warning_percent"`
+}
+
+// IncomeCategory returns the category with the given key, nil if unknown
+func (c *TaxConfig) IncomeCategory(key string) *IncomeCategory {
+	for i := range c.IncomeCategories {
+		if c.IncomeCategories[i].Key == key {
+			return &c.IncomeCategories[i]
+		}
+	}
+	return nil
 }
 
 // DefaultTaxConfig returns the default tax configuration for 2026
@@ -54,6 +124,31 @@ func DefaultTaxConfig() *TaxConfig {
 			{MinSalaries: 6, MaxSalaries: 72, BaseSalaries: -1, Label: "CASS proporțional"},
 			{MinSalaries: 72, MaxSalaries: 0, BaseSalaries: 72, Label: "CASS plafonat (72 salarii)"},
 		},
+		IncomeCategories: []IncomeCategory{
+			{Key: "dividends", Label: "Dividende", IncomeTaxPercent: 16, WithheldAtSource: true, CASS: true},
+			{Key: "rent", Label: "Chirii", IncomeTaxPercent: 10, DeductionPercent: 20, CASS: true},
+			{Key: "interest", Label: "Dobânzi", IncomeTaxPercent: 10, WithheldAtSource: true, CASS: true},
+			{Key: "investments", Label: "Câștiguri din investiții", IncomeTaxPercent: 10, CASS: true},
+		},
+		OtherCASSThresholds: []TaxThreshold{
+			{MinSalaries: 0, MaxSalaries: 6, BaseSalaries: 0, Label: "Fără CASS (sub 6 salarii)"},
+			{MinSalaries: 6, MaxSalaries: 12, BaseSalaries: 6, Label: "CASS pe 6 salarii"},
+			{MinSalaries: 12, MaxSalaries: 24, BaseSalaries: 12, Label: "CASS pe 12 salarii"},
+			{MinSalaries: 24, MaxSalaries: 0, BaseSalaries: 24, Label: "CASS pe 24 salarii"},
+		},
+		CASSCapSalaries: 72,
+		Regimes: RegimeConfig{
+			NormaVenit: map[string]float64{},
+			Micro: MicroConfig{
+				TurnoverTaxPercent:     1,
+				HighTurnoverTaxPercent: 3,
+				HighRateCAEN:           []string{},
+				DividendTaxPercent:     16,
+				AnnualFixedCosts:       0,
+			},
+		},
+		VATThreshold:      395000,
+		VATWarningPercent: 10,
 	}
 }
 
@@ -84,8 +179,23 @@ func EnsureTaxesExists() error {
 	return nil
 }
 
-// LoadTaxes reads and parses the taxes config file
+// LoadTaxes reads, parses and validates the taxes config file. An invalid
+// config returns an error wrapping a *ValidationError
 func LoadTaxes() (*TaxConfig, error) {
+	cfg, err := LoadTaxesUnchecked()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		taxesPath, _ := GetTaxesConfigPath()
+		return nil, fmt.Errorf("%s: %w", taxesPath, err)
+	}
+	return cfg, nil
+}
+
+// LoadTaxesUnchecked reads and parses the taxes config file without
+// validating it, for inspecting and fixing an invalid file
+func LoadTaxesUnchecked() (*TaxConfig, error) {
 	if err := EnsureTaxesExists(); err != nil {
 		return nil, err
 	}
@@ -104,6 +214,51 @@ func LoadTaxes() (*TaxConfig, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(data, &present); err != nil {
+		return nil, err
+	}
+	if err := cfg.fillMissing(present); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
+
+// fillMissing gives the fields absent from the file their default, so files
+// written before a field existed (e.g. income_categories) do not get zero.
+// Decoding over the defaults instead would let a user's list entry inherit
+// the fields it omits from the default entry at the same index. Objects such
+// as regimes, whose lists hold plain strings, are decoded over their default
+func (c *TaxConfig) fillMissing(present map[string]json.RawMessage) error {
+	def := reflect.ValueOf(DefaultTaxConfig()).Elem()
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		raw, ok := present[name]
+		switch {
+		case !ok:
+			v.Field(i).Set(def.Field(i))
+		case v.Field(i).Kind() == reflect.Struct:
+			v.Field(i).Set(def.Field(i))
+			if err := json.Unmarshal(raw, v.Field(i).Addr().Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SaveTaxes writes the taxes config file
+func SaveTaxes(cfg *TaxConfig) error {
+	taxesPath, err := GetTaxesConfigPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(taxesPath, data, 0644)
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const tokenFileName = "api-token"
+
+// GetTokenPath returns the full path to the token of the local API
+func GetTokenPath() (string, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), tokenFileName), nil
+}
+
+// LoadToken reads the token callers of the local API present, generating a
+// random one on first use. created reports whether it was just generated
+func LoadToken() (token string, created bool, err error) {
+	path, err := GetTokenPath()
+	if err != nil {
+		return "", false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		token = strings.TrimSpace(string(data))
+		if token == "" {
+			return "", false, errors.New(path + " is empty")
+		}
+		return token, false, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", false, err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, err
+	}
+	token = hex.EncodeToString(buf)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", false, err
+	}
+	// Whoever reads the token reads the accounting data, like the config
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", false, err
+	}
+	return token, true, nil
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationError lists every problem found in a tax config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid tax config: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the tax config for values that make the calculation
+// silently wrong: a non-positive SMB (division by zero), percentages out of
+// range and threshold lists that do not tile the income range from 0 to
+// unbounded. Returns a *ValidationError with all problems, nil when valid
+func (c *TaxConfig) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.SalariuMinimBrut <= 0 {
+		add("salariu_minim_brut must be positive, got %g", c.SalariuMinimBrut)
+	}
+	for _, p := range []struct {
+		name  string
+		value float64
+	}{
+		{"income_tax_percent", c.IncomeTaxPercent},
+		{"cas_percent", c.CASPercent},
+		{"cass_percent", c.CASSPercent},
+		{"vat_warning_percent", c.VATWarningPercent},
+	} {
+		if p.value < 0 || p.value > 100 {
+			add("%s must be between 0 and 100, got %g", p.name, p.value)
+		}
+	}
+	if c.CASSCapSalaries < 0 {
+		add("cass_cap_salaries must be 0 (no cap) or positive, got %g", c.CASSCapSalaries)
+	}
+	if c.VATThreshold < 0 {
+		add("vat_threshold must be 0 (disabled) or positive, got %g", c.VATThreshold)
+	}
+
+	problems = append(problems, validateThresholds("cas_thresholds", c.CASThresholds)...)
+	problems = append(problems, validateThresholds("cass_thresholds", c.CASSThresholds)...)
+	problems = append(problems, validateThresholds("other_cass_thresholds", c.OtherCASSThresholds)...)
+
+	seen := map[string]bool{}
+	for i, cat := range c.IncomeCategories {
+		switch {
+		case cat.Key == "":
+			add("income_categories[%d]: key is empty", i)
+		case seen[cat.Key]:
+			add("income_categories[%d]: duplicate key %q", i, cat.Key)
+		}
+		seen[cat.Key] = true
+		if cat.IncomeTaxPercent < 0 || cat.IncomeTaxPercent > 100 {
+			add("income_categories[%d]: income_tax_percent must be between 0 and 100, got %g", i, cat.IncomeTaxPercent)
+		}
+		if cat.DeductionPercent < 0 || cat.DeductionPercent > 100 {
+			add("income_categories[%d]: deduction_percent must be between 0 and 100, got %g", i, cat.DeductionPercent)
+		}
+	}
+
+	micro := c.Regimes.Micro
+	for _, p := range []struct {
+		name  string
+		value float64
+	}{
+		{"turnover_tax_percent", micro.TurnoverTaxPercent},
+		{"high_turnover_tax_percent", micro.HighTurnoverTaxPercent},
+		{"dividend_tax_percent", micro.DividendTaxPercent},
+	} {
+		if p.value < 0 || p.value > 100 {
+			add("regimes.micro.%s must be between 0 and 100, got %g", p.name, p.value)
+		}
+	}
+	// Map iteration order is random, keep the report stable
+	caens := make([]string, 0, len(c.Regimes.NormaVenit))
+	for caen := range c.Regimes.NormaVenit {
+		caens = append(caens, caen)
+	}
+	sort.Strings(caens)
+	for _, caen := range caens {
+		if norma := c.Regimes.NormaVenit[caen]; norma <= 0 {
+			add("regimes.norma_venit[%q] must be positive, got %g", caen, norma)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// validateThresholds checks that the brackets are sorted by min_salaries and
+// tile [0, unbounded) exactly: each max_salaries is the next min_salaries
+// and only the last bracket is open-ended (max_salaries 0)
+func validateThresholds(name string, thresholds []TaxThreshold) []string {
+	if len(thresholds) == 0 {
+		return []string{name + ": no thresholds"}
+	}
+
+	var problems []string
+	add := func(i int, format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("%s[%d]: ", name, i)+fmt.Sprintf(format, args...))
+	}
+
+	if thresholds[0].MinSalaries != 0 {
+		add(0, "starts at min_salaries %g, must start at 0", thresholds[0].MinSalaries)
+	}
+	last := len(thresholds) - 1
+	for i, t := range thresholds {
+		if t.BaseSalaries < 0 && t.BaseSalaries != -1 {
+			add(i, "invalid base_salaries %g, use a positive multiple of SMB, 0 (exempt) or -1 (proportional)", t.BaseSalaries)
+		}
+		if t.MaxSalaries == 0 {
+			if i != last {
+				add(i, "max_salaries 0 (no upper bound) on a threshold that is not the last")
+			}
+		} else if t.MaxSalaries <= t.MinSalaries {
+			add(i, "max_salaries %g must be above min_salaries %g", t.MaxSalaries, t.MinSalaries)
+		}
+		if i == last && t.MaxSalaries != 0 {
+			add(i, "income above %g salarii is not covered, the last threshold needs max_salaries 0", t.MaxSalaries)
+		}
+
+		if i == 0 {
+			continue
+		}
+		prev := thresholds[i-1]
+		switch {
+		case t.MinSalaries < prev.MinSalaries:
+			add(i, "min_salaries %g comes after %g, sort the thresholds by min_salaries", t.MinSalaries, prev.MinSalaries)
+		case prev.MaxSalaries == 0:
+			// Already reported as a misplaced open-ended threshold
+		case t.MinSalaries > prev.MaxSalaries:
+			add(i, "gap between %g and %g salarii (previous max_salaries and this min_salaries)", prev.MaxSalaries, t.MinSalaries)
+		case t.MinSalaries < prev.MaxSalaries:
+			add(i, "overlaps %s[%d] between %g and %g salarii", name, i-1, t.MinSalaries, prev.MaxSalaries)
+		}
+	}
+	return problems
+}
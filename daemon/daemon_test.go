@@ -0,0 +1,322 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"solo-cli/config"
+)
+
+// Tuesday
+var now = time.Date(2026, 6, 30, 9, 59, 30, 0, time.UTC)
+
+func TestScheduleNext(t *testing.T) {
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", now, time.Date(2026, 6, 30, 10, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 6, 30, 10, 7, 0, 0, time.UTC), time.Date(2026, 6, 30, 10, 15, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", now, time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 7, 3, 9, 0, 0, 0, time.UTC), time.Date(2026, 7, 6, 9, 0, 0, 0, time.UTC)},
+		{"30 4 * JAN,Jul 7", now, time.Date(2026, 7, 5, 4, 30, 0, 0, time.UTC)},
+		{"5,10-12/2 * * * *", time.Date(2026, 6, 30, 10, 10, 0, 0, time.UTC), time.Date(2026, 6, 30, 10, 12, 0, 0, time.UTC)},
+		{"0 0 1 * 0", now, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},                                         // 1st or Sunday
+		{"0 0 1 * 0", time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 7, 5, 0, 0, 0, 0, time.UTC)}, // Sunday
+		{"0 0 1 * *", now, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", now, time.Date(2026, 6, 30, 10, 0, 0, 0, time.UTC)},
+		{"@daily", now, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", now, time.Date(2026, 7, 5, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", now, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", now, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", now, time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.expr)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q after %s = %s, want %s", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleNextDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Bucharest")
+	if err != nil {
+		t.Skip(err)
+	}
+	s, _ := ParseSchedule("30 3 * * *")
+	// 03:30 happens twice on 2026-10-25, the second one is an hour later
+	first := s.Next(time.Date(2026, 10, 25, 0, 0, 0, 0, loc))
+	if first.Hour() != 3 || first.Minute() != 30 || first.Day() != 25 {
+		t.Fatalf("first = %s", first)
+	}
+	if next := s.Next(first); !next.After(first) || next.Day() != 26 {
+		t.Errorf("after %s = %s, want the 26th", first, next)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"* * 0 * *",
+		"@often",
+	} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded", expr)
+		}
+	}
+}
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.lock")
+	l, err := AcquireLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid, alive := LockHolder(path); pid != os.Getpid() || !alive {
+		t.Errorf("LockHolder = %d, %v", pid, alive)
+	}
+	_, err = AcquireLock(path)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.PID != os.Getpid() {
+		t.Fatalf("second AcquireLock err = %v", err)
+	}
+	if err := l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+
+	// A lock left by a process that has exited is taken over
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skip(err)
+	}
+	os.WriteFile(path, []byte(strconv.Itoa(cmd.Process.Pid)+"\n"), 0644)
+	l, err = AcquireLock(path)
+	if err != nil {
+		t.Fatalf("stale lock: %v", err)
+	}
+	if pid, _ := LockHolder(path); pid != os.Getpid() {
+		t.Errorf("lock holder = %d, want %d", pid, os.Getpid())
+	}
+	l.Release()
+
+	// Only the flock counts, not a PID written by hand
+	os.WriteFile(path, []byte(strconv.Itoa(os.Getppid())+"\n"), 0644)
+	l, err = AcquireLock(path)
+	if err != nil {
+		t.Fatalf("unheld lock naming a live process: %v", err)
+	}
+	defer l.Release()
+}
+
+func TestStatus(t *testing.T) {
+	config.SetConfigPath(filepath.Join(t.TempDir(), "config.json"))
+	defer config.SetConfigPath("")
+
+	s, err := LoadStatus()
+	if err != nil || s != nil {
+		t.Fatalf("LoadStatus before any run = %+v, %v", s, err)
+	}
+	want := &Status{PID: 42, Started: now, Jobs: []JobStatus{
+		{Name: "sync", Schedule: "@hourly", Runs: 1, Last: &Run{Started: now, Duration: 1.5, Error: "boom"}},
+	}}
+	if err := SaveStatus(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PID != 42 || !got.Started.Equal(now) || !got.Stopped.IsZero() || len(got.Jobs) != 1 || got.Jobs[0].Last.Error != "boom" {
+		t.Errorf("LoadStatus = %+v", got)
+	}
+}
+
+// fakeClock is a clock the test moves forward
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+	return c.t
+}
+
+type fakeTimer struct {
+	d    time.Duration
+	fire chan time.Time
+}
+
+// recorded keeps the last status the scheduler saved
+type recorded struct {
+	mu     sync.Mutex
+	status *Status
+}
+
+func (r *recorded) save(s *Status) {
+	r.mu.Lock()
+	r.status = s
+	r.mu.Unlock()
+}
+
+func (r *recorded) job(name string) JobStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, j := range r.status.Jobs {
+		if j.Name == name {
+			return j
+		}
+	}
+	return JobStatus{}
+}
+
+func (r *recorded) waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	job := func(name, spec string, run func(ctx context.Context, log *slog.Logger) error) *Job {
+		s, err := ParseSchedule(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &Job{Name: name, Spec: spec, Schedule: s, Run: run}
+	}
+	slowStarted := make(chan struct{}, 1)
+	jobs := []*Job{
+		job("ok", "* * * * *", func(ctx context.Context, log *slog.Logger) error { return nil }),
+		job("bad", "* * * * *", func(ctx context.Context, log *slog.Logger) error { return errors.New("boom") }),
+		job("panicky", "@hourly", func(ctx context.Context, log *slog.Logger) error { panic("oops") }),
+		job("slow", "* * * * *", func(ctx context.Context, log *slog.Logger) error {
+			slowStarted <- struct{}{}
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+	}
+
+	var logs bytes.Buffer
+	var rec recorded
+	clock := &fakeClock{t: now}
+	timers := make(chan fakeTimer)
+	s := &Scheduler{
+		Jobs:  jobs,
+		Log:   slog.New(slog.NewJSONHandler(&logs, nil)),
+		Save:  rec.save,
+		Grace: 10 * time.Millisecond,
+		now:   clock.now,
+		timer: func(d time.Duration) (<-chan time.Time, func() bool) {
+			fire := make(chan time.Time, 1)
+			timers <- fakeTimer{d, fire}
+			return fire, func() bool { return true }
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	tick := func(want time.Duration) {
+		t.Helper()
+		tm := <-timers
+		if tm.d != want {
+			t.Fatalf("timer = %s, want %s", tm.d, want)
+		}
+		tm.fire <- clock.advance(tm.d)
+	}
+	finished := func(name string, runs int) func() bool {
+		return func() bool {
+			j := rec.job(name)
+			return j.Runs == runs && !j.Running && j.Last != nil
+		}
+	}
+
+	// 10:00, every job is due
+	tick(30 * time.Second)
+	<-slowStarted
+	rec.waitFor(t, "first runs", func() bool {
+		return finished("ok", 1)() && finished("bad", 1)() && finished("panicky", 1)()
+	})
+	if next := rec.job("panicky").Next; !next.Equal(time.Date(2026, 6, 30, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("panicky next = %s", next)
+	}
+
+	// 10:01, slow is still running
+	tick(time.Minute)
+	rec.waitFor(t, "second runs", func() bool { return finished("ok", 2)() && finished("bad", 2)() })
+	<-timers // the scheduler is waiting for 10:02
+	cancel()
+	<-done
+
+	rec.mu.Lock()
+	st := rec.status
+	rec.mu.Unlock()
+	if st.PID != os.Getpid() || !st.Started.Equal(now) || st.Stopped.IsZero() {
+		t.Errorf("status = %+v", st)
+	}
+	for _, j := range st.Jobs {
+		if !j.Next.IsZero() || j.Running {
+			t.Errorf("%s after shutdown: next %s, running %v", j.Name, j.Next, j.Running)
+		}
+	}
+	checks := []struct {
+		name                    string
+		runs, failures, skipped int
+		err                     string
+	}{
+		{"ok", 2, 0, 0, ""},
+		{"bad", 2, 2, 0, "boom"},
+		{"panicky", 1, 1, 0, "panic: oops"},
+		{"slow", 1, 1, 1, "context canceled"},
+	}
+	for _, c := range checks {
+		j := rec.job(c.name)
+		if j.Runs != c.runs || j.Failures != c.failures || j.Skipped != c.skipped || j.Last == nil || j.Last.Error != c.err {
+			t.Errorf("%s = %+v, last %+v", c.name, j, j.Last)
+		}
+	}
+	for _, msg := range []string{"daemon started", "job failed", "job skipped", "cancelling running jobs", "daemon stopped"} {
+		if !strings.Contains(logs.String(), `"msg":"`+msg+`"`) {
+			t.Errorf("log has no %q:\n%s", msg, logs.String())
+		}
+	}
+}
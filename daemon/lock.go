@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Lock is an exclusive flock on a file, held for as long as the file stays
+// open. The file also names the PID of its holder for status
+type Lock struct {
+	path string
+	f    *os.File
+}
+
+// LockedError is returned when another live process holds the lock
+type LockedError struct {
+	PID int
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("another daemon is running (pid %d)", e.PID)
+}
+
+// AcquireLock takes the lock on path and writes this process' PID into it.
+// The kernel drops the lock when its holder exits, so a file left behind by
+// a process that is gone is simply locked again
+func AcquireLock(path string) (*Lock, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			pid := readPID(f)
+			f.Close()
+			if errors.Is(err, syscall.EWOULDBLOCK) {
+				return nil, &LockedError{PID: pid}
+			}
+			return nil, err
+		}
+		// The holder before us may have removed the file between our open
+		// and flock, leaving us the lock on a file nobody else can see
+		if same, err := isFile(f, path); err != nil || !same {
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := f.Truncate(0); err != nil {
+			f.Close()
+			return nil, err
+		}
+		if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &Lock{path: path, f: f}, nil
+	}
+}
+
+// Release removes the lock file, then drops the lock
+func (l *Lock) Release() error {
+	err := os.Remove(l.path)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// isFile reports whether the open f is still the file at path
+func isFile(f *os.File, path string) (bool, error) {
+	open, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	named, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return os.SameFile(open, named), nil
+}
+
+// readPID returns the PID written in a lock file, 0 when there is none yet
+func readPID(f *os.File) int {
+	data := make([]byte, 32)
+	n, _ := f.ReadAt(data, 0)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data[:n])))
+	if err != nil || pid <= 0 {
+		return 0
+	}
+	return pid
+}
+
+// LockHolder returns the PID in a lock file and whether that process is
+// still running
+func LockHolder(path string) (pid int, alive bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	pid = readPID(f)
+	if pid == 0 {
+		return 0, false
+	}
+	return pid, processAlive(pid)
+}
+
+func processAlive(pid int) bool {
+	if pid == os.Getpid() {
+		return true
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
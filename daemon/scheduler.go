@@ -0,0 +1,239 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Job is a task run on a cron schedule
+type Job struct {
+	Name     string
+	Spec     string // the schedule as written in the config
+	Schedule *Schedule
+	Timeout  time.Duration // 0 runs without a time limit
+	// Run does the work, logging details with log, which carries the job name
+	Run func(ctx context.Context, log *slog.Logger) error
+}
+
+// Scheduler starts each job when its schedule comes up. A job still
+// running when it comes up again is skipped rather than run twice
+type Scheduler struct {
+	Jobs []*Job
+	Log  *slog.Logger
+	// Save is called with the status whenever it changes
+	Save func(*Status)
+	// Grace is how long running jobs get to finish on shutdown before they
+	// are cancelled
+	Grace time.Duration
+
+	now   func() time.Time
+	timer func(d time.Duration) (<-chan time.Time, func() bool)
+
+	mu      sync.Mutex
+	saving  sync.Mutex // keeps saves in order
+	status  Status
+	next    map[*Job]time.Time
+	running map[*Job]bool
+	wg      sync.WaitGroup
+}
+
+// Run schedules the jobs until ctx is done, then waits for the running ones
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.now == nil {
+		s.now = time.Now
+	}
+	if s.timer == nil {
+		s.timer = func(d time.Duration) (<-chan time.Time, func() bool) {
+			t := time.NewTimer(d)
+			return t.C, t.Stop
+		}
+	}
+	if s.Log == nil {
+		s.Log = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	}
+	start := s.now()
+	s.next = map[*Job]time.Time{}
+	s.running = map[*Job]bool{}
+	s.status = Status{PID: os.Getpid(), Started: start}
+	for _, j := range s.Jobs {
+		s.next[j] = j.Schedule.Next(start)
+		s.status.Jobs = append(s.status.Jobs, JobStatus{Name: j.Name, Schedule: j.Spec, Next: s.next[j]})
+	}
+	s.save()
+	s.Log.Info("daemon started", "pid", s.status.PID, "jobs", len(s.Jobs))
+
+	// Jobs outlive ctx by the grace period
+	runCtx, cancelRuns := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRuns()
+	for {
+		var fire <-chan time.Time
+		stopTimer := func() bool { return false }
+		if wake, ok := s.wake(); ok {
+			fire, stopTimer = s.timer(wake.Sub(s.now()))
+		}
+		select {
+		case <-ctx.Done():
+			stopTimer()
+			s.shutdown(cancelRuns)
+			return
+		case <-fire:
+		}
+		now := s.now()
+		for _, j := range s.Jobs {
+			if next := s.next[j]; !next.IsZero() && !next.After(now) {
+				s.start(runCtx, j)
+				s.setNext(j, j.Schedule.Next(now))
+			}
+		}
+	}
+}
+
+// wake returns the earliest next run, ok is false when no job will run
+func (s *Scheduler) wake() (time.Time, bool) {
+	var wake time.Time
+	for _, next := range s.next {
+		if !next.IsZero() && (wake.IsZero() || next.Before(wake)) {
+			wake = next
+		}
+	}
+	return wake, !wake.IsZero()
+}
+
+func (s *Scheduler) setNext(j *Job, next time.Time) {
+	s.mu.Lock()
+	s.next[j] = next
+	s.jobStatus(j).Next = next
+	s.mu.Unlock()
+	s.save()
+}
+
+// start runs a job in the background unless it is still running
+func (s *Scheduler) start(ctx context.Context, j *Job) {
+	log := s.Log.With("job", j.Name)
+	s.mu.Lock()
+	if s.running[j] {
+		s.jobStatus(j).Skipped++
+		s.mu.Unlock()
+		log.Warn("job skipped", "reason", "previous run still running")
+		return
+	}
+	s.running[j] = true
+	st := s.jobStatus(j)
+	st.Running = true
+	st.Runs++
+	run := st.Runs
+	s.mu.Unlock()
+	s.save()
+
+	log = log.With("run", run)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		started := s.now()
+		log.Info("job started")
+		err := s.call(ctx, j, log)
+		elapsed := s.now().Sub(started)
+
+		s.mu.Lock()
+		s.running[j] = false
+		st := s.jobStatus(j)
+		st.Running = false
+		st.Last = &Run{Started: started, Duration: elapsed.Seconds()}
+		if err != nil {
+			st.Failures++
+			st.Last.Error = err.Error()
+		}
+		s.mu.Unlock()
+		s.save()
+
+		if err != nil {
+			log.Error("job failed", "duration", elapsed.Round(time.Millisecond).String(), "error", err.Error())
+			return
+		}
+		log.Info("job finished", "duration", elapsed.Round(time.Millisecond).String())
+	}()
+}
+
+// call runs the job within its timeout, turning a panic into an error so
+// one broken job does not stop the others
+func (s *Scheduler) call(ctx context.Context, j *Job, log *slog.Logger) (err error) {
+	if j.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.Run(ctx, log)
+}
+
+func (s *Scheduler) shutdown(cancelRuns context.CancelFunc) {
+	s.mu.Lock()
+	running := 0
+	for _, r := range s.running {
+		if r {
+			running++
+		}
+	}
+	s.mu.Unlock()
+	s.Log.Info("shutting down", "running_jobs", running)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(s.Grace):
+		s.Log.Warn("cancelling running jobs", "grace", s.Grace.String())
+		cancelRuns()
+		<-done
+	}
+
+	s.mu.Lock()
+	s.status.Stopped = s.now()
+	for i := range s.status.Jobs {
+		s.status.Jobs[i].Next = time.Time{}
+	}
+	s.mu.Unlock()
+	s.save()
+	s.Log.Info("daemon stopped")
+}
+
+// jobStatus returns the status entry of a job, with s.mu held
+func (s *Scheduler) jobStatus(j *Job) *JobStatus {
+	for i, job := range s.Jobs {
+		if job == j {
+			return &s.status.Jobs[i]
+		}
+	}
+	panic("unknown job " + j.Name)
+}
+
+func (s *Scheduler) save() {
+	if s.Save == nil {
+		return
+	}
+	s.saving.Lock()
+	defer s.saving.Unlock()
+	s.mu.Lock()
+	st := s.status
+	st.Jobs = make([]JobStatus, len(s.status.Jobs))
+	for i, j := range s.status.Jobs {
+		st.Jobs[i] = j
+		if j.Last != nil {
+			last := *j.Last
+			st.Jobs[i].Last = &last
+		}
+	}
+	s.mu.Unlock()
+	s.Save(&st)
+}
@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"solo-cli/config"
+)
+
+const (
+	statusFileName = "daemon-status.json"
+	lockFileName   = "daemon.lock"
+)
+
+// Status is what the daemon reports about itself and its jobs
+type Status struct {
+	PID     int         `json:"pid"`
+	Started time.Time   `json:"started"`
+	Stopped time.Time   `json:"stopped,omitzero"`
+	Jobs    []JobStatus `json:"jobs"`
+}
+
+// JobStatus is the state of one job
+type JobStatus struct {
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	Next     time.Time `json:"next,omitzero"`
+	Running  bool      `json:"running"`
+	Runs     int       `json:"runs"`
+	Failures int       `json:"failures"`
+	Skipped  int       `json:"skipped"` // runs left out while the previous one was running
+	Last     *Run      `json:"last,omitempty"`
+}
+
+// Run is the outcome of a finished run
+type Run struct {
+	Started  time.Time `json:"started"`
+	Duration float64   `json:"duration_seconds"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// GetLockPath returns the full path to the daemon lock file
+func GetLockPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, lockFileName), nil
+}
+
+// GetStatusPath returns the full path to the daemon status file
+func GetStatusPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, statusFileName), nil
+}
+
+// LoadStatus reads the status the daemon last wrote, nil when it never ran
+func LoadStatus() (*Status, error) {
+	path, err := GetStatusPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var s Status
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// SaveStatus writes the status file through a temporary file, so readers
+// never see it half written
+func SaveStatus(s *Status) error {
+	path, err := GetStatusPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
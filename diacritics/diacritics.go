@@ -0,0 +1,24 @@
+// Package diacritics folds accented letters to their ASCII base letter, so
+// names typed with or without Romanian diacritics compare equal
+package diacritics
+
+import "strings"
+
+// replacer covers both the comma (ș, ț) and the older cedilla (ş, ţ) forms
+// Romanian text uses, plus the accents common in supplier names
+var replacer = strings.NewReplacer(
+	"ă", "a", "â", "a", "î", "i", "ș", "s", "ş", "s", "ț", "t", "ţ", "t",
+	"Ă", "A", "Â", "A", "Î", "I", "Ș", "S", "Ş", "S", "Ț", "T", "Ţ", "T",
+	"é", "e", "è", "e", "ä", "a", "ö", "o", "ü", "u", "É", "E", "Ä", "A", "Ö", "O", "Ü", "U",
+)
+
+// Strip replaces accented letters with their base letter, keeping case
+func Strip(s string) string {
+	return replacer.Replace(s)
+}
+
+// Fold makes strings comparable ignoring case, diacritics and surrounding
+// spaces
+func Fold(s string) string {
+	return strings.ToLower(Strip(strings.TrimSpace(s)))
+}
@@ -0,0 +1,22 @@
+package diacritics
+
+import "testing"
+
+func TestStrip(t *testing.T) {
+	for in, want := range map[string]string{
+		"Întreținere și reparații": "Intretinere si reparatii",
+		"ŞŢşţ (cedilla)":           "STst (cedilla)",
+		"Müller Café":              "Muller Cafe",
+		"plain":                    "plain",
+	} {
+		if got := Strip(in); got != want {
+			t.Errorf("Strip(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	if Fold("  Chirie Spațiu ") != Fold("chirie spatiu") {
+		t.Errorf("Fold does not match %q and %q", "  Chirie Spațiu ", "chirie spatiu")
+	}
+}
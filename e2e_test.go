@@ -1,16 +1,26 @@
 package main
 
 import (
+	"archive/zip"
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
+
+	"solo-cli/daemon"
 )
 
 // The e2e suite builds the real binary once and runs it against a mock
@@ -66,19 +76,34 @@ func newMockAPI(t *testing.T) *mockAPI {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
-		fmt.Fprint(w, `{"Year":2026,"DisplayCurrency":"RON","TotalRevenues":50000,"TotalDeductibleExpenses":20000,"HasTaxes":true,"Taxes":6000}`)
+		year := r.URL.Query().Get("year")
+		if year == "" {
+			year = "2026"
+		}
+		fmt.Fprintf(w, `{"Year":%s,"DisplayCurrency":"RON","TotalRevenues":50000,"TotalDeductibleExpenses":20000,"HasTaxes":true,"Taxes":6000}`, year)
 	})
 	mux.HandleFunc("/proxy/accounting/revenues/list", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `{"Items":[
-			{"SerialCode":"INV-001","ClientName":"ACME Corp","Total":1000.50,"IsPaid":true,"Currency":{"ShortName":"RON"}},
-			{"SerialCode":"INV-002","ClientName":"Globex","Total":250.25,"IsPaid":false,"Currency":{"ShortName":"EUR"}}
+			{"SerialCode":"INV-001","ClientName":"ACME Corp","IssueDate":"2026-01-15","Total":1000.50,"IsPaid":true,"Currency":{"ShortName":"RON"}},
+			{"SerialCode":"INV-002","ClientName":"Globex","IssueDate":"2026-02-10","Total":250.25,"IsPaid":false,"Currency":{"ShortName":"EUR"},"InvoiceLocalAmount":{"Total":1245.00}}
 		]}`)
 	})
 	mux.HandleFunc("/proxy/accounting/expenses/list", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `{"Items":[{"SupplierName":"Hosting SRL","Total":99.99,"Category":"Servicii","Currency":{"ShortName":"RON"}}]}`)
+		fmt.Fprint(w, `{"Items":[{"SupplierName":"Hosting SRL","PurchaseDate":"2026-03-05T00:00:00","Total":99.99,"Category":"Servicii","Currency":{"ShortName":"RON"},"DocumentCode":"DOC-1","DocumentMimeType":"application/pdf"}]}`)
 	})
 	mux.HandleFunc("/proxy/accounting/expenses/rejected", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `{"Items":[{"Id":7,"DocumentName":"blurry.jpg","Reason":"unreadable"}]}`)
+		fmt.Fprint(w, `{"Items":[{"Id":7,"DocumentName":"blurry.jpg","Reason":"unreadable","DocumentCode":"REJ-7","CreatedOn":"2026-04-02T09:00:00"}]}`)
+	})
+	// Only the expense document can be downloaded, REJ-7 is a 404
+	mux.HandleFunc("/api/financial-documents/download/DOC-1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		fmt.Fprint(w, "%PDF-1.4 receipt")
+	})
+	mux.HandleFunc("/proxy/accounting/expenses/summary", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"RegisteredExpenses":1,"QueuedExpenses":1,"RejectedExpenses":1}`)
+	})
+	mux.HandleFunc("/proxy/accounting/revenues/summary", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"RegisteredRevenues":2,"QueuedRevenues":0,"RejectedRevenues":0}`)
 	})
 	mux.HandleFunc("/proxy/accounting/expenses/queued", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `{"Items":[{"Id":42,"DocumentName":"receipt.pdf","DaysPassed":3,"IsOverdue":true}]}`)
@@ -140,9 +165,16 @@ func newEnv(t *testing.T, password string) *env {
 
 // run executes the real binary and returns stdout, stderr and the exit code
 func (e *env) run(t *testing.T, api *mockAPI, args ...string) (string, string, int) {
+	t.Helper()
+	return e.runStdin(t, api, "", args...)
+}
+
+// runStdin is run with stdin read from a string
+func (e *env) runStdin(t *testing.T, api *mockAPI, stdin string, args ...string) (string, string, int) {
 	t.Helper()
 	cmd := exec.Command(binPath, append([]string{"--config", e.configPath}, args...)...)
 	cmd.Env = append(os.Environ(), "HOME="+e.home, "SOLO_API_BASE="+api.server.URL)
+	cmd.Stdin = strings.NewReader(stdin)
 	var stdout, stderr strings.Builder
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -169,7 +201,7 @@ func TestE2EVersionAndHelp(t *testing.T) {
 	if code != 0 {
 		t.Errorf("help exit code %d", code)
 	}
-	for _, cmd := range []string{"summary", "taxes", "revenues", "expenses", "queue", "efactura", "company", "upload", "setup-skills", "demo"} {
+	for _, cmd := range []string{"summary", "taxes", "revenues", "expenses", "queue", "efactura", "company", "upload", "calendar", "setup-skills", "demo"} {
 		if !strings.Contains(out, cmd) {
 			t.Errorf("help output missing command %q", cmd)
 		}
@@ -259,6 +291,43 @@ func TestE2ESummaryAndSessionReuse(t *testing.T) {
 	}
 }
 
+// The VAT turnover sums the RON value of the year's invoices (the local
+// amount of the EUR one), which passes a 2000 RON threshold in February
+func TestE2ESummaryVATThreshold(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	if err := os.WriteFile(filepath.Join(filepath.Dir(e.configPath), "taxes.json"), []byte(`{"vat_threshold":2000}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, errOut, code := e.run(t, api, "summary", "--vat")
+	if code != 0 {
+		t.Fatalf("summary failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"Year: 2026\n",
+		"VAT Turnover: 2245.50 / 2000.00 RON (112.3%)\n",
+		"VAT Headroom: 0.00 RON\n",
+		"VAT Crossing: exceeded in February 2026\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q\nfull output:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(errOut, "VAT threshold of 2000.00 RON exceeded in February 2026") {
+		t.Errorf("no VAT warning on stderr: %q", errOut)
+	}
+
+	// Within 10%: 2245.50 of 2400 leaves 6.4%
+	if err := os.WriteFile(filepath.Join(filepath.Dir(e.configPath), "taxes.json"), []byte(`{"vat_threshold":2400}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, errOut, _ = e.run(t, api, "summary", "--vat")
+	if !strings.Contains(errOut, "VAT threshold: only 154.50 RON (6.4%) left before 2400.00 RON") {
+		t.Errorf("no headroom warning on stderr: %q", errOut)
+	}
+}
+
 func TestE2EListCommands(t *testing.T) {
 	api := newMockAPI(t)
 	e := newEnv(t, "good-password")
@@ -312,6 +381,506 @@ func TestE2EListCommands(t *testing.T) {
 	}
 }
 
+func TestE2ETemplateOutput(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	out, errOut, code := e.run(t, api, "revenues", "--template", `{{.SerialCode | lpad 8}} {{money .Total}} {{.Currency.ShortName}} {{upper .ClientName | pad 10}}|{{date "02.01.2006" .IssueDate}}`)
+	if code != 0 {
+		t.Fatalf("revenues --template failed (%d): %s", code, errOut)
+	}
+	if want := " INV-001 1000.50 RON ACME CORP |15.01.2026\n INV-002 250.25 EUR GLOBEX    |10.02.2026\n"; out != want {
+		t.Errorf("revenues output:\n%q\nwant:\n%q", out, want)
+	}
+
+	// Items rendering to nothing are left out
+	out, _, code = e.run(t, api, "--template", `{{if not .IsPaid}}{{.SerialCode}}{{end}}`, "revenues")
+	if code != 0 || out != "INV-002\n" {
+		t.Errorf("filtered output (%d): %q", code, out)
+	}
+
+	tmplFile := filepath.Join(t.TempDir(), "tax.tmpl")
+	os.WriteFile(tmplFile, []byte("net={{ron .NetIncome}}\ntotal={{money .TotalTaxes}}\n"), 0644)
+	out, errOut, code = e.run(t, api, "taxes", "2026", "--template-file", tmplFile)
+	if code != 0 || !strings.HasPrefix(out, "net=30000.00 RON\ntotal=") || strings.Count(out, "\n") != 2 {
+		t.Errorf("taxes --template-file (%d): %q %s", code, out, errOut)
+	}
+
+	for args, want := range map[string]string{
+		"summary":  "2026 50000.00",
+		"expenses": "Hosting SRL|servicii",
+		"queue":    "42 receipt.pdf",
+		"efactura": "EF-9 Telecom SA",
+		"company":  "Test PFA (11111111)",
+	} {
+		tmpl := map[string]string{
+			"summary":  "{{.Year}} {{money .TotalRevenues}}",
+			"expenses": "{{.SupplierName}}|{{lower .Category}}",
+			"queue":    "{{.Id}} {{.DocumentName}}",
+			"efactura": "{{.SerialCode}} {{.PartyName}}",
+			"company":  "{{.Name}} ({{.Code1}})",
+		}[args]
+		out, errOut, code := e.run(t, api, args, "--template", tmpl)
+		if code != 0 || out != want+"\n" {
+			t.Errorf("%s --template (%d): %q %s", args, code, out, errOut)
+		}
+	}
+
+	// Receivables and the report lists print one row per item
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"receivables", "--template", "{{.Client}} {{money .Total}} {{.Invoices}}"}, "Globex 1245.00 1\n"},
+		{[]string{"report", "clients", "--year", "2026", "--template", "{{.Name}}|{{money .Revenue}}"}, "Globex|1245.00\nACME Corp|1000.50\n"},
+		{[]string{"report", "pnl", "--year", "2026", "--by", "quarter", "--template", "{{.Period}} {{money .Revenue}}"}, "2026-Q1 2245.50\n2026-Q2 0.00\n2026-Q3 0.00\n2026-Q4 0.00\n"},
+	} {
+		out, errOut, code := e.run(t, api, tt.args...)
+		if code != 0 || out != tt.want {
+			t.Errorf("%v (%d): %q, want %q %s", tt.args, code, out, tt.want, errOut)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"revenues", "--template", "{{.NoSuchField}}"},
+		{"revenues", "--template", "{{"},
+		{"report", "annual", "--template", "{{.}}"},
+		{"taxes", "compare", "--template", "{{.}}"},
+		{"taxes", "config", "show", "--template", "{{.}}"},
+	} {
+		if _, errOut, code := e.run(t, api, args...); code == 0 || !strings.Contains(errOut, "template") {
+			t.Errorf("%v: want a template error (%d): %s", args, code, errOut)
+		}
+	}
+}
+
+func TestE2EMCP(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	requests := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_summary","arguments":{"year":2026}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"list_revenues","arguments":{"unpaid":true}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"list_expenses","arguments":{"year":2026,"search":"hosting"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"get_company","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"calculate_taxes","arguments":{"year":2026,"revenue":100000,"deductible_expenses":10000}}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"delete_queued_expense","arguments":{"id":42}}}`,
+		`{"jsonrpc":"2.0","id":9,"method":"tools/call","params":{"name":"delete_queued_expense","arguments":{"id":42,"confirm":true}}}`,
+		`{"jsonrpc":"2.0","id":10,"method":"tools/call","params":{"name":"list_expenses","arguments":{"search":"servicii"}}}`,
+		`{"jsonrpc":"2.0","id":11,"method":"tools/call","params":{"name":"list_expenses","arguments":{"search":"telecom"}}}`,
+	}
+	out, errOut, code := e.runStdin(t, api, strings.Join(requests, "\n")+"\n", "mcp")
+	if code != 0 {
+		t.Fatalf("mcp failed (%d): %s", code, errOut)
+	}
+
+	type answer struct {
+		ID     int `json:"id"`
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+			Tools           []struct {
+				Name        string `json:"name"`
+				Annotations struct {
+					ReadOnlyHint    bool `json:"readOnlyHint"`
+					DestructiveHint bool `json:"destructiveHint"`
+				} `json:"annotations"`
+			} `json:"tools"`
+			StructuredContent json.RawMessage         `json:"structuredContent"`
+			Content           []struct{ Text string } `json:"content"`
+			IsError           bool                    `json:"isError"`
+		} `json:"result"`
+	}
+	answers := map[int]answer{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var a answer
+		if err := json.Unmarshal([]byte(line), &a); err != nil {
+			t.Fatalf("stdout must only carry JSON-RPC, got %q", line)
+		}
+		answers[a.ID] = a
+	}
+	if len(answers) != 11 {
+		t.Fatalf("want 11 answers, got %d:\n%s", len(answers), out)
+	}
+
+	if answers[1].Result.ProtocolVersion != "2025-06-18" {
+		t.Errorf("initialize = %+v", answers[1])
+	}
+	tools := map[string]bool{}
+	for _, tool := range answers[2].Result.Tools {
+		tools[tool.Name] = true
+		if tool.Name == "delete_queued_expense" && (!tool.Annotations.DestructiveHint || tool.Annotations.ReadOnlyHint) {
+			t.Errorf("delete annotations = %+v", tool.Annotations)
+		}
+	}
+	for _, name := range []string{"get_summary", "list_revenues", "list_expenses", "list_queue", "list_efactura", "get_company", "calculate_taxes", "upload_document", "delete_queued_expense"} {
+		if !tools[name] {
+			t.Errorf("tool %s missing", name)
+		}
+	}
+
+	for id, want := range map[int]string{
+		3:  `"TotalRevenues":50000`,
+		4:  `"SerialCode":"INV-002"`,
+		5:  `"SupplierName":"Hosting SRL"`,
+		6:  `"Code1":"11111111"`,
+		7:  `"NetIncome":90000`,
+		9:  `{"deleted":42}`,
+		10: `"SupplierName":"Hosting SRL"`,
+		11: `"count":0`,
+	} {
+		a := answers[id]
+		if a.Result.IsError || !strings.Contains(string(a.Result.StructuredContent), want) {
+			t.Errorf("answer %d missing %s: %s", id, want, a.Result.StructuredContent)
+		}
+	}
+	if !strings.Contains(string(answers[4].Result.StructuredContent), `"count":1`) {
+		t.Errorf("unpaid revenues: %s", answers[4].Result.StructuredContent)
+	}
+
+	// The first delete is refused until the agent confirms, so only one
+	// request reaches the API
+	if a := answers[8]; !a.Result.IsError || !strings.Contains(a.Result.Content[0].Text, "needs confirmation") {
+		t.Errorf("unconfirmed delete = %+v", a.Result)
+	}
+	if got := api.deleteHits.Load(); got != 1 {
+		t.Errorf("DELETE hits = %d, want 1", got)
+	}
+}
+
+func TestE2EServe(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	base, stop := e.start(t, api, "serve", "--listen", "127.0.0.1:0", "--token", "tok")
+
+	get := func(path, token string) (int, string) {
+		req, _ := http.NewRequest("GET", base+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if code, _ := get("/v1/summary", ""); code != http.StatusUnauthorized {
+		t.Errorf("no token: %d", code)
+	}
+	for path, want := range map[string]string{
+		"/v1/summary?year=2026":    `"TotalRevenues": 50000`,
+		"/v1/revenues?unpaid=true": `"SerialCode": "INV-002"`,
+		"/v1/expenses?year=2026":   `"SupplierName": "Hosting SRL"`,
+		"/v1/queue":                `"DocumentName": "receipt.pdf"`,
+		"/v1/efactura?search=tele": `"SerialCode": "EF-9"`,
+		"/v1/company":              `"Code1": "11111111"`,
+		"/v1/taxes?year=2026":      `"NetIncome": 30000`,
+	} {
+		if code, body := get(path, "tok"); code != http.StatusOK || !strings.Contains(body, want) {
+			t.Errorf("%s = %d, missing %s:\n%s", path, code, want, body)
+		}
+	}
+	if code, body := get("/openapi.json", ""); code != http.StatusOK || !strings.Contains(body, `"openapi": "3.1.0"`) {
+		t.Errorf("openapi = %d", code)
+	}
+
+	// SIGTERM stops the server cleanly
+	if logs := stop(); !strings.Contains(logs, "Stopped") {
+		t.Errorf("stderr = %s", logs)
+	}
+}
+
+// start runs a long-lived command until it prints "Listening on <url>",
+// returning the URL and a stop function that sends SIGTERM, checks the exit
+// and returns the rest of stderr
+func (e *env) start(t *testing.T, api *mockAPI, args ...string) (string, func() string) {
+	t.Helper()
+	cmd := exec.Command(binPath, append([]string{"--config", e.configPath}, args...)...)
+	cmd.Env = append(os.Environ(), "HOME="+e.home, "SOLO_API_BASE="+api.server.URL)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cmd.Process.Kill() })
+
+	// The address is printed once the server listens
+	lines := bufio.NewScanner(stderr)
+	base := ""
+	for base == "" && lines.Scan() {
+		if rest, ok := strings.CutPrefix(lines.Text(), "Listening on "); ok {
+			base, _, _ = strings.Cut(rest, " ")
+			base = strings.TrimRight(base, ",")
+		}
+	}
+	if base == "" {
+		t.Fatal("server did not report its address")
+	}
+	var logs strings.Builder
+	logsDone := make(chan struct{})
+	go func() {
+		for lines.Scan() {
+			logs.WriteString(lines.Text() + "\n")
+		}
+		close(logsDone)
+	}()
+
+	return base, func() string {
+		t.Helper()
+		cmd.Process.Signal(syscall.SIGTERM)
+		<-logsDone
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("exit after SIGTERM: %v\n%s", err, logs.String())
+		}
+		return logs.String()
+	}
+}
+
+func TestE2EExporter(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+	base, stop := e.start(t, api, "exporter", "--listen", "127.0.0.1:0")
+	base = strings.TrimSuffix(base, "/metrics")
+
+	// The first poll runs in the background, wait for its last source
+	var body string
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		resp, err := http.Get(base + "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if body = string(data); strings.Contains(body, `solo_scrape_last_success_timestamp_seconds{source="receivables"}`) {
+			break
+		}
+	}
+	for _, want := range []string{
+		`solo_revenues_ytd_ron{year="` + strconv.Itoa(time.Now().Year()) + `"} 50000`,
+		`solo_estimated_tax_ron{year="` + strconv.Itoa(time.Now().Year()) + `",tax="total"}`,
+		`solo_expense_documents{year="` + strconv.Itoa(time.Now().Year()) + `",state="rejected"} 1`,
+		"solo_queue_documents 1",
+		"solo_queue_overdue_documents 1",
+		"solo_rejected_documents 1",
+		"solo_receivables_unpaid_invoices 1",
+		"solo_receivables_unpaid_total_ron 1245",
+		`solo_scrape_errors_total{source="summary"} 0`,
+		`solo_scrape_success{source="queue"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %s:\n%s", want, body)
+		}
+	}
+	if logs := stop(); !strings.Contains(logs, "Stopped") {
+		t.Errorf("stderr = %s", logs)
+	}
+}
+
+func TestE2ENotify(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	var mu sync.Mutex
+	var posted []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		}
+		json.NewDecoder(r.Body).Decode(&ev)
+		mu.Lock()
+		posted = append(posted, ev.ID+" "+ev.Title)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+	hits := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return strings.Join(posted, "\n")
+	}
+
+	writeConfig := func(notify string) {
+		cfg := `{"username":"user@example.com","password":"good-password","notify":` + notify + `}`
+		if err := os.WriteFile(e.configPath, []byte(cfg), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(`{"notifiers":{"hook":{"type":"webhook","url":"` + hook.URL + `"}},"rules":[{"events":["rejected","overdue"],"notify":["hook"]},{"events":["paid"],"notify":["nope"]}]}`)
+	if _, stderr, code := e.run(t, api, "notify", "--once"); code != 1 || !strings.Contains(stderr, `rule 2: unknown notifier "nope"`) {
+		t.Errorf("invalid rule: code %d, stderr %s", code, stderr)
+	}
+	writeConfig(`{"notifiers":{"hook":{"type":"webhook","url":"` + hook.URL + `"}},"rules":[{"events":["rejected","overdue"],"notify":["hook"]}]}`)
+
+	// The first run only records the current events
+	_, stderr, code := e.run(t, api, "notify", "--once")
+	if code != 0 || !strings.Contains(stderr, "Recorded 3 current events") || hits() != "" {
+		t.Fatalf("first run: code %d, stderr %s, posted %s", code, stderr, hits())
+	}
+
+	// Forget the queue and rejected documents so they are new again
+	state := filepath.Join(e.home, ".config", "solo-cli", "notify-state.json")
+	if err := os.WriteFile(state, []byte(`{"seen":{"paid:INV-001":"2026-04-01T00:00:00Z"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	out, _, code := e.run(t, api, "notify", "--dry-run")
+	if code != 0 || out != "rejected:7: Document rejected: blurry.jpg -> hook\noverdue:42: Document overdue: receipt.pdf -> hook\n" || hits() != "" {
+		t.Errorf("dry run: code %d, out %q, posted %s", code, out, hits())
+	}
+	out, stderr, code = e.run(t, api, "notify", "--once")
+	if code != 0 || hits() != "rejected:7 Document rejected: blurry.jpg\noverdue:42 Document overdue: receipt.pdf" {
+		t.Errorf("run: code %d, out %s, stderr %s, posted %s", code, out, stderr, hits())
+	}
+	// Each event fires once
+	if out, _, _ = e.run(t, api, "notify", "--once"); out != "" || strings.Count(hits(), "\n") != 1 {
+		t.Errorf("second run: out %q, posted %s", out, hits())
+	}
+
+	// A dry run lists only the notifiers that still have to deliver
+	writeConfig(`{"notifiers":{"hook":{"type":"webhook","url":"` + hook.URL + `"},"hook2":{"type":"webhook","url":"` + hook.URL + `"}},"rules":[{"events":["rejected","overdue"],"notify":["hook","hook2"]}]}`)
+	pending := `{"seen":{"paid:INV-001":"2026-04-01T00:00:00Z","rejected:7":"2026-04-01T00:00:00Z","overdue:42":"2026-04-01T00:00:00Z"},"pending":{"rejected:7":["hook2"]}}`
+	if err := os.WriteFile(state, []byte(pending), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if out, _, code = e.run(t, api, "notify", "--dry-run"); code != 0 || out != "rejected:7: Document rejected: blurry.jpg -> hook2\n" {
+		t.Errorf("dry run with a pending delivery: code %d, out %q", code, out)
+	}
+
+	// Another process sending notifications holds the state
+	lock := filepath.Join(e.home, ".config", "solo-cli", "notify-state.lock")
+	held, err := daemon.AcquireLock(lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, stderr, code = e.run(t, api, "notify", "--once"); code != 1 || !strings.Contains(stderr, "another process") {
+		t.Errorf("locked state: code %d, stderr %s", code, stderr)
+	}
+	held.Release()
+	writeConfig(`{"notifiers":{"hook":{"type":"webhook","url":"` + hook.URL + `"}},"rules":[{"events":["rejected","overdue"],"notify":["hook"]}]}`)
+
+	out, _, code = e.run(t, api, "notify", "test")
+	if code != 0 || out != "Sent: hook\n" || !strings.Contains(hits(), "solo-cli test notification") {
+		t.Errorf("test: code %d, out %q, posted %s", code, out, hits())
+	}
+}
+
+func TestE2EDaemon(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+	syncDir := filepath.Join(t.TempDir(), "sync")
+	watch := t.TempDir()
+	jobs := `[
+		{"name":"sync","schedule":"@hourly","run":"sync","dir":"` + syncDir + `"},
+		{"name":"inbox","schedule":"*/5 * * * *","run":"upload","dir":"` + watch + `"},
+		{"name":"version","schedule":"0 8 * * mon-fri","run":"command","args":["version"]}
+	]`
+	cfg := `{"username":"user@example.com","password":"good-password","jobs":` + jobs + `}`
+	if err := os.WriteFile(e.configPath, []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _, code := e.run(t, api, "daemon", "status")
+	if code != 0 || out != "Daemon has not run yet\n" {
+		t.Errorf("status before running: code %d, out %q", code, out)
+	}
+
+	if _, stderr, code := e.run(t, api, "daemon", "run", "sync"); code != 0 || !strings.Contains(stderr, "msg=synced") {
+		t.Errorf("run sync: code %d, stderr %s", code, stderr)
+	}
+	for _, name := range []string{"summary", "revenues", "expenses", "queue", "rejected", "efactura"} {
+		if _, err := os.Stat(filepath.Join(syncDir, name+".json")); err != nil {
+			t.Errorf("sync: %v", err)
+		}
+	}
+
+	// Only settled documents are uploaded
+	old := time.Now().Add(-time.Minute)
+	for _, name := range []string{"receipt.pdf", "invoice.pdf.crdownload", ".hidden.pdf"} {
+		path := filepath.Join(watch, name)
+		os.WriteFile(path, []byte("%PDF-1.4 fake"), 0644)
+		os.Chtimes(path, old, old)
+	}
+	os.WriteFile(filepath.Join(watch, "fresh.pdf"), []byte("%PDF-1.4 fake"), 0644)
+	if _, stderr, code := e.run(t, api, "daemon", "run", "inbox"); code != 0 || strings.Count(stderr, "msg=uploaded") != 1 {
+		t.Errorf("run inbox: code %d, stderr %s", code, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(watch, "uploaded", "receipt.pdf")); err != nil {
+		t.Errorf("uploaded file not moved: %v", err)
+	}
+	for _, name := range []string{"invoice.pdf.crdownload", ".hidden.pdf", "fresh.pdf"} {
+		if _, err := os.Stat(filepath.Join(watch, name)); err != nil {
+			t.Errorf("%s should stay: %v", name, err)
+		}
+	}
+
+	if _, stderr, code := e.run(t, api, "daemon", "run", "version"); code != 0 || !strings.Contains(stderr, `text="solo-cli dev"`) {
+		t.Errorf("run version: code %d, stderr %s", code, stderr)
+	}
+	if _, stderr, code := e.run(t, api, "daemon", "run", "nope"); code != 1 || !strings.Contains(stderr, "no job named 'nope'") {
+		t.Errorf("run unknown job: code %d, stderr %s", code, stderr)
+	}
+
+	cmd := exec.Command(binPath, "--config", e.configPath, "daemon")
+	cmd.Env = append(os.Environ(), "HOME="+e.home, "SOLO_API_BASE="+api.server.URL)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cmd.Process.Kill() })
+	lines := bufio.NewScanner(stderr)
+	for lines.Scan() && !strings.Contains(lines.Text(), `"msg":"daemon started"`) {
+	}
+	var logs strings.Builder
+	logsDone := make(chan struct{})
+	go func() {
+		for lines.Scan() {
+			logs.WriteString(lines.Text() + "\n")
+		}
+		close(logsDone)
+	}()
+
+	out, _, _ = e.run(t, api, "daemon", "status")
+	if !strings.HasPrefix(out, fmt.Sprintf("Daemon running (pid %d) since ", cmd.Process.Pid)) || !strings.Contains(out, "*/5 * * * *") {
+		t.Errorf("status while running: %s", out)
+	}
+	if _, stderr, code := e.run(t, api, "daemon"); code != 1 || !strings.Contains(stderr, fmt.Sprintf("another daemon is running (pid %d)", cmd.Process.Pid)) {
+		t.Errorf("second daemon: code %d, stderr %s", code, stderr)
+	}
+	if _, stderr, code := e.run(t, api, "daemon", "run", "version"); code != 1 || !strings.Contains(stderr, fmt.Sprintf("the daemon is running (pid %d)", cmd.Process.Pid)) {
+		t.Errorf("run next to the daemon: code %d, stderr %s", code, stderr)
+	}
+
+	cmd.Process.Signal(syscall.SIGTERM)
+	<-logsDone
+	if err := cmd.Wait(); err != nil || !strings.Contains(logs.String(), `"msg":"daemon stopped"`) {
+		t.Errorf("exit after SIGTERM: %v\n%s", err, logs.String())
+	}
+	out, _, _ = e.run(t, api, "daemon", "status", "--json")
+	var st struct {
+		Running bool      `json:"running"`
+		Stopped time.Time `json:"stopped"`
+		Jobs    []struct {
+			Name string `json:"name"`
+		} `json:"jobs"`
+	}
+	if err := json.Unmarshal([]byte(out), &st); err != nil || st.Running || st.Stopped.IsZero() || len(st.Jobs) != 3 {
+		t.Errorf("status after stop: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(e.home, ".config", "solo-cli", "daemon.lock")); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
 func TestE2EQueueDelete(t *testing.T) {
 	api := newMockAPI(t)
 	e := newEnv(t, "good-password")
@@ -403,3 +972,701 @@ func TestE2ETaxesMathAndConfigFile(t *testing.T) {
 		t.Errorf("salariu_minim_brut = %v, want 4050 (January 1 2026 value)", taxCfg.SalariuMinimBrut)
 	}
 }
+
+// The calendar lists the D212 payment with the amount computed from the
+// previous year's summary (the mock echoes 50000/20000 for every year, so
+// 5700 due) and exports a valid iCalendar file
+func TestE2ECalendar(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	out, errOut, code := e.run(t, api, "calendar")
+	if code != 0 {
+		t.Fatalf("calendar failed (%d): %s", code, errOut)
+	}
+	if !strings.Contains(out, "\tPlată impozit pe venit și CAS/CASS\t5700.00 RON\n") {
+		t.Errorf("calendar output missing payment deadline:\n%s", out)
+	}
+	if !strings.Contains(out, "\tDepunere D212 (declarația unică)\t\n") {
+		t.Errorf("calendar output missing filing deadline:\n%s", out)
+	}
+
+	// The rules file must have been created so it can be edited
+	if _, err := os.Stat(filepath.Join(e.home, ".config", "solo-cli", "calendar.json")); err != nil {
+		t.Errorf("calendar.json not created: %v", err)
+	}
+
+	icsPath := filepath.Join(t.TempDir(), "termene.ics")
+	if _, errOut, code := e.run(t, api, "calendar", "ics", icsPath); code != 0 {
+		t.Fatalf("calendar ics failed (%d): %s", code, errOut)
+	}
+	data, err := os.ReadFile(icsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ics := string(data)
+	if !strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n") || !strings.Contains(ics, "UID:d212-plata-") {
+		t.Errorf("ics output invalid:\n%s", ics)
+	}
+}
+
+// Net 30000 only has the CASS 6 salarii threshold below it, and dropping
+// there costs more than it saves
+func TestE2ETaxesOptimize(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	out, errOut, code := e.run(t, api, "taxes", "optimize")
+	if code != 0 {
+		t.Fatalf("taxes optimize failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"Expense Optimizer (2026)",
+		"          0.00       30000.00        5700.00       24300.00         0.00",
+		"Best: no extra deductible expense pays for itself",
+		"Marginal Tax Rate",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("optimize output missing %q\nfull output:\n%s", want, out)
+		}
+	}
+}
+
+// Net 30000 under sistem real, a 40000 norm for the primary CAEN 6201 and a
+// micro SRL paying 1% of 50000 then dividends on the remaining 29500
+func TestE2ETaxesCompare(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	out, errOut, code := e.run(t, api, "taxes", "compare")
+	if code != 0 {
+		t.Fatalf("taxes compare failed (%d): %s", code, errOut)
+	}
+	if !strings.Contains(out, "PFA normă de venit: no norma_venit configured for CAEN 6201") {
+		t.Errorf("missing norma note\nfull output:\n%s", out)
+	}
+
+	taxCfg := `{"regimes":{"norma_venit":{"6201":40000}}}`
+	if err := os.WriteFile(filepath.Join(filepath.Dir(e.configPath), "taxes.json"), []byte(taxCfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, errOut, code = e.run(t, api, "taxes", "compare")
+	if code != 0 {
+		t.Fatalf("taxes compare failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"Tax Regime Comparison (2026)",
+		"                   PFA sistem real   PFA normă de venit       Micro SRL (1%)\n",
+		fmt.Sprintf("%-22s %20.2f %20.2f %20.2f", "Income/turnover tax", 2700.0, 3600.0, 500.0),
+		fmt.Sprintf("%-22s %20.2f %20.2f %20.2f", "Dividend tax", 0.0, 0.0, 4720.0),
+		fmt.Sprintf("%-22s %20.2f %20.2f %20.2f", "Net in pocket", 24300.0, 22400.0, 22350.0),
+		"Best: PFA sistem real (24300.00 RON in pocket)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("compare output missing %q\nfull output:\n%s", want, out)
+		}
+	}
+}
+
+// taxes config edits taxes.json without logging in, and an invalid file
+// stops the tax commands with every problem listed
+func TestE2ETaxesConfig(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+	taxesPath := filepath.Join(filepath.Dir(e.configPath), "taxes.json")
+
+	out, errOut, code := e.run(t, api, "taxes", "config", "set", "smb", "4325")
+	if code != 0 || out != "Set smb = 4325\n" {
+		t.Fatalf("set smb: code %d, out %q, stderr %q", code, out, errOut)
+	}
+	out, _, _ = e.run(t, api, "taxes", "config", "show")
+	if !strings.Contains(out, `"salariu_minim_brut": 4325`) {
+		t.Errorf("show missing the new SMB:\n%s", out)
+	}
+	if got := api.loginHits.Load(); got != 0 {
+		t.Errorf("taxes config logged in %d times, want 0", got)
+	}
+
+	_, errOut, code = e.run(t, api, "taxes", "config", "set", "smb", "0")
+	if code != 1 || !strings.Contains(errOut, "salariu_minim_brut must be positive") || !strings.Contains(errOut, "Nothing was saved") {
+		t.Errorf("set smb 0: code %d, stderr %q", code, errOut)
+	}
+
+	broken := `{"cas_thresholds":[{"min_salaries":0,"max_salaries":12},{"min_salaries":14,"max_salaries":0,"base_salaries":24}]}`
+	if err := os.WriteFile(taxesPath, []byte(broken), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"taxes"}, {"taxes", "config", "validate"}} {
+		_, errOut, code = e.run(t, api, args...)
+		if code != 1 || !strings.Contains(errOut, "taxes.json is invalid:\n  - cas_thresholds[1]: gap between 12 and 14 salarii") {
+			t.Errorf("%v on a broken config: code %d, stderr %q", args, code, errOut)
+		}
+	}
+	// The summary still works, only the VAT tracker is skipped
+	out, errOut, code = e.run(t, api, "summary", "--vat")
+	if code != 0 || strings.Contains(out, "VAT") || !strings.Contains(errOut, "is invalid") {
+		t.Errorf("summary on a broken config: code %d, out %q, stderr %q", code, out, errOut)
+	}
+
+	if _, errOut, code = e.run(t, api, "taxes", "config", "reset"); code != 0 {
+		t.Fatalf("reset failed: %s", errOut)
+	}
+	if backup, err := os.ReadFile(taxesPath + ".bak"); err != nil || string(backup) != broken {
+		t.Errorf("backup = %q, %v, want the broken file", backup, err)
+	}
+	if out, errOut, code = e.run(t, api, "taxes", "config", "validate"); code != 0 || !strings.Contains(out, "is valid") {
+		t.Errorf("validate after reset: code %d, out %q, stderr %q", code, out, errOut)
+	}
+}
+
+// Other income comes from income.json for the summary year plus --income
+// flags: 30000 rent (income.json) and 20000 dividends (flag) are 50000, 12.3
+// salarii, so CASS on other income is due on 12 salarii
+func TestE2ETaxesOtherIncome(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	income := `[{"year":2026,"category":"rent","amount":30000},{"year":2025,"category":"rent","amount":99999}]`
+	if err := os.WriteFile(filepath.Join(filepath.Dir(e.configPath), "income.json"), []byte(income), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, errOut, code := e.run(t, api, "taxes", "--income", "dividends=20000")
+	if code != 0 {
+		t.Fatalf("taxes failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"Other Income:         50000.00 RON",
+		"Chirii: 30000.00 RON → Tax (10% of 24000.00 RON): 2400.00 RON",
+		"Dividende: 20000.00 RON → Tax (16% of 20000.00 RON, withheld at source): 3200.00 RON",
+		"CASS on other income (10%): CASS pe 12 salarii",
+		"Base: 48600.00 RON → Amount: 4860.00 RON",
+		"Total Taxes:          16160.00 RON",
+		"Withheld at source: 3200.00 RON",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("taxes output missing %q\nfull output:\n%s", want, out)
+		}
+	}
+
+	_, errOut, code = e.run(t, api, "taxes", "--income", "lottery=100")
+	if code != 1 || !strings.Contains(errOut, "unknown income category 'lottery'") {
+		t.Errorf("unknown category: code %d, stderr %q", code, errOut)
+	}
+}
+
+func TestE2ERatesAndCurrency(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	// Before any import, converting to EUR leaves the RON invoice as is
+	out, errOut, code := e.run(t, api, "--currency", "EUR", "revenues")
+	if code != 0 || !strings.Contains(out, "INV-001\t1000.50 RON") || !strings.Contains(errOut, "1 amount(s) could not be converted to EUR") {
+		t.Errorf("revenues without rates: code %d, out %q, stderr %q", code, out, errOut)
+	}
+
+	xmlPath := filepath.Join(t.TempDir(), "nbrfxrates2026.xml")
+	xmlData := `<?xml version="1.0" encoding="utf-8"?>
+<DataSet xmlns="http://www.bnr.ro/xsd">
+	<Body>
+		<OrigCurrency>RON</OrigCurrency>
+		<Cube date="2026-01-15"><Rate currency="EUR">5.0000</Rate><Rate currency="HUF" multiplier="100">1.2500</Rate></Cube>
+		<Cube date="2026-02-10"><Rate currency="EUR">4.9800</Rate></Cube>
+	</Body>
+</DataSet>`
+	if err := os.WriteFile(xmlPath, []byte(xmlData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, errOut, code = e.run(t, api, "rates", "import", xmlPath)
+	if code != 0 {
+		t.Fatalf("rates import failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{"Imported 2 day(s) from " + xmlPath + " (2 new)", "Rates available from 2026-01-15 to 2026-02-10"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("import output missing %q\nfull output:\n%s", want, out)
+		}
+	}
+
+	// Saturday falls back to Thursday's fixing
+	out, _, code = e.run(t, api, "rates", "show", "2026-01-17")
+	if code != 0 || out != "BNR rates of 2026-01-15 (RON per unit)\nEUR\t5\nHUF\t0.0125\n" {
+		t.Errorf("rates show: code %d, out %q", code, out)
+	}
+	if _, errOut, code = e.run(t, api, "rates", "show", "2026-03-30"); code != 1 || !strings.Contains(errOut, "No BNR rates for 2026-03-30") {
+		t.Errorf("rates show outside range: code %d, stderr %q", code, errOut)
+	}
+
+	out, errOut, code = e.run(t, api, "--currency", "eur", "revenues")
+	wantRev := "INV-001\t200.10 EUR\tPAID\tACME Corp\nINV-002\t250.25 EUR\tUNPAID\tGlobex\n"
+	if code != 0 || out != wantRev || errOut != "" {
+		t.Errorf("revenues in EUR: code %d, stderr %q\ngot:\n%s\nwant:\n%s", code, errOut, out, wantRev)
+	}
+
+	out, errOut, code = e.run(t, api, "report", "currency", "2026")
+	if code != 0 {
+		t.Fatalf("report currency failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"Currency Exposure (2026, values in RON)",
+		"EUR             1         250.25 EUR         250.25 EUR        1245.00   55.4%",
+		"RON             1        1000.50 RON           0.00 RON        1000.50   44.6%",
+		"Total           2                                              2245.50",
+		"Foreign currency: 55.4% of invoicing",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report output missing %q\nfull output:\n%s", want, out)
+		}
+	}
+
+	if _, errOut, code = e.run(t, api, "--currency"); code != 1 || !strings.Contains(errOut, "--currency requires a currency code") {
+		t.Errorf("--currency without value: code %d, stderr %q", code, errOut)
+	}
+
+	// A corrupt store is reported instead of counting EUR as RON silently
+	if err := os.WriteFile(filepath.Join(e.home, ".config", "solo-cli", "rates.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, errOut, code = e.run(t, api, "receivables"); code != 0 || !strings.Contains(errOut, "Could not load the exchange rates") {
+		t.Errorf("corrupt rates.json: code %d, stderr %q", code, errOut)
+	}
+}
+
+func TestE2EReceivables(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	out, errOut, code := e.run(t, api, "receivables")
+	if code != 0 {
+		t.Fatalf("receivables failed (%d): %s", code, errOut)
+	}
+	if !strings.Contains(out, "Receivables (as of ") {
+		t.Errorf("header missing:\n%s", out)
+	}
+	// Only Globex owes money: its EUR invoice is valued at the local amount
+	var globex string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "Globex") {
+			globex = line
+		}
+	}
+	if !strings.Contains(globex, "1245.00") || !strings.HasSuffix(globex, "n/a  250.25 EUR") {
+		t.Errorf("Globex row = %q, want 1245.00 RON owed as 250.25 EUR", globex)
+	}
+	if strings.Contains(out, "ACME Corp") {
+		t.Errorf("paid client listed:\n%s", out)
+	}
+	if !strings.Contains(out, "Total                           1 ") {
+		t.Errorf("total row missing:\n%s", out)
+	}
+}
+
+func TestE2EReportPnL(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	out, errOut, code := e.run(t, api, "report", "pnl", "2026", "--by", "quarter")
+	if code != 0 {
+		t.Fatalf("report pnl failed (%d): %s", code, errOut)
+	}
+	// Both invoices and the deductible hosting expense fall in Q1, the EUR
+	// invoice at its local amount
+	for _, want := range []string{
+		"Profit and Loss 2026 by quarter (RON)",
+		"2026-Q1           2245.50          99.99             0.00        2145.51        2145.51",
+		"2026-Q4              0.00",
+		"2026              2245.50",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+
+	out, _, code = e.run(t, api, "report", "pnl", "--year", "2026", "--by", "q", "--format", "csv")
+	if code != 0 || !strings.HasPrefix(out, "period,revenue,") || !strings.Contains(out, "\n2026-Q1,2245.50,99.99,0.00,2145.51,2145.51\n") {
+		t.Errorf("csv output (%d):\n%s", code, out)
+	}
+
+	out, _, code = e.run(t, api, "report", "pnl", "2026", "--json")
+	var pnl struct {
+		By      string `json:"by"`
+		Periods []struct {
+			Period  string  `json:"period"`
+			Revenue float64 `json:"revenue"`
+		} `json:"periods"`
+	}
+	if code != 0 || json.Unmarshal([]byte(out), &pnl) != nil || pnl.By != "month" || len(pnl.Periods) != 12 || pnl.Periods[1].Revenue != 1245 {
+		t.Errorf("json output (%d):\n%s", code, out)
+	}
+
+	if _, errOut, code := e.run(t, api, "report", "pnl", "--by", "week"); code == 0 || !strings.Contains(errOut, "invalid period") {
+		t.Errorf("--by week: exit %d, stderr %q", code, errOut)
+	}
+}
+
+func TestE2EReportExpenses(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	out, errOut, code := e.run(t, api, "report", "expenses", "2026", "--group-by", "supplier")
+	if code != 0 {
+		t.Fatalf("report expenses failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"Expenses by supplier 2026 (RON)",
+		"Supplier                      Count         Amount   Share           2025   Change",
+		"Hosting SRL                       1          99.99  100.0%           0.00      new",
+		"Non-deductible: 0.00 RON (0.0% of expenses)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+
+	out, _, code = e.run(t, api, "report", "expenses", "--year", "2026", "--csv")
+	if code != 0 || !strings.Contains(out, "category,count,amount,") || !strings.Contains(out, "\nServicii,1,99.99,100.0,0.00,0.00,\n") {
+		t.Errorf("csv output (%d):\n%s", code, out)
+	}
+
+	out, _, code = e.run(t, api, "report", "expenses", "2025")
+	if code != 0 || !strings.Contains(out, "No expenses in 2025") {
+		t.Errorf("empty year (%d):\n%s", code, out)
+	}
+
+	if _, errOut, code := e.run(t, api, "report", "expenses", "--group-by", "month"); code == 0 || !strings.Contains(errOut, "invalid grouping") {
+		t.Errorf("--group-by month: exit %d, stderr %q", code, errOut)
+	}
+}
+
+func TestE2EReportClients(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	out, errOut, code := e.run(t, api, "report", "clients", "2026")
+	if code != 0 {
+		t.Fatalf("report clients failed (%d): %s", code, errOut)
+	}
+	// Globex's EUR invoice counts at its local amount, so it leads
+	for _, want := range []string{
+		"Revenue by Client 2026 (RON)",
+		"Globex                              1        1245.00   55.4%      1245.00   2026",
+		"ACME Corp                           1        1000.50   44.6%      1000.50   2026",
+		"Total                               2        2245.50  100.0%      1122.75",
+		"Concentration:   HHI 5059 (highly concentrated), largest client 55.4% of revenue",
+		"Clients:         2 (2 new, 0 returning)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table missing %q:\n%s", want, out)
+		}
+	}
+
+	out, _, code = e.run(t, api, "report", "clients", "--year", "2026", "--top", "1", "--format", "json")
+	var rep struct {
+		Clients []struct{ Name string } `json:"clients"`
+		Other   *struct{ Name string }  `json:"other"`
+		HHI     float64                 `json:"hhi"`
+	}
+	if code != 0 || json.Unmarshal([]byte(out), &rep) != nil || len(rep.Clients) != 1 || rep.Other == nil || rep.Other.Name != "Other (1)" || rep.HHI < 5000 {
+		t.Errorf("json output (%d):\n%s", code, out)
+	}
+}
+
+func TestE2EReportAnnual(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	_, errOut, code := e.run(t, api, "report", "annual", "2026")
+	if code == 0 || !strings.Contains(errOut, "--html <file> and/or --pdf <file>") {
+		t.Errorf("want an error without an output (%d): %s", code, errOut)
+	}
+
+	dir := t.TempDir()
+	htmlPath, pdfPath := filepath.Join(dir, "annual.html"), filepath.Join(dir, "annual.pdf")
+	_, errOut, code = e.run(t, api, "report", "annual", "--year", "2026", "--html", htmlPath, "--pdf", pdfPath)
+	if code != 0 {
+		t.Fatalf("report annual failed (%d): %s", code, errOut)
+	}
+	if !strings.Contains(errOut, "Wrote "+htmlPath) || !strings.Contains(errOut, "Wrote "+pdfPath) {
+		t.Errorf("stderr = %s", errOut)
+	}
+
+	page, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>Annual Report 2026 – Test PFA</title>",
+		"<p>CUI 11111111 · Reg. F1/1/2026</p>",
+		"(primary)</p>",
+		"<b>50,000.00 RON</b>",
+		"<td>Globex</td>",
+		"<td>Hosting SRL</td>",
+		"<svg xmlns=",
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+
+	doc, err := os.ReadFile(pdfPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Errorf("not a PDF: %.40q", doc)
+	}
+}
+
+func TestE2EStatementAndReminder(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+	dir := t.TempDir()
+
+	out, errOut, code := e.run(t, api, "statement", "glob")
+	if code != 0 {
+		t.Fatalf("statement failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"Statement of account: Globex",
+		"INV-002            2026-02-10 2026-03-12         250.25 EUR  Overdue ",
+		"Outstanding: 250.25 EUR (1245.00 RON)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("statement missing %q\nfull output:\n%s", want, out)
+		}
+	}
+
+	mdPath := filepath.Join(dir, "acme.md")
+	_, errOut, code = e.run(t, api, "statement", "ACME", "Corp", "-o", mdPath, "--reminder")
+	if code != 0 || !strings.Contains(errOut, "No overdue invoices for ACME Corp") {
+		t.Errorf("paid client: code %d, stderr %q", code, errOut)
+	}
+	if md, err := os.ReadFile(mdPath); err != nil || !strings.Contains(string(md), "| INV-001 | 2026-01-15 | 2026-02-14 | 1000.50 RON | Paid |") {
+		t.Errorf("markdown statement: %v\n%s", err, md)
+	}
+
+	emlPath := filepath.Join(dir, "globex.eml")
+	_, errOut, code = e.run(t, api, "statement", "Globex", "--terms", "14", "--reminder", emlPath, "--to", "ap@globex.example")
+	if code != 0 || !strings.Contains(errOut, "Wrote a reminder for 1 overdue invoice(s) to "+emlPath) {
+		t.Fatalf("reminder: code %d, stderr %q", code, errOut)
+	}
+	eml, err := os.ReadFile(emlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"To: <ap@globex.example>\r\n", "X-Unsent: 1\r\n", "INV-002 din 2026-02-10, scadent=C4=83 la 2026-02-24"} {
+		if !strings.Contains(string(eml), want) {
+			t.Errorf("eml missing %q\n%s", want, eml)
+		}
+	}
+	// The default template is written out for customizing
+	if _, err := os.Stat(filepath.Join(filepath.Dir(e.configPath), "reminder.tmpl")); err != nil {
+		t.Errorf("reminder.tmpl not created: %v", err)
+	}
+
+	_, errOut, code = e.run(t, api, "statement", "nobody")
+	if code != 1 || !strings.Contains(errOut, "no invoices for a client matching 'nobody'") {
+		t.Errorf("unknown client: code %d, stderr %q", code, errOut)
+	}
+}
+
+// The mock echoes the requested year with the same totals, so every year
+// shows the same amounts with no growth
+func TestE2ESummaryYears(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	out, errOut, code := e.run(t, api, "summary", "--years", "2024-2026")
+	if code != 0 {
+		t.Fatalf("summary --years failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"Year-over-Year Comparison (2024-2026, RON)",
+		"2024        50000.00",
+		"2026        50000.00    +0.0%      20000.00    +0.0%      30000.00    +0.0%",
+		"Revenue growth: +0.0% per year on average",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	_, errOut, code = e.run(t, api, "summary", "--years", "2026-2024")
+	if code != 1 || !strings.Contains(errOut, "invalid year range '2026-2024'") {
+		t.Errorf("reversed range: code %d, stderr %q", code, errOut)
+	}
+}
+
+func TestE2EExportJournal(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	out, errOut, code := e.run(t, api, "export", "ledger", "--year", "2026")
+	if code != 0 {
+		t.Fatalf("export ledger failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"2026-01-15 * (INV-001) ACME Corp  ; Invoice INV-001\n    ; id: inv-",
+		"    Assets:Receivables                    250.25 EUR @@ 1245.00 RON\n    Income:Services                     -1245.00 RON\n",
+		"2026-03-05 * Hosting SRL  ; Servicii\n",
+		"    Expenses:Services                      99.99 RON\n    Assets:Bank                           -99.99 RON\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("ledger missing %q:\n%s", want, out)
+		}
+	}
+	// The account mapping is written out for editing
+	if _, err := os.Stat(filepath.Join(filepath.Dir(e.configPath), "accounts.json")); err != nil {
+		t.Errorf("accounts.json not created: %v", err)
+	}
+
+	// Exporting to a file twice gives the same journal
+	path := filepath.Join(t.TempDir(), "solo.beancount")
+	var exports []string
+	for range 2 {
+		_, errOut, code := e.run(t, api, "export", "beancount", "2026", "-o", path)
+		if code != 0 || !strings.Contains(errOut, "Wrote 3 transactions to "+path) {
+			t.Fatalf("export beancount: code %d, stderr %q", code, errOut)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exports = append(exports, string(data))
+	}
+	if exports[0] != exports[1] || !strings.Contains(exports[0], "2026-01-15 open Income:Services\n") {
+		t.Errorf("beancount exports differ or lack the opens:\n%s", exports[0])
+	}
+
+	_, errOut, code = e.run(t, api, "export", "gnucash")
+	if code != 1 || !strings.Contains(errOut, "Unknown export: gnucash") {
+		t.Errorf("unknown format: code %d, stderr %q", code, errOut)
+	}
+}
+
+func TestE2EExportAudit(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	path := filepath.Join(t.TempDir(), "audit.zip")
+	_, errOut, code := e.run(t, api, "export", "audit", "--year", "2026", "-o", path)
+	if code != 0 {
+		t.Fatalf("export audit failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"Wrote " + path + ": 2 revenues, 1 expenses, 1 rejected, 1 e-Factura, 1 documents",
+		"note: document REJ-7: failed to download document REJ-7: status 404",
+	} {
+		if !strings.Contains(errOut, want) {
+			t.Errorf("stderr missing %q:\n%s", want, errOut)
+		}
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		members[f.Name] = string(data)
+	}
+	zr.Close()
+	for name, want := range map[string]string{
+		"company.json":  `"Code": "6201"`,
+		"revenues.csv":  ",INV-002,Globex,2026-02-10,,false,false,250.25,EUR,1245.00,,false,",
+		"expenses.csv":  "documents/expenses/2026-03-05_Hosting-SRL_DOC-1.pdf",
+		"rejected.json": `"Reason": "unreadable"`,
+		"efactura.csv":  "EF-9,2026-06-01,,Telecom SA,500.00,RON",
+		"taxes.json":    `"TotalRevenues": 50000`,
+		"documents/expenses/2026-03-05_Hosting-SRL_DOC-1.pdf": "%PDF-1.4 receipt",
+		"SHA256SUMS":    "  revenues.json\n",
+		"manifest.json": `"company": "Test PFA"`,
+	} {
+		if !strings.Contains(members[name], want) {
+			t.Errorf("%s missing %q:\n%s", name, want, members[name])
+		}
+	}
+
+	out, errOut, code := e.run(t, api, "export", "verify", path)
+	if code != 0 || !strings.Contains(out, "Audit archive 2026 for Test PFA") || !strings.Contains(out, "All 11 files match") {
+		t.Errorf("verify: code %d, stdout %q, stderr %q", code, out, errOut)
+	}
+
+	// A changed document fails the check
+	tampered := filepath.Join(t.TempDir(), "tampered.zip")
+	f, err := os.Create(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, data := range members {
+		if strings.HasPrefix(name, "documents/") {
+			data = "%PDF-1.4 forged!"
+		}
+		w, _ := zw.Create(name)
+		io.WriteString(w, data)
+	}
+	zw.Close()
+	f.Close()
+	out, _, code = e.run(t, api, "export", "verify", tampered)
+	if code != 1 || !strings.Contains(out, "FAILED documents/expenses/2026-03-05_Hosting-SRL_DOC-1.pdf: checksum mismatch") {
+		t.Errorf("tampered verify: code %d, stdout %q", code, out)
+	}
+}
+
+func TestE2EBankImport(t *testing.T) {
+	api := newMockAPI(t)
+	e := newEnv(t, "good-password")
+
+	path := filepath.Join(t.TempDir(), "george.csv")
+	statement := "Data finalizarii tranzactiei;Tip tranzactie;Descriere;Suma;Valuta;Nume partener;Referinta\n" +
+		"20.02.2026;Transfer primit;Factura INV-002;250,25;EUR;GLOBEX;FT1\n" +
+		"06.03.2026;Plata card;Servicii hosting;-99,99;RON;HOSTING SRL;FT2\n" +
+		"07.03.2026;Transfer primit;Avans;300,00;RON;ACME CORP;FT3\n"
+	if err := os.WriteFile(path, []byte(statement), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, errOut, code := e.run(t, api, "bank", "import", path)
+	if code != 0 {
+		t.Fatalf("bank import failed (%d): %s", code, errOut)
+	}
+	for _, want := range []string{
+		"Bank statement: BCR CSV, 3 transactions (2026-02-20 to 2026-03-07)",
+		"Paid invoices still open in SOLO (1, 1245.00 RON)",
+		"2026-02-20  INV-002          Globex                             250.25 EUR  reference",
+		"2026-03-06  2026-03-05 Hosting SRL                        -99.99 RON  amount and supplier",
+		"Unmatched credits (1)",
+		"no open invoice of this amount",
+		"Still unpaid: 0 invoice(s), 0.00 RON",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(errOut, "1 invoice(s) were paid but are still open in SOLO") {
+		t.Errorf("stderr %q", errOut)
+	}
+
+	out, _, code = e.run(t, api, "bank", "import", path, "--json")
+	var rec struct {
+		Statement struct{ Format string } `json:"statement"`
+		Invoices  []struct {
+			MatchedBy string `json:"matched_by"`
+		} `json:"paid_invoices"`
+	}
+	if code != 0 || json.Unmarshal([]byte(out), &rec) != nil || rec.Statement.Format != "BCR CSV" || len(rec.Invoices) != 1 {
+		t.Errorf("json output (%d):\n%s", code, out)
+	}
+
+	_, errOut, code = e.run(t, api, "bank", "import", path, "--format", "qif")
+	if code != 1 || !strings.Contains(errOut, "invalid statement format 'qif'") {
+		t.Errorf("bad format: code %d, stderr %q", code, errOut)
+	}
+}
@@ -0,0 +1,241 @@
+// Package journal converts invoices and expenses into plain-text accounting
+// journals for ledger, hledger and beancount. Transactions carry the SOLO
+// UniqueCode as their id and are written in a fixed order, so exporting the
+// same data again gives the same file
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/diacritics"
+	"solo-cli/money"
+)
+
+// Format is a plain-text accounting syntax
+type Format string
+
+const (
+	// Ledger is also read by hledger
+	Ledger    Format = "ledger"
+	Beancount Format = "beancount"
+)
+
+// ParseFormat accepts ledger, hledger and beancount
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "ledger", "hledger", "journal":
+		return Ledger, nil
+	case "beancount", "bean":
+		return Beancount, nil
+	}
+	return "", fmt.Errorf("invalid format '%s' (want ledger, hledger or beancount)", s)
+}
+
+// Price converts a foreign currency posting to RON: a unit Rate when the
+// API has the exchange rate, otherwise the Total RON value
+type Price struct {
+	Rate  float64
+	Total money.Money
+}
+
+// Posting is one leg of a transaction. An Elided posting has no amount and
+// balances the transaction, so a rate priced leg needs no rounding
+type Posting struct {
+	Account  string
+	Amount   money.Money
+	Currency string
+	Price    *Price
+	Elided   bool
+}
+
+// Meta is a key: value line under the transaction header
+type Meta struct {
+	Key, Value string
+}
+
+// Transaction is one journal entry
+type Transaction struct {
+	Date      string // YYYY-MM-DD
+	ID        string // stable across exports
+	Code      string // invoice number, may be empty
+	Payee     string
+	Narration string
+	Meta      []Meta
+	Postings  []Posting
+}
+
+// RevenueValuer and ExpenseValuer value an item in RON when it has no local
+// amount, failing when no rate is known
+type (
+	RevenueValuer func(client.Revenue) (money.Money, error)
+	ExpenseValuer func(client.Expense) (money.Money, error)
+)
+
+// fallbackID derives a stable id from the fields that identify an item
+// the API gave no UniqueCode
+func fallbackID(prefix string, fields ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return prefix + hex.EncodeToString(sum[:6])
+}
+
+// Build turns the invoices issued and the expenses bought in year into
+// transactions, oldest first. A paid invoice with a payment date also gets
+// its payment, even when paid the year after; cancelled invoices are left
+// out. revenueRON and expenseRON value the foreign currency items that
+// have no local amount
+func Build(revenues []client.Revenue, expenses []client.Expense, year int, accounts *config.AccountsConfig,
+	revenueRON RevenueValuer, expenseRON ExpenseValuer) []Transaction {
+	prefix := fmt.Sprintf("%04d-", year)
+	var txns []Transaction
+
+	for _, r := range revenues {
+		date := client.Day(r.IssueDate)
+		if !strings.HasPrefix(date, prefix) || (r.Status != nil && r.Status.IsCancelled) {
+			continue
+		}
+		id := r.UniqueCode
+		if id == "" {
+			id = fallbackID("inv-", r.SerialCode, date, r.ClientName, r.Total.String())
+		}
+		payee := strings.TrimSpace(r.ClientName)
+		cur := r.Currency.ISOCode()
+
+		receivable := Posting{Account: accounts.Receivable, Amount: r.Total, Currency: cur}
+		income := Posting{Account: accounts.Income, Amount: -r.Total, Currency: cur}
+		if cur != "RON" {
+			switch {
+			case r.InvoiceLocalAmount != nil && r.InvoiceLocalAmount.ExchangeRate != nil && *r.InvoiceLocalAmount.ExchangeRate > 0:
+				receivable.Price = &Price{Rate: *r.InvoiceLocalAmount.ExchangeRate}
+				income = Posting{Account: accounts.Income, Elided: true}
+			case r.InvoiceLocalAmount != nil:
+				receivable.Price = &Price{Total: r.InvoiceLocalAmount.Total}
+				income = Posting{Account: accounts.Income, Amount: -r.InvoiceLocalAmount.Total, Currency: "RON"}
+			default:
+				if value, err := revenueRON(r); err == nil {
+					receivable.Price = &Price{Total: value}
+					income = Posting{Account: accounts.Income, Amount: -value, Currency: "RON"}
+				}
+			}
+		}
+		txns = append(txns, Transaction{
+			Date:      date,
+			ID:        id,
+			Code:      r.SerialCode,
+			Payee:     payee,
+			Narration: strings.TrimSpace("Invoice " + r.SerialCode),
+			Postings:  []Posting{receivable, income},
+		})
+
+		if paid := client.Day(r.PaymentDate); r.IsPaid && paid != "" {
+			txns = append(txns, Transaction{
+				Date:      paid,
+				ID:        id + "-payment",
+				Code:      r.SerialCode,
+				Payee:     payee,
+				Narration: strings.TrimSpace("Payment " + r.SerialCode),
+				Postings: []Posting{
+					{Account: accounts.Bank, Amount: r.Total, Currency: cur},
+					{Account: accounts.Receivable, Amount: -r.Total, Currency: cur},
+				},
+			})
+		}
+	}
+
+	for _, e := range expenses {
+		date := client.Day(e.PurchaseDate)
+		if !strings.HasPrefix(date, prefix) {
+			continue
+		}
+		id := e.UniqueCode
+		if id == "" {
+			id = fallbackID("exp-", e.SupplierName, date, e.Total.String())
+		}
+		cur := e.Currency.ISOCode()
+
+		expense := Posting{Account: ExpenseAccount(accounts, e), Amount: e.Total, Currency: cur}
+		bank := Posting{Account: accounts.Bank, Amount: -e.Total, Currency: cur}
+		if cur != "RON" {
+			value, err := expenseRON(e)
+			if e.ExpenseLocalAmount != nil {
+				value, err = e.ExpenseLocalAmount.Total, nil
+			}
+			if err == nil {
+				expense.Price = &Price{Total: value}
+				bank = Posting{Account: accounts.Bank, Amount: -value, Currency: "RON"}
+			}
+		}
+		t := Transaction{
+			Date:      date,
+			ID:        id,
+			Payee:     strings.TrimSpace(e.SupplierName),
+			Narration: strings.TrimSpace(e.Category),
+			Postings:  []Posting{expense, bank},
+		}
+		if pct := e.DeductiblePercent(); pct < 100 {
+			t.Meta = append(t.Meta, Meta{"deductible", fmt.Sprintf("%g%%", pct)})
+		}
+		txns = append(txns, t)
+	}
+
+	sort.SliceStable(txns, func(i, j int) bool {
+		if txns[i].Date != txns[j].Date {
+			return txns[i].Date < txns[j].Date
+		}
+		return txns[i].ID < txns[j].ID
+	})
+	return txns
+}
+
+var nonAccount = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// accountName turns a category into an account name component all three
+// tools accept: ASCII words, each capitalized, joined by dashes
+func accountName(s string) string {
+	var words []string
+	for _, w := range nonAccount.Split(diacritics.Strip(s), -1) {
+		if w != "" {
+			words = append(words, strings.ToUpper(w[:1])+w[1:])
+		}
+	}
+	if len(words) == 0 {
+		return "Uncategorized"
+	}
+	return strings.Join(words, "-")
+}
+
+// ExpenseAccount is the account of an expense: the mapping of its category
+// or primary category, else the configured default expense account, else
+// Expenses: followed by the category
+func ExpenseAccount(accounts *config.AccountsConfig, e client.Expense) string {
+	for _, name := range []string{e.Category, e.PrimaryCategory} {
+		if diacritics.Fold(name) == "" {
+			continue
+		}
+		// Sorted so that keys differing only in case always map the same way
+		keys := make([]string, 0, len(accounts.Categories))
+		for key := range accounts.Categories {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if diacritics.Fold(key) == diacritics.Fold(name) {
+				return accounts.Categories[key]
+			}
+		}
+	}
+	if accounts.Expense != "" {
+		return accounts.Expense
+	}
+	name := e.Category
+	if strings.TrimSpace(name) == "" {
+		name = e.PrimaryCategory
+	}
+	return "Expenses:" + accountName(name)
+}
@@ -0,0 +1,167 @@
+package journal
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/money"
+)
+
+func sampleData() ([]client.Revenue, []client.Expense) {
+	rate := 4.9751
+	eur := client.Currency{ShortName: "EUR"}
+	revenues := []client.Revenue{
+		{UniqueCode: "r2", SerialCode: "INV-002", ClientName: "Globex \"EU\"", IssueDate: "2026-02-10", Total: money.MustParse("250.25"), Currency: eur,
+			InvoiceLocalAmount: &client.LocalAmount{Total: money.MustParse("1245.00"), ExchangeRate: &rate}},
+		{UniqueCode: "r1", SerialCode: "INV-001", ClientName: "ACME Corp", IssueDate: "2026-01-15T00:00:00", PaymentDate: "2026-01-20", IsPaid: true,
+			Total: money.MustParse("1000.50"), Currency: client.Currency{ShortName: "RON"}},
+		{SerialCode: "INV-003", ClientName: "Initech", IssueDate: "2026-03-01", Total: 100 * money.Lei, Currency: eur},
+		{UniqueCode: "r4", SerialCode: "INV-004", ClientName: "ACME Corp", IssueDate: "2026-03-02", Total: 999 * money.Lei, Status: &client.InvoiceStatus{IsCancelled: true}},
+		{UniqueCode: "r0", SerialCode: "INV-000", ClientName: "ACME Corp", IssueDate: "2025-12-30", Total: 10 * money.Lei},
+	}
+	expenses := []client.Expense{
+		{UniqueCode: "e1", SupplierName: "Hosting SRL", PurchaseDate: "2026-03-05T00:00:00", Category: "Servicii", Total: money.MustParse("99.99")},
+		{UniqueCode: "e2", SupplierName: "Petrom", PurchaseDate: "2026-03-05", Category: "Cheltuieli auto - Nedeductibilă", Total: 300 * money.Lei, Deductibility: "0%"},
+		{UniqueCode: "e3", SupplierName: "GitHub", PurchaseDate: "2026-04-01", Category: "Software", Total: 21 * money.Lei, Currency: client.Currency{ShortName: "USD"},
+			ExpenseLocalAmount: &client.ExpenseLocalAmount{Total: money.MustParse("96.60")}},
+	}
+	return revenues, expenses
+}
+
+func noRate(client.Revenue) (money.Money, error) { return 0, errors.New("no rate") }
+
+func noExpenseRate(client.Expense) (money.Money, error) { return 0, errors.New("no rate") }
+
+func build(t *testing.T) []Transaction {
+	t.Helper()
+	revenues, expenses := sampleData()
+	accounts := config.DefaultAccountsConfig()
+	accounts.Categories["CHELTUIELI AUTO - NEDEDUCTIBILA"] = "Expenses:Car:Personal"
+	return Build(revenues, expenses, 2026, accounts, noRate, noExpenseRate)
+}
+
+func TestBuild(t *testing.T) {
+	txns := build(t)
+
+	var ids []string
+	for _, tx := range txns {
+		ids = append(ids, tx.Date+" "+tx.ID)
+	}
+	// The cancelled invoice and last year's are left out, the paid one
+	// gets its payment
+	want := "2026-01-15 r1,2026-01-20 r1-payment,2026-02-10 r2,2026-03-01 inv-"
+	if got := strings.Join(ids, ","); !strings.HasPrefix(got, want) {
+		t.Fatalf("transactions = %s", got)
+	}
+	if len(txns) != 7 {
+		t.Fatalf("got %d transactions, want 7", len(txns))
+	}
+
+	// Priced at the API rate, the income leg is left for the tool to balance
+	inv2 := txns[2]
+	if p := inv2.Postings[0]; p.Price == nil || p.Price.Rate != 4.9751 || !inv2.Postings[1].Elided {
+		t.Errorf("INV-002 postings = %+v", inv2.Postings)
+	}
+	// No local amount and no BNR rate: booked in EUR
+	if p := txns[3].Postings; p[0].Price != nil || p[1].Currency != "EUR" || p[1].Amount != -100*money.Lei {
+		t.Errorf("INV-003 postings = %+v", p)
+	}
+
+	// Categories are matched ignoring case and diacritics
+	for _, tx := range txns {
+		switch tx.ID {
+		case "e2":
+			if tx.Postings[0].Account != "Expenses:Car:Personal" || len(tx.Meta) != 1 || tx.Meta[0].Value != "0%" {
+				t.Errorf("Petrom = %+v", tx)
+			}
+		case "e3":
+			if p := tx.Postings; p[0].Price == nil || p[0].Price.Total != money.MustParse("96.60") || p[1].Amount != money.MustParse("-96.60") {
+				t.Errorf("GitHub postings = %+v", p)
+			}
+		}
+	}
+}
+
+func TestExpenseAccount(t *testing.T) {
+	accounts := config.DefaultAccountsConfig()
+	tests := []struct {
+		category, primary, want string
+	}{
+		{"servicii", "", "Expenses:Services"},
+		{"Telecomunicații", "", "Expenses:Telecom"},
+		{"Unknown", "Software", "Expenses:Software"},
+		{"Cheltuieli protocol - Nedeductibilă", "", "Expenses:Cheltuieli-Protocol-Nedeductibila"},
+		{"Software & Subscriptions!", "", "Expenses:Software-Subscriptions"},
+		{"", "", "Expenses:Uncategorized"},
+	}
+	for _, tt := range tests {
+		if got := ExpenseAccount(accounts, client.Expense{Category: tt.category, PrimaryCategory: tt.primary}); got != tt.want {
+			t.Errorf("ExpenseAccount(%q, %q) = %q, want %q", tt.category, tt.primary, got, tt.want)
+		}
+	}
+
+	accounts.Expense = "Expenses:Other"
+	if got := ExpenseAccount(accounts, client.Expense{Category: "Unknown"}); got != "Expenses:Other" {
+		t.Errorf("default expense account = %q", got)
+	}
+}
+
+func TestWriteLedger(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, build(t), 2026, Ledger); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"2026-01-15 * (INV-001) ACME Corp  ; Invoice INV-001\n    ; id: r1\n" +
+			"    Assets:Receivables                   1000.50 RON\n" +
+			"    Income:Services                     -1000.50 RON\n",
+		"    Assets:Receivables                    250.25 EUR @ 4.9751 RON\n    Income:Services\n",
+		"    Expenses:Software                      21.00 USD @@ 96.60 RON\n",
+		"    ; deductible: 0%\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("ledger missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteBeancount(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, build(t), 2026, Beancount); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"2026-01-15 open Assets:Bank\n",
+		"2026-01-15 open Expenses:Car:Personal\n",
+		"2026-02-10 * \"Globex \\\"EU\\\"\" \"Invoice INV-002\"\n  id: \"r2\"\n  code: \"INV-002\"\n",
+		"  Income:Services                     -1000.50 RON\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("beancount missing %q\n%s", want, out)
+		}
+	}
+
+	// Exporting again gives the same file
+	var again bytes.Buffer
+	Write(&again, build(t), 2026, Beancount)
+	if again.String() != out {
+		t.Error("re-export differs")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"ledger": Ledger, "HLedger": Ledger, "beancount": Beancount} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseFormat("gnucash"); err == nil {
+		t.Error("gnucash accepted")
+	}
+}
@@ -0,0 +1,130 @@
+package journal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Write renders the transactions of year in the given format. Nothing in
+// the output depends on when it is written, so re-exports diff cleanly
+func Write(w io.Writer, txns []Transaction, year int, f Format) error {
+	bw := bufio.NewWriter(w)
+	width := accountWidth(txns)
+
+	switch f {
+	case Beancount:
+		fmt.Fprintf(bw, ";; SOLO %d exported by solo-cli. Transactions carry the SOLO id as metadata\n", year)
+		writeOpens(bw, txns)
+		for _, t := range txns {
+			bw.WriteString("\n")
+			writeBeancount(bw, t, width)
+		}
+	default:
+		fmt.Fprintf(bw, "; SOLO %d exported by solo-cli. Transactions carry the SOLO id as a tag\n", year)
+		for _, t := range txns {
+			bw.WriteString("\n")
+			writeLedger(bw, t, width)
+		}
+	}
+	return bw.Flush()
+}
+
+// accountWidth lines the amounts up after the longest account
+func accountWidth(txns []Transaction) int {
+	width := 30
+	for _, t := range txns {
+		for _, p := range t.Postings {
+			width = max(width, len(p.Account))
+		}
+	}
+	return width
+}
+
+// amount renders a posting amount with its price annotation
+func (p Posting) amount() string {
+	s := fmt.Sprintf("%13s %s", p.Amount, p.Currency)
+	switch {
+	case p.Price == nil:
+	case p.Price.Rate > 0:
+		s += " @ " + strconv.FormatFloat(p.Price.Rate, 'f', -1, 64) + " RON"
+	default:
+		s += " @@ " + p.Price.Total.String() + " RON"
+	}
+	return s
+}
+
+func writePostings(w *bufio.Writer, postings []Posting, indent string, width int) {
+	for _, p := range postings {
+		if p.Elided {
+			fmt.Fprintf(w, "%s%s\n", indent, p.Account)
+			continue
+		}
+		fmt.Fprintf(w, "%s%-*s %s\n", indent, width, p.Account, p.amount())
+	}
+}
+
+// oneLine keeps API text from breaking the journal syntax
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func writeLedger(w *bufio.Writer, t Transaction, width int) {
+	header := t.Date + " *"
+	if t.Code != "" {
+		header += " (" + oneLine(t.Code) + ")"
+	}
+	header += " " + oneLine(t.Payee)
+	if t.Narration != "" {
+		header += "  ; " + oneLine(t.Narration)
+	}
+	w.WriteString(header + "\n")
+	fmt.Fprintf(w, "    ; id: %s\n", t.ID)
+	for _, m := range t.Meta {
+		fmt.Fprintf(w, "    ; %s: %s\n", m.Key, oneLine(m.Value))
+	}
+	writePostings(w, t.Postings, "    ", width)
+}
+
+// quote renders a beancount string
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(oneLine(s), `\`, `\\`), `"`, `\"`) + `"`
+}
+
+func writeBeancount(w *bufio.Writer, t Transaction, width int) {
+	fmt.Fprintf(w, "%s * %s %s\n", t.Date, quote(t.Payee), quote(t.Narration))
+	fmt.Fprintf(w, "  id: %s\n", quote(t.ID))
+	if t.Code != "" {
+		fmt.Fprintf(w, "  code: %s\n", quote(t.Code))
+	}
+	for _, m := range t.Meta {
+		fmt.Fprintf(w, "  %s: %s\n", m.Key, quote(m.Value))
+	}
+	writePostings(w, t.Postings, "  ", width)
+}
+
+// writeOpens opens every account used on the first transaction's date, as
+// beancount requires
+func writeOpens(w *bufio.Writer, txns []Transaction) {
+	if len(txns) == 0 {
+		return
+	}
+	seen := map[string]bool{}
+	var accounts []string
+	for _, t := range txns {
+		for _, p := range t.Postings {
+			if !seen[p.Account] {
+				seen[p.Account] = true
+				accounts = append(accounts, p.Account)
+			}
+		}
+	}
+	sort.Strings(accounts)
+	w.WriteString("\n")
+	for _, a := range accounts {
+		fmt.Fprintf(w, "%s open %s\n", txns[0].Date, a)
+	}
+}
@@ -29,8 +29,12 @@ func main() {
 		}
 	}
 
+	args = parseCurrencyFlag(args)
+	args = parseTemplateFlags(args)
+
 	// Handle no args or help
 	if len(args) < 1 {
+		templateUnsupported("the TUI")
 		maybePromptSkillInstall()
 		runTUI()
 		return
@@ -38,6 +42,9 @@ func main() {
 
 	cmd := args[0]
 	cmdArgs := args[1:] // Additional arguments for commands
+	if !templateCommands[cmd] {
+		templateUnsupported("'" + cmd + "'")
+	}
 
 	switch cmd {
 	case "help", "--help", "-h":
@@ -57,9 +64,44 @@ func main() {
 	case "company":
 		withClient(runCompany)
 	case "taxes", "tax":
+		// Editing the tax rules needs no login
+		if len(cmdArgs) > 0 && cmdArgs[0] == "config" {
+			templateUnsupported("'taxes config'")
+			runTaxesConfig(cmdArgs[1:])
+			return
+		}
 		withClientArgs(runTaxes, cmdArgs)
 	case "upload", "up":
 		withClientArgs(runUpload, cmdArgs)
+	case "calendar", "cal":
+		withClientArgs(runCalendar, cmdArgs)
+	case "rates", "fx":
+		runRates(cmdArgs)
+	case "receivables", "ar":
+		withClient(runReceivables)
+	case "statement", "stmt":
+		withClientArgs(runStatement, cmdArgs)
+	case "report":
+		withClientArgs(runReport, cmdArgs)
+	case "mcp":
+		runMCP(cmdArgs)
+	case "serve":
+		runServe(cmdArgs)
+	case "exporter":
+		runExporter(cmdArgs)
+	case "notify":
+		runNotify(cmdArgs)
+	case "daemon":
+		runDaemon(cmdArgs)
+	case "export":
+		// Checking an archive needs no login
+		if len(cmdArgs) > 0 && cmdArgs[0] == "verify" {
+			runExportVerify(cmdArgs[1:])
+			return
+		}
+		withClientArgs(runExport, cmdArgs)
+	case "bank":
+		withClientArgs(runBank, cmdArgs)
 	case "setup-skills":
 		runSetupSkills()
 	case "tui":
@@ -81,20 +123,72 @@ Usage:
   solo-cli [options] [command] [args]
 
 Commands:
-  summary [year]  Show account summary (year, revenues, expenses, taxes)
-  taxes [year]    Show tax breakdown with thresholds (alias: tax)
+  summary [year]  Show account summary (year, revenues, expenses, taxes).
+                  --years 2022-2026|2022,2024|5 compares several years,
+                  --vat adds the turnover against the VAT threshold
+  taxes [year]    Show tax breakdown with thresholds (alias: tax).
+                  --income category=amount adds non-PFA income (repeatable).
+                  Subcommands: optimize [year], compare [year],
+                  config [show|validate|reset|set <key> <value>]
   revenues        List revenue invoices (aliases: revenue, rev)
   expenses        List expenses (aliases: expense, exp)
   queue           List expense queue (alias: q). Subcommands: delete <id>
   efactura        List e-Factura documents (aliases: einvoice, ei)
   company         Show company profile
   upload <file>   Upload expense document (alias: up)
+  calendar        List upcoming fiscal deadlines with amounts due (alias: cal).
+                  Subcommands: ics [file]
+  rates           Show BNR exchange rates (alias: fx). Subcommands:
+                  show [YYYY-MM-DD], import <nbrfxrates.xml>...
+  receivables     Unpaid invoices by client and age, with average days to pay
+                  (alias: ar)
+  statement <client>
+                  Account statement of a client (alias: stmt). Options:
+                  --format txt|md|html, --output file, --terms days,
+                  --reminder [file.eml] (email draft for overdue invoices),
+                  --to address
+  report          Reports. Subcommands: currency [year] (currency exposure),
+                  pnl [--year Y] [--by month|quarter] (profit and loss),
+                  expenses [--year Y] [--group-by category|supplier|
+                  deductibility] [--top N] (expense breakdown vs last year),
+                  clients [--year Y] [--top N] (revenue per client and
+                  concentration), annual [--year Y] --html file --pdf file
+                  (yearly report with charts for a partner or bank).
+                  --format table|csv|json
+  bank import <statement>
+                  Match a bank statement (BT, ING, BCR CSV, MT940, CAMT.053)
+                  to unpaid invoices and expenses. --format csv|mt940|camt053,
+                  --bank bt|ing|bcr, --json
+  export <format> Journal of invoices, payments and expenses for ledger,
+                  hledger or beancount. --year Y, --output file.
+                  audit: ZIP of the year's data and documents with SHA-256
+                  checksums (--no-documents); verify <archive.zip>
+  mcp             Model Context Protocol server on stdio giving AI agents
+                  typed tools for the SOLO.ro data
+  serve           Local REST/JSON API with one shared session. --listen
+                  addr (127.0.0.1:8787), --token T, --cache-ttl 5m;
+                  described at /openapi.json
+  exporter        Prometheus metrics on /metrics, polled from SOLO.ro.
+                  --listen addr (127.0.0.1:9787), --interval 5m
+  notify          Notify on rejected documents, overdue queue items and
+                  paid invoices (webhook, command, email, desktop) by the
+                  rules in config.json. --once, --dry-run; test [notifier]
+  daemon          Run the jobs of config.json on cron schedules (sync,
+                  upload folder, notify, report, export). --log json|text,
+                  --log-file F; status [--json]; run <job>
   setup-skills    Install AI skills for Claude Code and other agents
   tui             Start interactive TUI (default when no command)
   demo            Start TUI with demo data (for screenshots)
 
 Options:
   --config, -c    Path to custom config file
+  --currency CUR  Convert listed amounts to CUR (e.g. RON, EUR) at the BNR
+                  rate of their date (revenues, expenses, report)
+  --template T    Print each item through the Go text/template T, e.g.
+                  '{{.SerialCode}} {{money .Total}}' (revenues, expenses,
+                  queue, efactura, summary, taxes, company)
+  --template-file F
+                  Read the template from file F
   help, -h        Show this help message
   version, -v     Show version
 
@@ -105,8 +199,23 @@ Examples:
   solo-cli                          # Start TUI
   solo-cli summary                  # Show current year summary
   solo-cli summary 2025             # Show 2025 summary
+  solo-cli summary --vat            # Add the VAT threshold tracker
+  solo-cli summary --years 2022-2026 # Compare years with growth
   solo-cli upload invoice.pdf       # Upload expense document
   solo-cli queue delete 123         # Delete queued item
+  solo-cli calendar ics termene.ics # Export deadlines to iCalendar
+  solo-cli rates import nbrfxrates2026.xml
+  solo-cli export beancount --year 2025 -o solo.beancount
+  solo-cli export audit --year 2025 # Writes solo-audit-2025.zip
+  solo-cli report annual --year 2025 --pdf raport-2025.pdf
+  solo-cli bank import extras.mt940
+  solo-cli --currency EUR revenues  # Invoices valued in EUR
+  solo-cli revenues --template '{{.SerialCode}} {{ron .Total}}'
+  solo-cli serve --listen 127.0.0.1:8787
+  solo-cli exporter --interval 10m
+  solo-cli notify --once            # Send new events, e.g. from cron
+  solo-cli daemon status            # Last and next runs of the jobs
+  solo-cli statement acme -o acme.html --reminder
   solo-cli -c ~/my-config.json rev  # Use custom config
   solo-cli expenses | grep -i "food"
 
@@ -0,0 +1,313 @@
+// Package mcp serves tools to AI agents over the Model Context Protocol: JSON-RPC
+// 2.0 messages, one per line, on stdin and stdout. Only the tools part of
+// the protocol is implemented, which is all solo-cli offers
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+)
+
+// LatestVersion is the newest protocol revision the server speaks
+const LatestVersion = "2025-06-18"
+
+// supportedVersions are the revisions a client may ask for, newest first
+var supportedVersions = []string{LatestVersion, "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// maxMessage bounds one incoming line
+const maxMessage = 10 << 20
+
+// Property is one argument of a tool in its JSON schema
+type Property struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+}
+
+// Schema is the JSON schema of a tool's arguments, always an object
+type Schema struct {
+	Type       string              `json:"type"`
+	Properties map[string]Property `json:"properties"`
+	Required   []string            `json:"required,omitempty"`
+}
+
+// Annotations tell the client how careful to be with a tool
+type Annotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint"`
+	DestructiveHint bool   `json:"destructiveHint"`
+	IdempotentHint  bool   `json:"idempotentHint"`
+	OpenWorldHint   bool   `json:"openWorldHint"`
+}
+
+// Handler runs a tool with its raw arguments. The result must marshal to a
+// JSON object; an error is reported to the agent as a failed tool call
+type Handler func(args json.RawMessage) (any, error)
+
+// Tool is one operation offered to agents
+type Tool struct {
+	Name        string
+	Description string
+	Properties  map[string]Property
+	Required    []string
+	Annotations Annotations
+
+	// Confirm makes the tool refuse to run unless called with
+	// "confirm": true, which agents should only send after asking the user
+	Confirm bool
+
+	Handler Handler
+}
+
+func (t *Tool) schema() Schema {
+	s := Schema{Type: "object", Properties: map[string]Property{}, Required: t.Required}
+	for name, p := range t.Properties {
+		s.Properties[name] = p
+	}
+	if t.Confirm {
+		s.Properties["confirm"] = Property{Type: "boolean", Description: "Must be true. Ask the user to approve this exact call first"}
+		s.Required = append(slices.Clone(s.Required), "confirm")
+	}
+	return s
+}
+
+// Server dispatches requests to the registered tools
+type Server struct {
+	Name         string
+	Version      string
+	Instructions string
+	tools        []*Tool
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewServer creates a server that introduces itself as name and version
+func NewServer(name, version string) *Server {
+	return &Server{Name: name, Version: version}
+}
+
+// Add registers a tool; names must be unique
+func (s *Server) Add(t *Tool) {
+	if s.tool(t.Name) != nil {
+		panic("mcp: duplicate tool " + t.Name)
+	}
+	s.tools = append(s.tools, t)
+}
+
+func (s *Server) tool(name string) *Tool {
+	for _, t := range s.tools {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// Serve answers the messages read from r on w until r ends. Requests are
+// handled in order; notifications get no answer
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessage)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := s.handle(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) handle(line []byte) error {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		if line[0] == '[' {
+			return s.send(response{ID: json.RawMessage("null"), Error: &rpcError{codeInvalidRequest, "batches are not supported"}})
+		}
+		return s.send(response{ID: json.RawMessage("null"), Error: &rpcError{codeParseError, err.Error()}})
+	}
+	// Responses from the client and notifications need no answer
+	if req.Method == "" || len(req.ID) == 0 {
+		return nil
+	}
+	if req.JSONRPC != "2.0" {
+		return s.send(response{ID: req.ID, Error: &rpcError{codeInvalidRequest, `jsonrpc must be "2.0"`}})
+	}
+
+	var result any
+	var rerr *rpcError
+	switch req.Method {
+	case "initialize":
+		result, rerr = s.initialize(req.Params)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = s.list()
+	case "tools/call":
+		result, rerr = s.call(req.Params)
+	default:
+		rerr = &rpcError{codeMethodNotFound, "method not found: " + req.Method}
+	}
+	return s.send(response{ID: req.ID, Result: result, Error: rerr})
+}
+
+func (s *Server) send(resp response) error {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.out.Write(append(data, '\n'))
+	return err
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, *rpcError) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{codeInvalidParams, err.Error()}
+		}
+	}
+	// Answer with the client's revision when we know it, otherwise with
+	// ours and let the client decide
+	version := LatestVersion
+	if slices.Contains(supportedVersions, p.ProtocolVersion) {
+		version = p.ProtocolVersion
+	}
+	type info struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	return struct {
+		ProtocolVersion string         `json:"protocolVersion"`
+		Capabilities    map[string]any `json:"capabilities"`
+		ServerInfo      info           `json:"serverInfo"`
+		Instructions    string         `json:"instructions,omitempty"`
+	}{version, map[string]any{"tools": map[string]bool{"listChanged": false}}, info{s.Name, s.Version}, s.Instructions}, nil
+}
+
+type toolInfo struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema Schema      `json:"inputSchema"`
+	Annotations Annotations `json:"annotations"`
+}
+
+func (s *Server) list() any {
+	tools := []toolInfo{}
+	for _, t := range s.tools {
+		tools = append(tools, toolInfo{t.Name, t.Description, t.schema(), t.Annotations})
+	}
+	return struct {
+		Tools []toolInfo `json:"tools"`
+	}{tools}
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// CallResult is the answer to tools/call. Structured results are repeated
+// as JSON text for clients that only read the text
+type CallResult struct {
+	Content           []content `json:"content"`
+	StructuredContent any       `json:"structuredContent,omitempty"`
+	IsError           bool      `json:"isError"`
+}
+
+func errorResult(format string, args ...any) *CallResult {
+	return &CallResult{Content: []content{{"text", fmt.Sprintf(format, args...)}}, IsError: true}
+}
+
+func (s *Server) call(params json.RawMessage) (any, *rpcError) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{codeInvalidParams, err.Error()}
+	}
+	t := s.tool(p.Name)
+	if t == nil {
+		return nil, &rpcError{codeInvalidParams, "unknown tool: " + p.Name}
+	}
+	args := p.Arguments
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	if t.Confirm {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(args, &fields); err != nil {
+			return errorResult("%s: invalid arguments: %v", t.Name, err), nil
+		}
+		if string(fields["confirm"]) != "true" {
+			return errorResult("%s needs confirmation: describe the call to the user, and only after they approve it call again with \"confirm\": true", t.Name), nil
+		}
+		// The handler only sees its own arguments
+		delete(fields, "confirm")
+		args, _ = json.Marshal(fields)
+	}
+
+	value, err := t.Handler(args)
+	if err != nil {
+		return errorResult("%s failed: %v", t.Name, err), nil
+	}
+	text, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return errorResult("%s failed: %v", t.Name, err), nil
+	}
+	return &CallResult{Content: []content{{"text", string(text)}}, StructuredContent: value}, nil
+}
+
+// Args decodes a tool's arguments into v, rejecting unknown fields so a
+// misspelled argument is not silently ignored
+func Args(raw json.RawMessage, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testServer(deleted *[]int) *Server {
+	s := NewServer("test", "1.0")
+	s.Add(&Tool{
+		Name:        "echo",
+		Description: "Echoes its argument",
+		Properties:  map[string]Property{"text": {Type: "string"}},
+		Required:    []string{"text"},
+		Annotations: Annotations{ReadOnlyHint: true},
+		Handler: func(raw json.RawMessage) (any, error) {
+			var args struct {
+				Text string `json:"text"`
+			}
+			if err := Args(raw, &args); err != nil {
+				return nil, err
+			}
+			if args.Text == "fail" {
+				return nil, errors.New("boom")
+			}
+			return map[string]string{"text": args.Text}, nil
+		},
+	})
+	s.Add(&Tool{
+		Name:        "delete",
+		Properties:  map[string]Property{"id": {Type: "integer"}},
+		Required:    []string{"id"},
+		Annotations: Annotations{DestructiveHint: true},
+		Confirm:     true,
+		Handler: func(raw json.RawMessage) (any, error) {
+			var args struct {
+				ID int `json:"id"`
+			}
+			if err := Args(raw, &args); err != nil {
+				return nil, err
+			}
+			*deleted = append(*deleted, args.ID)
+			return map[string]int{"deleted": args.ID}, nil
+		},
+	})
+	return s
+}
+
+// exchange sends the lines to a server and decodes its answers by id
+func exchange(t *testing.T, s *Server, lines ...string) map[string]map[string]any {
+	t.Helper()
+	var out strings.Builder
+	if err := s.Serve(strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	answers := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var msg map[string]any
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("invalid answer %q: %v", line, err)
+		}
+		if msg["jsonrpc"] != "2.0" {
+			t.Errorf("answer without jsonrpc 2.0: %s", line)
+		}
+		id, _ := json.Marshal(msg["id"])
+		answers[string(id)] = msg
+	}
+	return answers
+}
+
+func result(t *testing.T, msg map[string]any) map[string]any {
+	t.Helper()
+	r, ok := msg["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("no result in %v", msg)
+	}
+	return r
+}
+
+func TestInitializeAndList(t *testing.T) {
+	var deleted []int
+	got := exchange(t, testServer(&deleted),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"x","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+		`{"jsonrpc":"2.0","id":"list","method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+	)
+	if len(got) != 4 {
+		t.Fatalf("want 4 answers (none to the notification), got %v", got)
+	}
+
+	init := result(t, got["1"])
+	if init["protocolVersion"] != "2025-03-26" || init["serverInfo"].(map[string]any)["name"] != "test" {
+		t.Errorf("initialize = %v", init)
+	}
+	if v := result(t, got["2"])["protocolVersion"]; v != LatestVersion {
+		t.Errorf("unknown revision answered with %v, want %s", v, LatestVersion)
+	}
+
+	tools := result(t, got[`"list"`])["tools"].([]any)
+	if len(tools) != 2 {
+		t.Fatalf("tools = %v", tools)
+	}
+	del := tools[1].(map[string]any)
+	schema := del["inputSchema"].(map[string]any)
+	if _, ok := schema["properties"].(map[string]any)["confirm"]; !ok {
+		t.Errorf("confirm missing from %v", schema)
+	}
+	if req := schema["required"].([]any); len(req) != 2 || req[1] != "confirm" {
+		t.Errorf("required = %v", req)
+	}
+	if del["annotations"].(map[string]any)["destructiveHint"] != true {
+		t.Errorf("annotations = %v", del["annotations"])
+	}
+	// The echo tool's own schema is left alone
+	if req := tools[0].(map[string]any)["inputSchema"].(map[string]any)["required"].([]any); len(req) != 1 {
+		t.Errorf("echo required = %v", req)
+	}
+}
+
+func TestCall(t *testing.T) {
+	var deleted []int
+	got := exchange(t, testServer(&deleted),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"fail"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"txt":"typo"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"nope"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"delete","arguments":{"id":7}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"delete","arguments":{"id":7,"confirm":false}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"delete","arguments":{"id":7,"confirm":true}}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"resources/list"}`,
+		`{not json`,
+	)
+
+	ok := result(t, got["1"])
+	if ok["isError"] != false || ok["structuredContent"].(map[string]any)["text"] != "hi" ||
+		!strings.Contains(ok["content"].([]any)[0].(map[string]any)["text"].(string), `"text": "hi"`) {
+		t.Errorf("echo = %v", ok)
+	}
+	for id, want := range map[string]string{"2": "boom", "3": "unknown field", "5": "needs confirmation", "6": "needs confirmation"} {
+		r := result(t, got[id])
+		if r["isError"] != true || !strings.Contains(r["content"].([]any)[0].(map[string]any)["text"].(string), want) {
+			t.Errorf("call %s = %v, want an error with %q", id, r, want)
+		}
+	}
+	if r := result(t, got["7"]); r["isError"] != false || len(deleted) != 1 || deleted[0] != 7 {
+		t.Errorf("confirmed delete = %v, deleted %v", r, deleted)
+	}
+
+	for id, code := range map[string]float64{"4": codeInvalidParams, "8": codeMethodNotFound, "null": codeParseError} {
+		e, ok := got[id]["error"].(map[string]any)
+		if !ok || e["code"] != code {
+			t.Errorf("answer %s = %v, want error %v", id, got[id], code)
+		}
+	}
+}
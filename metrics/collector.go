@@ -0,0 +1,283 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/money"
+	"solo-cli/report"
+	"solo-cli/taxes"
+)
+
+// Source is where the collector reads the accounting data
+type Source interface {
+	Summary(year int) (*client.Summary, error)
+	RevenueCounts(year int) (*client.RevenueCounts, error)
+	ExpenseCounts(year int) (*client.ExpenseCounts, error)
+	Queue() ([]client.QueuedExpense, error)
+	Rejected() ([]client.RejectedExpense, error)
+	Revenues() ([]client.Revenue, error)
+	Taxes(summary *client.Summary) (*taxes.TaxBreakdown, error)
+	// Relogin logs in again when the session ended, reporting whether it did
+	Relogin() (bool, error)
+}
+
+// Sources are the polled endpoints, the values of the source label
+var Sources = []string{"summary", "taxes", "revenue_counts", "expense_counts", "queue", "rejected", "receivables"}
+
+// sourceState is what the last polls of one source left behind
+type sourceState struct {
+	families    []Family // from the last successful poll
+	errors      int
+	ok          bool
+	lastSuccess time.Time
+}
+
+// Collector polls a Source and keeps the last values for scrapes. A source
+// that fails keeps its previous values, its errors counter and last success
+// timestamp tell how stale they are
+type Collector struct {
+	src Source
+	ron func(client.Revenue) money.Money
+	now func() time.Time
+
+	mu       sync.Mutex
+	state    map[string]*sourceState
+	polled   time.Time
+	duration time.Duration
+}
+
+// NewCollector creates a collector valuing foreign currency invoices in RON
+// with ron
+func NewCollector(src Source, ron func(client.Revenue) money.Money) *Collector {
+	state := map[string]*sourceState{}
+	for _, name := range Sources {
+		state[name] = &sourceState{}
+	}
+	return &Collector{src: src, ron: ron, now: time.Now, state: state}
+}
+
+// Run polls right away and then every interval until ctx is done
+func (c *Collector) Run(ctx context.Context, interval time.Duration) {
+	c.Poll()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Poll()
+		}
+	}
+}
+
+// Poll reads every source once and returns the number that failed. The
+// session is renewed at most once per poll
+func (c *Collector) Poll() int {
+	start := c.now()
+	year := start.Year()
+	relogged := false
+	failed := 0
+
+	poll := func(name string, read func() ([]Family, error)) bool {
+		families, err := read()
+		if err != nil && !relogged {
+			relogged = true
+			if again, lerr := c.src.Relogin(); lerr == nil && again {
+				families, err = read()
+			}
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		s := c.state[name]
+		s.ok = err == nil
+		if err != nil {
+			s.errors++
+			failed++
+			return false
+		}
+		s.families = families
+		s.lastSuccess = c.now()
+		return true
+	}
+
+	var summary *client.Summary
+	poll("summary", func() (f []Family, err error) {
+		if summary, err = c.src.Summary(year); err != nil {
+			return nil, err
+		}
+		return summaryFamilies(summary), nil
+	})
+	if summary != nil {
+		poll("taxes", func() ([]Family, error) {
+			b, err := c.src.Taxes(summary)
+			if err != nil {
+				return nil, err
+			}
+			return taxFamilies(summary.Year, b), nil
+		})
+	}
+	poll("revenue_counts", func() ([]Family, error) {
+		counts, err := c.src.RevenueCounts(year)
+		if err != nil {
+			return nil, err
+		}
+		return []Family{documents("solo_revenue_documents", "Revenue documents by processing state",
+			year, counts.RegisteredRevenues, counts.QueuedRevenues, counts.RejectedRevenues)}, nil
+	})
+	poll("expense_counts", func() ([]Family, error) {
+		counts, err := c.src.ExpenseCounts(year)
+		if err != nil {
+			return nil, err
+		}
+		return []Family{documents("solo_expense_documents", "Expense documents by processing state",
+			year, counts.RegisteredExpenses, counts.QueuedExpenses, counts.RejectedExpenses)}, nil
+	})
+	poll("queue", func() ([]Family, error) {
+		items, err := c.src.Queue()
+		if err != nil {
+			return nil, err
+		}
+		return queueFamilies(items), nil
+	})
+	poll("rejected", func() ([]Family, error) {
+		items, err := c.src.Rejected()
+		if err != nil {
+			return nil, err
+		}
+		return []Family{gauge("solo_rejected_documents", "Documents rejected by the accountant", float64(len(items)))}, nil
+	})
+	poll("receivables", func() ([]Family, error) {
+		items, err := c.src.Revenues()
+		if err != nil {
+			return nil, err
+		}
+		return receivableFamilies(report.AgeReceivables(items, start, c.ron)), nil
+	})
+
+	c.mu.Lock()
+	c.polled = start
+	c.duration = c.now().Sub(start)
+	c.mu.Unlock()
+	return failed
+}
+
+// Families returns the current metrics, the values of each source followed
+// by the exporter's own
+func (c *Collector) Families() []Family {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var out []Family
+	for _, name := range Sources {
+		out = append(out, c.state[name].families...)
+	}
+	errors := Family{Name: "solo_scrape_errors_total", Help: "Failed polls of SOLO.ro by source", Type: "counter"}
+	success := Family{Name: "solo_scrape_success", Help: "Whether the last poll of the source succeeded", Type: "gauge"}
+	last := Family{Name: "solo_scrape_last_success_timestamp_seconds", Help: "Unix time of the last successful poll of the source", Type: "gauge"}
+	for _, name := range Sources {
+		s := c.state[name]
+		labels := []Label{{"source", name}}
+		errors.Samples = append(errors.Samples, Sample{labels, float64(s.errors)})
+		success.Samples = append(success.Samples, Sample{labels, boolValue(s.ok)})
+		if !s.lastSuccess.IsZero() {
+			last.Samples = append(last.Samples, Sample{labels, unixSeconds(s.lastSuccess)})
+		}
+	}
+	out = append(out, errors, success, last)
+	if !c.polled.IsZero() {
+		out = append(out,
+			gauge("solo_poll_timestamp_seconds", "Unix time the last poll started", unixSeconds(c.polled)),
+			gauge("solo_poll_duration_seconds", "How long the last poll took", c.duration.Seconds()))
+	}
+	return out
+}
+
+// ServeHTTP writes the current metrics for a Prometheus scrape
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	Write(w, c.Families())
+}
+
+func summaryFamilies(s *client.Summary) []Family {
+	year := []Label{{"year", strconv.Itoa(s.Year)}}
+	return []Family{
+		{Name: "solo_revenues_ytd_ron", Help: "Revenues of the year so far", Type: "gauge",
+			Samples: []Sample{{year, s.TotalRevenues.Float64()}}},
+		{Name: "solo_deductible_expenses_ytd_ron", Help: "Deductible expenses of the year so far", Type: "gauge",
+			Samples: []Sample{{year, s.TotalDeductibleExpenses.Float64()}}},
+		{Name: "solo_net_income_ytd_ron", Help: "Revenues minus deductible expenses of the year so far", Type: "gauge",
+			Samples: []Sample{{year, (s.TotalRevenues - s.TotalDeductibleExpenses).Float64()}}},
+	}
+}
+
+func taxFamilies(year int, b *taxes.TaxBreakdown) []Family {
+	y := strconv.Itoa(year)
+	f := Family{Name: "solo_estimated_tax_ron", Help: "Estimated taxes on the income of the year so far", Type: "gauge"}
+	for _, t := range []struct {
+		name   string
+		amount money.Money
+	}{{"cas", b.CAS.Amount}, {"cass", b.CASS.Amount}, {"income_tax", b.IncomeTax}, {"total", b.TotalTaxes}} {
+		f.Samples = append(f.Samples, Sample{[]Label{{"year", y}, {"tax", t.name}}, t.amount.Float64()})
+	}
+	return []Family{f}
+}
+
+func documents(name, help string, year, registered, queued, rejected int) Family {
+	f := Family{Name: name, Help: help, Type: "gauge"}
+	y := strconv.Itoa(year)
+	for _, s := range []struct {
+		state string
+		n     int
+	}{{"registered", registered}, {"queued", queued}, {"rejected", rejected}} {
+		f.Samples = append(f.Samples, Sample{[]Label{{"year", y}, {"state", s.state}}, float64(s.n)})
+	}
+	return f
+}
+
+func queueFamilies(items []client.QueuedExpense) []Family {
+	overdue, oldest := 0, 0
+	for _, q := range items {
+		if q.IsOverdue {
+			overdue++
+		}
+		oldest = max(oldest, q.DaysPassed)
+	}
+	return []Family{
+		gauge("solo_queue_documents", "Documents waiting to be processed by the accountant", float64(len(items))),
+		gauge("solo_queue_overdue_documents", "Queued documents past their processing deadline", float64(overdue)),
+		gauge("solo_queue_oldest_document_days", "Days the oldest queued document has waited", float64(oldest)),
+	}
+}
+
+func receivableFamilies(rep *report.Receivables) []Family {
+	byAge := Family{Name: "solo_receivables_unpaid_ron", Help: "Unpaid invoices in RON by days since issue", Type: "gauge"}
+	for i, amount := range rep.Buckets {
+		byAge.Samples = append(byAge.Samples, Sample{[]Label{{"age", report.AgingBuckets[i]}}, amount.Float64()})
+	}
+	return []Family{
+		byAge,
+		gauge("solo_receivables_unpaid_total_ron", "Unpaid invoices in RON", rep.Total.Float64()),
+		gauge("solo_receivables_unpaid_invoices", "Unpaid invoices", float64(rep.Invoices)),
+	}
+}
+
+func gauge(name, help string, v float64) Family {
+	return Family{Name: name, Help: help, Type: "gauge", Samples: []Sample{{Value: v}}}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixMilli()) / 1000
+}
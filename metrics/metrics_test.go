@@ -0,0 +1,193 @@
+package metrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/money"
+	"solo-cli/taxes"
+)
+
+func TestWrite(t *testing.T) {
+	var out strings.Builder
+	err := Write(&out, []Family{
+		{Name: "a_total", Help: "Line\nbreak \\ here", Type: "counter", Samples: []Sample{
+			{Labels: []Label{{"source", `say "hi"`}, {"x", "a\\b"}}, Value: 3},
+		}},
+		{Name: "empty", Help: "Left out", Type: "gauge"},
+		gauge("b", "Plain", 0.5),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP a_total Line\nbreak \\ here
+# TYPE a_total counter
+a_total{source="say \"hi\"",x="a\\b"} 3
+# HELP b Plain
+# TYPE b gauge
+b 0.5
+`
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+// fakeSource serves fixed data, failing the sources in fail
+type fakeSource struct {
+	fail     map[string]bool
+	expired  bool // every call fails until Relogin
+	relogins int
+}
+
+func (f *fakeSource) err(name string) error {
+	if f.expired {
+		return errors.New("session expired")
+	}
+	if f.fail[name] {
+		return errors.New(name + " down")
+	}
+	return nil
+}
+
+func (f *fakeSource) Summary(year int) (*client.Summary, error) {
+	return &client.Summary{Year: year, TotalRevenues: 100000 * money.Lei, TotalDeductibleExpenses: 20000 * money.Lei}, f.err("summary")
+}
+
+func (f *fakeSource) RevenueCounts(year int) (*client.RevenueCounts, error) {
+	return &client.RevenueCounts{RegisteredRevenues: 12, QueuedRevenues: 1}, f.err("revenue_counts")
+}
+
+func (f *fakeSource) ExpenseCounts(year int) (*client.ExpenseCounts, error) {
+	return &client.ExpenseCounts{RegisteredExpenses: 30, QueuedExpenses: 3, RejectedExpenses: 1}, f.err("expense_counts")
+}
+
+func (f *fakeSource) Queue() ([]client.QueuedExpense, error) {
+	return []client.QueuedExpense{{Id: 1, DaysPassed: 2}, {Id: 2, DaysPassed: 20, IsOverdue: true}, {Id: 3, DaysPassed: 16, IsOverdue: true}}, f.err("queue")
+}
+
+func (f *fakeSource) Rejected() ([]client.RejectedExpense, error) {
+	return []client.RejectedExpense{{Id: 9}}, f.err("rejected")
+}
+
+func (f *fakeSource) Revenues() ([]client.Revenue, error) {
+	ron := client.Currency{ShortName: "RON"}
+	return []client.Revenue{
+		{SerialCode: "A-1", ClientName: "ACME", IssueDate: "2026-06-20", Total: 1000 * money.Lei, Currency: ron},
+		{SerialCode: "A-2", ClientName: "ACME", IssueDate: "2026-03-01", Total: 500 * money.Lei, Currency: ron},
+		{SerialCode: "A-3", ClientName: "ACME", IssueDate: "2026-02-01", Total: 700 * money.Lei, Currency: ron, IsPaid: true},
+	}, f.err("receivables")
+}
+
+func (f *fakeSource) Taxes(s *client.Summary) (*taxes.TaxBreakdown, error) {
+	return &taxes.TaxBreakdown{
+		CAS: taxes.ThresholdResult{Amount: 9720 * money.Lei}, CASS: taxes.ThresholdResult{Amount: 8000 * money.Lei},
+		IncomeTax: 6228 * money.Lei, TotalTaxes: 23948 * money.Lei,
+	}, f.err("taxes")
+}
+
+func (f *fakeSource) Relogin() (bool, error) {
+	f.relogins++
+	if !f.expired {
+		return false, nil
+	}
+	f.expired = false
+	return true, nil
+}
+
+func newTestCollector(src Source) *Collector {
+	c := NewCollector(src, client.Revenue.TotalRON)
+	c.now = func() time.Time { return time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC) }
+	return c
+}
+
+func scrape(t *testing.T, c *Collector) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type = %s", ct)
+	}
+	return rec.Body.String()
+}
+
+func expect(t *testing.T, body string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(body, "\n"+line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+}
+
+func TestCollectorPoll(t *testing.T) {
+	c := newTestCollector(&fakeSource{})
+	if failed := c.Poll(); failed != 0 {
+		t.Fatalf("%d sources failed", failed)
+	}
+	expect(t, scrape(t, c),
+		`solo_revenues_ytd_ron{year="2026"} 100000`,
+		`solo_deductible_expenses_ytd_ron{year="2026"} 20000`,
+		`solo_net_income_ytd_ron{year="2026"} 80000`,
+		`solo_estimated_tax_ron{year="2026",tax="cass"} 8000`,
+		`solo_estimated_tax_ron{year="2026",tax="total"} 23948`,
+		`solo_revenue_documents{year="2026",state="queued"} 1`,
+		`solo_expense_documents{year="2026",state="registered"} 30`,
+		`solo_queue_documents 3`,
+		`solo_queue_overdue_documents 2`,
+		`solo_queue_oldest_document_days 20`,
+		`solo_rejected_documents 1`,
+		`solo_receivables_unpaid_ron{age="0-30"} 1000`,
+		`solo_receivables_unpaid_ron{age="90+"} 500`,
+		`solo_receivables_unpaid_total_ron 1500`,
+		`solo_receivables_unpaid_invoices 2`,
+		`solo_scrape_errors_total{source="queue"} 0`,
+		`solo_scrape_success{source="summary"} 1`,
+		`solo_scrape_last_success_timestamp_seconds{source="taxes"} 1.7828208e+09`,
+		`solo_poll_duration_seconds 0`,
+	)
+}
+
+func TestCollectorFailureKeepsValues(t *testing.T) {
+	src := &fakeSource{}
+	c := newTestCollector(src)
+	c.Poll()
+
+	src.fail = map[string]bool{"queue": true}
+	if failed := c.Poll(); failed != 1 {
+		t.Fatalf("%d sources failed, want the queue", failed)
+	}
+	if src.relogins != 1 {
+		t.Errorf("relogins = %d, want a single session check", src.relogins)
+	}
+	c.Poll()
+	expect(t, scrape(t, c),
+		`solo_queue_documents 3`,
+		`solo_scrape_errors_total{source="queue"} 2`,
+		`solo_scrape_success{source="queue"} 0`,
+		`solo_scrape_success{source="rejected"} 1`,
+	)
+}
+
+func TestCollectorRelogin(t *testing.T) {
+	src := &fakeSource{expired: true}
+	c := newTestCollector(src)
+	if failed := c.Poll(); failed != 0 {
+		t.Errorf("%d sources failed after logging in again", failed)
+	}
+	if src.relogins != 1 {
+		t.Errorf("relogins = %d", src.relogins)
+	}
+	expect(t, scrape(t, c), `solo_scrape_errors_total{source="summary"} 0`, `solo_queue_documents 3`)
+}
+
+func TestCollectorBeforePoll(t *testing.T) {
+	body := scrape(t, newTestCollector(&fakeSource{}))
+	if strings.Contains(body, "solo_queue_documents") || strings.Contains(body, "solo_poll_timestamp_seconds") {
+		t.Errorf("values before the first poll:\n%s", body)
+	}
+	expect(t, body, `solo_scrape_success{source="summary"} 0`)
+}
@@ -0,0 +1,203 @@
+// Package money represents RON amounts exactly, as an integer number of
+// bani, with the rounding rules ANAF applies to declared amounts
+package money
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in bani (1/100 RON). Sums and differences are exact
+type Money int64
+
+// Lei is one leu in bani
+const Lei Money = 100
+
+// FromFloat converts a float amount in lei, rounding half away from zero to
+// the ban. Only for values that are floats by nature (config, rates)
+func FromFloat(lei float64) Money {
+	return Money(math.Round(lei * 100))
+}
+
+// Parse reads a decimal amount in lei ("1234.5", "-0.07", "12") exactly,
+// rounding half away from zero to the ban when it has more than two
+// decimals. Exponent notation is accepted through a float conversion
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		return FromFloat(f), nil
+	}
+
+	neg := false
+	digits := s
+	switch digits[0] {
+	case '-':
+		neg = true
+		digits = digits[1:]
+	case '+':
+		digits = digits[1:]
+	}
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	// Third decimal decides the rounding, the rest cannot change it
+	roundUp := len(frac) > 2 && frac[2] >= '5'
+	if len(frac) > 2 {
+		for _, c := range frac[2:] {
+			if c < '0' || c > '9' {
+				return 0, fmt.Errorf("invalid amount %q", s)
+			}
+		}
+		frac = frac[:2]
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+
+	w, err := strconv.ParseUint(whole, 10, 63)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	f, err := strconv.ParseUint(frac, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if w > math.MaxInt64/100-1 {
+		return 0, fmt.Errorf("amount %q out of range", s)
+	}
+
+	m := Money(w)*Lei + Money(f)
+	if roundUp {
+		m++
+	}
+	if neg {
+		m = -m
+	}
+	return m, nil
+}
+
+// MustParse is Parse for constants, panicking on invalid input
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// UnmarshalJSON decodes a JSON number (or numeric string) from its decimal
+// text, so 0.1 is exactly 10 bani instead of a binary approximation
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) >= 2 && data[0] == '"' {
+		data = data[1 : len(data)-1]
+	}
+	v, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// MarshalJSON encodes the amount as a JSON number with two decimals
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// Float64 returns the amount in lei, for ratios and display
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// String formats the amount in lei with two decimals, "-1234.05"
+func (m Money) String() string {
+	v := int64(m)
+	if v < 0 {
+		// uint64 of the negation is right even for MinInt64
+		u := uint64(-v)
+		return fmt.Sprintf("-%d.%02d", u/100, u%100)
+	}
+	return fmt.Sprintf("%d.%02d", v/100, v%100)
+}
+
+// Format makes Money print like a float64 in lei with the float verbs, so
+// "%.2f" and "%12.2f" keep working, and like String with %v and %s
+func (m Money) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		fmt.Fprintf(f, fmt.FormatString(f, 's'), m.String())
+	case 'd':
+		fmt.Fprintf(f, fmt.FormatString(f, 'd'), int64(m))
+	default:
+		fmt.Fprintf(f, fmt.FormatString(f, verb), m.Float64())
+	}
+}
+
+// MulFloat multiplies by a factor (a number of salaries, a ratio), rounding
+// half away from zero to the ban
+func (m Money) MulFloat(factor float64) Money {
+	return Money(math.Round(float64(m) * factor))
+}
+
+// Percent returns pct percent of the amount rounded half away from zero to
+// the ban. The percentage is taken to four decimals and computed in
+// integers, so 10% of 0.05 RON is exactly 1 ban
+func (m Money) Percent(pct float64) Money {
+	// pct * 10000 parts per million
+	ppm := int64(math.Round(pct * 10000))
+	return Money(divRound(int64(m)*ppm, 1_000_000))
+}
+
+// RoundLei rounds to whole lei the way ANAF rounds declared taxes and
+// contributions: under 50 bani is dropped, 50 bani and over rounds up
+func (m Money) RoundLei() Money {
+	return Money(divRound(int64(m), 100)) * Lei
+}
+
+// Div splits the amount into n equal parts (an average), rounding half away
+// from zero to the ban. n must be positive
+func (m Money) Div(n int64) Money {
+	return Money(divRound(int64(m), n))
+}
+
+// divRound divides rounding half away from zero
+func divRound(a, b int64) int64 {
+	q, r := a/b, a%b
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= b {
+		if a < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+// Sum adds up amounts exactly
+func Sum(amounts ...Money) Money {
+	var total Money
+	for _, a := range amounts {
+		total += a
+	}
+	return total
+}
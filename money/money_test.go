@@ -0,0 +1,173 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want Money
+	}{
+		{"0", 0},
+		{"12", 1200},
+		{"1234.5", 123450},
+		{"1234.05", 123405},
+		{"-0.07", -7},
+		{"+3.10", 310},
+		{".5", 50},
+		{"0.1", 10},
+		{"2.004", 200},
+		{"2.005", 201}, // half rounds away from zero
+		{"-2.005", -201},
+		{"2.0049999", 200},
+		{"1e3", 100000},
+		{" 7.25 ", 725},
+	} {
+		got, err := Parse(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("Parse(%q) = %d, %v, want %d", tc.in, got, err, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"", "-", ".", "abc", "1.2.3", "1.2x3", "1,50", "99999999999999999999"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for m, want := range map[Money]string{
+		0:         "0.00",
+		5:         "0.05",
+		-5:        "-0.05",
+		123405:    "1234.05",
+		-123450:   "-1234.50",
+		100 * Lei: "100.00",
+	} {
+		if got := m.String(); got != want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(m), got, want)
+		}
+	}
+}
+
+// The float verbs keep working on Money so existing format strings print lei
+func TestFormat(t *testing.T) {
+	m := MustParse("1234.5")
+	for format, want := range map[string]string{
+		"%v":     "1234.50",
+		"%s":     "1234.50",
+		"%10s":   "   1234.50",
+		"%.2f":   "1234.50",
+		"%.0f":   "1234",
+		"%12.2f": "     1234.50",
+		"%d":     "123450",
+	} {
+		if got := fmt.Sprintf(format, m); got != want {
+			t.Errorf("Sprintf(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		A, B, C, D Money
+	}
+	if err := json.Unmarshal([]byte(`{"A":0.1,"B":"19.99","C":null,"D":1234.565}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 10 || v.B != 1999 || v.C != 0 || v.D != 123457 {
+		t.Errorf("decoded %+v", v)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil || string(data) != `{"A":0.10,"B":19.99,"C":0.00,"D":1234.57}` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"A":"ten"}`), &v); err == nil {
+		t.Error("decoding a non-number succeeded")
+	}
+}
+
+// A thousand 0.10 invoices sum to exactly 100 lei, float64 ends up at
+// 99.9999999999986
+func TestSumIsExact(t *testing.T) {
+	var total Money
+	for range 1000 {
+		total += MustParse("0.10")
+	}
+	if total != 100*Lei {
+		t.Errorf("total = %v, want 100.00", total)
+	}
+	if Sum(MustParse("0.10"), MustParse("0.20")) != MustParse("0.30") {
+		t.Error("0.10 + 0.20 != 0.30")
+	}
+}
+
+func TestPercent(t *testing.T) {
+	for _, tc := range []struct {
+		m    Money
+		pct  float64
+		want Money
+	}{
+		{MustParse("60750"), 10, MustParse("6075")},
+		{5, 10, 1},   // 0.5 ban rounds up
+		{-5, 10, -1}, // and away from zero for negatives
+		{MustParse("100"), 16, MustParse("16")},
+		{MustParse("100"), 12.5, MustParse("12.50")},
+		{MustParse("0.99"), 0, 0},
+		{MustParse("123.45"), 100, MustParse("123.45")},
+	} {
+		if got := tc.m.Percent(tc.pct); got != tc.want {
+			t.Errorf("%v.Percent(%g) = %v, want %v", tc.m, tc.pct, got, tc.want)
+		}
+	}
+}
+
+// ANAF rounds declared amounts to whole lei: under 50 bani down, from 50 up
+func TestRoundLei(t *testing.T) {
+	for in, want := range map[string]string{
+		"4252.49": "4252.00",
+		"4252.50": "4253.00",
+		"4252.99": "4253.00",
+		"0.49":    "0.00",
+		"-1.50":   "-2.00",
+		"-1.49":   "-1.00",
+		"17":      "17.00",
+	} {
+		if got := MustParse(in).RoundLei().String(); got != want {
+			t.Errorf("RoundLei(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestMulFloat(t *testing.T) {
+	smb := MustParse("4050")
+	if got := smb.MulFloat(11.3); got != MustParse("45765") {
+		t.Errorf("4050 * 11.3 = %v", got)
+	}
+	if got := MustParse("0.03").MulFloat(0.5); got != 2 {
+		t.Errorf("0.03 * 0.5 = %v, want 0.02 (half away from zero)", got)
+	}
+}
+
+func TestDiv(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		n    int64
+		want string
+	}{
+		{"2245.50", 2, "1122.75"},
+		{"10.00", 3, "3.33"},
+		{"0.05", 2, "0.03"},
+		{"-0.05", 2, "-0.03"},
+	} {
+		if got := MustParse(tt.in).Div(tt.n).String(); got != tt.want {
+			t.Errorf("%s / %d = %s, want %s", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Desktop shows the event as a desktop notification, calling
+// org.freedesktop.Notifications.Notify on the D-Bus session bus
+type Desktop struct {
+	// Address is the bus address, empty uses DBUS_SESSION_BUS_ADDRESS or
+	// $XDG_RUNTIME_DIR/bus
+	Address string
+}
+
+func (d *Desktop) Notify(ctx context.Context, e Event) error {
+	address := d.Address
+	if address == "" {
+		address = os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	}
+	if address == "" && os.Getenv("XDG_RUNTIME_DIR") != "" {
+		address = "unix:path=" + os.Getenv("XDG_RUNTIME_DIR") + "/bus"
+	}
+	if address == "" {
+		return errors.New("no D-Bus session bus (DBUS_SESSION_BUS_ADDRESS is not set)")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	bus, err := dialBus(ctx, address)
+	if err != nil {
+		return fmt.Errorf("D-Bus: %w", err)
+	}
+	defer bus.conn.Close()
+
+	if _, err := bus.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello", "", nil); err != nil {
+		return fmt.Errorf("D-Bus: %w", err)
+	}
+	// Notify(app_name, replaces_id, app_icon, summary, body, actions, hints, expire_timeout)
+	_, err = bus.call("org.freedesktop.Notifications", "/org/freedesktop/Notifications", "org.freedesktop.Notifications", "Notify",
+		"susssasa{sv}i", func(w *dbusWriter) {
+			w.string("solo-cli")
+			w.uint32(0)
+			w.string("")
+			w.string(e.Title)
+			w.string(e.Message)
+			w.array(4, func() {})
+			w.array(8, func() {})
+			w.uint32(^uint32(0)) // -1, the server's default timeout
+		})
+	if err != nil {
+		return fmt.Errorf("D-Bus: %w", err)
+	}
+	return nil
+}
+
+// D-Bus message types and header fields
+const (
+	dbusMethodCall   = 1
+	dbusMethodReturn = 2
+	dbusError        = 3
+
+	dbusFieldPath        = 1
+	dbusFieldInterface   = 2
+	dbusFieldMember      = 3
+	dbusFieldErrorName   = 4
+	dbusFieldReplySerial = 5
+	dbusFieldDestination = 6
+	dbusFieldSignature   = 8
+)
+
+// dbusMaxMessage bounds what is read from the bus, the protocol's own limit
+const dbusMaxMessage = 128 << 20
+
+// busConn is just enough of a D-Bus client to call methods: SASL EXTERNAL
+// authentication over a unix socket and little-endian messages
+type busConn struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	serial uint32
+}
+
+// dialBus connects and authenticates to the first unix socket of a bus
+// address such as "unix:path=/run/user/1000/bus"
+func dialBus(ctx context.Context, address string) (*busConn, error) {
+	var errs []error
+	for _, addr := range strings.Split(address, ";") {
+		transport, params, _ := strings.Cut(addr, ":")
+		if transport != "unix" {
+			errs = append(errs, fmt.Errorf("unsupported transport %q", transport))
+			continue
+		}
+		socket := ""
+		for _, kv := range strings.Split(params, ",") {
+			k, v, _ := strings.Cut(kv, "=")
+			switch k {
+			case "path":
+				socket = unescapeBusValue(v)
+			case "abstract":
+				socket = "@" + unescapeBusValue(v)
+			}
+		}
+		if socket == "" {
+			errs = append(errs, fmt.Errorf("no socket in %q", addr))
+			continue
+		}
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "unix", socket)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		bus := &busConn{conn: conn, r: bufio.NewReader(conn)}
+		if err := bus.auth(); err != nil {
+			conn.Close()
+			errs = append(errs, err)
+			continue
+		}
+		return bus, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// unescapeBusValue decodes the %xx escapes of an address value
+func unescapeBusValue(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '%' && i+2 < len(v) {
+			if c, err := strconv.ParseUint(v[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String()
+}
+
+// auth identifies as the process' user
+func (b *busConn) auth() error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := io.WriteString(b.conn, "\x00AUTH EXTERNAL "+uid+"\r\n"); err != nil {
+		return err
+	}
+	line, err := b.r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("authentication rejected: %s", strings.TrimSpace(line))
+	}
+	_, err = io.WriteString(b.conn, "BEGIN\r\n")
+	return err
+}
+
+// call sends a method call and waits for its reply, skipping signals and
+// other messages in between
+func (b *busConn) call(dest, path, iface, member, sig string, body func(*dbusWriter)) (*dbusMessage, error) {
+	b.serial++
+	msg := encodeMessage(dbusMethodCall, b.serial, []dbusField{
+		{dbusFieldPath, "o", path},
+		{dbusFieldInterface, "s", iface},
+		{dbusFieldMember, "s", member},
+		{dbusFieldDestination, "s", dest},
+	}, sig, body)
+	if _, err := b.conn.Write(msg); err != nil {
+		return nil, err
+	}
+	for {
+		reply, err := readMessage(b.r)
+		if err != nil {
+			return nil, err
+		}
+		if reply.ReplySerial != b.serial {
+			continue
+		}
+		switch reply.Type {
+		case dbusMethodReturn:
+			return reply, nil
+		case dbusError:
+			if text := reply.errorText(); text != "" {
+				return nil, fmt.Errorf("%s: %s", reply.ErrorName, text)
+			}
+			return nil, errors.New(reply.ErrorName)
+		}
+	}
+}
+
+// dbusWriter marshals values with the alignment D-Bus wants, counted from
+// the start of the buffer
+type dbusWriter struct {
+	buf []byte
+}
+
+func (w *dbusWriter) align(n int) {
+	for len(w.buf)%n != 0 {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *dbusWriter) byte(v byte) {
+	w.buf = append(w.buf, v)
+}
+
+func (w *dbusWriter) uint32(v uint32) {
+	w.align(4)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, v)
+}
+
+func (w *dbusWriter) string(s string) {
+	w.uint32(uint32(len(s)))
+	w.buf = append(w.buf, s...)
+	w.buf = append(w.buf, 0)
+}
+
+func (w *dbusWriter) signature(s string) {
+	w.buf = append(w.buf, byte(len(s)))
+	w.buf = append(w.buf, s...)
+	w.buf = append(w.buf, 0)
+}
+
+// array writes the elements fill adds, prefixed by their length in bytes
+func (w *dbusWriter) array(elemAlign int, fill func()) {
+	w.align(4)
+	at := len(w.buf)
+	w.uint32(0)
+	w.align(elemAlign)
+	start := len(w.buf)
+	fill()
+	binary.LittleEndian.PutUint32(w.buf[at:], uint32(len(w.buf)-start))
+}
+
+// dbusField is a header field whose value is a string or a uint32
+type dbusField struct {
+	code  byte
+	sig   string
+	value any
+}
+
+// encodeMessage builds a message with a body of the given signature
+func encodeMessage(typ byte, serial uint32, fields []dbusField, sig string, body func(*dbusWriter)) []byte {
+	var bw dbusWriter
+	if body != nil {
+		body(&bw)
+	}
+	if sig != "" {
+		fields = append(fields, dbusField{dbusFieldSignature, "g", sig})
+	}
+
+	var w dbusWriter
+	w.byte('l')
+	w.byte(typ)
+	w.byte(0)
+	w.byte(1)
+	w.uint32(uint32(len(bw.buf)))
+	w.uint32(serial)
+	w.array(8, func() {
+		for _, f := range fields {
+			w.align(8)
+			w.byte(f.code)
+			w.signature(f.sig)
+			switch v := f.value.(type) {
+			case uint32:
+				w.uint32(v)
+			case string:
+				if f.sig == "g" {
+					w.signature(v)
+				} else {
+					w.string(v)
+				}
+			}
+		}
+	})
+	w.align(8)
+	return append(w.buf, bw.buf...)
+}
+
+// dbusMessage is a received message with the header fields this client
+// looks at
+type dbusMessage struct {
+	Type        byte
+	Serial      uint32
+	ReplySerial uint32
+	Member      string
+	ErrorName   string
+	Signature   string
+	Body        []byte
+	order       binary.ByteOrder
+}
+
+// dbusReader unmarshals values, with offsets counted from the message start
+type dbusReader struct {
+	buf   []byte
+	pos   int
+	order binary.ByteOrder
+	err   error
+}
+
+func (r *dbusReader) need(n int) bool {
+	if r.err == nil && r.pos+n > len(r.buf) {
+		r.err = errors.New("truncated D-Bus message")
+	}
+	return r.err == nil
+}
+
+func (r *dbusReader) align(n int) {
+	if p := (r.pos + n - 1) / n * n; r.need(p - r.pos) {
+		r.pos = p
+	}
+}
+
+func (r *dbusReader) byte() byte {
+	if !r.need(1) {
+		return 0
+	}
+	r.pos++
+	return r.buf[r.pos-1]
+}
+
+func (r *dbusReader) uint32() uint32 {
+	r.align(4)
+	if !r.need(4) {
+		return 0
+	}
+	r.pos += 4
+	return r.order.Uint32(r.buf[r.pos-4:])
+}
+
+func (r *dbusReader) string() string {
+	n := int(r.uint32())
+	if !r.need(n + 1) {
+		return ""
+	}
+	r.pos += n + 1
+	return string(r.buf[r.pos-n-1 : r.pos-1])
+}
+
+func (r *dbusReader) signature() string {
+	n := int(r.byte())
+	if !r.need(n + 1) {
+		return ""
+	}
+	r.pos += n + 1
+	return string(r.buf[r.pos-n-1 : r.pos-1])
+}
+
+// readMessage reads one message, in either byte order
+func readMessage(rd io.Reader) (*dbusMessage, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(rd, fixed); err != nil {
+		return nil, err
+	}
+	var order binary.ByteOrder
+	switch fixed[0] {
+	case 'l':
+		order = binary.LittleEndian
+	case 'B':
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("invalid D-Bus byte order %q", fixed[0])
+	}
+	bodyLen, fieldsLen := order.Uint32(fixed[4:]), order.Uint32(fixed[12:])
+	if bodyLen > dbusMaxMessage || fieldsLen > dbusMaxMessage {
+		return nil, errors.New("D-Bus message too large")
+	}
+	headerLen := (16 + int(fieldsLen) + 7) / 8 * 8
+	buf := make([]byte, headerLen+int(bodyLen))
+	copy(buf, fixed)
+	if _, err := io.ReadFull(rd, buf[16:]); err != nil {
+		return nil, err
+	}
+
+	m := &dbusMessage{Type: fixed[1], Serial: order.Uint32(fixed[8:]), Body: buf[headerLen:], order: order}
+	r := &dbusReader{buf: buf[:16+fieldsLen], pos: 16, order: order}
+	for r.pos < len(r.buf) && r.err == nil {
+		r.align(8)
+		code := r.byte()
+		switch sig := r.signature(); sig {
+		case "u":
+			v := r.uint32()
+			if code == dbusFieldReplySerial {
+				m.ReplySerial = v
+			}
+		case "s", "o":
+			v := r.string()
+			switch code {
+			case dbusFieldMember:
+				m.Member = v
+			case dbusFieldErrorName:
+				m.ErrorName = v
+			}
+		case "g":
+			v := r.signature()
+			if code == dbusFieldSignature {
+				m.Signature = v
+			}
+		default:
+			// Fields of other types are not defined by the protocol
+			r.err = fmt.Errorf("unexpected D-Bus header field type %q", sig)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return m, nil
+}
+
+// errorText is the message an error reply carries as its first argument
+func (m *dbusMessage) errorText() string {
+	if !strings.HasPrefix(m.Signature, "s") {
+		return ""
+	}
+	r := &dbusReader{buf: m.Body, order: m.order}
+	s := r.string()
+	if r.err != nil {
+		return ""
+	}
+	return s
+}
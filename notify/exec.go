@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Exec runs a command for each event, with the event as JSON on stdin and
+// its fields in SOLO_EVENT, SOLO_EVENT_ID, SOLO_TITLE and SOLO_MESSAGE
+type Exec struct {
+	Command []string
+	Timeout time.Duration // 0 is 30 seconds
+}
+
+func (x *Exec) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	timeout := x.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, x.Command[0], x.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"SOLO_EVENT="+e.Kind, "SOLO_EVENT_ID="+e.ID, "SOLO_TITLE="+e.Title, "SOLO_MESSAGE="+e.Message)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", x.Command[0], err, lastLine(msg))
+		}
+		return fmt.Errorf("%s: %w", x.Command[0], err)
+	}
+	return nil
+}
+
+func lastLine(s string) string {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
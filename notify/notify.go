@@ -0,0 +1,225 @@
+// Package notify turns changes in the SOLO.ro data into events (a document
+// rejected, a queued document past its deadline, an invoice paid) and
+// delivers each one once to webhooks, commands, email or the desktop
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+)
+
+// Event kinds
+const (
+	Rejected = "rejected"
+	Overdue  = "overdue"
+	Paid     = "paid"
+)
+
+// Kinds lists the event kinds rules can select
+var Kinds = []string{Rejected, Overdue, Paid}
+
+// Event is something worth telling about. ID stays the same across polls,
+// which is what de-duplication keys on
+type Event struct {
+	Kind    string    `json:"event"`
+	ID      string    `json:"id"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+	Data    any       `json:"data,omitempty"` // the document or invoice
+}
+
+// Notifier delivers events to one destination
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// New creates the notifier a config entry describes
+func New(cfg config.NotifierConfig) (Notifier, error) {
+	switch cfg.Type {
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, errors.New("webhook needs a url")
+		}
+		return &Webhook{URL: cfg.URL, Headers: cfg.Headers}, nil
+	case "exec":
+		if len(cfg.Command) == 0 {
+			return nil, errors.New("exec needs a command")
+		}
+		return &Exec{Command: cfg.Command}, nil
+	case "email":
+		if cfg.SMTP == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, errors.New("email needs smtp, from and to")
+		}
+		return &Email{Addr: cfg.SMTP, Username: cfg.Username, Password: cfg.Password, From: cfg.From, To: cfg.To}, nil
+	case "desktop":
+		return &Desktop{}, nil
+	case "":
+		return nil, errors.New("type is missing (webhook, exec, email or desktop)")
+	}
+	return nil, fmt.Errorf("unknown type %q (webhook, exec, email or desktop)", cfg.Type)
+}
+
+// Detect lists the events the current data shows: rejected documents,
+// queued documents past their deadline and paid invoices
+func Detect(queue []client.QueuedExpense, rejected []client.RejectedExpense, revenues []client.Revenue, now time.Time) []Event {
+	var events []Event
+	for _, r := range rejected {
+		msg := r.DocumentName + " was rejected"
+		if r.Reason != "" {
+			msg += ": " + r.Reason
+		}
+		events = append(events, Event{Kind: Rejected, ID: Rejected + ":" + strconv.Itoa(r.Id),
+			Title: "Document rejected: " + r.DocumentName, Message: msg, Time: now, Data: r})
+	}
+	for _, q := range queue {
+		if !q.IsOverdue {
+			continue
+		}
+		msg := fmt.Sprintf("%s has waited %d days in the queue", q.DocumentName, q.DaysPassed)
+		if deadline := client.Day(q.ProcessingDeadline); deadline != "" {
+			msg += ", past its deadline of " + deadline
+		}
+		events = append(events, Event{Kind: Overdue, ID: Overdue + ":" + strconv.Itoa(q.Id),
+			Title: "Document overdue: " + q.DocumentName, Message: msg, Time: now, Data: q})
+	}
+	for _, r := range revenues {
+		if !r.IsPaid {
+			continue
+		}
+		key := r.UniqueCode
+		if key == "" {
+			key = r.SerialCode
+		}
+		msg := fmt.Sprintf("%s paid %s (%s %s)", r.ClientName, r.SerialCode, r.Total, r.Currency.ShortName)
+		if paid := client.Day(r.PaymentDate); paid != "" {
+			msg += " on " + paid
+		}
+		events = append(events, Event{Kind: Paid, ID: Paid + ":" + key,
+			Title: "Invoice paid: " + r.SerialCode, Message: msg, Time: now, Data: r})
+	}
+	return events
+}
+
+// Dispatcher routes events to notifiers by the config rules
+type Dispatcher struct {
+	Notifiers map[string]Notifier
+	Rules     []config.NotifyRule
+}
+
+// NewDispatcher builds the notifiers of a config and checks that the rules
+// name known events and notifiers
+func NewDispatcher(cfg *config.NotifyConfig) (*Dispatcher, error) {
+	if cfg == nil || len(cfg.Notifiers) == 0 {
+		return nil, errors.New(`no notifiers configured, add a "notify" section to config.json`)
+	}
+	d := &Dispatcher{Notifiers: map[string]Notifier{}, Rules: cfg.Rules}
+	var problems []error
+	for name, nc := range cfg.Notifiers {
+		n, err := New(nc)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("notifier %q: %w", name, err))
+			continue
+		}
+		d.Notifiers[name] = n
+	}
+	if len(cfg.Rules) == 0 {
+		problems = append(problems, errors.New("no rules, nothing would be sent"))
+	}
+	for i, r := range cfg.Rules {
+		for _, kind := range r.Events {
+			if !slices.Contains(Kinds, kind) {
+				problems = append(problems, fmt.Errorf("rule %d: unknown event %q (rejected, overdue or paid)", i+1, kind))
+			}
+		}
+		if len(r.Notify) == 0 {
+			problems = append(problems, fmt.Errorf("rule %d: notify is empty", i+1))
+		}
+		for _, name := range r.Notify {
+			if _, ok := cfg.Notifiers[name]; !ok {
+				problems = append(problems, fmt.Errorf("rule %d: unknown notifier %q", i+1, name))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Slice(problems, func(i, j int) bool { return problems[i].Error() < problems[j].Error() })
+		return nil, fmt.Errorf("invalid notify config: %w", errors.Join(problems...))
+	}
+	return d, nil
+}
+
+// Targets returns the names of the notifiers an event goes to, once each in
+// rule order
+func (d *Dispatcher) Targets(e Event) []string {
+	var names []string
+	for _, r := range d.Rules {
+		if len(r.Events) > 0 && !slices.Contains(r.Events, e.Kind) {
+			continue
+		}
+		for _, name := range r.Notify {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Delivery is the outcome of sending one event to one notifier
+type Delivery struct {
+	Event    Event
+	Notifier string
+	Err      error
+}
+
+// Dispatch sends the events not delivered yet and records them in state.
+// An event is new the first time it is seen; notifiers that fail get it
+// again on later polls while the event lasts. A fresh state only records
+// the current events, so starting out does not replay the whole history
+func (d *Dispatcher) Dispatch(ctx context.Context, state *State, events []Event, now time.Time) []Delivery {
+	baseline := state.fresh
+	state.fresh = false
+	current := map[string]bool{}
+	var out []Delivery
+	for _, e := range events {
+		current[e.ID] = true
+		var targets []string
+		if _, seen := state.Seen[e.ID]; !seen {
+			state.Seen[e.ID] = now
+			if baseline {
+				continue
+			}
+			targets = d.Targets(e)
+		} else if pending, ok := state.Pending[e.ID]; ok {
+			targets = pending
+		}
+
+		var failed []string
+		for _, name := range targets {
+			n, ok := d.Notifiers[name]
+			if !ok {
+				continue // removed from the config since
+			}
+			err := n.Notify(ctx, e)
+			if err != nil {
+				failed = append(failed, name)
+			}
+			out = append(out, Delivery{Event: e, Notifier: name, Err: err})
+		}
+		if len(failed) > 0 {
+			state.Pending[e.ID] = failed
+		} else {
+			delete(state.Pending, e.ID)
+		}
+	}
+	state.prune(current, now)
+	return out
+}
@@ -0,0 +1,355 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/money"
+)
+
+var now = time.Date(2026, 6, 30, 9, 0, 0, 0, time.UTC)
+
+func testEvents() []Event {
+	return Detect(
+		[]client.QueuedExpense{
+			{Id: 1, DocumentName: "fresh.pdf", DaysPassed: 1},
+			{Id: 2, DocumentName: "old.pdf", DaysPassed: 16, IsOverdue: true, ProcessingDeadline: "2026-06-28T00:00:00"},
+		},
+		[]client.RejectedExpense{{Id: 7, DocumentName: "blurry.jpg", Reason: "unreadable"}},
+		[]client.Revenue{
+			{SerialCode: "INV-1", ClientName: "ACME", Total: 1000 * money.Lei, Currency: client.Currency{ShortName: "RON"}, IsPaid: true, PaymentDate: "2026-06-29T00:00:00"},
+			{SerialCode: "INV-2", ClientName: "Globex", Total: 50 * money.Lei},
+		},
+		now,
+	)
+}
+
+func TestDetect(t *testing.T) {
+	events := testEvents()
+	want := map[string]string{
+		"rejected:7": "blurry.jpg was rejected: unreadable",
+		"overdue:2":  "old.pdf has waited 16 days in the queue, past its deadline of 2026-06-28",
+		"paid:INV-1": "ACME paid INV-1 (1000.00 RON) on 2026-06-29",
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v", events)
+	}
+	for _, e := range events {
+		if want[e.ID] != e.Message {
+			t.Errorf("%s message = %q, want %q", e.ID, e.Message, want[e.ID])
+		}
+	}
+}
+
+// recorder is a notifier that records events, failing while err is set
+type recorder struct {
+	got []string
+	err error
+}
+
+func (r *recorder) Notify(ctx context.Context, e Event) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.got = append(r.got, e.ID)
+	return nil
+}
+
+func TestDispatch(t *testing.T) {
+	mail, hook := &recorder{}, &recorder{}
+	d := &Dispatcher{
+		Notifiers: map[string]Notifier{"mail": mail, "hook": hook},
+		Rules: []config.NotifyRule{
+			{Events: []string{Rejected, Overdue}, Notify: []string{"mail"}},
+			{Notify: []string{"hook", "mail"}},
+		},
+	}
+	if got := d.Targets(Event{Kind: Paid}); strings.Join(got, ",") != "hook,mail" {
+		t.Errorf("paid targets = %v", got)
+	}
+
+	// A fresh state records what is there without sending it
+	state := NewState()
+	events := testEvents()
+	if out := d.Dispatch(context.Background(), state, events[:1], now); len(out) != 0 || len(state.Seen) != 1 {
+		t.Fatalf("baseline sent %v, seen %v", out, state.Seen)
+	}
+
+	// New events are sent once, a failing notifier gets them again later
+	hook.err = errors.New("down")
+	out := d.Dispatch(context.Background(), state, events, now)
+	if len(out) != 4 || len(mail.got) != 2 {
+		t.Fatalf("deliveries = %+v, mail got %v", out, mail.got)
+	}
+	if p := state.Pending; len(p) != 2 || p["paid:INV-1"][0] != "hook" {
+		t.Errorf("pending = %v", p)
+	}
+	hook.err = nil
+	d.Dispatch(context.Background(), state, events, now)
+	if len(mail.got) != 2 || len(hook.got) != 2 || len(state.Pending) != 0 {
+		t.Errorf("retry: mail %v, hook %v, pending %v", mail.got, hook.got, state.Pending)
+	}
+	if out := d.Dispatch(context.Background(), state, events, now); len(out) != 0 {
+		t.Errorf("sent again: %+v", out)
+	}
+
+	// Events that ended are forgotten after a while
+	d.Dispatch(context.Background(), state, nil, now.Add(100*24*time.Hour))
+	if len(state.Seen) != 0 {
+		t.Errorf("seen = %v", state.Seen)
+	}
+}
+
+func TestNewDispatcher(t *testing.T) {
+	_, err := NewDispatcher(&config.NotifyConfig{
+		Notifiers: map[string]config.NotifierConfig{
+			"hook": {Type: "webhook"},
+			"box":  {Type: "pager"},
+			"me":   {Type: "desktop"},
+		},
+		Rules: []config.NotifyRule{{Events: []string{"paid", "late"}, Notify: []string{"me", "slack"}}},
+	})
+	for _, want := range []string{`"hook": webhook needs a url`, `"box": unknown type "pager"`, `unknown event "late"`, `unknown notifier "slack"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v, want %s", err, want)
+		}
+	}
+	if _, err := NewDispatcher(nil); err == nil {
+		t.Error("no config accepted")
+	}
+	d, err := NewDispatcher(&config.NotifyConfig{
+		Notifiers: map[string]config.NotifierConfig{"me": {Type: "desktop"}},
+		Rules:     []config.NotifyRule{{Notify: []string{"me"}}},
+	})
+	if err != nil || d.Notifiers["me"] == nil {
+		t.Errorf("valid config: %v", err)
+	}
+}
+
+func TestState(t *testing.T) {
+	config.SetConfigPath(filepath.Join(t.TempDir(), "config.json"))
+	defer config.SetConfigPath("")
+
+	s, err := LoadState()
+	if err != nil || !s.Fresh() {
+		t.Fatalf("missing file: %v, fresh %v", err, s.Fresh())
+	}
+	s.Seen["paid:INV-1"] = now
+	s.Pending["paid:INV-1"] = []string{"hook"}
+	if err := SaveState(s); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState()
+	if err != nil || loaded.Fresh() || !loaded.Seen["paid:INV-1"].Equal(now) || loaded.Pending["paid:INV-1"][0] != "hook" {
+		t.Errorf("loaded %+v, %v", loaded, err)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var got Event
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.Kind == Paid {
+			http.Error(w, "nope", http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	hook := &Webhook{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"}}
+	events := testEvents()
+	if err := hook.Notify(context.Background(), events[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "rejected:7" || got.Title != "Document rejected: blurry.jpg" || auth != "Bearer x" {
+		t.Errorf("posted %+v with %q", got, auth)
+	}
+	if err := hook.Notify(context.Background(), events[2]); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("error status: %v", err)
+	}
+}
+
+func TestExec(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	x := &Exec{Command: []string{"sh", "-c", `cat > "$0"; echo "$SOLO_EVENT $SOLO_TITLE" >> "$0"`, out}}
+	if err := x.Notify(context.Background(), testEvents()[1]); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(out)
+	if !strings.Contains(string(data), `"id":"overdue:2"`) || !strings.HasSuffix(string(data), "overdue Document overdue: old.pdf\n") {
+		t.Errorf("command saw %s", data)
+	}
+
+	fail := &Exec{Command: []string{"sh", "-c", "echo broken >&2; exit 3"}}
+	if err := fail.Notify(context.Background(), testEvents()[1]); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("failing command: %v", err)
+	}
+}
+
+// fakeSMTP accepts one message and sends its envelope and data to got
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		var session strings.Builder
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.Fields(line + " x")[0])
+			switch cmd {
+			case "EHLO", "HELO":
+				reply("250-fake\r\n250 8BITMIME")
+			case "MAIL", "RCPT":
+				session.WriteString(line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 go on")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					session.WriteString(l)
+				}
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				got <- session.String()
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+	return ln.Addr().String(), got
+}
+
+func TestEmail(t *testing.T) {
+	addr, got := fakeSMTP(t)
+	m := &Email{Addr: addr, From: "SOLO <solo@example.com>", To: []string{"Ana Pop <ana@example.com>"}}
+	if err := m.Notify(context.Background(), testEvents()[2]); err != nil {
+		t.Fatal(err)
+	}
+	session := <-got
+	for _, want := range []string{
+		"MAIL FROM:<solo@example.com>",
+		"RCPT TO:<ana@example.com>",
+		"Subject: [solo-cli] Invoice paid: INV-1",
+		`To: "Ana Pop" <ana@example.com>`,
+		"ACME paid INV-1 (1000.00 RON) on 2026-06-29",
+		`"SerialCode": "INV-1"`,
+	} {
+		if !strings.Contains(session, want) {
+			t.Errorf("missing %q in:\n%s", want, session)
+		}
+	}
+
+	bad := &Email{Addr: addr, From: "not an address", To: []string{"ana@example.com"}}
+	if err := bad.Notify(context.Background(), testEvents()[2]); err == nil {
+		t.Error("invalid from accepted")
+	}
+}
+
+// fakeBus is a session bus that answers Hello and Notify, or fails Notify
+// with errName
+func fakeBus(t *testing.T, errName string) (string, <-chan []string) {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "bus")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	calls := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		if b, _ := r.ReadByte(); b != 0 {
+			return
+		}
+		if line, _ := r.ReadString('\n'); !strings.HasPrefix(line, "AUTH EXTERNAL ") {
+			return
+		}
+		io.WriteString(conn, "OK 0123456789abcdef\r\n")
+		if line, _ := r.ReadString('\n'); line != "BEGIN\r\n" {
+			return
+		}
+		var seen []string
+		for len(seen) < 2 {
+			m, err := readMessage(r)
+			if err != nil {
+				return
+			}
+			if m.Member == "Notify" {
+				// app_name, replaces_id, app_icon, summary and body
+				br := &dbusReader{buf: m.Body, order: m.order}
+				app, _, _, summary, body := br.string(), br.uint32(), br.string(), br.string(), br.string()
+				seen = append(seen, m.Signature, app, summary, body)
+				if errName != "" {
+					conn.Write(encodeMessage(dbusError, 100, []dbusField{
+						{dbusFieldErrorName, "s", errName}, {dbusFieldReplySerial, "u", m.Serial},
+					}, "s", func(w *dbusWriter) { w.string("no notification daemon") }))
+					break
+				}
+			} else {
+				seen = append(seen, m.Member)
+				// A signal in between is skipped by the client
+				conn.Write(encodeMessage(4, 99, []dbusField{{dbusFieldMember, "s", "NameAcquired"}}, "s", func(w *dbusWriter) { w.string(":1.1") }))
+			}
+			conn.Write(encodeMessage(dbusMethodReturn, 100, []dbusField{{dbusFieldReplySerial, "u", m.Serial}}, "u", func(w *dbusWriter) { w.uint32(1) }))
+		}
+		calls <- seen
+	}()
+	return "unix:path=" + socket, calls
+}
+
+func TestDesktop(t *testing.T) {
+	addr, calls := fakeBus(t, "")
+	d := &Desktop{Address: "tcp:host=x;" + addr}
+	if err := d.Notify(context.Background(), testEvents()[0]); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(<-calls, "|")
+	if got != "Hello|susssasa{sv}i|solo-cli|Document rejected: blurry.jpg|blurry.jpg was rejected: unreadable" {
+		t.Errorf("bus saw %s", got)
+	}
+
+	addr, _ = fakeBus(t, "org.freedesktop.DBus.Error.ServiceUnknown")
+	err := (&Desktop{Address: addr}).Notify(context.Background(), testEvents()[0])
+	if err == nil || !strings.Contains(err.Error(), "ServiceUnknown: no notification daemon") {
+		t.Errorf("error reply: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"solo-cli/config"
+)
+
+const (
+	stateFileName = "notify-state.json"
+	lockFileName  = "notify-state.lock"
+)
+
+// forgetAfter is how long an event that is no longer current stays in the
+// state, so a document that briefly drops out of a list does not fire again
+const forgetAfter = 90 * 24 * time.Hour
+
+// State remembers which events were already delivered
+type State struct {
+	// Seen holds the events dispatched so far, by ID, with when they were
+	// first seen
+	Seen map[string]time.Time `json:"seen"`
+	// Pending holds the notifiers that failed to deliver an event
+	Pending map[string][]string `json:"pending,omitempty"`
+
+	fresh bool // no state file yet
+}
+
+// NewState returns an empty state whose first dispatch only records events
+func NewState() *State {
+	return &State{Seen: map[string]time.Time{}, Pending: map[string][]string{}, fresh: true}
+}
+
+// Fresh reports whether the state has not recorded any dispatch yet
+func (s *State) Fresh() bool {
+	return s.fresh
+}
+
+// prune forgets events that ended long ago and pending deliveries of
+// events that ended
+func (s *State) prune(current map[string]bool, now time.Time) {
+	for id, seen := range s.Seen {
+		if !current[id] && now.Sub(seen) > forgetAfter {
+			delete(s.Seen, id)
+		}
+	}
+	for id := range s.Pending {
+		if !current[id] {
+			delete(s.Pending, id)
+		}
+	}
+}
+
+// GetStatePath returns the full path to the notification state file
+func GetStatePath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, stateFileName), nil
+}
+
+// GetLockPath returns the full path to the lock file that 'solo-cli notify'
+// and the daemon's notify job hold from LoadState to SaveState, so an event
+// is not sent by both
+func GetLockPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, lockFileName), nil
+}
+
+// LoadState reads the notification state. A missing file is a fresh state
+func LoadState() (*State, error) {
+	path, err := GetStatePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewState(), nil
+		}
+		return nil, err
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Seen == nil {
+		s.Seen = map[string]time.Time{}
+	}
+	if s.Pending == nil {
+		s.Pending = map[string][]string{}
+	}
+	return &s, nil
+}
+
+// SaveState writes the notification state to the config directory, through
+// a temporary file so an interrupted write keeps the previous state
+func SaveState(s *State) error {
+	path, err := GetStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,144 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"solo-cli/diacritics"
+)
+
+// romanian places the Romanian letters missing from WinAnsiEncoding on
+// codes WinAnsi leaves unused. The cedilla forms share the comma glyphs
+var romanian = []struct {
+	code  byte
+	glyph string
+	runes []rune
+}{
+	{0x7f, "abreve", []rune{'ă'}},
+	{0x81, "Abreve", []rune{'Ă'}},
+	{0x8d, "scommaaccent", []rune{'ș', 'ş'}},
+	{0x8f, "Scommaaccent", []rune{'Ș', 'Ş'}},
+	{0x90, "tcommaaccent", []rune{'ț', 'ţ'}},
+	{0x9d, "Tcommaaccent", []rune{'Ț', 'Ţ'}},
+}
+
+// winAnsi are the WinAnsiEncoding codes 0x80-0x9f of the characters a
+// report may use
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+var encoding = func() map[rune]byte {
+	m := map[rune]byte{}
+	for r, c := range winAnsi {
+		m[r] = c
+	}
+	for _, ro := range romanian {
+		for _, r := range ro.runes {
+			m[r] = ro.code
+		}
+	}
+	return m
+}()
+
+func differences() string {
+	var parts []string
+	for _, ro := range romanian {
+		parts = append(parts, fmt.Sprintf("%d /%s", ro.code, ro.glyph))
+	}
+	return strings.Join(parts, " ")
+}
+
+// encode maps text to the font encoding: ASCII and Latin-1 as they are,
+// Romanian letters and common punctuation to their codes, anything else
+// to a question mark
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch c, ok := encoding[r]; {
+		case ok:
+			b.WriteByte(c)
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		case r == '\t' || r == '\n':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// infoString is a document information string: PDFDocEncoding is ASCII
+// compatible, anything else goes as UTF-16
+func infoString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return "(" + escape(s) + ")"
+	}
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// Glyph widths of the printable ASCII characters (32-126) in 1/1000 em,
+// from the Adobe font metrics
+var widths = [2][95]int{
+	{ // Helvetica
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	{ // Helvetica-Bold
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// TextWidth measures s in points. Characters outside ASCII count as their
+// base letter, or as wide as a digit
+func TextWidth(font Font, size float64, s string) float64 {
+	total := 0
+	for _, r := range diacritics.Strip(s) {
+		if r >= 32 && r <= 126 {
+			total += widths[font][r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Fit shortens s with an ellipsis to fit width
+func Fit(font Font, size float64, s string, width float64) string {
+	if TextWidth(font, size, s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if t := strings.TrimRight(string(runes), " ") + "…"; TextWidth(font, size, t) <= width {
+			return t
+		}
+	}
+	return ""
+}
@@ -0,0 +1,193 @@
+// Package pdf writes simple PDF documents without dependencies: text in the
+// standard Helvetica fonts, lines and filled rectangles on A4 pages. That is
+// enough for reports and keeps the binary free of cgo and font files
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// A4 page size in points
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Font is one of the two standard fonts, which viewers have built in
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+func (f Font) resource() string {
+	if f == Bold {
+		return "/F2"
+	}
+	return "/F1"
+}
+
+// Color is an RGB color with components from 0 to 1
+type Color struct{ R, G, B float64 }
+
+// RGB builds a color from 0-255 components
+func RGB(r, g, b uint8) Color {
+	return Color{float64(r) / 255, float64(g) / 255, float64(b) / 255}
+}
+
+// Black is the default text and line color
+var Black = Color{}
+
+// Document is a PDF being built page by page
+type Document struct {
+	Title   string
+	Author  string
+	Creator string
+	Created time.Time
+	pages   []*Page
+}
+
+// New starts an empty document
+func New() *Document {
+	return &Document{}
+}
+
+// Page is one A4 page. Coordinates are in points from the top left corner,
+// y growing down, and converted to PDF's bottom-left origin when drawn
+type Page struct {
+	content bytes.Buffer
+}
+
+// AddPage appends a blank page
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages is the number of pages so far
+func (d *Document) Pages() int {
+	return len(d.pages)
+}
+
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+func (p *Page) op(format string, args ...any) {
+	fmt.Fprintf(&p.content, format, args...)
+	p.content.WriteByte('\n')
+}
+
+// Text draws s with its baseline at y
+func (p *Page) Text(x, y float64, font Font, size float64, color Color, s string) {
+	p.op("BT %s %s Tf %s %s %s rg %s %s Td (%s) Tj ET", font.resource(), num(size),
+		num(color.R), num(color.G), num(color.B), num(x), num(A4Height-y), escape(encode(s)))
+}
+
+// TextRight draws s ending at x
+func (p *Page) TextRight(x, y float64, font Font, size float64, color Color, s string) {
+	p.Text(x-TextWidth(font, size, s), y, font, size, color, s)
+}
+
+// Rect fills a rectangle whose top left corner is x, y
+func (p *Page) Rect(x, y, w, h float64, fill Color) {
+	p.op("%s %s %s rg %s %s %s %s re f", num(fill.R), num(fill.G), num(fill.B),
+		num(x), num(A4Height-y-h), num(w), num(h))
+}
+
+// Line strokes a straight line
+func (p *Page) Line(x1, y1, x2, y2, width float64, color Color) {
+	p.op("%s %s %s RG %s w %s %s m %s %s l S", num(color.R), num(color.G), num(color.B), num(width),
+		num(x1), num(A4Height-y1), num(x2), num(A4Height-y2))
+}
+
+// escape quotes a PDF literal string
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Write serializes the document. The content streams are compressed and
+// the output only depends on the document, so the same report gives the
+// same bytes
+func (d *Document) Write(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 page tree, 3-4 fonts, 5 encoding, 6 info, then a page
+	// and its content stream per page
+	pageRef := func(i int) int { return 7 + 2*i }
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageRef(i)))
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding 5 0 R >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding 5 0 R >>")
+	object("<< /Type /Encoding /BaseEncoding /WinAnsiEncoding /Differences [" + differences() + "] >>")
+	info := "<< /Producer " + infoString("solo-cli")
+	if d.Title != "" {
+		info += " /Title " + infoString(d.Title)
+	}
+	if d.Author != "" {
+		info += " /Author " + infoString(d.Author)
+	}
+	if d.Creator != "" {
+		info += " /Creator " + infoString(d.Creator)
+	}
+	if !d.Created.IsZero() {
+		info += " /CreationDate " + infoString(d.Created.UTC().Format("D:20060102150405Z"))
+	}
+	object(info + " >>")
+
+	for i, p := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(A4Width), num(A4Height), pageRef(i)+1))
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(p.content.Bytes())
+		zw.Close()
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 6 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
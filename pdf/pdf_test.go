@@ -0,0 +1,116 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	tests := map[string]string{
+		"Plain (text) \\": `Plain \(text\) \\`,
+		"Îngrijire ș ţ Ă": `\316ngrijire \215 \220 \201`,
+		"ăâ €5 – 中":       `\177\342 \2005 \226 ?`,
+	}
+	for in, want := range tests {
+		if got := escape(encode(in)); got != want {
+			t.Errorf("escape(encode(%q)) = %s, want %s", in, got, want)
+		}
+	}
+	if got := infoString("Raport anual"); got != "(Raport anual)" {
+		t.Errorf("ASCII info string = %s", got)
+	}
+	if got := infoString("ș"); got != "<FEFF0219>" {
+		t.Errorf("UTF-16 info string = %s", got)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	if got := TextWidth(Regular, 10, "1,000.50"); got != 5*5.56+2*2.78+5.56 {
+		t.Errorf("width = %v", got)
+	}
+	if TextWidth(Bold, 10, "Total") <= TextWidth(Regular, 10, "Total") {
+		t.Error("bold is not wider")
+	}
+	if TextWidth(Regular, 10, "ș") != TextWidth(Regular, 10, "s") {
+		t.Error("diacritics not measured as their base letter")
+	}
+	if got := Fit(Regular, 10, "A very long client name SRL", 60); TextWidth(Regular, 10, got) > 60 || !strings.HasSuffix(got, "…") {
+		t.Errorf("Fit = %q", got)
+	}
+	if got := Fit(Regular, 10, "Short", 60); got != "Short" {
+		t.Errorf("Fit shortened %q", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	d := New()
+	d.Title = "Raport anual 2025 – Test"
+	d.Created = time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+	for i := range 2 {
+		p := d.AddPage()
+		p.Text(40, 60, Bold, 18, Black, fmt.Sprintf("Page %d", i+1))
+		p.Rect(40, 100, 100, 20, RGB(37, 99, 235))
+		p.Line(40, 130, 200, 130, 0.5, Black)
+	}
+
+	var a, b bytes.Buffer
+	if err := d.Write(&a); err != nil {
+		t.Fatal(err)
+	}
+	d.Write(&b)
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("output is not deterministic")
+	}
+	out := a.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("missing header or trailer")
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) || !bytes.Contains(out, []byte("/CreationDate (D:20260105100000Z)")) {
+		t.Error("page count or creation date missing")
+	}
+
+	// Every xref entry points at its object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	lines := strings.Split(string(out[xref:]), "\n")
+	if lines[0] != "xref" {
+		t.Fatalf("startxref points at %q", lines[0])
+	}
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	if count != 11 {
+		t.Errorf("xref has %d entries, want 11", count)
+	}
+	for i := 1; i < count; i++ {
+		off, _ := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		if want := fmt.Sprintf("%d 0 obj\n", i); !bytes.HasPrefix(out[off:], []byte(want)) {
+			t.Errorf("object %d offset %d points at %q", i, off, out[off:off+10])
+		}
+	}
+
+	// The page content inflates to the drawing operators
+	start := bytes.Index(out, []byte("stream\n")) + len("stream\n")
+	zr, err := zlib.NewReader(bytes.NewReader(out[start:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(zr)
+	for _, want := range []string{
+		"BT /F2 18 Tf 0 0 0 rg 40 781.89 Td (Page 1) Tj ET",
+		"0.15 0.39 0.92 rg 40 721.89 100 20 re f",
+		"0 0 0 RG 0.5 w 40 711.89 m 200 711.89 l S",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("content missing %q:\n%s", want, content)
+		}
+	}
+}
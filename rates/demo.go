@@ -0,0 +1,13 @@
+package rates
+
+import "time"
+
+// Demo returns a store with flat EUR and USD rates for every day of year,
+// enough to value the demo invoices
+func Demo(year int) *Store {
+	s := &Store{Days: map[string]map[string]float64{}}
+	for d := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC); d.Year() == year; d = d.AddDate(0, 0, 1) {
+		s.Days[d.Format(dateLayout)] = map[string]float64{"EUR": 4.9750, "USD": 4.5600}
+	}
+	return s
+}
@@ -0,0 +1,147 @@
+package rates
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+// convertItem values an item in currency on its date. The API's local RON
+// amount is used as is when present, so only items without one need a
+// rate. A nil store converts nothing but RON and local amounts
+func (s *Store) convertItem(total money.Money, cur string, local *money.Money, date, to string) (money.Money, error) {
+	cur, to = strings.ToUpper(cur), strings.ToUpper(to)
+	if cur == "" {
+		cur = "RON"
+	}
+	if local != nil && to == "RON" {
+		return *local, nil
+	}
+	if cur == to {
+		return total, nil
+	}
+
+	day, ok := client.ParseDay(date)
+	if !ok {
+		return 0, fmt.Errorf("invalid date %q", date)
+	}
+	if local != nil {
+		return s.Convert(*local, "RON", to, day)
+	}
+	return s.Convert(total, cur, to, day)
+}
+
+// RevenueIn values an invoice in currency on its issue date
+func (s *Store) RevenueIn(r client.Revenue, currency string) (money.Money, error) {
+	var local *money.Money
+	if r.InvoiceLocalAmount != nil {
+		local = &r.InvoiceLocalAmount.Total
+	}
+	return s.convertItem(r.Total, r.Currency.ShortName, local, r.IssueDate, currency)
+}
+
+// ExpenseIn values an expense in currency on its purchase date
+func (s *Store) ExpenseIn(e client.Expense, currency string) (money.Money, error) {
+	var local *money.Money
+	if e.ExpenseLocalAmount != nil {
+		local = &e.ExpenseLocalAmount.Total
+	}
+	return s.convertItem(e.Total, e.Currency.ShortName, local, e.PurchaseDate, currency)
+}
+
+// RevenueRON values an invoice in RON, falling back to the raw total when
+// no rate is available so aggregates degrade instead of failing
+func (s *Store) RevenueRON(r client.Revenue) money.Money {
+	if v, err := s.RevenueIn(r, "RON"); err == nil {
+		return v
+	}
+	return r.TotalRON()
+}
+
+// ExpenseRON values an expense in RON, falling back to the raw total when
+// no rate is available
+func (s *Store) ExpenseRON(e client.Expense) money.Money {
+	if v, err := s.ExpenseIn(e, "RON"); err == nil {
+		return v
+	}
+	return e.TotalRON()
+}
+
+// ExposureRow is one invoice currency of the exposure report
+type ExposureRow struct {
+	Currency string
+	Invoices int
+	Amount   money.Money // in the invoice currency
+	Unpaid   money.Money // unpaid part, in the invoice currency
+	Value    money.Money // in the report currency, 0 when Missing
+	Share    float64     // Value / total value * 100
+	Missing  int         // invoices that could not be converted
+}
+
+// Exposure is the split of a year's invoicing by currency
+type Exposure struct {
+	Year     int
+	Currency string // report currency
+	Rows     []ExposureRow
+	Total    money.Money // in the report currency
+	Foreign  float64     // share invoiced in a currency other than RON
+	Missing  int
+}
+
+// CurrencyExposure groups the invoices issued in year by their currency
+// and values each group in the report currency on the issue dates. Rows are
+// sorted by value, largest first
+func (s *Store) CurrencyExposure(items []client.Revenue, year int, currency string) *Exposure {
+	currency = strings.ToUpper(currency)
+	exp := &Exposure{Year: year, Currency: currency}
+	rows := map[string]*ExposureRow{}
+
+	for _, r := range items {
+		if !client.InYear(r.IssueDate, year) {
+			continue
+		}
+		cur := r.Currency.ISOCode()
+		row := rows[cur]
+		if row == nil {
+			row = &ExposureRow{Currency: cur}
+			rows[cur] = row
+		}
+		row.Invoices++
+		row.Amount += r.Total
+		if !r.IsPaid {
+			row.Unpaid += r.Total
+		}
+		v, err := s.RevenueIn(r, currency)
+		if err != nil {
+			row.Missing++
+			exp.Missing++
+			continue
+		}
+		row.Value += v
+		exp.Total += v
+	}
+
+	var foreign money.Money
+	for _, row := range rows {
+		if exp.Total > 0 {
+			row.Share = row.Value.Float64() / exp.Total.Float64() * 100
+		}
+		if row.Currency != "RON" {
+			foreign += row.Value
+		}
+		exp.Rows = append(exp.Rows, *row)
+	}
+	if exp.Total > 0 {
+		exp.Foreign = foreign.Float64() / exp.Total.Float64() * 100
+	}
+	sort.Slice(exp.Rows, func(i, j int) bool {
+		if exp.Rows[i].Value != exp.Rows[j].Value {
+			return exp.Rows[i].Value > exp.Rows[j].Value
+		}
+		return exp.Rows[i].Currency < exp.Rows[j].Currency
+	})
+	return exp
+}
@@ -0,0 +1,244 @@
+// Package rates keeps a local table of BNR reference exchange rates, imported
+// from the nbrfxrates.xml files BNR publishes, and converts amounts between
+// currencies on a given date
+package rates
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"solo-cli/config"
+	"solo-cli/money"
+)
+
+const ratesFileName = "rates.json"
+
+// dateLayout is the day format of the BNR files and the store keys
+const dateLayout = "2006-01-02"
+
+// maxStaleDays is how far back a missing day falls back to the last
+// published rate. BNR publishes on working days only, so weekends and
+// holidays use the previous fixing; a longer gap means rates are missing
+const maxStaleDays = 7
+
+// Store holds RON per unit of each currency by day. RON itself is implicit
+type Store struct {
+	Days map[string]map[string]float64 `json:"days"`
+
+	dates []string // sorted keys of Days, rebuilt on change
+}
+
+// bnrDataSet mirrors the nbrfxrates.xml layout. Daily files hold one Cube,
+// the yearly archives (nbrfxrates2025.xml) one per working day
+type bnrDataSet struct {
+	XMLName      xml.Name `xml:"DataSet"`
+	OrigCurrency string   `xml:"Body>OrigCurrency"`
+	Cubes        []struct {
+		Date  string `xml:"date,attr"`
+		Rates []struct {
+			Currency   string  `xml:"currency,attr"`
+			Multiplier float64 `xml:"multiplier,attr"`
+			Value      float64 `xml:",chardata"`
+		} `xml:"Rate"`
+	} `xml:"Body>Cube"`
+}
+
+// ParseBNR reads a BNR reference rate file into day -> currency -> RON per
+// unit, dividing out the multiplier of currencies quoted per 100 units
+func ParseBNR(r io.Reader) (map[string]map[string]float64, error) {
+	var ds bnrDataSet
+	if err := xml.NewDecoder(r).Decode(&ds); err != nil {
+		return nil, fmt.Errorf("not a BNR rates file: %w", err)
+	}
+	if ds.OrigCurrency != "" && ds.OrigCurrency != "RON" {
+		return nil, fmt.Errorf("rates are against %s, want RON", ds.OrigCurrency)
+	}
+	if len(ds.Cubes) == 0 {
+		return nil, fmt.Errorf("no rates in file")
+	}
+
+	days := map[string]map[string]float64{}
+	for _, cube := range ds.Cubes {
+		if _, err := time.Parse(dateLayout, cube.Date); err != nil {
+			return nil, fmt.Errorf("invalid date %q", cube.Date)
+		}
+		day := map[string]float64{}
+		for _, rate := range cube.Rates {
+			if rate.Value <= 0 {
+				continue
+			}
+			multiplier := rate.Multiplier
+			if multiplier <= 0 {
+				multiplier = 1
+			}
+			day[strings.ToUpper(rate.Currency)] = rate.Value / multiplier
+		}
+		days[cube.Date] = day
+	}
+	return days, nil
+}
+
+// Add merges imported days into the store, newer imports winning, and
+// returns how many days were new
+func (s *Store) Add(days map[string]map[string]float64) int {
+	if s.Days == nil {
+		s.Days = map[string]map[string]float64{}
+	}
+	added := 0
+	for date, day := range days {
+		if _, ok := s.Days[date]; !ok {
+			added++
+			s.Days[date] = map[string]float64{}
+		}
+		for cur, rate := range day {
+			s.Days[date][cur] = rate
+		}
+	}
+	s.dates = nil
+	return added
+}
+
+func (s *Store) sortedDates() []string {
+	if len(s.dates) != len(s.Days) {
+		s.dates = make([]string, 0, len(s.Days))
+		for d := range s.Days {
+			s.dates = append(s.dates, d)
+		}
+		sort.Strings(s.dates)
+	}
+	return s.dates
+}
+
+// Range returns the first and last day in the store, empty when it is empty
+func (s *Store) Range() (string, string) {
+	if s == nil {
+		return "", ""
+	}
+	dates := s.sortedDates()
+	if len(dates) == 0 {
+		return "", ""
+	}
+	return dates[0], dates[len(dates)-1]
+}
+
+// window returns the fixings that can stand in for date, oldest first: the
+// day itself and up to maxStaleDays before it
+func (s *Store) window(date time.Time) []string {
+	day := date.Format(dateLayout)
+	oldest := date.AddDate(0, 0, -maxStaleDays).Format(dateLayout)
+	dates := s.sortedDates()
+	start := sort.SearchStrings(dates, oldest)
+	end := sort.Search(len(dates), func(i int) bool { return dates[i] > day })
+	if start >= end {
+		return nil
+	}
+	return dates[start:end]
+}
+
+// Rate returns RON per unit of currency on date: the fixing of that day or
+// the last one published before it, at most maxStaleDays earlier. The
+// returned day is the fixing actually used
+func (s *Store) Rate(currency string, date time.Time) (float64, string, error) {
+	currency = strings.ToUpper(currency)
+	if currency == "RON" || currency == "" {
+		return 1, date.Format(dateLayout), nil
+	}
+	if s == nil || len(s.Days) == 0 {
+		return 0, "", fmt.Errorf("no exchange rates imported, run 'solo-cli rates import <nbrfxrates.xml>'")
+	}
+
+	dates := s.window(date)
+	for i := len(dates) - 1; i >= 0; i-- {
+		if rate, ok := s.Days[dates[i]][currency]; ok {
+			return rate, dates[i], nil
+		}
+	}
+	return 0, "", fmt.Errorf("no BNR rate for %s on %s or the %d days before", currency, date.Format(dateLayout), maxStaleDays)
+}
+
+// Fixing returns the day and rates of the fixing in effect on date, with
+// the same fallback as Rate. ok is false when there is none
+func (s *Store) Fixing(date time.Time) (string, map[string]float64, bool) {
+	if s == nil {
+		return "", nil, false
+	}
+	dates := s.window(date)
+	if len(dates) == 0 {
+		return "", nil, false
+	}
+	day := dates[len(dates)-1]
+	return day, s.Days[day], true
+}
+
+// Convert converts amount from one currency to another through RON at the
+// BNR rates of date, rounding once to the ban
+func (s *Store) Convert(amount money.Money, from, to string, date time.Time) (money.Money, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return amount, nil
+	}
+	fromRate, _, err := s.Rate(from, date)
+	if err != nil {
+		return 0, err
+	}
+	toRate, _, err := s.Rate(to, date)
+	if err != nil {
+		return 0, err
+	}
+	return amount.MulFloat(fromRate / toRate), nil
+}
+
+// GetPath returns the full path to the local rates store
+func GetPath() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, ratesFileName), nil
+}
+
+// Load reads the local rates store. A missing file is an empty store
+func Load() (*Store, error) {
+	path, err := GetPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Store{Days: map[string]map[string]float64{}}, nil
+		}
+		return nil, err
+	}
+	var s Store
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Days == nil {
+		s.Days = map[string]map[string]float64{}
+	}
+	return &s, nil
+}
+
+// Save writes the store to the config directory
+func Save(s *Store) error {
+	path, err := GetPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
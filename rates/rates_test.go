@@ -0,0 +1,258 @@
+package rates
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/money"
+)
+
+const sampleBNR = `<?xml version="1.0" encoding="utf-8"?>
+<DataSet xmlns="http://www.bnr.ro/xsd" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.bnr.ro/xsd nbrfxrates.xsd">
+	<Header>
+		<Publisher>National Bank of Romania</Publisher>
+		<PublishingDate>2026-01-16</PublishingDate>
+		<MessageType>DR</MessageType>
+	</Header>
+	<Body>
+		<Subject>Reference rates</Subject>
+		<OrigCurrency>RON</OrigCurrency>
+		<Cube date="2026-01-15">
+			<Rate currency="EUR">5.0000</Rate>
+			<Rate currency="USD">4.5000</Rate>
+			<Rate currency="HUF" multiplier="100">1.2500</Rate>
+		</Cube>
+		<Cube date="2026-01-16">
+			<Rate currency="EUR">5.1000</Rate>
+			<Rate currency="USD">4.6000</Rate>
+		</Cube>
+	</Body>
+</DataSet>`
+
+func date(s string) time.Time {
+	d, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func sampleStore(t *testing.T) *Store {
+	t.Helper()
+	days, err := ParseBNR(strings.NewReader(sampleBNR))
+	if err != nil {
+		t.Fatalf("ParseBNR: %v", err)
+	}
+	s := &Store{}
+	if added := s.Add(days); added != 2 {
+		t.Fatalf("Add = %d new days, want 2", added)
+	}
+	return s
+}
+
+func TestParseBNR(t *testing.T) {
+	days, err := ParseBNR(strings.NewReader(sampleBNR))
+	if err != nil {
+		t.Fatalf("ParseBNR: %v", err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("got %d days, want 2", len(days))
+	}
+	if got := days["2026-01-15"]["EUR"]; got != 5 {
+		t.Errorf("EUR = %v, want 5", got)
+	}
+	// HUF is quoted per 100 units
+	if got := days["2026-01-15"]["HUF"]; got != 0.0125 {
+		t.Errorf("HUF = %v, want 0.0125", got)
+	}
+
+	if _, err := ParseBNR(strings.NewReader("not xml")); err == nil {
+		t.Error("ParseBNR accepted garbage")
+	}
+	if _, err := ParseBNR(strings.NewReader(`<DataSet><Body><OrigCurrency>RON</OrigCurrency></Body></DataSet>`)); err == nil {
+		t.Error("ParseBNR accepted a file without rates")
+	}
+}
+
+func TestAddCountsNewDays(t *testing.T) {
+	s := sampleStore(t)
+	if added := s.Add(map[string]map[string]float64{
+		"2026-01-16": {"GBP": 5.8},
+		"2026-01-19": {"EUR": 5.05},
+	}); added != 1 {
+		t.Errorf("Add = %d new days, want 1", added)
+	}
+	// Merging keeps the rates already on the day
+	if s.Days["2026-01-16"]["EUR"] != 5.1 || s.Days["2026-01-16"]["GBP"] != 5.8 {
+		t.Errorf("2026-01-16 = %v, want EUR and GBP", s.Days["2026-01-16"])
+	}
+	if first, last := s.Range(); first != "2026-01-15" || last != "2026-01-19" {
+		t.Errorf("Range = %s..%s", first, last)
+	}
+}
+
+func TestRateFallsBackOverWeekend(t *testing.T) {
+	s := sampleStore(t)
+
+	// Saturday and Sunday use Friday's fixing
+	rate, day, err := s.Rate("eur", date("2026-01-18"))
+	if err != nil || rate != 5.1 || day != "2026-01-16" {
+		t.Errorf("Rate(EUR, Sunday) = %v, %s, %v, want 5.1 from 2026-01-16", rate, day, err)
+	}
+	// HUF is missing on the 16th, so the 15th is used
+	if rate, day, _ := s.Rate("HUF", date("2026-01-16")); rate != 0.0125 || day != "2026-01-15" {
+		t.Errorf("Rate(HUF) = %v from %s, want 0.0125 from 2026-01-15", rate, day)
+	}
+	if rate, _, err := s.Rate("RON", date("2020-01-01")); err != nil || rate != 1 {
+		t.Errorf("Rate(RON) = %v, %v, want 1", rate, err)
+	}
+
+	// More than a week after the last fixing, or before the first, is missing
+	if _, _, err := s.Rate("EUR", date("2026-01-24")); err == nil {
+		t.Error("Rate accepted a fixing older than a week")
+	}
+	if _, _, err := s.Rate("EUR", date("2026-01-14")); err == nil {
+		t.Error("Rate used a later fixing")
+	}
+
+	var empty *Store
+	if _, _, err := empty.Rate("EUR", date("2026-01-15")); err == nil || !strings.Contains(err.Error(), "rates import") {
+		t.Errorf("nil store error = %v, want an import hint", err)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	s := sampleStore(t)
+	d := date("2026-01-15")
+
+	tests := []struct {
+		amount   money.Money
+		from, to string
+		want     money.Money
+	}{
+		{100 * money.Lei, "EUR", "RON", 500 * money.Lei},
+		{money.MustParse("1000.50"), "RON", "EUR", money.MustParse("200.10")},
+		{900 * money.Lei, "USD", "EUR", 810 * money.Lei},
+		{money.MustParse("12.34"), "EUR", "EUR", money.MustParse("12.34")},
+	}
+	for _, tt := range tests {
+		got, err := s.Convert(tt.amount, tt.from, tt.to, d)
+		if err != nil || got != tt.want {
+			t.Errorf("Convert(%s %s -> %s) = %s, %v, want %s", tt.amount, tt.from, tt.to, got, err, tt.want)
+		}
+	}
+	if _, err := s.Convert(100*money.Lei, "CHF", "RON", d); err == nil {
+		t.Error("Convert accepted a currency without rates")
+	}
+}
+
+func revenue(issued, cur string, total money.Money, local *money.Money, paid bool) client.Revenue {
+	r := client.Revenue{IssueDate: issued + "T00:00:00", Total: total, IsPaid: paid, Currency: client.Currency{ShortName: cur}}
+	if local != nil {
+		r.InvoiceLocalAmount = &client.LocalAmount{Total: *local}
+	}
+	return r
+}
+
+func TestRevenueIn(t *testing.T) {
+	s := sampleStore(t)
+	local := money.MustParse("1245.00")
+	eurWithLocal := revenue("2026-01-15", "EUR", money.MustParse("250.25"), &local, true)
+	eurNoLocal := revenue("2026-01-15", "EUR", 100*money.Lei, nil, true)
+	ron := revenue("2026-01-15", "", money.MustParse("1000.50"), nil, true)
+
+	tests := []struct {
+		name string
+		r    client.Revenue
+		to   string
+		want money.Money
+	}{
+		{"local amount wins for RON", eurWithLocal, "RON", local},
+		{"own currency is kept", eurWithLocal, "EUR", money.MustParse("250.25")},
+		{"missing local amount is converted", eurNoLocal, "RON", 500 * money.Lei},
+		{"RON invoice to EUR", ron, "EUR", money.MustParse("200.10")},
+		{"RON invoice to RON", ron, "RON", money.MustParse("1000.50")},
+	}
+	for _, tt := range tests {
+		got, err := s.RevenueIn(tt.r, tt.to)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %s, %v, want %s", tt.name, got, err, tt.want)
+		}
+	}
+
+	// Without rates only the API's own figures are available
+	var none *Store
+	if _, err := none.RevenueIn(eurNoLocal, "RON"); err == nil {
+		t.Error("RevenueIn converted without rates")
+	}
+	if got := none.RevenueRON(eurNoLocal); got != 100*money.Lei {
+		t.Errorf("RevenueRON fallback = %s, want the raw total", got)
+	}
+	if got := none.RevenueRON(eurWithLocal); got != local {
+		t.Errorf("RevenueRON = %s, want the local amount", got)
+	}
+}
+
+func TestCurrencyExposure(t *testing.T) {
+	s := sampleStore(t)
+	items := []client.Revenue{
+		revenue("2026-01-15", "RON", 1000*money.Lei, nil, true),
+		revenue("2026-01-16", "EUR", 200*money.Lei, nil, false),
+		revenue("2026-01-15", "EUR", 100*money.Lei, nil, true),
+		revenue("2026-03-02", "CHF", 50*money.Lei, nil, false), // no rate
+		revenue("2025-12-30", "EUR", 999*money.Lei, nil, true), // other year
+	}
+
+	exp := s.CurrencyExposure(items, 2026, "ron")
+	if exp.Currency != "RON" || len(exp.Rows) != 3 {
+		t.Fatalf("exposure = %+v, want 3 rows in RON", exp)
+	}
+
+	// EUR: 200 * 5.1 + 100 * 5 = 1520
+	eur := exp.Rows[0]
+	if eur.Currency != "EUR" || eur.Invoices != 2 || eur.Amount != 300*money.Lei || eur.Unpaid != 200*money.Lei || eur.Value != 1520*money.Lei {
+		t.Errorf("EUR row = %+v", eur)
+	}
+	if ron := exp.Rows[1]; ron.Currency != "RON" || ron.Value != 1000*money.Lei {
+		t.Errorf("RON row = %+v", ron)
+	}
+	if chf := exp.Rows[2]; chf.Currency != "CHF" || chf.Missing != 1 || chf.Value != 0 || chf.Unpaid != 50*money.Lei {
+		t.Errorf("CHF row = %+v", chf)
+	}
+
+	if exp.Total != 2520*money.Lei || exp.Missing != 1 {
+		t.Errorf("total = %s, missing %d, want 2520 and 1", exp.Total, exp.Missing)
+	}
+	if got := exp.Rows[0].Share; got < 60.31 || got > 60.32 {
+		t.Errorf("EUR share = %.2f, want 60.32", got)
+	}
+	if exp.Foreign < 60.31 || exp.Foreign > 60.32 {
+		t.Errorf("foreign share = %.2f, want 60.32", exp.Foreign)
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	config.SetConfigPath(filepath.Join(t.TempDir(), "config.json"))
+	t.Cleanup(func() { config.SetConfigPath("") })
+
+	// A missing file is an empty store
+	s, err := Load()
+	if err != nil || len(s.Days) != 0 {
+		t.Fatalf("Load = %+v, %v, want an empty store", s, err)
+	}
+
+	if err := Save(sampleStore(t)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	s, err = Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if rate, day, err := s.Rate("EUR", date("2026-01-17")); err != nil || rate != 5.1 || day != "2026-01-16" {
+		t.Errorf("Rate after reload = %v from %s, %v", rate, day, err)
+	}
+}
@@ -0,0 +1,156 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/money"
+	"solo-cli/taxes"
+)
+
+// annualTop is how many clients and suppliers the annual report lists
+const annualTop = 5
+
+// AnnualCompany is the header of the annual report
+type AnnualCompany struct {
+	Name         string   `json:"name"`
+	CUI          string   `json:"cui"`
+	Registration string   `json:"registration"`
+	Address      string   `json:"address"`
+	CAEN         []string `json:"caen"` // "6201 Software development (primary)"
+}
+
+// NewAnnualCompany takes the header from the company profile and its CAEN
+// codes, primary first
+func NewAnnualCompany(info *client.CompanyInfo, codes []client.CAENCode) *AnnualCompany {
+	c := &AnnualCompany{Name: info.Name, CUI: info.Code1, Registration: info.Code2, Address: info.Address}
+	for _, primary := range []bool{true, false} {
+		for _, code := range codes {
+			if code.IsPrimary != primary {
+				continue
+			}
+			s := strings.TrimSpace(code.Code + " " + code.Name)
+			if primary {
+				s += " (primary)"
+			}
+			c.CAEN = append(c.CAEN, s)
+		}
+	}
+	return c
+}
+
+// Bracket is one CAS or CASS threshold of the tax rules, in RON
+type Bracket struct {
+	Label   string `json:"label"`
+	Range   string `json:"range"` // net income range
+	Base    string `json:"base"`  // what the contribution is computed on
+	Applied bool   `json:"applied"`
+}
+
+// Annual is the yearly report meant for a partner or a bank: the company,
+// the year's totals and taxes as SOLO reports them, the monthly profit and
+// loss and the largest clients and suppliers
+type Annual struct {
+	Year      int            `json:"year"`
+	Generated time.Time      `json:"generated"`
+	Generator string         `json:"generator,omitempty"`
+	Company   *AnnualCompany `json:"company,omitempty"`
+
+	// Revenue and DeductibleExpenses are the SOLO summary the taxes are
+	// computed on
+	Revenue            money.Money         `json:"revenue"`
+	DeductibleExpenses money.Money         `json:"deductible_expenses"`
+	Taxes              *taxes.TaxBreakdown `json:"taxes"`
+	IncomeTaxPercent   float64             `json:"income_tax_percent"`
+	CAS                []Bracket           `json:"cas_brackets"`
+	CASS               []Bracket           `json:"cass_brackets"`
+
+	PnL       *PnL              `json:"pnl"`
+	Clients   *ClientReport     `json:"clients"`
+	Suppliers *ExpenseBreakdown `json:"suppliers"`
+}
+
+// BuildAnnual assembles the report for summary's year. The taxes follow
+// cfg with the declared extra income; the monthly profit and loss, clients
+// and suppliers come from the complete lists valued in RON
+func BuildAnnual(summary *client.Summary, revenues []client.Revenue, expenses []client.Expense,
+	extra []config.ExtraIncome, cfg *config.TaxConfig,
+	revenueRON func(client.Revenue) money.Money, expenseRON func(client.Expense) money.Money) *Annual {
+	year := summary.Year
+	breakdown := taxes.CalculateWithIncome(summary.TotalRevenues, summary.TotalDeductibleExpenses, extra, cfg)
+	return &Annual{
+		Year:               year,
+		Revenue:            summary.TotalRevenues,
+		DeductibleExpenses: summary.TotalDeductibleExpenses,
+		Taxes:              breakdown,
+		IncomeTaxPercent:   cfg.IncomeTaxPercent,
+		CAS:                brackets(cfg.CASThresholds, breakdown.SalariuMinimBrut, breakdown.NetIncome),
+		CASS:               brackets(cfg.CASSThresholds, breakdown.SalariuMinimBrut, breakdown.NetIncome),
+		PnL:                BuildPnL(revenues, expenses, year, ByMonth, revenueRON, expenseRON),
+		Clients:            BuildClientReport(revenues, year, annualTop, revenueRON),
+		Suppliers:          BuildExpenseBreakdown(expenses, year, GroupSupplier, annualTop, expenseRON),
+	}
+}
+
+// brackets describes the thresholds in RON, marking the one the net income
+// falls in the same way the tax calculation picks it
+func brackets(thresholds []config.TaxThreshold, smb, netIncome money.Money) []Bracket {
+	var out []Bracket
+	for _, t := range thresholds {
+		lo := smb.MulFloat(t.MinSalaries)
+		b := Bracket{Label: t.Label, Applied: taxes.InBracket(t, netIncome, smb)}
+		if t.MaxSalaries == 0 {
+			b.Range = "from " + wholeAmount(lo)
+		} else {
+			b.Range = wholeAmount(lo) + " – " + wholeAmount(smb.MulFloat(t.MaxSalaries))
+		}
+		switch {
+		case t.BaseSalaries == 0:
+			b.Base = "exempt"
+		case t.BaseSalaries < 0:
+			b.Base = "net income"
+		default:
+			b.Base = fmt.Sprintf("%s salaries (%s)", strconv.FormatFloat(t.BaseSalaries, 'f', -1, 64), wholeAmount(smb.MulFloat(t.BaseSalaries)))
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// FormatAmount groups the lei by thousands: 1,234,567.89
+func FormatAmount(m money.Money) string {
+	s := m.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String() + "." + frac
+}
+
+// wholeAmount is FormatAmount without the bani when there are none
+func wholeAmount(m money.Money) string {
+	return strings.TrimSuffix(FormatAmount(m), ".00")
+}
+
+// compactAmount labels a chart axis: 900, 1.5k, 20k, 1.2M
+func compactAmount(lei float64) string {
+	switch {
+	case lei >= 1e6:
+		return strconv.FormatFloat(lei/1e6, 'f', -1, 64) + "M"
+	case lei >= 1e3:
+		return strconv.FormatFloat(lei/1e3, 'f', -1, 64) + "k"
+	}
+	return strconv.FormatFloat(lei, 'f', -1, 64)
+}
@@ -0,0 +1,122 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+var annualTemplate = template.Must(template.New("annual").Funcs(template.FuncMap{
+	"svg": func(c *chart) template.HTML { return c.svg() },
+	"num": func(t *table, col int) bool { return col < len(t.Right) && t.Right[col] },
+	"rowClass": func(t *table, row int) string {
+		switch {
+		case t.Total && row == len(t.Rows)-1:
+			return "total"
+		case t.Mark[row]:
+			return "mark"
+		}
+		return ""
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}{{with .Company}} – {{.Name}}{{end}}</title>
+<style>
+@page { size: A4; margin: 16mm; }
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 820px; color: #111827; font-size: 14px; }
+header { border-bottom: 2px solid #2563eb; padding-bottom: 0.8em; margin-bottom: 1.2em; }
+header h1 { margin: 0; font-size: 1.6em; }
+header p { margin: 0.15em 0; color: #4b5563; }
+header .title { float: right; text-align: right; }
+header .title strong { display: block; font-size: 1.3em; color: #2563eb; }
+.figures { display: grid; grid-template-columns: repeat(4, 1fr); gap: 0.6em; margin: 1em 0 1.5em; }
+.figures div { background: #f3f4f6; border-radius: 6px; padding: 0.6em 0.8em; }
+.figures span { display: block; font-size: 0.8em; color: #4b5563; }
+.figures b { font-size: 1.05em; font-variant-numeric: tabular-nums; }
+h2 { font-size: 1.1em; margin: 1.6em 0 0.4em; break-after: avoid; }
+section { break-inside: avoid; }
+table { border-collapse: collapse; width: 100%; font-size: 0.92em; }
+th, td { padding: 0.3em 0.6em; border-bottom: 1px solid #e5e7eb; text-align: left; }
+th { background: #f3f4f6; }
+.num { text-align: right; font-variant-numeric: tabular-nums; white-space: nowrap; }
+tr.total td { font-weight: bold; border-top: 1.5px solid #111827; }
+tr.mark td { background: #dbeafe; font-weight: bold; }
+.note { color: #4b5563; font-size: 0.85em; margin: 0.4em 0; }
+.pair { display: grid; grid-template-columns: 1fr 1fr; gap: 1.2em; }
+svg { display: block; width: 100%; height: auto; }
+footer { margin-top: 2em; color: #6b7280; font-size: 0.8em; border-top: 1px solid #e5e7eb; padding-top: 0.6em; }
+</style>
+</head>
+<body>
+<header>
+<div class="title"><strong>{{.Title}}</strong>1 January – 31 December {{.Year}}</div>
+{{with .Company}}<h1>{{.Name}}</h1>{{end}}
+{{range .CompanyLines}}<p>{{.}}</p>
+{{end}}</header>
+<div class="figures">
+{{range .Figures}}<div><span>{{.Label}}</span><b>{{.Value}}</b></div>
+{{end}}</div>
+{{range .Sections}}{{if .Chart}}<section>
+<h2>{{.Chart.Title}}</h2>
+{{svg .Chart}}
+</section>
+{{else if .Table}}{{template "table" .Table}}{{else}}<div class="pair">
+{{range .Pair}}{{template "table" .}}{{end}}</div>
+{{end}}{{end}}<footer>{{.Footer}}</footer>
+</body>
+</html>
+{{define "table"}}{{$t := .}}<section>
+<h2>{{.Title}}</h2>
+<table>
+<thead><tr>{{range $i, $h := .Head}}<th{{if num $t $i}} class="num"{{end}}>{{$h}}</th>{{end}}</tr></thead>
+<tbody>
+{{range $r, $row := .Rows}}<tr{{with rowClass $t $r}} class="{{.}}"{{end}}>{{range $i, $c := $row}}<td{{if num $t $i}} class="num"{{end}}>{{$c}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{with .Note}}<p class="note">{{.}}</p>
+{{end}}</section>
+{{end}}`))
+
+// HTML writes the report as a standalone page with inline SVG charts. It
+// prints to A4 from a browser
+func (a *Annual) HTML(w io.Writer) error {
+	return annualTemplate.Execute(w, struct {
+		Title        string
+		Year         int
+		Company      *AnnualCompany
+		CompanyLines []string
+		Figures      []figure
+		Sections     []section
+		Footer       string
+	}{a.title(), a.Year, a.Company, a.companyLines(), a.figures(), a.sections(), a.footer()})
+}
+
+// svg draws the chart; text is escaped since client names end up in it
+func (c *chart) svg() template.HTML {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %s %s" font-family="Helvetica, Arial, sans-serif" font-size="%d" role="img" aria-label="%s">`,
+		svgNum(c.Width), svgNum(c.Height), chartFontSize, template.HTMLEscapeString(c.Title))
+	for _, y := range c.Grid {
+		fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s"/>`, svgNum(c.Axis[0]), svgNum(y), svgNum(c.Axis[2]), svgNum(y), gridColor)
+	}
+	for _, bar := range c.Bars {
+		fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`, svgNum(bar.X), svgNum(bar.Y), svgNum(bar.W), svgNum(bar.H), bar.Color)
+	}
+	fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s"/>`, svgNum(c.Axis[0]), svgNum(c.Axis[1]), svgNum(c.Axis[2]), svgNum(c.Axis[3]), textColor)
+	for _, t := range c.Texts {
+		fmt.Fprintf(&b, `<text x="%s" y="%s" text-anchor="%s" fill="%s">%s</text>`, svgNum(t.X), svgNum(t.Y), t.Anchor, textColor, template.HTMLEscapeString(t.Text))
+	}
+	for _, l := range c.Legend {
+		fmt.Fprintf(&b, `<rect x="%s" y="%s" width="9" height="9" fill="%s"/><text x="%s" y="%s" fill="%s">%s</text>`,
+			svgNum(l.X), svgNum(l.Y-8), l.Color, svgNum(l.X+13), svgNum(l.Y), textColor, template.HTMLEscapeString(l.Text))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func svgNum(f float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", f), "0"), ".")
+}
@@ -0,0 +1,239 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"solo-cli/pdf"
+)
+
+const (
+	pdfMargin   = 40.0
+	pdfWidth    = pdf.A4Width - 2*pdfMargin
+	pdfBottom   = pdf.A4Height - 50 // the footer goes below
+	pdfFontSize = 8.5
+	pdfRow      = 14.0
+	pdfPad      = 5.0
+)
+
+var (
+	pdfText   = pdf.RGB(0x11, 0x18, 0x27)
+	pdfMuted  = hexColor(textColor)
+	pdfAccent = hexColor(revenueColor)
+	pdfShade  = pdf.RGB(0xf3, 0xf4, 0xf6)
+	pdfMark   = pdf.RGB(0xdb, 0xea, 0xfe)
+	pdfRule   = hexColor(gridColor)
+)
+
+// hexColor parses a #rrggbb chart color
+func hexColor(s string) pdf.Color {
+	v, _ := strconv.ParseUint(s[1:], 16, 32)
+	return pdf.RGB(uint8(v>>16), uint8(v>>8), uint8(v))
+}
+
+// pdfLayout flows the report down the pages
+type pdfLayout struct {
+	doc   *pdf.Document
+	pages []*pdf.Page
+	page  *pdf.Page
+	y     float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = l.doc.AddPage()
+	l.pages = append(l.pages, l.page)
+	l.y = pdfMargin
+}
+
+// need starts a new page unless height fits on this one
+func (l *pdfLayout) need(height float64) {
+	if l.y+height > pdfBottom {
+		l.newPage()
+	}
+}
+
+// PDF writes the report as an A4 document with the same sections as the
+// HTML page, the charts drawn as vector graphics
+func (a *Annual) PDF(w io.Writer) error {
+	doc := pdf.New()
+	doc.Title = a.title()
+	doc.Creator = a.Generator
+	doc.Created = a.Generated
+	if a.Company != nil {
+		doc.Title += " – " + a.Company.Name
+		doc.Author = a.Company.Name
+	}
+	l := &pdfLayout{doc: doc}
+	l.newPage()
+
+	a.pdfHeader(l)
+	a.pdfFigures(l)
+	for _, s := range a.sections() {
+		switch {
+		case s.Chart != nil:
+			l.need(22 + s.Chart.Height)
+			l.page.Text(pdfMargin, l.y+12, pdf.Bold, 11, pdfText, s.Chart.Title)
+			l.y += 22
+			drawChart(l.page, s.Chart, pdfMargin, l.y)
+			l.y += s.Chart.Height + 10
+		case s.Table != nil:
+			drawTable(l, s.Table, pdfMargin, pdfWidth)
+		default:
+			half := (pdfWidth - 16) / 2
+			l.need(max(tableHeight(s.Pair[0]), tableHeight(s.Pair[1])))
+			top := l.y
+			drawTable(l, s.Pair[0], pdfMargin, half)
+			left := l.y
+			l.y = top
+			drawTable(l, s.Pair[1], pdfMargin+half+16, half)
+			l.y = max(l.y, left)
+		}
+	}
+
+	for i, p := range l.pages {
+		y := pdf.A4Height - 28
+		p.Line(pdfMargin, y-10, pdfMargin+pdfWidth, y-10, 0.5, pdfRule)
+		p.Text(pdfMargin, y, pdf.Regular, 7, pdfMuted, pdf.Fit(pdf.Regular, 7, a.footer(), pdfWidth-60))
+		p.TextRight(pdfMargin+pdfWidth, y, pdf.Regular, 7, pdfMuted, fmt.Sprintf("Page %d of %d", i+1, len(l.pages)))
+	}
+	return doc.Write(w)
+}
+
+func (a *Annual) pdfHeader(l *pdfLayout) {
+	p := l.page
+	right := pdfMargin + pdfWidth
+	p.TextRight(right, l.y+14, pdf.Bold, 15, pdfAccent, a.title())
+	p.TextRight(right, l.y+28, pdf.Regular, 9, pdfMuted, fmt.Sprintf("1 January – 31 December %d", a.Year))
+
+	if a.Company != nil {
+		p.Text(pdfMargin, l.y+16, pdf.Bold, 17, pdfText, pdf.Fit(pdf.Bold, 17, a.Company.Name, pdfWidth-180))
+		l.y += 22
+	}
+	for _, line := range a.companyLines() {
+		l.y += 12
+		p.Text(pdfMargin, l.y, pdf.Regular, 9, pdfMuted, pdf.Fit(pdf.Regular, 9, line, pdfWidth-180))
+	}
+	l.y = max(l.y, pdfMargin+28) + 10
+	p.Line(pdfMargin, l.y, right, l.y, 1.5, pdfAccent)
+	l.y += 14
+}
+
+func (a *Annual) pdfFigures(l *pdfLayout) {
+	const cols, gap, height = 4, 8.0, 34.0
+	width := (pdfWidth - gap*(cols-1)) / cols
+	figures := a.figures()
+	for i, f := range figures {
+		x := pdfMargin + float64(i%cols)*(width+gap)
+		y := l.y + float64(i/cols)*(height+gap)
+		l.page.Rect(x, y, width, height, pdfShade)
+		l.page.Text(x+7, y+13, pdf.Regular, 7.5, pdfMuted, f.Label)
+		l.page.Text(x+7, y+27, pdf.Bold, 10, pdfText, pdf.Fit(pdf.Bold, 10, f.Value, width-14))
+	}
+	rows := (len(figures) + cols - 1) / cols
+	l.y += float64(rows)*(height+gap) + 8
+}
+
+func tableHeight(t *table) float64 {
+	h := 22 + pdfRow*float64(len(t.Rows)+1) + 10
+	if t.Note != "" {
+		h += 14
+	}
+	return h
+}
+
+// columnWidths sizes the columns to their content and gives the first,
+// text column whatever is left, shrinking it when the table is too wide
+func columnWidths(t *table, width float64) []float64 {
+	widths := make([]float64, len(t.Head))
+	for i, h := range t.Head {
+		widths[i] = pdf.TextWidth(pdf.Bold, pdfFontSize, h) + 2*pdfPad
+	}
+	for _, row := range t.Rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], pdf.TextWidth(pdf.Bold, pdfFontSize, c)+2*pdfPad)
+		}
+	}
+	rest := width
+	for _, w := range widths[1:] {
+		rest -= w
+	}
+	widths[0] = max(rest, 40)
+	return widths
+}
+
+// drawTable draws t at l.y, breaking to a new page between rows and
+// repeating the header there
+func drawTable(l *pdfLayout, t *table, x, width float64) {
+	l.need(22 + pdfRow*3)
+	l.page.Text(x, l.y+12, pdf.Bold, 11, pdfText, t.Title)
+	l.y += 20
+	widths := columnWidths(t, width)
+
+	row := func(cells []string, font pdf.Font, fill *pdf.Color) {
+		if fill != nil {
+			l.page.Rect(x, l.y, width, pdfRow, *fill)
+		}
+		cx := x
+		for i, c := range cells {
+			w := widths[i]
+			c = pdf.Fit(font, pdfFontSize, c, w-2*pdfPad)
+			if i < len(t.Right) && t.Right[i] {
+				l.page.TextRight(cx+w-pdfPad, l.y+10, font, pdfFontSize, pdfText, c)
+			} else {
+				l.page.Text(cx+pdfPad, l.y+10, font, pdfFontSize, pdfText, c)
+			}
+			cx += w
+		}
+		l.y += pdfRow
+	}
+	header := func() { row(t.Head, pdf.Bold, &pdfShade) }
+	header()
+	for i, cells := range t.Rows {
+		if l.y+pdfRow > pdfBottom {
+			l.newPage()
+			header()
+		}
+		total := t.Total && i == len(t.Rows)-1
+		switch {
+		case total:
+			l.page.Line(x, l.y, x+width, l.y, 1, pdfText)
+			row(cells, pdf.Bold, nil)
+		case t.Mark[i]:
+			row(cells, pdf.Bold, &pdfMark)
+		default:
+			row(cells, pdf.Regular, nil)
+			l.page.Line(x, l.y, x+width, l.y, 0.4, pdfRule)
+		}
+	}
+	if t.Note != "" {
+		l.y += 12
+		l.page.Text(x, l.y, pdf.Regular, 7.5, pdfMuted, pdf.Fit(pdf.Regular, 7.5, t.Note, width))
+	}
+	l.y += 14
+}
+
+// drawChart paints a laid out chart with its top left corner at x, y
+func drawChart(p *pdf.Page, c *chart, x, y float64) {
+	for _, gy := range c.Grid {
+		p.Line(x+c.Axis[0], y+gy, x+c.Axis[2], y+gy, 0.5, pdfRule)
+	}
+	for _, b := range c.Bars {
+		p.Rect(x+b.X, y+b.Y, b.W, b.H, hexColor(b.Color))
+	}
+	p.Line(x+c.Axis[0], y+c.Axis[1], x+c.Axis[2], y+c.Axis[3], 0.6, pdfMuted)
+	for _, t := range c.Texts {
+		tx := x + t.X
+		switch t.Anchor {
+		case "end":
+			tx -= pdf.TextWidth(pdf.Regular, chartFontSize, t.Text)
+		case "middle":
+			tx -= pdf.TextWidth(pdf.Regular, chartFontSize, t.Text) / 2
+		}
+		p.Text(tx, y+t.Y, pdf.Regular, chartFontSize, pdfMuted, t.Text)
+	}
+	for _, l := range c.Legend {
+		p.Rect(x+l.X, y+l.Y-8, 9, 9, hexColor(l.Color))
+		p.Text(x+l.X+13, y+l.Y, pdf.Regular, chartFontSize, pdfMuted, l.Text)
+	}
+}
@@ -0,0 +1,157 @@
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/money"
+)
+
+func TestFormatAmount(t *testing.T) {
+	for m, want := range map[money.Money]string{
+		0:                           "0.00",
+		money.MustParse("999.5"):    "999.50",
+		money.MustParse("1234.5"):   "1,234.50",
+		money.MustParse("1234567"):  "1,234,567.00",
+		money.MustParse("-98765.4"): "-98,765.40",
+	} {
+		if got := FormatAmount(m); got != want {
+			t.Errorf("FormatAmount(%s) = %q, want %q", m, got, want)
+		}
+	}
+	if got := wholeAmount(48600 * money.Lei); got != "48,600" {
+		t.Errorf("wholeAmount = %q", got)
+	}
+	if compactAmount(1500) != "1.5k" || compactAmount(2e6) != "2M" || compactAmount(900) != "900" {
+		t.Errorf("compactAmount = %s %s %s", compactAmount(1500), compactAmount(2e6), compactAmount(900))
+	}
+}
+
+func annualFixture() *Annual {
+	revenues := []client.Revenue{
+		{ClientName: "ACME <Corp> & Sons", IssueDate: "2025-01-15", Total: 60000 * money.Lei},
+		{ClientName: "Globex", IssueDate: "2025-06-10", Total: 40000 * money.Lei},
+		{ClientName: "Old Client", IssueDate: "2024-06-10", Total: 999 * money.Lei},
+	}
+	expenses := []client.Expense{
+		{SupplierName: "Hosting SRL", PurchaseDate: "2025-03-05T00:00:00", Total: 10000 * money.Lei, Deductibility: "100%"},
+	}
+	summary := &client.Summary{Year: 2025, TotalRevenues: 100000 * money.Lei, TotalDeductibleExpenses: 10000 * money.Lei}
+	a := BuildAnnual(summary, revenues, expenses, nil, config.DefaultTaxConfig(), client.Revenue.TotalRON, client.Expense.TotalRON)
+	a.Generated = time.Date(2026, 1, 20, 10, 0, 0, 0, time.UTC)
+	a.Generator = "solo-cli test"
+	a.Company = NewAnnualCompany(&client.CompanyInfo{Name: "Test PFA", Code1: "11111111"},
+		[]client.CAENCode{{Code: "6202", Name: "Consultancy"}, {Code: "6201", Name: "Software", IsPrimary: true}})
+	return a
+}
+
+func TestBuildAnnual(t *testing.T) {
+	a := annualFixture()
+
+	if a.Year != 2025 || a.Taxes.NetIncome != 90000*money.Lei {
+		t.Fatalf("year %d, net income %s", a.Year, a.Taxes.NetIncome)
+	}
+	if a.PnL.Total.Revenue != 100000*money.Lei || a.PnL.Rows[0].Revenue != 60000*money.Lei {
+		t.Errorf("P&L total %s, January %s", a.PnL.Total.Revenue, a.PnL.Rows[0].Revenue)
+	}
+	if a.Clients.Invoices != 2 || len(a.Suppliers.Groups) != 1 {
+		t.Errorf("clients %+v, suppliers %+v", a.Clients, a.Suppliers.Groups)
+	}
+	if len(a.Company.CAEN) != 2 || a.Company.CAEN[0] != "6201 Software (primary)" {
+		t.Errorf("CAEN = %q, want the primary code first", a.Company.CAEN)
+	}
+
+	// Exactly one bracket of each contribution applies, the one the tax
+	// calculation picked
+	for name, list := range map[string][]Bracket{"CAS": a.CAS, "CASS": a.CASS} {
+		var applied []string
+		for _, b := range list {
+			if b.Applied {
+				applied = append(applied, b.Label)
+			}
+		}
+		want := a.Taxes.CAS.Label
+		if name == "CASS" {
+			want = a.Taxes.CASS.Label
+		}
+		if len(applied) != 1 || applied[0] != want {
+			t.Errorf("%s applied brackets = %q, want %q", name, applied, want)
+		}
+	}
+}
+
+func TestAnnualHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := annualFixture().HTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<title>Annual Report 2025 – Test PFA</title>",
+		"<p>CUI 11111111</p>",
+		"<p>CAEN 6201 Software (primary)</p>",
+		"<b>100,000.00 RON</b>",
+		"<h2>Profit and loss by month</h2>",
+		"<h2>Tax breakdown</h2>",
+		"<h2>CAS brackets</h2>",
+		"<h2>Top suppliers</h2>",
+		`<tr class="total">`,
+		`<tr class="mark">`,
+		"<svg xmlns=",
+		"ACME &lt;Corp&gt; &amp; Sons",
+		"Generated 2026-01-20 by solo-cli test",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<Corp>") {
+		t.Error("client name not escaped")
+	}
+	if strings.Count(out, "<svg") != 2 {
+		t.Errorf("want the monthly and client charts, got %d", strings.Count(out, "<svg"))
+	}
+}
+
+func TestAnnualPDF(t *testing.T) {
+	var first, second bytes.Buffer
+	if err := annualFixture().PDF(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := annualFixture().PDF(&second); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("the same report gave different PDFs")
+	}
+	out := first.String()
+	if !strings.HasPrefix(out, "%PDF-1.4") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatalf("not a PDF: %.40q", out)
+	}
+	// The en dash in the title needs the UTF-16 form
+	if !strings.Contains(out, "/Title <FEFF0041006E006E00750061006C") {
+		t.Errorf("info has no UTF-16 title")
+	}
+
+	var text strings.Builder
+	for _, m := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllStringSubmatch(out, -1) {
+		zr, err := zlib.NewReader(strings.NewReader(m[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(zr)
+		text.Write(b)
+	}
+	for _, want := range []string{"(Test PFA)", "(Tax breakdown)", "(100,000.00)", "(Hosting SRL)", "(Page 1 of "} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("PDF content missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"solo-cli/money"
+	"solo-cli/pdf"
+)
+
+// The HTML and PDF renderings of the annual report draw the same figures,
+// tables and charts, laid out here once
+
+// figure is one key number of the report
+type figure struct {
+	Label, Value string
+}
+
+// table is a titled table; numeric columns are right aligned
+type table struct {
+	Title string
+	Note  string
+	Head  []string
+	Right []bool
+	Rows  [][]string
+	Mark  []bool // highlighted rows, e.g. the bracket that applies
+	Total bool   // the last row is a total
+}
+
+func (t *table) add(marked bool, cells ...string) {
+	t.Rows = append(t.Rows, cells)
+	t.Mark = append(t.Mark, marked)
+}
+
+func (a *Annual) title() string {
+	return fmt.Sprintf("Annual Report %d", a.Year)
+}
+
+func (a *Annual) footer() string {
+	s := "Generated " + a.Generated.Format("2006-01-02")
+	if a.Generator != "" {
+		s += " by " + a.Generator
+	}
+	return s + ". Amounts in RON; foreign currency at the local amount or the BNR rate of the issue date."
+}
+
+// companyLines are the header lines under the company name
+func (a *Annual) companyLines() []string {
+	if a.Company == nil {
+		return nil
+	}
+	var lines []string
+	id := ""
+	if a.Company.CUI != "" {
+		id = "CUI " + a.Company.CUI
+	}
+	if a.Company.Registration != "" {
+		if id != "" {
+			id += " · "
+		}
+		id += "Reg. " + a.Company.Registration
+	}
+	for _, l := range []string{id, a.Company.Address} {
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	for _, c := range a.Company.CAEN {
+		lines = append(lines, "CAEN "+c)
+	}
+	return lines
+}
+
+func ron(m money.Money) string {
+	return FormatAmount(m) + " RON"
+}
+
+func percent(f float64) string {
+	return strconv.FormatFloat(f, 'f', 1, 64) + "%"
+}
+
+func (a *Annual) figures() []figure {
+	t := a.Taxes
+	return []figure{
+		{"Revenue", ron(a.Revenue)},
+		{"Deductible expenses", ron(a.DeductibleExpenses)},
+		{"Net income", ron(t.NetIncome)},
+		{"Taxes and contributions", ron(t.TotalTaxes)},
+		{"Net after tax", ron(t.NetAfterTax)},
+		{"Effective tax rate", percent(t.EffectiveRate)},
+		{"Invoices issued", fmt.Sprintf("%d to %d clients", a.Clients.Invoices, a.Clients.ThisYear().Clients)},
+		{"Expenses recorded", strconv.Itoa(a.Suppliers.Total.Count)},
+	}
+}
+
+var monthNames = [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+func (a *Annual) pnlTable() table {
+	t := table{
+		Title: "Profit and loss by month",
+		Note:  "From the invoices issued and the expenses bought in each month.",
+		Head:  []string{"Month", "Revenue", "Deductible", "Non-deductible", "Net income", "Net YTD"},
+		Right: []bool{false, true, true, true, true, true},
+		Total: true,
+	}
+	for i, r := range a.PnL.Rows {
+		t.add(false, monthNames[i], FormatAmount(r.Revenue), FormatAmount(r.Deductible), FormatAmount(r.NonDeductible), FormatAmount(r.Net), FormatAmount(r.YTD))
+	}
+	r := a.PnL.Total
+	t.add(false, "Total", FormatAmount(r.Revenue), FormatAmount(r.Deductible), FormatAmount(r.NonDeductible), FormatAmount(r.Net), FormatAmount(r.YTD))
+	return t
+}
+
+func (a *Annual) taxTable() table {
+	b := a.Taxes
+	t := table{
+		Title: "Tax breakdown",
+		Note:  fmt.Sprintf("Net income of %s is %.1f times the minimum gross salary of %s.", ron(b.NetIncome), b.SalariesCount, ron(b.SalariuMinimBrut)),
+		Head:  []string{"Tax", "Bracket", "Base", "Amount"},
+		Right: []bool{false, false, true, true},
+		Total: true,
+	}
+	t.add(false, "CAS ("+percent(b.CAS.Percentage)+")", b.CAS.Label, FormatAmount(b.CAS.Base), FormatAmount(b.CAS.Amount))
+	t.add(false, "CASS ("+percent(b.CASS.Percentage)+")", b.CASS.Label, FormatAmount(b.CASS.Base), FormatAmount(b.CASS.Amount))
+	t.add(false, "Income tax ("+percent(a.IncomeTaxPercent)+")", "", FormatAmount(max(b.NetIncome-b.CAS.Amount-b.CASS.Amount, 0)), FormatAmount(b.IncomeTax))
+	if len(b.OtherIncome) > 0 {
+		for _, o := range b.OtherIncome {
+			t.add(false, "Tax on "+o.Category.Label, "", FormatAmount(o.Taxable), FormatAmount(o.Tax))
+		}
+		t.add(false, "CASS on other income", b.OtherCASS.Label, FormatAmount(b.OtherCASS.Base), FormatAmount(b.OtherCASS.Amount))
+	}
+	t.add(false, "Total", "", "", FormatAmount(b.TotalTaxes))
+	return t
+}
+
+func bracketTable(title string, brackets []Bracket) table {
+	t := table{
+		Title: title,
+		Head:  []string{"Bracket", "Net income", "Base"},
+		Right: []bool{false, true, true},
+	}
+	for _, b := range brackets {
+		t.add(b.Applied, b.Label, b.Range, b.Base)
+	}
+	return t
+}
+
+func (a *Annual) clientsTable() table {
+	r := a.Clients
+	this := r.ThisYear()
+	t := table{
+		Title: "Top clients",
+		Note: fmt.Sprintf("Concentration: HHI %.0f (%s), largest client %s of revenue. %d clients, %d new this year.",
+			r.HHI, r.Concentration(), percent(r.TopShare), this.Clients, this.New),
+		Head:  []string{"Client", "Invoices", "Revenue", "Share"},
+		Right: []bool{false, true, true, true},
+		Total: true,
+	}
+	rows := append([]ClientRevenue{}, r.Clients...)
+	if r.Other != nil {
+		rows = append(rows, *r.Other)
+	}
+	for _, c := range rows {
+		t.add(false, c.Name, strconv.Itoa(c.Invoices), FormatAmount(c.Revenue), percent(c.Share))
+	}
+	t.add(false, "Total", strconv.Itoa(r.Invoices), FormatAmount(r.Revenue), percent(r.TotalShare()))
+	return t
+}
+
+func (a *Annual) suppliersTable() table {
+	b := a.Suppliers
+	t := table{
+		Title: "Top suppliers",
+		Head:  []string{"Supplier", "Expenses", "Amount", "Share"},
+		Right: []bool{false, true, true, true},
+		Total: true,
+	}
+	rows := append([]ExpenseGroup{}, b.Groups...)
+	if b.Other != nil {
+		rows = append(rows, *b.Other)
+	}
+	for _, g := range rows {
+		t.add(false, g.Name, strconv.Itoa(g.Count), FormatAmount(g.Amount), percent(g.Share))
+	}
+	share := 0.0
+	if b.Total.Amount > 0 {
+		share = 100
+	}
+	t.add(false, "Total", strconv.Itoa(b.Total.Count), FormatAmount(b.Total.Amount), percent(share))
+	return t
+}
+
+// section is one block of the report body in order
+type section struct {
+	Table *table
+	Chart *chart
+	Pair  [2]*table // two tables side by side
+}
+
+func (a *Annual) sections() []section {
+	pnl, tax := a.pnlTable(), a.taxTable()
+	cas, cass := bracketTable("CAS brackets", a.CAS), bracketTable("CASS brackets", a.CASS)
+	clients, suppliers := a.clientsTable(), a.suppliersTable()
+	monthly, shares := a.monthlyChart(), a.clientChart()
+
+	out := []section{{Chart: &monthly}, {Table: &pnl}, {Table: &tax}, {Pair: [2]*table{&cas, &cass}}}
+	if a.Clients.Invoices > 0 {
+		out = append(out, section{Chart: &shares}, section{Table: &clients})
+	}
+	if a.Suppliers.Total.Count > 0 {
+		out = append(out, section{Table: &suppliers})
+	}
+	return out
+}
+
+// Charts
+
+// chartBar is a bar in chart coordinates, top left origin
+type chartBar struct {
+	X, Y, W, H float64
+	Color      string
+}
+
+type chartText struct {
+	X, Y   float64
+	Text   string
+	Anchor string // start, middle or end
+}
+
+// chart is a laid out chart both renderings paint as they are
+type chart struct {
+	Title         string
+	Width, Height float64
+	Bars          []chartBar
+	Grid          []float64 // y of the horizontal grid lines
+	Axis          [4]float64
+	Texts         []chartText
+	Legend        []legendItem
+}
+
+type legendItem struct {
+	X, Y        float64
+	Text, Color string
+}
+
+// chartFontSize is the size of the chart labels, which are fitted with the
+// PDF font metrics so both renderings cut long names alike
+const chartFontSize = 9
+
+const (
+	chartWidth  = 515
+	chartHeight = 200
+)
+
+var (
+	revenueColor = "#2563eb"
+	expenseColor = "#f97316"
+	gridColor    = "#e5e7eb"
+	textColor    = "#4b5563"
+)
+
+// niceStep rounds a tick step up to 1, 2 or 5 times a power of ten
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if raw <= m*exp {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+// monthlyChart sets each month's revenue next to its expenses, deductible
+// or not
+func (a *Annual) monthlyChart() chart {
+	c := chart{Title: "Revenue and expenses by month", Width: chartWidth, Height: chartHeight}
+	left, top, right, bottom := 44.0, 24.0, c.Width-4, c.Height-18
+	c.Axis = [4]float64{left, bottom, right, bottom}
+
+	peak := 0.0
+	for _, r := range a.PnL.Rows {
+		peak = max(peak, r.Revenue.Float64(), (r.Deductible + r.NonDeductible).Float64())
+	}
+	step := niceStep(peak / 4)
+	ceiling := step * math.Max(1, math.Ceil(peak/step))
+	scale := (bottom - top) / ceiling
+	for v := step; v <= ceiling+step/2; v += step {
+		y := bottom - v*scale
+		c.Grid = append(c.Grid, y)
+		c.Texts = append(c.Texts, chartText{left - 4, y + 3, compactAmount(v), "end"})
+	}
+	c.Texts = append(c.Texts, chartText{left - 4, bottom + 3, "0", "end"})
+
+	slot := (right - left) / 12
+	bar := slot * 0.34
+	for i, r := range a.PnL.Rows {
+		x := left + float64(i)*slot + slot*0.14
+		for j, v := range []struct {
+			value money.Money
+			color string
+		}{{r.Revenue, revenueColor}, {r.Deductible + r.NonDeductible, expenseColor}} {
+			h := math.Max(v.value.Float64(), 0) * scale
+			if h > 0 {
+				c.Bars = append(c.Bars, chartBar{x + float64(j)*bar, bottom - h, bar, h, v.color})
+			}
+		}
+		c.Texts = append(c.Texts, chartText{left + (float64(i)+0.5)*slot, c.Height - 4, monthNames[i], "middle"})
+	}
+	c.Legend = []legendItem{{left, 10, "Revenue", revenueColor}, {left + 80, 10, "Expenses", expenseColor}}
+	return c
+}
+
+// clientChart shows the top clients' share of revenue as horizontal bars
+func (a *Annual) clientChart() chart {
+	rows := append([]ClientRevenue{}, a.Clients.Clients...)
+	if a.Clients.Other != nil {
+		rows = append(rows, *a.Clients.Other)
+	}
+	const rowHeight, label = 18.0, 170.0
+	c := chart{Title: "Share of revenue by client", Width: chartWidth, Height: float64(len(rows))*rowHeight + 6}
+	c.Axis = [4]float64{label, 0, label, c.Height}
+	width := c.Width - label - 50
+	for i, r := range rows {
+		y := float64(i)*rowHeight + 3
+		c.Bars = append(c.Bars, chartBar{label, y, math.Max(r.Share, 0) / 100 * width, rowHeight - 6, revenueColor})
+		c.Texts = append(c.Texts,
+			chartText{label - 6, y + rowHeight - 9, pdf.Fit(pdf.Regular, chartFontSize, r.Name, label-10), "end"},
+			chartText{label + math.Max(r.Share, 0)/100*width + 4, y + rowHeight - 9, percent(r.Share), "start"})
+	}
+	return c
+}
@@ -0,0 +1,214 @@
+package report
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+// ClientRevenue is one client's invoicing in the report year, in RON
+type ClientRevenue struct {
+	Name       string      `json:"name"`
+	Invoices   int         `json:"invoices"`
+	Revenue    money.Money `json:"revenue"`
+	Share      float64     `json:"share_percent"`
+	AvgInvoice money.Money `json:"average_invoice"`
+	Since      int         `json:"first_invoiced_year,omitempty"` // 0 for the combined Other line
+	New        bool        `json:"new"`                           // First invoiced in the report year
+}
+
+// ClientYear counts the clients invoiced in a year, split into new (first
+// invoiced that year) and returning
+type ClientYear struct {
+	Year      int         `json:"year"`
+	Clients   int         `json:"clients"`
+	New       int         `json:"new"`
+	Returning int         `json:"returning"`
+	Revenue   money.Money `json:"revenue"`
+}
+
+// ClientReport is how a year's revenue spreads across clients
+type ClientReport struct {
+	Year       int             `json:"year"`
+	Clients    []ClientRevenue `json:"clients"`
+	Other      *ClientRevenue  `json:"other,omitempty"` // The clients past the top N, combined
+	Invoices   int             `json:"invoices"`
+	Revenue    money.Money     `json:"revenue"`
+	AvgInvoice money.Money     `json:"average_invoice"`
+	// HHI is the Herfindahl-Hirschman index, the sum of the squared client
+	// shares in percent: 10000 is a single client
+	HHI      float64      `json:"hhi"`
+	TopShare float64      `json:"top_client_share_percent"`
+	ByYear   []ClientYear `json:"by_year"` // Every year with invoices up to Year
+}
+
+// Concentration rates the HHI with the usual competition authority bands
+func (r *ClientReport) Concentration() string {
+	switch {
+	case r.HHI > 2500:
+		return "highly concentrated"
+	case r.HHI >= 1500:
+		return "moderately concentrated"
+	}
+	return "diversified"
+}
+
+// ThisYear is the new vs returning split of the report year
+func (r *ClientReport) ThisYear() ClientYear {
+	for _, y := range r.ByYear {
+		if y.Year == r.Year {
+			return y
+		}
+	}
+	return ClientYear{Year: r.Year}
+}
+
+// issueYear is the year of an ISO date, 0 when malformed
+func issueYear(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	y, err := strconv.Atoi(date[:4])
+	if err != nil {
+		return 0
+	}
+	return y
+}
+
+// BuildClientReport totals the invoices issued in year per client, largest
+// first. Client names are matched case-insensitively and cancelled invoices
+// are left out. The new vs returning split looks at every earlier year.
+// top > 0 keeps the largest top clients and combines the rest into Other;
+// the concentration index always covers every client
+func BuildClientReport(revenues []client.Revenue, year, top int, ron func(client.Revenue) money.Money) *ClientReport {
+	type clientYears struct {
+		name  string
+		first int
+		years map[int]bool
+	}
+	seen := map[string]*clientYears{}
+	clients := map[string]*ClientRevenue{}
+	var order []string
+	years := map[int]*ClientYear{}
+	r := &ClientReport{Year: year}
+
+	for _, inv := range revenues {
+		if inv.Status != nil && inv.Status.IsCancelled {
+			continue
+		}
+		y := issueYear(inv.IssueDate)
+		if y == 0 || y > year {
+			continue
+		}
+		name := strings.TrimSpace(inv.ClientName)
+		key := strings.ToLower(name)
+		c, ok := seen[key]
+		if !ok {
+			c = &clientYears{name: name, first: y, years: map[int]bool{}}
+			seen[key] = c
+		}
+		c.first = min(c.first, y)
+		c.years[y] = true
+
+		value := ron(inv)
+		if years[y] == nil {
+			years[y] = &ClientYear{Year: y}
+		}
+		years[y].Revenue += value
+		if y != year {
+			continue
+		}
+
+		cr, ok := clients[key]
+		if !ok {
+			cr = &ClientRevenue{Name: c.name}
+			clients[key] = cr
+			order = append(order, key)
+		}
+		cr.Invoices++
+		cr.Revenue += value
+		r.Invoices++
+		r.Revenue += value
+	}
+
+	for _, c := range seen {
+		for y := range c.years {
+			years[y].Clients++
+			if y == c.first {
+				years[y].New++
+			} else {
+				years[y].Returning++
+			}
+		}
+	}
+	for _, y := range years {
+		r.ByYear = append(r.ByYear, *y)
+	}
+	slices.SortFunc(r.ByYear, func(a, b ClientYear) int { return cmp.Compare(a.Year, b.Year) })
+
+	for _, key := range order {
+		cr := clients[key]
+		cr.Share = percentOf(cr.Revenue, r.Revenue)
+		cr.AvgInvoice = cr.Revenue.Div(int64(cr.Invoices))
+		cr.Since = seen[key].first
+		cr.New = cr.Since == year
+		r.HHI += cr.Share * cr.Share
+		r.Clients = append(r.Clients, *cr)
+	}
+	slices.SortStableFunc(r.Clients, func(a, b ClientRevenue) int { return cmp.Compare(b.Revenue, a.Revenue) })
+	if len(r.Clients) > 0 {
+		r.TopShare = r.Clients[0].Share
+	}
+	if r.Invoices > 0 {
+		r.AvgInvoice = r.Revenue.Div(int64(r.Invoices))
+	}
+
+	if top > 0 && len(r.Clients) > top {
+		rest := r.Clients[top:]
+		r.Other = &ClientRevenue{Name: fmt.Sprintf("Other (%d)", len(rest))}
+		for _, c := range rest {
+			r.Other.Invoices += c.Invoices
+			r.Other.Revenue += c.Revenue
+		}
+		r.Other.Share = percentOf(r.Other.Revenue, r.Revenue)
+		r.Other.AvgInvoice = r.Other.Revenue.Div(int64(r.Other.Invoices))
+		r.Clients = r.Clients[:top]
+	}
+	return r
+}
+
+// TotalShare is the share of the total line, 100 once anything was invoiced
+func (r *ClientReport) TotalShare() float64 {
+	if r.Revenue > 0 {
+		return 100
+	}
+	return 0
+}
+
+// WriteCSV writes one line per client, then Other and the total
+func (r *ClientReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"client", "invoices", "revenue", "share_percent", "average_invoice", "first_invoiced_year", "new"})
+	rows := slices.Clip(r.Clients)
+	if r.Other != nil {
+		rows = append(rows, *r.Other)
+	}
+	for _, c := range rows {
+		since := ""
+		if c.Since != 0 {
+			since = strconv.Itoa(c.Since)
+		}
+		cw.Write([]string{c.Name, strconv.Itoa(c.Invoices), c.Revenue.String(), strconv.FormatFloat(c.Share, 'f', 1, 64),
+			c.AvgInvoice.String(), since, strconv.FormatBool(c.New)})
+	}
+	cw.Write([]string{"Total", strconv.Itoa(r.Invoices), r.Revenue.String(), strconv.FormatFloat(r.TotalShare(), 'f', 1, 64), r.AvgInvoice.String(), "", ""})
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+func clientItems() []client.Revenue {
+	local := client.LocalAmount{Total: 5000 * money.Lei}
+	return []client.Revenue{
+		{ClientName: "ACME Corp", IssueDate: "2024-03-01", Total: 1000 * money.Lei},
+		{ClientName: "ACME Corp", IssueDate: "2025-01-10", Total: 3000 * money.Lei},
+		{ClientName: "acme corp ", IssueDate: "2025-02-10", Total: 1000 * money.Lei, Currency: client.Currency{ShortName: "EUR"}, InvoiceLocalAmount: &local},
+		{ClientName: "Globex", IssueDate: "2025-04-01", Total: 1500 * money.Lei},
+		{ClientName: "Initech", IssueDate: "2024-05-01", Total: 200 * money.Lei},
+		{ClientName: "Initech", IssueDate: "2025-06-01", Total: 500 * money.Lei},
+		{ClientName: "Initech", IssueDate: "2025-07-01", Total: 9999 * money.Lei, Status: &client.InvoiceStatus{IsCancelled: true}},
+		{ClientName: "Umbrella", IssueDate: "2026-01-01", Total: 7777 * money.Lei},
+	}
+}
+
+func TestBuildClientReport(t *testing.T) {
+	r := BuildClientReport(clientItems(), 2025, 0, client.Revenue.TotalRON)
+
+	if len(r.Clients) != 3 || r.Clients[0].Name != "ACME Corp" || r.Clients[1].Name != "Globex" || r.Clients[2].Name != "Initech" {
+		t.Fatalf("clients = %+v, want ACME Corp, Globex, Initech by revenue", r.Clients)
+	}
+	acme := r.Clients[0]
+	// 3000 + the local RON amount of the EUR invoice, names merged
+	if acme.Invoices != 2 || acme.Revenue != 8000*money.Lei || acme.AvgInvoice != 4000*money.Lei || acme.Share != 80 {
+		t.Errorf("ACME = %+v", acme)
+	}
+	if acme.New || acme.Since != 2024 || !r.Clients[1].New || r.Clients[1].Since != 2025 {
+		t.Errorf("new flags: ACME %+v, Globex %+v", acme, r.Clients[1])
+	}
+	if r.Invoices != 4 || r.Revenue != 10000*money.Lei || r.AvgInvoice != 2500*money.Lei || r.TopShare != 80 {
+		t.Errorf("totals = %d invoices, %s revenue, %s average", r.Invoices, r.Revenue, r.AvgInvoice)
+	}
+
+	// 80² + 15² + 5²
+	if math.Abs(r.HHI-6650) > 1e-6 || r.Concentration() != "highly concentrated" {
+		t.Errorf("HHI = %v (%s), want 6650", r.HHI, r.Concentration())
+	}
+
+	if len(r.ByYear) != 2 {
+		t.Fatalf("by year = %+v, want 2024 and 2025 (2026 is after the report year)", r.ByYear)
+	}
+	y24, y25 := r.ByYear[0], r.ByYear[1]
+	if y24.Year != 2024 || y24.Clients != 2 || y24.New != 2 || y24.Revenue != 1200*money.Lei {
+		t.Errorf("2024 = %+v", y24)
+	}
+	if y25 != r.ThisYear() || y25.Clients != 3 || y25.New != 1 || y25.Returning != 2 {
+		t.Errorf("2025 = %+v", y25)
+	}
+}
+
+func TestClientReportTopAndCSV(t *testing.T) {
+	r := BuildClientReport(clientItems(), 2025, 1, client.Revenue.TotalRON)
+	if len(r.Clients) != 1 || r.Other == nil || r.Other.Name != "Other (2)" || r.Other.Invoices != 2 || r.Other.Share != 20 {
+		t.Fatalf("clients = %+v, other = %+v", r.Clients, r.Other)
+	}
+	if math.Abs(r.HHI-6650) > 1e-6 {
+		t.Errorf("HHI = %v, must cover the combined clients too", r.HHI)
+	}
+
+	var b bytes.Buffer
+	if err := r.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "client,invoices,revenue,share_percent,average_invoice,first_invoiced_year,new\n" +
+		"ACME Corp,2,8000.00,80.0,4000.00,2024,false\n" +
+		"Other (2),2,2000.00,20.0,1000.00,,false\n" +
+		"Total,4,10000.00,100.0,2500.00,,\n"
+	if b.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestConcentrationBands(t *testing.T) {
+	for hhi, want := range map[float64]string{1000: "diversified", 1500: "moderately concentrated", 2600: "highly concentrated"} {
+		if got := (&ClientReport{HHI: hhi}).Concentration(); got != want {
+			t.Errorf("Concentration(%v) = %s, want %s", hhi, got, want)
+		}
+	}
+	if r := BuildClientReport(nil, 2025, 0, client.Revenue.TotalRON); r.Invoices != 0 || r.HHI != 0 || !strings.Contains(r.Concentration(), "diversified") {
+		t.Errorf("empty report = %+v", r)
+	}
+}
+
+// Without revenue the total's share is 0 like every row's
+func TestClientsCSVNoRevenue(t *testing.T) {
+	var b bytes.Buffer
+	if err := BuildClientReport(nil, 2025, 0, client.Revenue.TotalRON).WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Total,0,0.00,0.0,0.00,,\n"; !strings.HasSuffix(b.String(), want) {
+		t.Errorf("csv =\n%s\nwant a last line %q", b.String(), want)
+	}
+}
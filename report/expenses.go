@@ -0,0 +1,213 @@
+package report
+
+import (
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+// GroupBy is the dimension of the expense breakdown
+type GroupBy string
+
+const (
+	GroupCategory      GroupBy = "category"
+	GroupSupplier      GroupBy = "supplier"
+	GroupDeductibility GroupBy = "deductibility"
+)
+
+// GroupBys lists the groupings in display order
+var GroupBys = []GroupBy{GroupCategory, GroupSupplier, GroupDeductibility}
+
+// ParseGroupBy accepts category|supplier|deductibility and their short forms
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch strings.ToLower(s) {
+	case "category", "categories", "cat":
+		return GroupCategory, nil
+	case "supplier", "suppliers", "vendor":
+		return GroupSupplier, nil
+	case "deductibility", "deductible", "ded":
+		return GroupDeductibility, nil
+	}
+	return "", fmt.Errorf("invalid grouping '%s' (want category, supplier or deductibility)", s)
+}
+
+// Title is the column heading of the grouping
+func (g GroupBy) Title() string {
+	return strings.ToUpper(string(g[:1])) + string(g[1:])
+}
+
+// groupName is the group an expense falls in. Category falls back to the
+// primary category, deductibility is bucketed by percentage
+func groupName(e client.Expense, by GroupBy) string {
+	switch by {
+	case GroupSupplier:
+		if name := strings.TrimSpace(e.SupplierName); name != "" {
+			return name
+		}
+		return "Unknown supplier"
+	case GroupDeductibility:
+		switch pct := e.DeductiblePercent(); pct {
+		case 100:
+			return "Deductible"
+		case 0:
+			return "Non-deductible"
+		default:
+			return fmt.Sprintf("Deductible %g%%", pct)
+		}
+	}
+	for _, name := range []string{e.Category, e.PrimaryCategory} {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+	return "Uncategorized"
+}
+
+// ExpenseGroup is the year's spend of one category, supplier or
+// deductibility bucket, in RON
+type ExpenseGroup struct {
+	Name          string      `json:"name"`
+	Count         int         `json:"count"`
+	Amount        money.Money `json:"amount"`
+	NonDeductible money.Money `json:"non_deductible"`
+	Share         float64     `json:"share_percent"`
+	Previous      money.Money `json:"previous_year"`
+	Change        *float64    `json:"change_percent"` // nil when nothing was spent the year before
+}
+
+func (g *ExpenseGroup) add(o ExpenseGroup) {
+	g.Count += o.Count
+	g.Amount += o.Amount
+	g.NonDeductible += o.NonDeductible
+	g.Previous += o.Previous
+}
+
+// ExpenseBreakdown is where a year's expenses went, largest group first
+type ExpenseBreakdown struct {
+	Year    int            `json:"year"`
+	GroupBy GroupBy        `json:"group_by"`
+	Groups  []ExpenseGroup `json:"groups"`
+	Other   *ExpenseGroup  `json:"other,omitempty"` // The groups past the top N, combined
+	Total   ExpenseGroup   `json:"total"`
+}
+
+// BuildExpenseBreakdown groups the expenses bought in year, with the same
+// groups' spend the year before for the year-over-year change. Names are
+// matched case-insensitively. top > 0 keeps the largest top groups and
+// combines the rest into Other. ron values an expense in RON
+func BuildExpenseBreakdown(expenses []client.Expense, year int, by GroupBy, top int, ron func(client.Expense) money.Money) *ExpenseBreakdown {
+	groups := map[string]*ExpenseGroup{}
+	var order []string
+	b := &ExpenseBreakdown{Year: year, GroupBy: by, Total: ExpenseGroup{Name: "Total"}}
+
+	for _, e := range expenses {
+		current := client.InYear(e.PurchaseDate, year)
+		if !current && !client.InYear(e.PurchaseDate, year-1) {
+			continue
+		}
+		name := groupName(e, by)
+		key := strings.ToLower(name)
+		g, ok := groups[key]
+		if !ok {
+			g = &ExpenseGroup{Name: name}
+			groups[key] = g
+			order = append(order, key)
+		}
+
+		value := ron(e)
+		if !current {
+			g.Previous += value
+			b.Total.Previous += value
+			continue
+		}
+		g.Count++
+		g.Amount += value
+		g.NonDeductible += value - value.MulFloat(e.DeductiblePercent()/100)
+	}
+
+	for _, key := range order {
+		// Groups with no spend this year only count toward last year's total
+		if g := groups[key]; g.Count > 0 {
+			b.Groups = append(b.Groups, *g)
+			b.Total.add(ExpenseGroup{Count: g.Count, Amount: g.Amount, NonDeductible: g.NonDeductible})
+		}
+	}
+	slices.SortStableFunc(b.Groups, func(x, y ExpenseGroup) int {
+		return cmp.Compare(y.Amount, x.Amount)
+	})
+
+	if top > 0 && len(b.Groups) > top {
+		rest := b.Groups[top:]
+		b.Other = &ExpenseGroup{Name: fmt.Sprintf("Other (%d)", len(rest))}
+		for _, g := range rest {
+			b.Other.add(g)
+		}
+		b.Groups = b.Groups[:top]
+	}
+
+	for _, g := range b.rows() {
+		g.Share = percentOf(g.Amount, b.Total.Amount)
+		if g.Previous > 0 {
+			change := (g.Amount - g.Previous).Float64() / g.Previous.Float64() * 100
+			g.Change = &change
+		}
+	}
+	return b
+}
+
+// rows are the groups, Other and Total in display order
+func (b *ExpenseBreakdown) rows() []*ExpenseGroup {
+	rows := make([]*ExpenseGroup, 0, len(b.Groups)+2)
+	for i := range b.Groups {
+		rows = append(rows, &b.Groups[i])
+	}
+	if b.Other != nil {
+		rows = append(rows, b.Other)
+	}
+	return append(rows, &b.Total)
+}
+
+func percentOf(part, whole money.Money) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part.Float64() / whole.Float64() * 100
+}
+
+// NonDeductibleShare is the non-deductible part of the year's expenses in
+// percent
+func (b *ExpenseBreakdown) NonDeductibleShare() float64 {
+	return percentOf(b.Total.NonDeductible, b.Total.Amount)
+}
+
+// FormatChange renders a year-over-year change as "+12%", "-5%" or "new"
+// when nothing was spent the year before
+func FormatChange(change *float64) string {
+	if change == nil {
+		return "new"
+	}
+	return fmt.Sprintf("%+.0f%%", *change)
+}
+
+// WriteCSV writes one line per group, then Other and Total
+func (b *ExpenseBreakdown) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{string(b.GroupBy), "count", "amount", "share_percent", "non_deductible", "previous_year", "change_percent"})
+	for _, g := range b.rows() {
+		change := ""
+		if g.Change != nil {
+			change = strconv.FormatFloat(*g.Change, 'f', 1, 64)
+		}
+		cw.Write([]string{g.Name, strconv.Itoa(g.Count), g.Amount.String(), strconv.FormatFloat(g.Share, 'f', 1, 64),
+			g.NonDeductible.String(), g.Previous.String(), change})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+func breakdownItems() []client.Expense {
+	local := client.ExpenseLocalAmount{Total: 500 * money.Lei}
+	return []client.Expense{
+		{PurchaseDate: "2025-01-05", SupplierName: "Hosting SRL", Category: "Servicii", Total: 300 * money.Lei},
+		{PurchaseDate: "2025-02-05", SupplierName: "hosting srl", Category: "servicii", Total: 100 * money.Lei, Currency: client.Currency{ShortName: "EUR"}, ExpenseLocalAmount: &local},
+		{PurchaseDate: "2025-03-01", SupplierName: "Emag", Category: "Echipamente", Total: 250 * money.Lei, Deductibility: "50%"},
+		{PurchaseDate: "2025-04-01", SupplierName: "Restaurant", PrimaryCategory: "Protocol", Total: 150 * money.Lei, Deductibility: "0%"},
+		{PurchaseDate: "2025-04-02", Total: 50 * money.Lei},
+		{PurchaseDate: "2024-06-01", SupplierName: "Hosting SRL", Category: "Servicii", Total: 400 * money.Lei},
+		{PurchaseDate: "2024-07-01", SupplierName: "Gone SRL", Category: "Chirie", Total: 1000 * money.Lei},
+		{PurchaseDate: "2023-01-01", Category: "Servicii", Total: 9999 * money.Lei},
+	}
+}
+
+func TestExpenseBreakdownByCategory(t *testing.T) {
+	b := BuildExpenseBreakdown(breakdownItems(), 2025, GroupCategory, 0, client.Expense.TotalRON)
+
+	var names []string
+	for _, g := range b.Groups {
+		names = append(names, g.Name)
+	}
+	// Largest first, names merged case-insensitively, primary category as
+	// the fallback and groups with no spend this year left out
+	if got := strings.Join(names, ","); got != "Servicii,Echipamente,Protocol,Uncategorized" {
+		t.Fatalf("groups = %s", got)
+	}
+
+	services := b.Groups[0]
+	if services.Count != 2 || services.Amount != 800*money.Lei || services.Previous != 400*money.Lei {
+		t.Errorf("Servicii = %+v, want the local RON amount and last year's spend", services)
+	}
+	if services.Change == nil || *services.Change != 100 || FormatChange(services.Change) != "+100%" {
+		t.Errorf("Servicii change = %v", services.Change)
+	}
+	if services.Share != 64 {
+		t.Errorf("Servicii share = %v, want 64", services.Share)
+	}
+	if b.Groups[1].Change != nil || FormatChange(b.Groups[1].Change) != "new" {
+		t.Errorf("Echipamente change = %v, want new", b.Groups[1].Change)
+	}
+
+	total := b.Total
+	if total.Count != 5 || total.Amount != 1250*money.Lei || total.Previous != 1400*money.Lei || total.Share != 100 {
+		t.Errorf("total = %+v", total)
+	}
+	if total.NonDeductible != 275*money.Lei || b.NonDeductibleShare() != 22 {
+		t.Errorf("non-deductible = %s (%v%%), want 125 of Echipamente and all of Protocol", total.NonDeductible, b.NonDeductibleShare())
+	}
+}
+
+func TestExpenseBreakdownTopAndGroupings(t *testing.T) {
+	b := BuildExpenseBreakdown(breakdownItems(), 2025, GroupSupplier, 2, client.Expense.TotalRON)
+	if len(b.Groups) != 2 || b.Groups[0].Name != "Hosting SRL" || b.Other == nil {
+		t.Fatalf("groups = %+v, other = %+v", b.Groups, b.Other)
+	}
+	if b.Other.Name != "Other (2)" || b.Other.Count != 2 || b.Other.Amount != 200*money.Lei || b.Other.Share != 16 {
+		t.Errorf("other = %+v", b.Other)
+	}
+
+	b = BuildExpenseBreakdown(breakdownItems(), 2025, GroupDeductibility, 0, client.Expense.TotalRON)
+	got := map[string]money.Money{}
+	for _, g := range b.Groups {
+		got[g.Name] = g.Amount
+	}
+	if got["Deductible"] != 850*money.Lei || got["Deductible 50%"] != 250*money.Lei || got["Non-deductible"] != 150*money.Lei {
+		t.Errorf("deductibility groups = %v", got)
+	}
+}
+
+func TestExpenseBreakdownWriteCSV(t *testing.T) {
+	b := BuildExpenseBreakdown(breakdownItems(), 2025, GroupCategory, 1, client.Expense.TotalRON)
+	var out bytes.Buffer
+	if err := b.WriteCSV(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := "category,count,amount,share_percent,non_deductible,previous_year,change_percent\n" +
+		"Servicii,2,800.00,64.0,0.00,400.00,100.0\n" +
+		"Other (3),3,450.00,36.0,275.00,0.00,\n" +
+		"Total,5,1250.00,100.0,275.00,1400.00,-10.7\n"
+	if out.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	for in, want := range map[string]GroupBy{"category": GroupCategory, "Suppliers": GroupSupplier, "ded": GroupDeductibility} {
+		if got, err := ParseGroupBy(in); err != nil || got != want {
+			t.Errorf("ParseGroupBy(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseGroupBy("month"); err == nil {
+		t.Error("ParseGroupBy accepted month")
+	}
+}
@@ -0,0 +1,131 @@
+// Package report aggregates the complete revenue and expense lists into
+// period and category reports
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+// Period is the granularity of a period report
+type Period string
+
+const (
+	ByMonth   Period = "month"
+	ByQuarter Period = "quarter"
+)
+
+// ParsePeriod accepts month|quarter (and the m, q, monthly, quarterly forms)
+func ParsePeriod(s string) (Period, error) {
+	switch strings.ToLower(s) {
+	case "month", "months", "monthly", "m":
+		return ByMonth, nil
+	case "quarter", "quarters", "quarterly", "q":
+		return ByQuarter, nil
+	}
+	return "", fmt.Errorf("invalid period '%s' (want month or quarter)", s)
+}
+
+// PnLRow is the profit and loss of one period, all in RON
+type PnLRow struct {
+	Period        string      `json:"period"`
+	Revenue       money.Money `json:"revenue"`
+	Deductible    money.Money `json:"deductible_expenses"`
+	NonDeductible money.Money `json:"non_deductible_expenses"`
+	Net           money.Money `json:"net_income"` // Revenue - Deductible
+	YTD           money.Money `json:"net_income_ytd"`
+}
+
+// PnL is a year's profit and loss split by period
+type PnL struct {
+	Year  int      `json:"year"`
+	By    Period   `json:"by"`
+	Rows  []PnLRow `json:"periods"`
+	Total PnLRow   `json:"total"`
+}
+
+// monthOf returns the 1-12 month of an ISO date in year, 0 when the date is
+// in another year or malformed
+func monthOf(date string, year int) int {
+	d, ok := client.ParseDay(date)
+	if !ok || d.Year() != year {
+		return 0
+	}
+	return int(d.Month())
+}
+
+// BuildPnL aggregates the invoices issued and the expenses bought in year
+// per month or quarter. Expenses split into the deductible and the
+// non-deductible part by their Deductibility; net income is revenue minus
+// the deductible expenses, as taxed, with a running year-to-date total.
+// revenueRON and expenseRON value items in RON
+func BuildPnL(revenues []client.Revenue, expenses []client.Expense, year int, by Period,
+	revenueRON func(client.Revenue) money.Money, expenseRON func(client.Expense) money.Money) *PnL {
+	var months [12]PnLRow
+	for _, r := range revenues {
+		if r.Status != nil && r.Status.IsCancelled {
+			continue
+		}
+		if mo := monthOf(r.IssueDate, year); mo != 0 {
+			months[mo-1].Revenue += revenueRON(r)
+		}
+	}
+	for _, e := range expenses {
+		mo := monthOf(e.PurchaseDate, year)
+		if mo == 0 {
+			continue
+		}
+		value := expenseRON(e)
+		deductible := value.MulFloat(e.DeductiblePercent() / 100)
+		months[mo-1].Deductible += deductible
+		months[mo-1].NonDeductible += value - deductible
+	}
+
+	p := &PnL{Year: year, By: by, Total: PnLRow{Period: strconv.Itoa(year)}}
+	size := 1
+	if by == ByQuarter {
+		size = 3
+	}
+	for start := 0; start < 12; start += size {
+		row := PnLRow{Period: fmt.Sprintf("%d-%02d", year, start+1)}
+		if by == ByQuarter {
+			row.Period = fmt.Sprintf("%d-Q%d", year, start/3+1)
+		}
+		for _, m := range months[start : start+size] {
+			row.Revenue += m.Revenue
+			row.Deductible += m.Deductible
+			row.NonDeductible += m.NonDeductible
+		}
+		row.Net = row.Revenue - row.Deductible
+
+		p.Total.Revenue += row.Revenue
+		p.Total.Deductible += row.Deductible
+		p.Total.NonDeductible += row.NonDeductible
+		p.Total.Net += row.Net
+		row.YTD = p.Total.Net
+		p.Rows = append(p.Rows, row)
+	}
+	p.Total.YTD = p.Total.Net
+	return p
+}
+
+// pnlCSVHeader matches the JSON field names
+var pnlCSVHeader = []string{"period", "revenue", "deductible_expenses", "non_deductible_expenses", "net_income", "net_income_ytd"}
+
+// WriteCSV writes one line per period and a final total line
+func (p *PnL) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(pnlCSVHeader)
+	for _, row := range append(slices.Clip(p.Rows), p.Total) {
+		cw.Write([]string{row.Period, row.Revenue.String(), row.Deductible.String(), row.NonDeductible.String(), row.Net.String(), row.YTD.String()})
+	}
+	cw.Flush()
+	return cw.Error()
+}
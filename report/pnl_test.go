@@ -0,0 +1,110 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+func sampleItems() ([]client.Revenue, []client.Expense) {
+	local := client.LocalAmount{Total: 5000 * money.Lei}
+	revenues := []client.Revenue{
+		{IssueDate: "2025-01-10", Total: 1000 * money.Lei},
+		{IssueDate: "2025-02-20T00:00:00+02:00", Total: 1000 * money.Lei, Currency: client.Currency{ShortName: "EUR"}, InvoiceLocalAmount: &local},
+		{IssueDate: "2025-05-01", Total: 2000 * money.Lei},
+		{IssueDate: "2025-06-01", Total: 700 * money.Lei, Status: &client.InvoiceStatus{IsCancelled: true}},
+		{IssueDate: "2024-12-31", Total: 9999 * money.Lei},
+	}
+	expenses := []client.Expense{
+		{PurchaseDate: "2025-01-05", Total: 400 * money.Lei, Deductibility: "50%"},
+		{PurchaseDate: "2025-03-05", Total: 100 * money.Lei},
+		{PurchaseDate: "2025-04-05", Total: 3000 * money.Lei, Deductibility: "100%"},
+		{PurchaseDate: "2025-04-06", Total: 80 * money.Lei, Category: "Cheltuieli nedeductibile"},
+		{PurchaseDate: "", Total: 50 * money.Lei},
+	}
+	return revenues, expenses
+}
+
+func TestBuildPnLByMonth(t *testing.T) {
+	revenues, expenses := sampleItems()
+	p := BuildPnL(revenues, expenses, 2025, ByMonth, client.Revenue.TotalRON, client.Expense.TotalRON)
+
+	if len(p.Rows) != 12 || p.Rows[0].Period != "2025-01" || p.Rows[11].Period != "2025-12" {
+		t.Fatalf("rows = %+v, want 12 months", p.Rows)
+	}
+	jan := p.Rows[0]
+	if jan.Revenue != 1000*money.Lei || jan.Deductible != 200*money.Lei || jan.NonDeductible != 200*money.Lei || jan.Net != 800*money.Lei {
+		t.Errorf("January = %+v", jan)
+	}
+	if feb := p.Rows[1]; feb.Revenue != 5000*money.Lei || feb.YTD != 5800*money.Lei {
+		t.Errorf("February = %+v, want the local RON amount and a running total", feb)
+	}
+	if apr := p.Rows[3]; apr.Net != -3000*money.Lei || apr.NonDeductible != 80*money.Lei {
+		t.Errorf("April = %+v", apr)
+	}
+	if jun := p.Rows[5]; jun.Revenue != 0 {
+		t.Errorf("cancelled invoice counted in June: %+v", jun)
+	}
+
+	total := p.Total
+	if total.Period != "2025" || total.Revenue != 8000*money.Lei || total.Deductible != 3300*money.Lei ||
+		total.NonDeductible != 280*money.Lei || total.Net != 4700*money.Lei || total.YTD != total.Net {
+		t.Errorf("total = %+v", total)
+	}
+	if p.Rows[11].YTD != total.Net {
+		t.Errorf("December YTD = %s, want the year's net %s", p.Rows[11].YTD, total.Net)
+	}
+}
+
+func TestBuildPnLByQuarter(t *testing.T) {
+	revenues, expenses := sampleItems()
+	p := BuildPnL(revenues, expenses, 2025, ByQuarter, client.Revenue.TotalRON, client.Expense.TotalRON)
+
+	if len(p.Rows) != 4 {
+		t.Fatalf("rows = %+v, want 4 quarters", p.Rows)
+	}
+	q1, q2 := p.Rows[0], p.Rows[1]
+	if q1.Period != "2025-Q1" || q1.Revenue != 6000*money.Lei || q1.Deductible != 300*money.Lei || q1.Net != 5700*money.Lei {
+		t.Errorf("Q1 = %+v", q1)
+	}
+	if q2.Net != -1000*money.Lei || q2.YTD != 4700*money.Lei {
+		t.Errorf("Q2 = %+v", q2)
+	}
+}
+
+func TestPnLWriteCSV(t *testing.T) {
+	revenues, expenses := sampleItems()
+	p := BuildPnL(revenues, expenses, 2025, ByQuarter, client.Revenue.TotalRON, client.Expense.TotalRON)
+
+	var b bytes.Buffer
+	if err := p.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("csv has %d lines, want header, 4 quarters and total:\n%s", len(lines), b.String())
+	}
+	if lines[0] != "period,revenue,deductible_expenses,non_deductible_expenses,net_income,net_income_ytd" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if lines[1] != "2025-Q1,6000.00,300.00,200.00,5700.00,5700.00" || lines[5] != "2025,8000.00,3300.00,280.00,4700.00,4700.00" {
+		t.Errorf("rows:\n%s", b.String())
+	}
+	if len(p.Rows) != 4 {
+		t.Error("WriteCSV modified the rows")
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	for in, want := range map[string]Period{"month": ByMonth, "M": ByMonth, "quarterly": ByQuarter, "q": ByQuarter} {
+		if got, err := ParsePeriod(in); err != nil || got != want {
+			t.Errorf("ParsePeriod(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParsePeriod("week"); err == nil {
+		t.Error("ParsePeriod accepted week")
+	}
+}
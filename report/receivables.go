@@ -0,0 +1,128 @@
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+// AgingBuckets labels the receivables aging buckets, in days since issue
+var AgingBuckets = [4]string{"0-30", "31-60", "61-90", "90+"}
+
+// agingBucket returns the bucket index for an invoice age in days
+func agingBucket(days int) int {
+	switch {
+	case days <= 30:
+		return 0
+	case days <= 60:
+		return 1
+	case days <= 90:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// ClientReceivables is the money one client still owes
+type ClientReceivables struct {
+	Client       string
+	Invoices     int                    // unpaid invoices
+	Buckets      [4]money.Money         // RON owed by age, see AgingBuckets
+	Total        money.Money            // RON owed
+	ByCurrency   map[string]money.Money // owed in the invoice currencies
+	OldestDays   int                    // age of the oldest unpaid invoice
+	PaidInvoices int                    // paid invoices with a payment date
+	AvgDaysToPay float64                // over PaidInvoices, 0 when there are none
+}
+
+// Receivables is the aging of all unpaid invoices on a day
+type Receivables struct {
+	AsOf       time.Time
+	Clients    []ClientReceivables // largest amount owed first
+	Buckets    [4]money.Money
+	Total      money.Money
+	ByCurrency map[string]money.Money
+	Invoices   int
+}
+
+// daysBetween counts the calendar days from one day to another
+func daysBetween(from, to time.Time) int {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// AgeReceivables groups the unpaid invoices by client into aging buckets by
+// days since issue, valuing them in RON with ron. The average days-to-pay of
+// each client comes from its paid invoices. Cancelled invoices are skipped
+// and clients that owe nothing are left out
+func AgeReceivables(items []client.Revenue, now time.Time, ron func(client.Revenue) money.Money) *Receivables {
+	rep := &Receivables{AsOf: now, ByCurrency: map[string]money.Money{}}
+	clients := map[string]*ClientReceivables{}
+	daysToPay := map[string]int{}
+
+	get := func(name string) *ClientReceivables {
+		c := clients[name]
+		if c == nil {
+			c = &ClientReceivables{Client: name, ByCurrency: map[string]money.Money{}}
+			clients[name] = c
+		}
+		return c
+	}
+
+	for _, r := range items {
+		if r.Status != nil && r.Status.IsCancelled {
+			continue
+		}
+		issued, ok := client.ParseDay(r.IssueDate)
+		if !ok {
+			continue
+		}
+		name := strings.TrimSpace(r.ClientName)
+
+		if r.IsPaid {
+			if paid, ok := client.ParseDay(r.PaymentDate); ok && !paid.Before(issued) {
+				c := get(name)
+				c.PaidInvoices++
+				daysToPay[name] += daysBetween(issued, paid)
+			}
+			continue
+		}
+
+		cur := r.Currency.ISOCode()
+		age := max(daysBetween(issued, now), 0)
+		value := ron(r)
+		bucket := agingBucket(age)
+
+		c := get(name)
+		c.Invoices++
+		c.Buckets[bucket] += value
+		c.Total += value
+		c.ByCurrency[cur] += r.Total
+		c.OldestDays = max(c.OldestDays, age)
+
+		rep.Invoices++
+		rep.Buckets[bucket] += value
+		rep.Total += value
+		rep.ByCurrency[cur] += r.Total
+	}
+
+	for name, c := range clients {
+		if c.PaidInvoices > 0 {
+			c.AvgDaysToPay = float64(daysToPay[name]) / float64(c.PaidInvoices)
+		}
+		if c.Invoices > 0 {
+			rep.Clients = append(rep.Clients, *c)
+		}
+	}
+	sort.Slice(rep.Clients, func(i, j int) bool {
+		if rep.Clients[i].Total != rep.Clients[j].Total {
+			return rep.Clients[i].Total > rep.Clients[j].Total
+		}
+		return rep.Clients[i].Client < rep.Clients[j].Client
+	})
+	return rep
+}
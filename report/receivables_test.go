@@ -0,0 +1,53 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+func TestAgeReceivables(t *testing.T) {
+	now := time.Date(2026, 6, 30, 15, 0, 0, 0, time.UTC)
+	local := client.LocalAmount{Total: 2500 * money.Lei}
+	eur := client.Currency{ShortName: "EUR"}
+	items := []client.Revenue{
+		{ClientName: "ACME", IssueDate: "2026-06-30", Total: 100 * money.Lei},                                            // 0 days
+		{ClientName: "ACME", IssueDate: "2026-05-31T10:00:00+03:00", Total: 200 * money.Lei},                             // 30 days
+		{ClientName: "ACME", IssueDate: "2026-05-30", Total: 500 * money.Lei, Currency: eur, InvoiceLocalAmount: &local}, // 31 days
+		{ClientName: "ACME", IssueDate: "2026-01-02", PaymentDate: "2026-01-12", IsPaid: true, Total: money.Lei},
+		{ClientName: "ACME", IssueDate: "2026-02-01", PaymentDate: "2026-03-03", IsPaid: true, Total: money.Lei},
+		{ClientName: "Globex", IssueDate: "2026-04-01", Total: 300 * money.Lei}, // 90 days
+		{ClientName: "Globex", IssueDate: "2026-03-31", Total: 400 * money.Lei}, // 91 days
+		{ClientName: "Paid Co", IssueDate: "2026-01-05", PaymentDate: "2026-01-06", IsPaid: true, Total: money.Lei},
+		{ClientName: "Storno", IssueDate: "2026-01-05", Total: 999 * money.Lei, Status: &client.InvoiceStatus{IsCancelled: true}},
+	}
+
+	rep := AgeReceivables(items, now, client.Revenue.TotalRON)
+
+	if len(rep.Clients) != 2 {
+		t.Fatalf("clients = %+v, want ACME and Globex only", rep.Clients)
+	}
+	acme, globex := rep.Clients[0], rep.Clients[1]
+	if acme.Client != "ACME" || acme.Total != 2800*money.Lei || acme.Buckets != [4]money.Money{300 * money.Lei, 2500 * money.Lei} {
+		t.Errorf("ACME = %+v, want 300 in 0-30 and 2500 in 31-60", acme)
+	}
+	if acme.ByCurrency["RON"] != 300*money.Lei || acme.ByCurrency["EUR"] != 500*money.Lei || acme.OldestDays != 31 {
+		t.Errorf("ACME currencies = %v, oldest %d", acme.ByCurrency, acme.OldestDays)
+	}
+	// 10 and 30 days
+	if acme.PaidInvoices != 2 || acme.AvgDaysToPay != 20 {
+		t.Errorf("ACME pays in %.1f days over %d invoices, want 20 over 2", acme.AvgDaysToPay, acme.PaidInvoices)
+	}
+	if globex.Buckets != [4]money.Money{0, 0, 300 * money.Lei, 400 * money.Lei} || globex.PaidInvoices != 0 {
+		t.Errorf("Globex = %+v, want 300 in 61-90 and 400 in 90+", globex)
+	}
+
+	if rep.Total != 3500*money.Lei || rep.Invoices != 5 || rep.Buckets[3] != 400*money.Lei {
+		t.Errorf("totals = %s over %d invoices, buckets %v", rep.Total, rep.Invoices, rep.Buckets)
+	}
+	if got := client.FormatCurrencies(rep.ByCurrency); got != "1000.00 RON + 500.00 EUR" {
+		t.Errorf("FormatCurrencies = %q", got)
+	}
+}
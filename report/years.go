@@ -0,0 +1,91 @@
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/money"
+	"solo-cli/taxes"
+)
+
+// YearRow is one year of the comparison. Growth is against the previous
+// row, nil for the first year or when the previous amount was not positive
+type YearRow struct {
+	Year             int         `json:"year"`
+	Revenue          money.Money `json:"revenue"`
+	Deductible       money.Money `json:"deductible_expenses"`
+	Net              money.Money `json:"net_income"`
+	Taxes            money.Money `json:"taxes"` // Computed from taxes.json
+	RevenueGrowth    *float64    `json:"revenue_growth_percent"`
+	DeductibleGrowth *float64    `json:"deductible_growth_percent"`
+	NetGrowth        *float64    `json:"net_income_growth_percent"`
+	TaxesGrowth      *float64    `json:"taxes_growth_percent"`
+}
+
+// YearComparison lines up several years' summaries
+type YearComparison struct {
+	Rows []YearRow `json:"years"`
+	// RevenueCAGR is the compound annual revenue growth from the first to
+	// the last year, nil when it cannot be computed
+	RevenueCAGR *float64 `json:"revenue_cagr_percent"`
+}
+
+// Growth is the change from prev to cur in percent, nil when prev is not
+// positive
+func Growth(cur, prev money.Money) *float64 {
+	if prev <= 0 {
+		return nil
+	}
+	g := (cur - prev).Float64() / prev.Float64() * 100
+	return &g
+}
+
+// CompareYears lines up the summaries oldest first with the growth from
+// year to year. Taxes are computed with cfg and the declared extra income
+// of each year, and left at zero when cfg is nil
+func CompareYears(summaries []*client.Summary, cfg *config.TaxConfig, extra []config.ExtraIncome) *YearComparison {
+	sorted := slices.Clone(summaries)
+	slices.SortFunc(sorted, func(a, b *client.Summary) int { return cmp.Compare(a.Year, b.Year) })
+
+	c := &YearComparison{}
+	for i, s := range sorted {
+		row := YearRow{
+			Year:       s.Year,
+			Revenue:    s.TotalRevenues,
+			Deductible: s.TotalDeductibleExpenses,
+			Net:        s.TotalRevenues - s.TotalDeductibleExpenses,
+		}
+		if cfg != nil {
+			row.Taxes = taxes.CalculateWithIncome(s.TotalRevenues, s.TotalDeductibleExpenses, config.ExtraIncomeForYear(extra, s.Year), cfg).TotalTaxes
+		}
+		if i > 0 {
+			prev := c.Rows[i-1]
+			row.RevenueGrowth = Growth(row.Revenue, prev.Revenue)
+			row.DeductibleGrowth = Growth(row.Deductible, prev.Deductible)
+			row.NetGrowth = Growth(row.Net, prev.Net)
+			row.TaxesGrowth = Growth(row.Taxes, prev.Taxes)
+		}
+		c.Rows = append(c.Rows, row)
+	}
+
+	if n := len(c.Rows); n > 1 {
+		first, last := c.Rows[0], c.Rows[n-1]
+		if span := last.Year - first.Year; span > 0 && first.Revenue > 0 && last.Revenue > 0 {
+			cagr := (math.Pow(last.Revenue.Float64()/first.Revenue.Float64(), 1/float64(span)) - 1) * 100
+			c.RevenueCAGR = &cagr
+		}
+	}
+	return c
+}
+
+// FormatGrowth renders a growth as "+12.5%", empty when there is none
+func FormatGrowth(g *float64) string {
+	if g == nil {
+		return ""
+	}
+	return fmt.Sprintf("%+.1f%%", *g)
+}
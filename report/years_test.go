@@ -0,0 +1,72 @@
+package report
+
+import (
+	"math"
+	"testing"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/money"
+)
+
+func TestCompareYears(t *testing.T) {
+	summaries := []*client.Summary{
+		{Year: 2026, TotalRevenues: 121000 * money.Lei, TotalDeductibleExpenses: 21000 * money.Lei},
+		{Year: 2024, TotalRevenues: 100000 * money.Lei, TotalDeductibleExpenses: 0},
+		{Year: 2025, TotalRevenues: 110000 * money.Lei, TotalDeductibleExpenses: 10000 * money.Lei},
+	}
+	c := CompareYears(summaries, nil, nil)
+
+	if len(c.Rows) != 3 || c.Rows[0].Year != 2024 || c.Rows[2].Year != 2026 {
+		t.Fatalf("rows not sorted by year: %+v", c.Rows)
+	}
+	if c.Rows[0].RevenueGrowth != nil {
+		t.Error("first year has a growth")
+	}
+	if g := c.Rows[1].RevenueGrowth; g == nil || math.Abs(*g-10) > 1e-9 {
+		t.Errorf("2025 revenue growth = %v, want 10", g)
+	}
+	// Nothing deducted in 2024, so no growth to speak of
+	if c.Rows[1].DeductibleGrowth != nil {
+		t.Error("growth from zero deductible expenses")
+	}
+	if g := c.Rows[2].DeductibleGrowth; g == nil || math.Abs(*g-110) > 1e-9 {
+		t.Errorf("2026 deductible growth = %v, want 110", g)
+	}
+	if c.Rows[2].Net != 100000*money.Lei || c.Rows[2].Taxes != 0 {
+		t.Errorf("2026 net %s taxes %s", c.Rows[2].Net, c.Rows[2].Taxes)
+	}
+	if c.RevenueCAGR == nil || math.Abs(*c.RevenueCAGR-10) > 1e-9 {
+		t.Errorf("CAGR = %v, want 10", c.RevenueCAGR)
+	}
+	if got := FormatGrowth(c.Rows[1].RevenueGrowth); got != "+10.0%" {
+		t.Errorf("FormatGrowth = %q", got)
+	}
+	if got := FormatGrowth(nil); got != "" {
+		t.Errorf("FormatGrowth(nil) = %q", got)
+	}
+}
+
+func TestCompareYearsTaxes(t *testing.T) {
+	cfg := config.DefaultTaxConfig()
+	c := CompareYears([]*client.Summary{
+		{Year: 2025, TotalRevenues: 50000 * money.Lei},
+		{Year: 2026, TotalRevenues: 100000 * money.Lei},
+	}, cfg, nil)
+	if c.Rows[0].Taxes <= 0 || c.Rows[1].Taxes <= c.Rows[0].Taxes || c.Rows[1].TaxesGrowth == nil {
+		t.Errorf("taxes not computed: %+v", c.Rows)
+	}
+
+	// A single year, or a first year without revenue, has no average growth
+	if c := CompareYears(oneYear(2026, 100), nil, nil); c.RevenueCAGR != nil {
+		t.Error("CAGR of a single year")
+	}
+	if c := CompareYears(append(oneYear(2025, 0), oneYear(2026, 100)...), nil, nil); c.RevenueCAGR != nil {
+		t.Error("CAGR from zero revenue")
+	}
+}
+
+// oneYear is a single summary with the given revenue in lei
+func oneYear(year int, lei int64) []*client.Summary {
+	return []*client.Summary{{Year: year, TotalRevenues: money.Money(lei) * money.Lei}}
+}
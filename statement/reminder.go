@@ -0,0 +1,159 @@
+package statement
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"text/template"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+// ReminderData is what the reminder template is executed with: the
+// statement fields and methods (.Client, .Issuer, .AsOf, .Overdue,
+// .OverdueByCurrency, ...) and the recipient
+type ReminderData struct {
+	*Statement
+	To string
+}
+
+// reminderFuncs are the helpers available to reminder templates
+var reminderFuncs = template.FuncMap{
+	"date":       func(t time.Time) string { return t.Format(dateLayout) },
+	"money":      func(m money.Money) string { return m.String() },
+	"currencies": client.FormatCurrencies,
+}
+
+// addressHeaders are parsed as address lists so non-ASCII names get encoded
+var addressHeaders = map[string]bool{"From": true, "To": true, "Cc": true, "Bcc": true, "Reply-To": true}
+
+// reservedHeaders are always set by Reminder and ignored in templates
+var reservedHeaders = map[string]bool{"Date": true, "Mime-Version": true, "Content-Type": true, "Content-Transfer-Encoding": true, "X-Unsent": true}
+
+// Reminder renders a payment reminder email draft (.eml) for the overdue
+// invoices from a text/template. Leading "Header: value" lines of the
+// rendered template, up to the first blank line, become email headers and
+// the rest the body. The draft is marked unsent so mail clients open it for
+// editing. to, when set, is the recipient unless the template sets To
+func (s *Statement) Reminder(w io.Writer, tmpl, to string, now time.Time) error {
+	t, err := template.New("reminder").Funcs(reminderFuncs).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("reminder template: %w", err)
+	}
+	var rendered bytes.Buffer
+	if err := t.Execute(&rendered, ReminderData{Statement: s, To: to}); err != nil {
+		return fmt.Errorf("reminder template: %w", err)
+	}
+
+	headers, body := splitHeaders(rendered.String())
+	if to != "" && !hasHeader(headers, "To") {
+		headers = append(headers, [2]string{"To", to})
+	}
+	if !hasHeader(headers, "Subject") {
+		headers = append(headers, [2]string{"Subject", "Payment reminder"})
+	}
+
+	var b strings.Builder
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	for _, h := range headers {
+		if h[1] == "" {
+			continue
+		}
+		value, err := encodeHeader(h[0], h[1])
+		if err != nil {
+			return err
+		}
+		b.WriteString(h[0] + ": " + value + "\r\n")
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	b.WriteString("X-Unsent: 1\r\n")
+	b.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&b)
+	body = strings.ReplaceAll(strings.TrimLeft(body, "\n"), "\r\n", "\n")
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return err
+	}
+	if err := qp.Close(); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// splitHeaders separates leading "Header: value" lines from the body. Text
+// that does not start with a header is all body
+func splitHeaders(text string) ([][2]string, string) {
+	var headers [][2]string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	consumed := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		consumed += len(line) + 1
+		if strings.TrimSpace(line) == "" {
+			if len(headers) == 0 {
+				return nil, text
+			}
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, text
+		}
+		headers = append(headers, [2]string{canonicalHeader(name), strings.TrimSpace(value)})
+	}
+
+	kept := headers[:0]
+	for _, h := range headers {
+		if !reservedHeaders[h[0]] {
+			kept = append(kept, h)
+		}
+	}
+	return kept, text[min(consumed, len(text)):]
+}
+
+// canonicalHeader normalizes the case of a header name (reply-to -> Reply-To)
+func canonicalHeader(name string) string {
+	parts := strings.Split(strings.ToLower(name), "-")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+func hasHeader(headers [][2]string, name string) bool {
+	for _, h := range headers {
+		if h[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeHeader makes a header value ASCII-only as RFC 2047 requires
+func encodeHeader(name, value string) (string, error) {
+	if addressHeaders[name] {
+		list, err := mail.ParseAddressList(value)
+		if err != nil {
+			return "", fmt.Errorf("reminder template: invalid %s address '%s': %w", name, value, err)
+		}
+		addrs := make([]string, len(list))
+		for i, a := range list {
+			addrs[i] = a.String()
+		}
+		return strings.Join(addrs, ", "), nil
+	}
+	return mime.QEncoding.Encode("UTF-8", value), nil
+}
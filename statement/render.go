@@ -0,0 +1,155 @@
+package statement
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+// Formats lists the statement formats Render accepts
+var Formats = []string{"txt", "md", "html"}
+
+// Render writes the statement in one of Formats
+func (s *Statement) Render(w io.Writer, format string) error {
+	switch format {
+	case "txt", "text":
+		return s.Text(w)
+	case "md", "markdown":
+		return s.Markdown(w)
+	case "html":
+		return s.HTML(w)
+	}
+	return fmt.Errorf("unknown format '%s' (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// title is the heading shared by every format
+func (s *Statement) title() string {
+	return "Statement of account: " + s.Client
+}
+
+// subtitle dates the statement and states the payment terms
+func (s *Statement) subtitle() string {
+	line := fmt.Sprintf("As of %s, payment terms %d days", s.AsOf.Format(dateLayout), s.TermsDays)
+	if s.Issuer != "" {
+		line = "Issued by " + s.Issuer + ". " + line
+	}
+	return line
+}
+
+// totals returns the summary rows below the invoice table
+func (s *Statement) totals() [][2]string {
+	withRON := func(amounts map[string]money.Money, ron money.Money) string {
+		if len(amounts) == 0 {
+			return "0.00 RON"
+		}
+		text := client.FormatCurrencies(amounts)
+		if _, hasRON := amounts["RON"]; !hasRON || len(amounts) > 1 {
+			text += fmt.Sprintf(" (%.2f RON)", ron)
+		}
+		return text
+	}
+	return [][2]string{
+		{"Invoiced", client.FormatCurrencies(s.Invoiced)},
+		{"Outstanding", withRON(s.Outstanding, s.OutstandingRON)},
+		{"Overdue", withRON(s.OverdueByCurrency, s.OverdueRON)},
+	}
+}
+
+// Text writes the statement as aligned plain text
+func (s *Statement) Text(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintln(&b, s.title())
+	fmt.Fprintln(&b, s.subtitle())
+	fmt.Fprintln(&b, "══════════════════════════════════════════")
+	if len(s.Lines) == 0 {
+		fmt.Fprintln(&b, "No invoices")
+	} else {
+		fmt.Fprintf(&b, "%-18s %-10s %-10s %18s  %s\n", "Invoice", "Issued", "Due", "Amount", "Status")
+		for _, l := range s.Lines {
+			fmt.Fprintf(&b, "%-18s %-10s %-10s %18s  %s\n", l.Number, l.IssueDate.Format(dateLayout),
+				l.DueDate.Format(dateLayout), l.Total.String()+" "+l.Currency, l.Status())
+		}
+	}
+	fmt.Fprintln(&b)
+	for _, t := range s.totals() {
+		fmt.Fprintf(&b, "%-12s %s\n", t[0]+":", t[1])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscape keeps client data from breaking the table
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "*", `\*`, "_", `\_`).Replace(s)
+}
+
+// Markdown writes the statement as a Markdown document with a table
+func (s *Statement) Markdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdownEscape(s.title()))
+	fmt.Fprintf(&b, "%s\n\n", markdownEscape(s.subtitle()))
+	if len(s.Lines) == 0 {
+		fmt.Fprintf(&b, "No invoices.\n\n")
+	} else {
+		fmt.Fprintf(&b, "| Invoice | Issued | Due | Amount | Status |\n")
+		fmt.Fprintf(&b, "|---|---|---|---:|---|\n")
+		for _, l := range s.Lines {
+			status := l.Status()
+			if l.Overdue() {
+				status = "**" + status + "**"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s %s | %s |\n", markdownEscape(l.Number), l.IssueDate.Format(dateLayout),
+				l.DueDate.Format(dateLayout), l.Total, l.Currency, status)
+		}
+		fmt.Fprintln(&b)
+	}
+	for _, t := range s.totals() {
+		fmt.Fprintf(&b, "- **%s:** %s\n", t[0], t[1])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var htmlTemplate = template.Must(template.New("statement").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { padding: 0.35em 0.9em; border-bottom: 1px solid #ddd; text-align: left; }
+td.amount { text-align: right; font-variant-numeric: tabular-nums; }
+.overdue { color: #b91c1c; font-weight: bold; }
+.paid { color: #15803d; }
+dt { font-weight: bold; float: left; width: 8em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Subtitle}}</p>
+{{if .Lines}}<table>
+<thead><tr><th>Invoice</th><th>Issued</th><th>Due</th><th>Amount</th><th>Status</th></tr></thead>
+<tbody>
+{{range .Lines}}<tr><td>{{.Number}}</td><td>{{.IssueDate.Format "2006-01-02"}}</td><td>{{.DueDate.Format "2006-01-02"}}</td><td class="amount">{{.Total}} {{.Currency}}</td><td{{if .Overdue}} class="overdue"{{else if .Paid}} class="paid"{{end}}>{{.Status}}</td></tr>
+{{end}}</tbody>
+</table>{{else}}<p>No invoices.</p>{{end}}
+<dl>
+{{range .Totals}}<dt>{{index . 0}}</dt><dd>{{index . 1}}</dd>
+{{end}}</dl>
+</body>
+</html>
+`))
+
+// HTML writes the statement as a standalone HTML page
+func (s *Statement) HTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, struct {
+		Title, Subtitle string
+		Lines           []Line
+		Totals          [][2]string
+	}{s.title(), s.subtitle(), s.Lines, s.totals()})
+}
@@ -0,0 +1,173 @@
+// Package statement builds per-client account statements from the revenue
+// list and renders them as text, Markdown or HTML, plus payment reminder
+// emails for the overdue invoices
+package statement
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/money"
+)
+
+const dateLayout = "2006-01-02"
+
+// Line is one invoice on the statement
+type Line struct {
+	Number      string
+	IssueDate   time.Time
+	DueDate     time.Time // issue date plus the payment terms
+	PaymentDate time.Time // zero while unpaid or when the API has none
+	Currency    string
+	Total       money.Money // in Currency
+	RON         money.Money // RON value, the local amount when the API has one
+	Paid        bool
+	DaysOverdue int // days past DueDate while unpaid, 0 otherwise
+}
+
+// Overdue reports whether the invoice is unpaid past its due date
+func (l Line) Overdue() bool {
+	return l.DaysOverdue > 0
+}
+
+// Status describes the payment state of the invoice
+func (l Line) Status() string {
+	switch {
+	case l.Paid && !l.PaymentDate.IsZero():
+		return "Paid " + l.PaymentDate.Format(dateLayout)
+	case l.Paid:
+		return "Paid"
+	case l.Overdue():
+		return fmt.Sprintf("Overdue %d days", l.DaysOverdue)
+	default:
+		return "Unpaid"
+	}
+}
+
+// Statement is the account of one client on a day
+type Statement struct {
+	Client    string
+	Issuer    string // company issuing the invoices, may be empty
+	AsOf      time.Time
+	TermsDays int
+	Lines     []Line // oldest first
+
+	Invoiced          map[string]money.Money // by currency
+	Outstanding       map[string]money.Money // unpaid, by currency
+	OverdueByCurrency map[string]money.Money
+	OutstandingRON    money.Money
+	OverdueRON        money.Money
+}
+
+// Overdue returns the unpaid invoices past their due date, oldest first
+func (s *Statement) Overdue() []Line {
+	var lines []Line
+	for _, l := range s.Lines {
+		if l.Overdue() {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// MatchClient resolves a client name as typed on the command line: an exact
+// case-insensitive match wins, otherwise the query must be part of exactly
+// one client name
+func MatchClient(items []client.Revenue, query string) (string, error) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return "", fmt.Errorf("missing client name")
+	}
+
+	seen := map[string]bool{}
+	var matches []string
+	for _, r := range items {
+		// The same client may be spelled with different case
+		name := strings.TrimSpace(r.ClientName)
+		lower := strings.ToLower(name)
+		if seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		if lower == q {
+			return name, nil
+		}
+		if strings.Contains(lower, q) {
+			matches = append(matches, name)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no invoices for a client matching '%s'", query)
+	case 1:
+		return matches[0], nil
+	}
+	sort.Strings(matches)
+	return "", fmt.Errorf("'%s' matches %d clients: %s", query, len(matches), strings.Join(matches, ", "))
+}
+
+// Build collects the invoices of one client into a statement as of a day.
+// An unpaid invoice is overdue termsDays after issue; ron values invoices
+// in RON. Cancelled invoices are left out
+func Build(items []client.Revenue, clientName string, asOf time.Time, termsDays int, ron func(client.Revenue) money.Money) *Statement {
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	s := &Statement{
+		Client:            clientName,
+		AsOf:              asOf,
+		TermsDays:         termsDays,
+		Invoiced:          map[string]money.Money{},
+		Outstanding:       map[string]money.Money{},
+		OverdueByCurrency: map[string]money.Money{},
+	}
+
+	for _, r := range items {
+		if !strings.EqualFold(strings.TrimSpace(r.ClientName), clientName) {
+			continue
+		}
+		if r.Status != nil && r.Status.IsCancelled {
+			continue
+		}
+		issued, ok := client.ParseDay(r.IssueDate)
+		if !ok {
+			continue
+		}
+		cur := r.Currency.ISOCode()
+
+		l := Line{
+			Number:    r.SerialCode,
+			IssueDate: issued,
+			DueDate:   issued.AddDate(0, 0, termsDays),
+			Currency:  cur,
+			Total:     r.Total,
+			RON:       ron(r),
+			Paid:      r.IsPaid,
+		}
+		if paid, ok := client.ParseDay(r.PaymentDate); ok && r.IsPaid {
+			l.PaymentDate = paid
+		}
+
+		s.Invoiced[cur] += l.Total
+		if !l.Paid {
+			s.Outstanding[cur] += l.Total
+			s.OutstandingRON += l.RON
+			if asOf.After(l.DueDate) {
+				l.DaysOverdue = int(asOf.Sub(l.DueDate).Hours() / 24)
+				s.OverdueByCurrency[cur] += l.Total
+				s.OverdueRON += l.RON
+			}
+		}
+		s.Lines = append(s.Lines, l)
+	}
+
+	sort.SliceStable(s.Lines, func(i, j int) bool {
+		if !s.Lines[i].IssueDate.Equal(s.Lines[j].IssueDate) {
+			return s.Lines[i].IssueDate.Before(s.Lines[j].IssueDate)
+		}
+		return s.Lines[i].Number < s.Lines[j].Number
+	})
+	return s
+}
@@ -0,0 +1,217 @@
+package statement
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/money"
+)
+
+var asOf = time.Date(2026, 6, 30, 18, 0, 0, 0, time.UTC)
+
+func sampleRevenues() []client.Revenue {
+	local := client.LocalAmount{Total: 2500 * money.Lei}
+	return []client.Revenue{
+		{SerialCode: "INV-003", ClientName: "ACME Corp", IssueDate: "2026-06-20", Total: 100 * money.Lei, Currency: client.Currency{ShortName: "RON"}},
+		{SerialCode: "INV-001", ClientName: "ACME Corp", IssueDate: "2026-01-10", PaymentDate: "2026-01-25T00:00:00", IsPaid: true, Total: 1000 * money.Lei},
+		{SerialCode: "INV-002", ClientName: "acme corp ", IssueDate: "2026-05-01", Total: 500 * money.Lei, Currency: client.Currency{ShortName: "EUR"}, InvoiceLocalAmount: &local},
+		{SerialCode: "INV-004", ClientName: "ACME Corp", IssueDate: "2026-02-01", Total: 999 * money.Lei, Status: &client.InvoiceStatus{IsCancelled: true}},
+		{SerialCode: "G-1", ClientName: "Globex", IssueDate: "2026-01-01", Total: 50 * money.Lei},
+		{SerialCode: "A-1", ClientName: "ACME Holding", IssueDate: "2026-01-01", Total: 50 * money.Lei},
+	}
+}
+
+func sampleStatement() *Statement {
+	s := Build(sampleRevenues(), "ACME Corp", asOf, 30, client.Revenue.TotalRON)
+	s.Issuer = "Ștefan Dev PFA"
+	return s
+}
+
+func TestMatchClient(t *testing.T) {
+	items := sampleRevenues()
+	tests := []struct {
+		query, want, err string
+	}{
+		{"acme corp", "ACME Corp", ""},
+		{"glob", "Globex", ""},
+		{"acme", "", "matches 2 clients: ACME Corp, ACME Holding"},
+		{"initech", "", "no invoices"},
+	}
+	for _, tt := range tests {
+		got, err := MatchClient(items, tt.query)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("MatchClient(%q) error = %v, want %q", tt.query, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("MatchClient(%q) = %q, %v, want %q", tt.query, got, err, tt.want)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	s := sampleStatement()
+
+	if len(s.Lines) != 3 {
+		t.Fatalf("lines = %+v, want 3 (cancelled and other clients left out)", s.Lines)
+	}
+	var numbers []string
+	for _, l := range s.Lines {
+		numbers = append(numbers, l.Number)
+	}
+	if got := strings.Join(numbers, ","); got != "INV-001,INV-002,INV-003" {
+		t.Errorf("order = %s, want oldest first", got)
+	}
+
+	paid, eur, recent := s.Lines[0], s.Lines[1], s.Lines[2]
+	if paid.Status() != "Paid 2026-01-25" {
+		t.Errorf("paid status = %q", paid.Status())
+	}
+	// Issued 1 May, due 31 May, 30 days late on 30 June
+	if !eur.Overdue() || eur.DaysOverdue != 30 || eur.Status() != "Overdue 30 days" || eur.RON != 2500*money.Lei {
+		t.Errorf("EUR line = %+v", eur)
+	}
+	if recent.Overdue() || recent.Status() != "Unpaid" {
+		t.Errorf("recent line = %+v, want unpaid within terms", recent)
+	}
+
+	if s.Outstanding["EUR"] != 500*money.Lei || s.Outstanding["RON"] != 100*money.Lei || s.OutstandingRON != 2600*money.Lei {
+		t.Errorf("outstanding = %v (%s RON)", s.Outstanding, s.OutstandingRON)
+	}
+	if len(s.OverdueByCurrency) != 1 || s.OverdueRON != 2500*money.Lei || len(s.Overdue()) != 1 {
+		t.Errorf("overdue = %v (%s RON)", s.OverdueByCurrency, s.OverdueRON)
+	}
+}
+
+func TestRenderFormats(t *testing.T) {
+	s := sampleStatement()
+
+	var txt bytes.Buffer
+	if err := s.Render(&txt, "txt"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Statement of account: ACME Corp",
+		"Issued by Ștefan Dev PFA. As of 2026-06-30, payment terms 30 days",
+		"INV-002            2026-05-01 2026-05-31         500.00 EUR  Overdue 30 days",
+		"Invoiced:    1100.00 RON + 500.00 EUR",
+		"Outstanding: 100.00 RON + 500.00 EUR (2600.00 RON)",
+	} {
+		if !strings.Contains(txt.String(), want) {
+			t.Errorf("text missing %q\n%s", want, txt.String())
+		}
+	}
+
+	var md bytes.Buffer
+	if err := s.Render(&md, "md"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "| INV-002 | 2026-05-01 | 2026-05-31 | 500.00 EUR | **Overdue 30 days** |") {
+		t.Errorf("markdown row missing:\n%s", md.String())
+	}
+
+	s.Client = "<script>ACME</script>"
+	var html bytes.Buffer
+	if err := s.Render(&html, "html"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html.String(), "<script>") || !strings.Contains(html.String(), `<td class="overdue">Overdue 30 days</td>`) {
+		t.Errorf("html not escaped or overdue not marked:\n%s", html.String())
+	}
+
+	if err := s.Render(io.Discard, "pdf"); err == nil {
+		t.Error("Render accepted an unknown format")
+	}
+}
+
+// readEML parses a draft and decodes its quoted-printable body
+func readEML(t *testing.T, data string) (*mail.Message, string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("invalid eml: %v\n%s", err, data)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg, string(body)
+}
+
+func TestReminderDefaultTemplate(t *testing.T) {
+	s := sampleStatement()
+	var b bytes.Buffer
+	if err := s.Reminder(&b, config.DefaultReminderTemplate, "Ana Pop <ana@acme.example>", asOf); err != nil {
+		t.Fatalf("Reminder: %v", err)
+	}
+	if strings.Contains(b.String(), "\n") && !strings.Contains(b.String(), "\r\n") {
+		t.Error("eml lines must end in CRLF")
+	}
+
+	msg, body := readEML(t, b.String())
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Reamintire plată facturi restante - Ștefan Dev PFA" {
+		t.Errorf("subject = %q, %v", subject, err)
+	}
+	if msg.Header.Get("X-Unsent") != "1" || msg.Header.Get("To") != `"Ana Pop" <ana@acme.example>` {
+		t.Errorf("headers = %v", msg.Header)
+	}
+	for _, want := range []string{
+		"restante la data de 2026-06-30",
+		"- INV-002 din 2026-05-01, scadentă la 2026-05-31: 500.00 EUR (30 zile întârziere)",
+		"Total restant: 500.00 EUR",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "INV-003") {
+		t.Error("invoice within terms listed as overdue")
+	}
+}
+
+func TestReminderCustomTemplate(t *testing.T) {
+	s := sampleStatement()
+
+	tmpl := "to: billing@acme.example\nDate: ignored\nSubject: Overdue: {{len .Overdue}}\n\nPlease pay {{currencies .OverdueByCurrency}}.\n"
+	var b bytes.Buffer
+	if err := s.Reminder(&b, tmpl, "other@acme.example", asOf); err != nil {
+		t.Fatalf("Reminder: %v", err)
+	}
+	msg, body := readEML(t, b.String())
+	if msg.Header.Get("To") != "<billing@acme.example>" || msg.Header.Get("Subject") != "Overdue: 1" {
+		t.Errorf("headers = %v", msg.Header)
+	}
+	if msg.Header.Get("Date") != asOf.Format(time.RFC1123Z) {
+		t.Errorf("Date = %q, the template must not override it", msg.Header.Get("Date"))
+	}
+	if body != "Please pay 500.00 EUR.\r\n" {
+		t.Errorf("body = %q", body)
+	}
+
+	// A template without headers is all body with a default subject
+	b.Reset()
+	if err := s.Reminder(&b, "Hello {{.Client}}", "", asOf); err != nil {
+		t.Fatal(err)
+	}
+	if msg, body := readEML(t, b.String()); msg.Header.Get("Subject") != "Payment reminder" || body != "Hello ACME Corp" {
+		t.Errorf("headerless template: subject %q, body %q", msg.Header.Get("Subject"), body)
+	}
+
+	if err := s.Reminder(io.Discard, "{{.Nope}}", "", asOf); err == nil {
+		t.Error("Reminder accepted a template referencing a missing field")
+	}
+	if err := s.Reminder(io.Discard, "To: not an address\n\nhi", "", asOf); err == nil {
+		t.Error("Reminder accepted an invalid To address")
+	}
+}
@@ -0,0 +1,109 @@
+package taxes
+
+import (
+	"fmt"
+	"slices"
+
+	"solo-cli/config"
+	"solo-cli/money"
+)
+
+// RegimeResult is the outcome of one tax regime for the same business year
+type RegimeResult struct {
+	Name      string
+	Available bool   // false when the regime is not configured
+	Note      string // assumptions, or why the regime is unavailable
+
+	TaxBase     money.Money // what income tax or turnover tax is computed on
+	IncomeTax   money.Money // income tax (PFA) or turnover tax (micro)
+	CAS         money.Money
+	CASS        money.Money
+	DividendTax money.Money
+	TotalTaxes  money.Money
+	NetInPocket money.Money // revenues - expenses - regime costs - taxes
+}
+
+// Compare runs the same revenues and expenses through PFA sistem real, PFA
+// normă de venit for the primary CAEN code and a micro SRL paying out the
+// whole profit as dividends
+func Compare(totalRevenues, totalExpenses money.Money, primaryCAEN string, cfg *config.TaxConfig) []RegimeResult {
+	return []RegimeResult{
+		compareReal(totalRevenues, totalExpenses, cfg),
+		compareNorma(totalRevenues, totalExpenses, primaryCAEN, cfg),
+		compareMicro(totalRevenues, totalExpenses, primaryCAEN, cfg),
+	}
+}
+
+func compareReal(totalRevenues, totalExpenses money.Money, cfg *config.TaxConfig) RegimeResult {
+	r := Calculate(totalRevenues, totalExpenses, cfg)
+	return RegimeResult{
+		Name:        "PFA sistem real",
+		Available:   true,
+		TaxBase:     max(r.NetIncome-r.CAS.Amount-r.CASS.Amount, 0),
+		IncomeTax:   r.IncomeTax,
+		CAS:         r.CAS.Amount,
+		CASS:        r.CASS.Amount,
+		TotalTaxes:  r.TotalTaxes,
+		NetInPocket: r.NetAfterTax,
+	}
+}
+
+// compareNorma taxes the annual norm instead of the real net income: the
+// income tax, CAS and CASS follow the same rules with the norm as income,
+// while the real expenses are still paid
+func compareNorma(totalRevenues, totalExpenses money.Money, primaryCAEN string, cfg *config.TaxConfig) RegimeResult {
+	result := RegimeResult{Name: "PFA normă de venit"}
+	normaLei, ok := cfg.Regimes.NormaVenit[primaryCAEN]
+	if !ok || normaLei <= 0 {
+		result.Note = fmt.Sprintf("no norma_venit configured for CAEN %s", primaryCAEN)
+		if primaryCAEN == "" {
+			result.Note = "primary CAEN code unknown"
+		}
+		return result
+	}
+
+	norma := money.FromFloat(normaLei)
+	r := Calculate(norma, 0, cfg)
+	result.Available = true
+	result.Note = fmt.Sprintf("annual norm %s for CAEN %s", FormatRON(norma), primaryCAEN)
+	result.TaxBase = max(norma-r.CAS.Amount-r.CASS.Amount, 0)
+	result.IncomeTax = r.IncomeTax
+	result.CAS = r.CAS.Amount
+	result.CASS = r.CASS.Amount
+	result.TotalTaxes = r.TotalTaxes
+	result.NetInPocket = totalRevenues - totalExpenses - r.TotalTaxes
+	return result
+}
+
+// compareMicro pays the turnover tax on revenues, distributes the remaining
+// profit as dividends and pays dividend tax plus CASS on the 6/12/24
+// salarii brackets for other income. No CAS is paid, so no pension accrues
+func compareMicro(totalRevenues, totalExpenses money.Money, primaryCAEN string, cfg *config.TaxConfig) RegimeResult {
+	micro := cfg.Regimes.Micro
+	rate := micro.TurnoverTaxPercent
+	if slices.Contains(micro.HighRateCAEN, primaryCAEN) {
+		rate = micro.HighTurnoverTaxPercent
+	}
+
+	result := RegimeResult{
+		Name:      fmt.Sprintf("Micro SRL (%g%%)", rate),
+		Available: true,
+		TaxBase:   totalRevenues,
+		Note:      "whole profit paid out as dividends, no CAS (no pension contribution)",
+	}
+	result.IncomeTax = taxOn(totalRevenues, rate)
+
+	profit := totalRevenues - totalExpenses - money.FromFloat(micro.AnnualFixedCosts) - result.IncomeTax
+	if profit < 0 {
+		profit = 0
+	}
+	result.DividendTax = taxOn(profit, micro.DividendTaxPercent)
+
+	smb := money.FromFloat(cfg.SalariuMinimBrut)
+	cass := calculateContribution(profit, smb, cfg.CASSPercent, cfg.OtherCASSThresholds)
+	result.CASS = cass.Amount
+
+	result.TotalTaxes = result.IncomeTax + result.DividendTax + result.CASS
+	result.NetInPocket = profit - result.DividendTax - result.CASS
+	return result
+}
@@ -0,0 +1,88 @@
+package taxes
+
+import (
+	"testing"
+
+	"solo-cli/money"
+)
+
+var (
+	compareRevenues = 100000 * money.Lei
+	compareExpenses = 30000 * money.Lei
+)
+
+// Sistem real is exactly the regular calculation
+func TestCompareReal(t *testing.T) {
+	cfg := defaultCfg()
+
+	got := Compare(compareRevenues, compareExpenses, "6201", cfg)
+	if len(got) != 3 {
+		t.Fatalf("got %d regimes, want 3", len(got))
+	}
+
+	want := Calculate(compareRevenues, compareExpenses, cfg)
+	real := got[0]
+	if !real.Available || real.TotalTaxes != want.TotalTaxes || real.NetInPocket != want.NetAfterTax {
+		t.Errorf("sistem real = %+v, want taxes %v and net %v", real, want.TotalTaxes, want.NetAfterTax)
+	}
+}
+
+func TestCompareNormaNotConfigured(t *testing.T) {
+	got := Compare(compareRevenues, compareExpenses, "6201", defaultCfg())[1]
+	if got.Available || got.Note == "" {
+		t.Errorf("norma without config = %+v, want unavailable with a note", got)
+	}
+}
+
+// Under normă de venit the taxes follow the norm, not the real net income
+func TestCompareNorma(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.Regimes.NormaVenit = map[string]float64{"6201": 40000}
+
+	got := Compare(compareRevenues, compareExpenses, "6201", cfg)[1]
+
+	want := Calculate(40000*money.Lei, 0, cfg)
+	if !got.Available || got.TotalTaxes != want.TotalTaxes {
+		t.Fatalf("norma = %+v, want taxes %v", got, want.TotalTaxes)
+	}
+	if w := 70000*money.Lei - want.TotalTaxes; got.NetInPocket != w {
+		t.Errorf("NetInPocket = %v, want %v", got.NetInPocket, w)
+	}
+}
+
+// 1% turnover tax, 16% on the dividends and CASS on the 12 salarii bracket
+// (69000 RON of dividends is 17 SMB)
+func TestCompareMicro(t *testing.T) {
+	cfg := defaultCfg()
+
+	got := Compare(compareRevenues, compareExpenses, "6201", cfg)[2]
+
+	profit := 69000 * money.Lei
+	dividendTax := 11040 * money.Lei
+	cass := 4860 * money.Lei
+	if got.IncomeTax != 1000*money.Lei || got.DividendTax != dividendTax || got.CASS != cass {
+		t.Fatalf("micro = %+v, want turnover 1000, dividend tax %v, CASS %v", got, dividendTax, cass)
+	}
+	if got.CAS != 0 {
+		t.Errorf("micro CAS = %v, want 0", got.CAS)
+	}
+	if w := profit - dividendTax - cass; got.NetInPocket != w {
+		t.Errorf("NetInPocket = %v, want %v", got.NetInPocket, w)
+	}
+}
+
+func TestCompareMicroHighRateAndFixedCosts(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.Regimes.Micro.HighRateCAEN = []string{"6201"}
+	cfg.Regimes.Micro.AnnualFixedCosts = 80000
+
+	got := Compare(compareRevenues, compareExpenses, "6201", cfg)[2]
+
+	if got.IncomeTax != 3000*money.Lei {
+		t.Errorf("turnover tax = %v, want 3000 (3%%)", got.IncomeTax)
+	}
+	// Fixed costs eat the whole profit: nothing to pay out, nothing left
+	if got.DividendTax != 0 || got.CASS != 0 || got.NetInPocket != 0 {
+		t.Errorf("loss-making micro = %+v, want no dividends", got)
+	}
+}
@@ -0,0 +1,64 @@
+package taxes
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"solo-cli/money"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenInputs cover every CAS and CASS bracket, the exact bracket
+// boundaries and amounts whose taxes land on half a leu
+var goldenInputs = [][2]string{
+	{"0", "0"},
+	{"12345.67", "2345.67"},
+	{"24300", "0"},
+	{"24299.99", "0"},
+	{"48599.99", "0"},
+	{"48600", "0"},
+	{"48600.01", "0"},
+	{"60750", "0"},
+	{"61234.56", "789.01"},
+	{"97199.99", "0"},
+	{"97200", "0"},
+	{"125840.50", "42350.75"},
+	{"291600", "0"},
+	{"291600.01", "0"},
+	{"500000.05", "100000.10"},
+}
+
+// The golden breakdowns were checked against an independent decimal
+// implementation of the ANAF rules: every contribution and the income tax
+// are whole lei, the net amounts are exact to the ban
+func TestCalculateGolden(t *testing.T) {
+	cfg := defaultCfg()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-10s %-10s | %-10s %-8s %-8s %-8s %-8s %s\n", "revenues", "expenses", "net", "cas", "cass", "tax", "total", "net_after")
+	for _, in := range goldenInputs {
+		r := Calculate(money.MustParse(in[0]), money.MustParse(in[1]), cfg)
+		fmt.Fprintf(&b, "%-10s %-10s | %-10s %-8s %-8s %-8s %-8s %s\n", in[0], in[1],
+			r.NetIncome, r.CAS.Amount, r.CASS.Amount, r.IncomeTax, r.TotalTaxes, r.NetAfterTax)
+	}
+
+	path := filepath.Join("testdata", "breakdown.golden")
+	got := b.String()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch:\n got:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package taxes
+
+import (
+	"slices"
+
+	"solo-cli/config"
+	"solo-cli/money"
+)
+
+// OptimizationPoint is the outcome of adding extra deductible expenses
+type OptimizationPoint struct {
+	ExtraExpenses money.Money
+	NetIncome     money.Money
+	TotalTaxes    money.Money
+	NetAfterTax   money.Money
+	Gain          money.Money // NetAfterTax change vs spending nothing extra
+	CASLabel      string
+	CASSLabel     string
+}
+
+// CurvePoint is one sample of the tax curve over net income
+type CurvePoint struct {
+	NetIncome     money.Money
+	TotalTaxes    money.Money
+	EffectiveRate float64 // total taxes / net income * 100
+	MarginalRate  float64 // extra tax per extra RON up to the next sample * 100
+}
+
+// Optimization holds the combined CAS, CASS and income tax effect of extra
+// deductible expenses
+type Optimization struct {
+	// Candidates are the current position followed by every expense amount
+	// that drops net income just below a CAS or CASS threshold, by
+	// increasing extra expenses
+	Candidates []OptimizationPoint
+	// Best is the candidate with the highest net after tax, the current
+	// position when no extra spending pays for itself
+	Best OptimizationPoint
+	// Curve samples the taxes from 0 up to the current net income
+	Curve []CurvePoint
+}
+
+// optimizeCurvePoints is how many samples Optimize takes for its curve
+const optimizeCurvePoints = 20
+
+// Optimize evaluates extra deductible expenses across both contributions and
+// income tax together. Net after tax only ever rises with spending at a
+// threshold cliff, so the candidates are the points just below each
+// threshold (1 RON under, like ExpensesToPrev) plus spending nothing
+func Optimize(totalRevenues, totalExpenses money.Money, cfg *config.TaxConfig) *Optimization {
+	base := Calculate(totalRevenues, totalExpenses, cfg)
+	smb := money.FromFloat(cfg.SalariuMinimBrut)
+
+	extras := []money.Money{0}
+	seen := map[money.Money]bool{}
+	for _, thresholds := range [][]config.TaxThreshold{cfg.CASThresholds, cfg.CASSThresholds} {
+		for _, t := range thresholds {
+			boundary := smb.MulFloat(t.MinSalaries)
+			if t.MinSalaries <= 0 || boundary > base.NetIncome || seen[boundary] {
+				continue
+			}
+			seen[boundary] = true
+			extras = append(extras, base.NetIncome-boundary+money.Lei)
+		}
+	}
+	slices.Sort(extras)
+
+	opt := &Optimization{}
+	for _, extra := range extras {
+		r := Calculate(totalRevenues, totalExpenses+extra, cfg)
+		p := OptimizationPoint{
+			ExtraExpenses: extra,
+			NetIncome:     r.NetIncome,
+			TotalTaxes:    r.TotalTaxes,
+			NetAfterTax:   r.NetAfterTax,
+			Gain:          r.NetAfterTax - base.NetAfterTax,
+			CASLabel:      r.CAS.Label,
+			CASSLabel:     r.CASS.Label,
+		}
+		opt.Candidates = append(opt.Candidates, p)
+		// Strictly greater keeps the smallest spend on ties
+		if len(opt.Candidates) == 1 || p.NetAfterTax > opt.Best.NetAfterTax {
+			opt.Best = p
+		}
+	}
+
+	opt.Curve = Curve(base.NetIncome, optimizeCurvePoints, cfg)
+	return opt
+}
+
+// Curve samples total taxes, effective and marginal rates at evenly spaced
+// net incomes from 0 to maxIncome (inclusive). The marginal rate of
+// a sample is measured up to the next one, so threshold cliffs show up as
+// spikes; the last sample reuses the previous rate
+func Curve(maxIncome money.Money, points int, cfg *config.TaxConfig) []CurvePoint {
+	if points < 2 || maxIncome <= 0 {
+		return nil
+	}
+
+	step := maxIncome.Float64() / float64(points-1)
+	curve := make([]CurvePoint, points)
+	for i := range curve {
+		r := Calculate(maxIncome.MulFloat(float64(i)/float64(points-1)), 0, cfg)
+		curve[i] = CurvePoint{NetIncome: r.NetIncome, TotalTaxes: r.TotalTaxes, EffectiveRate: r.EffectiveRate}
+	}
+	for i := 0; i < points-1; i++ {
+		curve[i].MarginalRate = (curve[i+1].TotalTaxes - curve[i].TotalTaxes).Float64() / step * 100
+	}
+	curve[points-1].MarginalRate = curve[points-2].MarginalRate
+	return curve
+}
@@ -0,0 +1,92 @@
+package taxes
+
+import (
+	"math"
+	"testing"
+
+	"solo-cli/money"
+)
+
+// Just over the 12 salarii CAS threshold the whole 12 SMB CAS base kicks
+// in, so spending the surplus plus 1 RON is the best move
+func TestOptimizeDropsCASBracket(t *testing.T) {
+	cfg := defaultCfg()
+	revenues := 12*smbOf(cfg) + 1000*money.Lei
+
+	opt := Optimize(revenues, 0, cfg)
+
+	if len(opt.Candidates) < 2 || opt.Candidates[0].ExtraExpenses != 0 {
+		t.Fatalf("candidates must start with the current position: %+v", opt.Candidates)
+	}
+	if opt.Best.ExtraExpenses != 1001*money.Lei {
+		t.Fatalf("Best.ExtraExpenses = %v, want 1001", opt.Best.ExtraExpenses)
+	}
+	if opt.Best.CASLabel != cfg.CASThresholds[0].Label {
+		t.Errorf("Best.CASLabel = %q, want %q", opt.Best.CASLabel, cfg.CASThresholds[0].Label)
+	}
+
+	base := Calculate(revenues, 0, cfg)
+	after := Calculate(revenues, 1001*money.Lei, cfg)
+	if opt.Best.Gain != after.NetAfterTax-base.NetAfterTax || opt.Best.Gain <= 0 {
+		t.Errorf("Best.Gain = %v, want %v (positive)", opt.Best.Gain, after.NetAfterTax-base.NetAfterTax)
+	}
+}
+
+// Dropping into the CASS minimum bracket never pays off, so with only that
+// threshold below net income the best move is to spend nothing
+func TestOptimizeNothingWorthSpending(t *testing.T) {
+	cfg := defaultCfg()
+
+	opt := Optimize(30000*money.Lei, 0, cfg)
+
+	if len(opt.Candidates) != 2 {
+		t.Fatalf("got %d candidates, want 2 (current + CASS 6 salarii)", len(opt.Candidates))
+	}
+	if opt.Best.ExtraExpenses != 0 || opt.Best.Gain != 0 {
+		t.Errorf("Best = %+v, want the current position", opt.Best)
+	}
+	if opt.Candidates[1].Gain >= 0 {
+		t.Errorf("dropping to CASS minimum gained %v, want a loss", opt.Candidates[1].Gain)
+	}
+}
+
+func TestOptimizeZeroIncome(t *testing.T) {
+	opt := Optimize(0, 0, defaultCfg())
+	if len(opt.Candidates) != 1 || opt.Best.ExtraExpenses != 0 {
+		t.Errorf("zero income: candidates %+v, best %+v", opt.Candidates, opt.Best)
+	}
+	if opt.Curve != nil {
+		t.Errorf("zero income curve = %v, want nil", opt.Curve)
+	}
+}
+
+// The marginal rate spikes across the 12 salarii CAS cliff and is the plain
+// CASS + income tax rate inside the proportional bracket
+func TestCurve(t *testing.T) {
+	cfg := defaultCfg()
+	smb := smbOf(cfg)
+
+	curve := Curve(24*smb, 25, cfg)
+	if len(curve) != 25 {
+		t.Fatalf("len = %d, want 25", len(curve))
+	}
+	if curve[0].NetIncome != 0 || curve[24].NetIncome != 24*smb {
+		t.Errorf("curve spans %v..%v, want 0..%v", curve[0].NetIncome, curve[24].NetIncome, 24*smb)
+	}
+
+	// Samples are 1 SMB apart: 8 → 9 SMB is proportional CASS (10%) plus
+	// 10% income tax on the remaining 90%, so 19%, give or take the whole
+	// lei rounding of each sample's taxes
+	if math.Abs(curve[8].MarginalRate-19) > 0.1 {
+		t.Errorf("marginal rate at 8 SMB = %f, want 19", curve[8].MarginalRate)
+	}
+	// 11 → 12 SMB crosses into CAS on 12 salarii
+	if curve[11].MarginalRate < 100 {
+		t.Errorf("marginal rate across the CAS cliff = %f, want a spike over 100%%", curve[11].MarginalRate)
+	}
+	for _, p := range curve[1:] {
+		if p.EffectiveRate <= 0 {
+			t.Errorf("effective rate at %v = %f, want positive", p.NetIncome, p.EffectiveRate)
+		}
+	}
+}
@@ -2,89 +2,169 @@ package taxes
 
 import (
 	"fmt"
-	"math"
 
 	"solo-cli/config"
+	"solo-cli/money"
 )
 
 // ThresholdResult describes the tax computed for a specific contribution
 type ThresholdResult struct {
 	Label          string
 	Percentage     float64
-	Base           float64
-	Amount         float64
-	NextLabel      string  // label of the next threshold (empty if at max)
-	BufferToNext   float64 // how much more net income before reaching the next threshold
-	PrevLabel      string  // label of the previous (lower) bracket (empty if already at lowest)
-	ExpensesToPrev float64 // extra deductible expenses needed to drop into the previous bracket
+	Base           money.Money
+	Amount         money.Money // in whole lei, as declared to ANAF
+	NextLabel      string      // label of the next threshold (empty if at max)
+	BufferToNext   money.Money // how much more net income before reaching the next threshold
+	PrevLabel      string      // label of the previous (lower) bracket (empty if already at lowest)
+	ExpensesToPrev money.Money // extra deductible expenses needed to drop into the previous bracket
 }
 
 // TaxBreakdown holds the full tax calculation result
 type TaxBreakdown struct {
-	NetIncome       float64
-	SalariuMinimBrut float64
-	SalariesCount   float64 // net income expressed in multiples of SMB
+	NetIncome        money.Money
+	SalariuMinimBrut money.Money
+	SalariesCount    float64 // net income expressed in multiples of SMB
 
 	CAS       ThresholdResult
 	CASS      ThresholdResult
-	IncomeTax float64 // 10% of (net income - CAS - CASS)
+	IncomeTax money.Money // 10% of (net income - CAS - CASS), in whole lei
 
-	TotalTaxes float64
-	NetAfterTax float64
-	EffectiveRate float64 // total taxes / net income * 100
+	// Non-PFA income, only set when extra income was declared
+	OtherIncome      []OtherIncomeResult
+	OtherIncomeTotal money.Money
+	OtherCASS        ThresholdResult // CASS over the combined non-PFA income
+	OtherIncomeTax   money.Money     // income tax on other income paid through the declarația unică
+	WithheldTax      money.Money     // income tax on other income already withheld at source
+
+	TotalTaxes    money.Money
+	NetAfterTax   money.Money
+	EffectiveRate float64 // total taxes / total income * 100
+}
+
+// OtherIncomeResult is the income tax on one declared non-PFA income
+type OtherIncomeResult struct {
+	Category config.IncomeCategory
+	Amount   money.Money
+	Taxable  money.Money // amount after the flat-rate deduction
+	Tax      money.Money
 }
 
 // Calculate computes the full tax breakdown from revenues and expenses
-func Calculate(totalRevenues, totalExpenses float64, cfg *config.TaxConfig) *TaxBreakdown {
+func Calculate(totalRevenues, totalExpenses money.Money, cfg *config.TaxConfig) *TaxBreakdown {
+	return CalculateWithIncome(totalRevenues, totalExpenses, nil, cfg)
+}
+
+// CalculateWithIncome computes the tax breakdown including declared non-PFA
+// income. Each category is taxed by its own rules and the CASS-eligible
+// ones share one CASS base on the 6/12/24 salarii brackets, capped together
+// with the PFA base at CASSCapSalaries. Unknown categories are ignored.
+// Amounts are exact to the ban; every tax and contribution is rounded to
+// whole lei like the declarația unică does
+func CalculateWithIncome(totalRevenues, totalExpenses money.Money, extra []config.ExtraIncome, cfg *config.TaxConfig) *TaxBreakdown {
 	netIncome := totalRevenues - totalExpenses
 	if netIncome < 0 {
 		netIncome = 0
 	}
 
-	smb := cfg.SalariuMinimBrut
-	salaries := netIncome / smb
+	smb := money.FromFloat(cfg.SalariuMinimBrut)
+	salaries := netIncome.Float64() / cfg.SalariuMinimBrut
 
-	cas := calculateContribution(netIncome, salaries, smb, cfg.CASPercent, cfg.CASThresholds)
-	cass := calculateContribution(netIncome, salaries, smb, cfg.CASSPercent, cfg.CASSThresholds)
+	cas := calculateContribution(netIncome, smb, cfg.CASPercent, cfg.CASThresholds)
+	cass := calculateContribution(netIncome, smb, cfg.CASSPercent, cfg.CASSThresholds)
 
 	// Income tax = percentage of (net income - CAS - CASS)
 	taxableIncome := netIncome - cas.Amount - cass.Amount
 	if taxableIncome < 0 {
 		taxableIncome = 0
 	}
-	incomeTax := math.Round(taxableIncome*cfg.IncomeTaxPercent) / 100
-
-	totalTaxes := cas.Amount + cass.Amount + incomeTax
-	netAfterTax := netIncome - totalTaxes
-
-	effectiveRate := 0.0
-	if netIncome > 0 {
-		effectiveRate = totalTaxes / netIncome * 100
-	}
+	incomeTax := taxOn(taxableIncome, cfg.IncomeTaxPercent)
 
-	return &TaxBreakdown{
+	result := &TaxBreakdown{
 		NetIncome:        netIncome,
 		SalariuMinimBrut: smb,
 		SalariesCount:    salaries,
 		CAS:              cas,
 		CASS:             cass,
 		IncomeTax:        incomeTax,
-		TotalTaxes:       totalTaxes,
-		NetAfterTax:      netAfterTax,
-		EffectiveRate:    effectiveRate,
 	}
+	if len(extra) > 0 {
+		result.addOtherIncome(extra, cfg)
+	}
+
+	result.TotalTaxes = cas.Amount + cass.Amount + incomeTax + result.OtherCASS.Amount + result.OtherIncomeTax + result.WithheldTax
+	totalIncome := netIncome + result.OtherIncomeTotal
+	result.NetAfterTax = totalIncome - result.TotalTaxes
+	if totalIncome > 0 {
+		result.EffectiveRate = result.TotalTaxes.Float64() / totalIncome.Float64() * 100
+	}
+
+	return result
 }
 
-func calculateContribution(netIncome, salaries, smb, percent float64, thresholds []config.TaxThreshold) ThresholdResult {
-	result := ThresholdResult{Percentage: percent}
+// addOtherIncome taxes the declared non-PFA income and computes the CASS
+// on the combined CASS-eligible amount
+func (r *TaxBreakdown) addOtherIncome(extra []config.ExtraIncome, cfg *config.TaxConfig) {
+	smb := money.FromFloat(cfg.SalariuMinimBrut)
+	var cassIncome money.Money
+	for _, e := range extra {
+		cat := cfg.IncomeCategory(e.Category)
+		if cat == nil || e.Amount <= 0 {
+			continue
+		}
+		taxable := e.Amount.Percent(100 - cat.DeductionPercent)
+		tax := taxOn(taxable, cat.IncomeTaxPercent)
+		r.OtherIncome = append(r.OtherIncome, OtherIncomeResult{Category: *cat, Amount: e.Amount, Taxable: taxable, Tax: tax})
+		r.OtherIncomeTotal += e.Amount
+		if cat.WithheldAtSource {
+			r.WithheldTax += tax
+		} else {
+			r.OtherIncomeTax += tax
+		}
+		if cat.CASS {
+			cassIncome += e.Amount
+		}
+	}
 
-	for i, t := range thresholds {
-		maxSal := t.MaxSalaries
-		if maxSal == 0 {
-			maxSal = math.MaxFloat64
+	r.OtherCASS = calculateContribution(cassIncome, smb, cfg.CASSPercent, cfg.OtherCASSThresholds)
+	// The dropping-a-bracket hint is PFA expense advice, it does not apply
+	// to income that cannot be offset by expenses
+	r.OtherCASS.PrevLabel, r.OtherCASS.ExpensesToPrev = "", 0
+
+	// The PFA and other income CASS bases are capped together
+	if cfg.CASSCapSalaries > 0 && r.OtherCASS.Base > 0 {
+		room := smb.MulFloat(cfg.CASSCapSalaries) - r.CASS.Base
+		if room < 0 {
+			room = 0
 		}
+		if r.OtherCASS.Base > room {
+			r.OtherCASS.Base = room
+			r.OtherCASS.Amount = taxOn(room, cfg.CASSPercent)
+		}
+	}
+}
 
-		if salaries >= t.MinSalaries && salaries < maxSal {
+// taxOn is percent of base rounded to whole lei, as taxes and contributions
+// are declared to ANAF
+func taxOn(base money.Money, percent float64) money.Money {
+	return base.Percent(percent).RoundLei()
+}
+
+// InBracket reports whether a net income falls in a threshold's bracket.
+// The income is compared with the bracket bounds in bani, so an income of
+// exactly 12 SMB is in the 12 salarii bracket regardless of float division
+func InBracket(t config.TaxThreshold, netIncome, smb money.Money) bool {
+	if netIncome < smb.MulFloat(t.MinSalaries) {
+		return false
+	}
+	return t.MaxSalaries == 0 || netIncome < smb.MulFloat(t.MaxSalaries)
+}
+
+// calculateContribution applies the bracket the net income falls in
+func calculateContribution(netIncome, smb money.Money, percent float64, thresholds []config.TaxThreshold) ThresholdResult {
+	result := ThresholdResult{Percentage: percent}
+
+	for i, t := range thresholds {
+		if InBracket(t, netIncome, smb) {
 			result.Label = t.Label
 
 			switch {
@@ -95,18 +175,18 @@ func calculateContribution(netIncome, salaries, smb, percent float64, thresholds
 			case t.BaseSalaries == -1:
 				// Proportional: use actual net income
 				result.Base = netIncome
-				result.Amount = math.Round(netIncome*percent) / 100
+				result.Amount = taxOn(netIncome, percent)
 			default:
 				// Fixed: base = BaseSalaries * SMB
-				result.Base = t.BaseSalaries * smb
-				result.Amount = math.Round(t.BaseSalaries*smb*percent) / 100
+				result.Base = smb.MulFloat(t.BaseSalaries)
+				result.Amount = taxOn(result.Base, percent)
 			}
 
 			// Calculate buffer to next threshold
 			if i+1 < len(thresholds) {
 				next := thresholds[i+1]
 				result.NextLabel = next.Label
-				nextThresholdIncome := next.MinSalaries * smb
+				nextThresholdIncome := smb.MulFloat(next.MinSalaries)
 				result.BufferToNext = nextThresholdIncome - netIncome
 				if result.BufferToNext < 0 {
 					result.BufferToNext = 0
@@ -118,22 +198,22 @@ func calculateContribution(netIncome, salaries, smb, percent float64, thresholds
 			// required expense. Otherwise the suggestion is bad advice.
 			if i > 0 {
 				prev := thresholds[i-1]
-				currentMinIncome := t.MinSalaries * smb
-				expensesNeeded := math.Round((netIncome-currentMinIncome)*100)/100 + 1
+				currentMinIncome := smb.MulFloat(t.MinSalaries)
+				expensesNeeded := netIncome - currentMinIncome + money.Lei
 				if expensesNeeded < 0 {
 					expensesNeeded = 0
 				}
 
 				// Hypothetical income just below current bracket's lower bound
-				hypoIncome := currentMinIncome - 1
-				var prevAmount float64
+				hypoIncome := currentMinIncome - money.Lei
+				var prevAmount money.Money
 				switch {
 				case prev.BaseSalaries == 0:
 					prevAmount = 0
 				case prev.BaseSalaries == -1:
-					prevAmount = math.Round(hypoIncome*percent) / 100
+					prevAmount = taxOn(hypoIncome, percent)
 				default:
-					prevAmount = math.Round(prev.BaseSalaries*smb*percent) / 100
+					prevAmount = taxOn(smb.MulFloat(prev.BaseSalaries), percent)
 				}
 
 				if result.Amount-prevAmount > expensesNeeded {
@@ -151,21 +231,21 @@ func calculateContribution(netIncome, salaries, smb, percent float64, thresholds
 		last := thresholds[len(thresholds)-1]
 		result.Label = last.Label
 		if last.BaseSalaries > 0 {
-			result.Base = last.BaseSalaries * smb
-			result.Amount = math.Round(last.BaseSalaries*smb*percent) / 100
+			result.Base = smb.MulFloat(last.BaseSalaries)
+			result.Amount = taxOn(result.Base, percent)
 		}
 	}
 
 	return result
 }
 
-// FormatRON formats a float as RON currency
-func FormatRON(amount float64) string {
-	return fmt.Sprintf("%.2f RON", amount)
+// FormatRON formats an amount as RON currency
+func FormatRON(amount money.Money) string {
+	return amount.String() + " RON"
 }
 
 // FormatBuffer returns a human-readable buffer description
-func FormatBuffer(buffer float64) string {
+func FormatBuffer(buffer money.Money) string {
 	if buffer <= 0 {
 		return "plafonul a fost atins"
 	}
@@ -173,6 +253,6 @@ func FormatBuffer(buffer float64) string {
 }
 
 // FormatExpensesHint returns a human-readable hint for dropping a bracket
-func FormatExpensesHint(amount float64) string {
+func FormatExpensesHint(amount money.Money) string {
 	return fmt.Sprintf("Surplus: %s (adaugă cheltuieli pentru a coborî sub plafon)", FormatRON(amount))
 }
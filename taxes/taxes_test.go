@@ -1,18 +1,45 @@
 package taxes
 
 import (
-	"math"
 	"testing"
 
 	"solo-cli/config"
+	"solo-cli/money"
 )
 
 func defaultCfg() *config.TaxConfig {
 	return config.DefaultTaxConfig()
 }
 
-func almostEqual(a, b float64) bool {
-	return math.Abs(a-b) < 0.01
+// smbOf is the configured SMB as money
+func smbOf(cfg *config.TaxConfig) money.Money {
+	return money.FromFloat(cfg.SalariuMinimBrut)
+}
+
+// A bracket holds its lower bound and not its upper one, the open-ended
+// last bracket everything above
+func TestInBracket(t *testing.T) {
+	smb := 4050 * money.Lei
+	mid := config.TaxThreshold{MinSalaries: 12, MaxSalaries: 24}
+	top := config.TaxThreshold{MinSalaries: 24}
+	tests := []struct {
+		income   money.Money
+		mid, top bool
+	}{
+		{12*smb - 1, false, false},
+		{12 * smb, true, false},
+		{24*smb - 1, true, false},
+		{24 * smb, false, true},
+		{1000 * smb, false, true},
+	}
+	for _, tt := range tests {
+		if got := InBracket(mid, tt.income, smb); got != tt.mid {
+			t.Errorf("InBracket(12-24, %s) = %v", tt.income, got)
+		}
+		if got := InBracket(top, tt.income, smb); got != tt.top {
+			t.Errorf("InBracket(24-, %s) = %v", tt.income, got)
+		}
+	}
 }
 
 func TestCalculateZeroAndNegativeNetIncome(t *testing.T) {
@@ -20,21 +47,21 @@ func TestCalculateZeroAndNegativeNetIncome(t *testing.T) {
 
 	for _, tc := range []struct {
 		name               string
-		revenues, expenses float64
+		revenues, expenses money.Money
 	}{
 		{"zero", 0, 0},
-		{"negative", 1000, 5000},
+		{"negative", 1000 * money.Lei, 5000 * money.Lei},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			r := Calculate(tc.revenues, tc.expenses, cfg)
 			if r.NetIncome != 0 {
-				t.Errorf("NetIncome = %f, want 0", r.NetIncome)
+				t.Errorf("NetIncome = %v, want 0", r.NetIncome)
 			}
 			if r.CAS.Amount != 0 {
-				t.Errorf("CAS.Amount = %f, want 0", r.CAS.Amount)
+				t.Errorf("CAS.Amount = %v, want 0", r.CAS.Amount)
 			}
 			if r.IncomeTax != 0 {
-				t.Errorf("IncomeTax = %f, want 0", r.IncomeTax)
+				t.Errorf("IncomeTax = %v, want 0", r.IncomeTax)
 			}
 			if r.EffectiveRate != 0 {
 				t.Errorf("EffectiveRate = %f, want 0", r.EffectiveRate)
@@ -46,59 +73,54 @@ func TestCalculateZeroAndNegativeNetIncome(t *testing.T) {
 // Net income below 6 salarii: CAS exempt, CASS on minimum base of 6 SMB
 func TestCalculateBelowAllThresholds(t *testing.T) {
 	cfg := defaultCfg()
-	smb := cfg.SalariuMinimBrut
+	smb := smbOf(cfg)
 
 	r := Calculate(3*smb, 0, cfg)
 
 	if r.CAS.Amount != 0 {
-		t.Errorf("CAS.Amount = %f, want 0 (exempt under 12 salarii)", r.CAS.Amount)
+		t.Errorf("CAS.Amount = %v, want 0 (exempt under 12 salarii)", r.CAS.Amount)
 	}
-	wantCASS := math.Round(6*smb*cfg.CASSPercent) / 100
-	if !almostEqual(r.CASS.Amount, wantCASS) {
-		t.Errorf("CASS.Amount = %f, want %f (minimum on 6 salarii)", r.CASS.Amount, wantCASS)
+	// 10% of 6 * 4050
+	if want := money.MustParse("2430"); r.CASS.Amount != want {
+		t.Errorf("CASS.Amount = %v, want %v (minimum on 6 salarii)", r.CASS.Amount, want)
 	}
 	if r.CASS.Base != 6*smb {
-		t.Errorf("CASS.Base = %f, want %f", r.CASS.Base, 6*smb)
+		t.Errorf("CASS.Base = %v, want %v", r.CASS.Base, 6*smb)
 	}
 }
 
 // Net income between 12 and 24 salarii: CAS on fixed 12 SMB base, CASS proportional
 func TestCalculateMidBracket(t *testing.T) {
 	cfg := defaultCfg()
-	smb := cfg.SalariuMinimBrut
-	netIncome := 15 * smb
+	netIncome := 15 * smbOf(cfg)
 
 	r := Calculate(netIncome, 0, cfg)
 
-	wantCAS := math.Round(12*smb*cfg.CASPercent) / 100
-	if !almostEqual(r.CAS.Amount, wantCAS) {
-		t.Errorf("CAS.Amount = %f, want %f", r.CAS.Amount, wantCAS)
+	// 25% of 12 * 4050, 10% of 60750, 10% of 60750 - 12150 - 6075
+	wantCAS := money.MustParse("12150")
+	if r.CAS.Amount != wantCAS {
+		t.Errorf("CAS.Amount = %v, want %v", r.CAS.Amount, wantCAS)
 	}
-	wantCASS := math.Round(netIncome*cfg.CASSPercent) / 100
-	if !almostEqual(r.CASS.Amount, wantCASS) {
-		t.Errorf("CASS.Amount = %f, want %f (proportional)", r.CASS.Amount, wantCASS)
+	wantCASS := money.MustParse("6075")
+	if r.CASS.Amount != wantCASS {
+		t.Errorf("CASS.Amount = %v, want %v (proportional)", r.CASS.Amount, wantCASS)
 	}
-
-	wantTaxable := netIncome - wantCAS - wantCASS
-	wantIncomeTax := math.Round(wantTaxable*cfg.IncomeTaxPercent) / 100
-	if !almostEqual(r.IncomeTax, wantIncomeTax) {
-		t.Errorf("IncomeTax = %f, want %f", r.IncomeTax, wantIncomeTax)
+	wantIncomeTax := money.MustParse("4253") // 4252.50 rounds up
+	if r.IncomeTax != wantIncomeTax {
+		t.Errorf("IncomeTax = %v, want %v", r.IncomeTax, wantIncomeTax)
 	}
-	if !almostEqual(r.TotalTaxes, wantCAS+wantCASS+wantIncomeTax) {
-		t.Errorf("TotalTaxes = %f, want %f", r.TotalTaxes, wantCAS+wantCASS+wantIncomeTax)
+	if want := wantCAS + wantCASS + wantIncomeTax; r.TotalTaxes != want {
+		t.Errorf("TotalTaxes = %v, want %v", r.TotalTaxes, want)
 	}
 }
 
 // Above the top CAS threshold (24+ salarii): CAS on fixed 24 SMB base
 func TestCalculateTopCASBracket(t *testing.T) {
 	cfg := defaultCfg()
-	smb := cfg.SalariuMinimBrut
-
-	r := Calculate(30*smb, 0, cfg)
+	r := Calculate(30*smbOf(cfg), 0, cfg)
 
-	wantCAS := math.Round(24*smb*cfg.CASPercent) / 100
-	if !almostEqual(r.CAS.Amount, wantCAS) {
-		t.Errorf("CAS.Amount = %f, want %f", r.CAS.Amount, wantCAS)
+	if want := money.MustParse("24300"); r.CAS.Amount != want {
+		t.Errorf("CAS.Amount = %v, want %v", r.CAS.Amount, want)
 	}
 	if r.CAS.NextLabel != "" {
 		t.Errorf("CAS.NextLabel = %q, want empty (already at top bracket)", r.CAS.NextLabel)
@@ -108,37 +130,37 @@ func TestCalculateTopCASBracket(t *testing.T) {
 // Above the CASS cap: CASS frozen at the capped base regardless of income
 func TestCalculateCASSCapped(t *testing.T) {
 	cfg := defaultCfg()
-	smb := cfg.SalariuMinimBrut
+	smb := smbOf(cfg)
 	capSalaries := cfg.CASSThresholds[len(cfg.CASSThresholds)-1].MinSalaries
 
-	r := Calculate((capSalaries+10)*smb, 0, cfg)
+	r := Calculate(smb.MulFloat(capSalaries+10), 0, cfg)
 
-	wantCASS := math.Round(capSalaries*smb*cfg.CASSPercent) / 100
-	if !almostEqual(r.CASS.Amount, wantCASS) {
-		t.Errorf("CASS.Amount = %f, want %f (capped)", r.CASS.Amount, wantCASS)
+	capBase := smb.MulFloat(capSalaries)
+	if want := capBase.Percent(cfg.CASSPercent).RoundLei(); r.CASS.Amount != want {
+		t.Errorf("CASS.Amount = %v, want %v (capped)", r.CASS.Amount, want)
 	}
-	if r.CASS.Base != capSalaries*smb {
-		t.Errorf("CASS.Base = %f, want %f", r.CASS.Base, capSalaries*smb)
+	if r.CASS.Base != capBase {
+		t.Errorf("CASS.Base = %v, want %v", r.CASS.Base, capBase)
 	}
 }
 
 // Buffer must point at the boundary where the contribution actually changes
 func TestBufferToNext(t *testing.T) {
 	cfg := defaultCfg()
-	smb := cfg.SalariuMinimBrut
-	netIncome := 11.2 * smb
+	smb := smbOf(cfg)
+	netIncome := smb.MulFloat(11.2)
 
 	r := Calculate(netIncome, 0, cfg)
 
 	wantCASBuffer := 12*smb - netIncome
-	if !almostEqual(r.CAS.BufferToNext, wantCASBuffer) {
-		t.Errorf("CAS.BufferToNext = %f, want %f", r.CAS.BufferToNext, wantCASBuffer)
+	if r.CAS.BufferToNext != wantCASBuffer {
+		t.Errorf("CAS.BufferToNext = %v, want %v", r.CAS.BufferToNext, wantCASBuffer)
 	}
 
 	capSalaries := cfg.CASSThresholds[len(cfg.CASSThresholds)-1].MinSalaries
-	wantCASSBuffer := capSalaries*smb - netIncome
-	if !almostEqual(r.CASS.BufferToNext, wantCASSBuffer) {
-		t.Errorf("CASS.BufferToNext = %f, want %f", r.CASS.BufferToNext, wantCASSBuffer)
+	wantCASSBuffer := smb.MulFloat(capSalaries) - netIncome
+	if r.CASS.BufferToNext != wantCASSBuffer {
+		t.Errorf("CASS.BufferToNext = %v, want %v", r.CASS.BufferToNext, wantCASSBuffer)
 	}
 }
 
@@ -146,21 +168,22 @@ func TestBufferToNext(t *testing.T) {
 // expense costs, suppressed for proportional CASS where it is a net loss
 func TestSurplusHint(t *testing.T) {
 	cfg := defaultCfg()
-	smb := cfg.SalariuMinimBrut
-	netIncome := 12.5 * smb
+	smb := smbOf(cfg)
+	netIncome := smb.MulFloat(12.5)
 
 	r := Calculate(netIncome, 0, cfg)
 
 	if r.CAS.PrevLabel == "" {
 		t.Fatal("CAS.PrevLabel empty, want surplus hint just above 12 salarii")
 	}
-	wantExpenses := math.Round((netIncome-12*smb)*100)/100 + 1
-	if !almostEqual(r.CAS.ExpensesToPrev, wantExpenses) {
-		t.Errorf("CAS.ExpensesToPrev = %f, want %f", r.CAS.ExpensesToPrev, wantExpenses)
+	// 1 RON under 12 salarii: 50625 - 48600 + 1
+	wantExpenses := money.MustParse("2026")
+	if r.CAS.ExpensesToPrev != wantExpenses {
+		t.Errorf("CAS.ExpensesToPrev = %v, want %v", r.CAS.ExpensesToPrev, wantExpenses)
 	}
 	// CAS saving (12 SMB * 25%) must beat the expense needed
-	if saving := math.Round(12*smb*cfg.CASPercent) / 100; saving <= wantExpenses {
-		t.Errorf("hint fired but saving %f <= expense %f", saving, wantExpenses)
+	if saving := (12 * smb).Percent(cfg.CASPercent); saving <= wantExpenses {
+		t.Errorf("hint fired but saving %v <= expense %v", saving, wantExpenses)
 	}
 
 	if r.CASS.PrevLabel != "" {
@@ -173,37 +196,122 @@ func TestSurplusHint(t *testing.T) {
 // proportional in between, so no CASS hint should reference 12 salarii
 func TestCalculateJustUnderCASThreshold(t *testing.T) {
 	cfg := defaultCfg()
-	netIncome := 11.3 * cfg.SalariuMinimBrut
+	smb := smbOf(cfg)
+	netIncome := smb.MulFloat(11.3)
+	expenses := 25000 * money.Lei
 
-	r := Calculate(netIncome+25000, 25000, cfg)
+	r := Calculate(netIncome+expenses, expenses, cfg)
 
-	if !almostEqual(r.NetIncome, netIncome) {
-		t.Fatalf("NetIncome = %f, want %f", r.NetIncome, netIncome)
+	if r.NetIncome != netIncome {
+		t.Fatalf("NetIncome = %v, want %v", r.NetIncome, netIncome)
 	}
 	if r.CAS.Amount != 0 {
-		t.Errorf("CAS.Amount = %f, want 0", r.CAS.Amount)
+		t.Errorf("CAS.Amount = %v, want 0", r.CAS.Amount)
 	}
-	if !almostEqual(r.CAS.BufferToNext, 12*cfg.SalariuMinimBrut-netIncome) {
-		t.Errorf("CAS.BufferToNext = %f, want %f", r.CAS.BufferToNext, 12*cfg.SalariuMinimBrut-netIncome)
+	if r.CAS.BufferToNext != 12*smb-netIncome {
+		t.Errorf("CAS.BufferToNext = %v, want %v", r.CAS.BufferToNext, 12*smb-netIncome)
 	}
-	wantCASS := math.Round(netIncome*cfg.CASSPercent) / 100
-	if !almostEqual(r.CASS.Amount, wantCASS) {
-		t.Errorf("CASS.Amount = %f, want %f", r.CASS.Amount, wantCASS)
+	// 10% of 45765 is 4576.50, rounded up to whole lei
+	if want := money.MustParse("4577"); r.CASS.Amount != want {
+		t.Errorf("CASS.Amount = %v, want %v", r.CASS.Amount, want)
 	}
 	capSalaries := cfg.CASSThresholds[len(cfg.CASSThresholds)-1].MinSalaries
-	if !almostEqual(r.CASS.BufferToNext, capSalaries*cfg.SalariuMinimBrut-netIncome) {
-		t.Errorf("CASS.BufferToNext = %f, want distance to cap", r.CASS.BufferToNext)
+	if r.CASS.BufferToNext != smb.MulFloat(capSalaries)-netIncome {
+		t.Errorf("CASS.BufferToNext = %v, want distance to cap", r.CASS.BufferToNext)
 	}
 }
 
 func TestFormatHelpers(t *testing.T) {
-	if got := FormatRON(1234.5); got != "1234.50 RON" {
+	if got := FormatRON(money.MustParse("1234.5")); got != "1234.50 RON" {
+		t.Errorf("FormatRON = %q", got)
+	}
+	if got := FormatRON(money.MustParse("-0.07")); got != "-0.07 RON" {
 		t.Errorf("FormatRON = %q", got)
 	}
 	if got := FormatBuffer(0); got != "plafonul a fost atins" {
 		t.Errorf("FormatBuffer(0) = %q", got)
 	}
-	if got := FormatBuffer(100); got == "plafonul a fost atins" {
+	if got := FormatBuffer(100 * money.Lei); got == "plafonul a fost atins" {
 		t.Errorf("FormatBuffer(100) = %q, want remaining amount", got)
 	}
 }
+
+// Non-PFA income: each category by its own rules, CASS on the combined
+// eligible amount with the 6/12/24 salarii brackets
+func TestCalculateWithOtherIncome(t *testing.T) {
+	cfg := defaultCfg()
+	smb := smbOf(cfg)
+	lei := func(n int64) money.Money { return money.Money(n) * money.Lei }
+
+	extra := []config.ExtraIncome{
+		{Year: 2026, Category: "dividends", Amount: lei(20000)},
+		{Year: 2026, Category: "rent", Amount: lei(60000)},
+		{Year: 2026, Category: "bogus", Amount: lei(99999)}, // unknown, ignored
+	}
+	r := CalculateWithIncome(lei(50000), lei(20000), extra, cfg)
+	pfaOnly := Calculate(lei(50000), lei(20000), cfg)
+
+	if len(r.OtherIncome) != 2 || r.OtherIncomeTotal != lei(80000) {
+		t.Fatalf("OtherIncome = %+v (total %v), want dividends and rent totalling 80000", r.OtherIncome, r.OtherIncomeTotal)
+	}
+	// Dividends: 16% withheld at source
+	if r.WithheldTax != lei(3200) {
+		t.Errorf("WithheldTax = %v, want 3200", r.WithheldTax)
+	}
+	// Rent: 10% on 80% after the flat-rate deduction
+	if r.OtherIncomeTax != lei(4800) {
+		t.Errorf("OtherIncomeTax = %v, want 4800", r.OtherIncomeTax)
+	}
+	// 80000 is 19.75 salarii: CASS on 12 salarii
+	if r.OtherCASS.Base != 12*smb || r.OtherCASS.Amount != lei(4860) {
+		t.Errorf("OtherCASS = %+v, want base %v", r.OtherCASS, 12*smb)
+	}
+	if r.OtherCASS.PrevLabel != "" {
+		t.Errorf("OtherCASS carries an expenses hint: %q", r.OtherCASS.PrevLabel)
+	}
+
+	// The PFA part is untouched
+	if r.CASS.Amount != pfaOnly.CASS.Amount || r.IncomeTax != pfaOnly.IncomeTax {
+		t.Errorf("PFA taxes changed by other income: CASS %v vs %v, income tax %v vs %v",
+			r.CASS.Amount, pfaOnly.CASS.Amount, r.IncomeTax, pfaOnly.IncomeTax)
+	}
+	wantTotal := pfaOnly.TotalTaxes + r.OtherCASS.Amount + lei(4800) + lei(3200)
+	if r.TotalTaxes != wantTotal {
+		t.Errorf("TotalTaxes = %v, want %v", r.TotalTaxes, wantTotal)
+	}
+	if want := lei(30000+80000) - wantTotal; r.NetAfterTax != want {
+		t.Errorf("NetAfterTax = %v, want %v", r.NetAfterTax, want)
+	}
+}
+
+// Below 6 salarii of other income no CASS is due on it
+func TestCalculateOtherIncomeBelowCASSThreshold(t *testing.T) {
+	cfg := defaultCfg()
+	r := CalculateWithIncome(30000*money.Lei, 0, []config.ExtraIncome{{Category: "interest", Amount: 1000 * money.Lei}}, cfg)
+	if r.OtherCASS.Amount != 0 {
+		t.Errorf("OtherCASS.Amount = %v, want 0 under 6 salarii", r.OtherCASS.Amount)
+	}
+	if r.WithheldTax != 100*money.Lei {
+		t.Errorf("WithheldTax = %v, want 100", r.WithheldTax)
+	}
+}
+
+// A PFA already at the CASS cap leaves no room for CASS on other income
+func TestCalculateOtherIncomeCASSCap(t *testing.T) {
+	cfg := defaultCfg()
+	smb := smbOf(cfg)
+
+	r := CalculateWithIncome(80*smb, 0, []config.ExtraIncome{{Category: "dividends", Amount: 30 * smb}}, cfg)
+	if r.CASS.Base != 72*smb {
+		t.Fatalf("PFA CASS base = %v, want the 72 salarii cap", r.CASS.Base)
+	}
+	if r.OtherCASS.Base != 0 || r.OtherCASS.Amount != 0 {
+		t.Errorf("OtherCASS = %+v, want nothing over the cap", r.OtherCASS)
+	}
+
+	// Partially used cap: 66 salarii PFA leaves room for 6 of the 24
+	r = CalculateWithIncome(66*smb, 0, []config.ExtraIncome{{Category: "dividends", Amount: 30 * smb}}, cfg)
+	if r.OtherCASS.Base != 6*smb {
+		t.Errorf("OtherCASS.Base = %v, want %v (room left under the cap)", r.OtherCASS.Base, 6*smb)
+	}
+}
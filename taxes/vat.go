@@ -0,0 +1,67 @@
+package taxes
+
+import (
+	"time"
+
+	"solo-cli/config"
+	"solo-cli/money"
+)
+
+// VATStatus tracks a year's turnover against the VAT registration threshold
+type VATStatus struct {
+	Year        int
+	Threshold   money.Money
+	Turnover    money.Money
+	Headroom    money.Money // turnover left before the threshold, 0 once exceeded
+	UsedPercent float64     // turnover / threshold * 100
+	Warning     bool        // headroom within VATWarningPercent of the threshold
+	Exceeded    bool
+	// CrossingMonth is the month the turnover passed the threshold or, when
+	// Projected, the month it will at the year's average monthly turnover.
+	// 0 when it is not expected to cross this year
+	CrossingMonth time.Month
+	Projected     bool
+}
+
+// TrackVAT sums the monthly RON turnover of a year and compares it to the
+// VAT threshold. The crossing month is projected only for the current year,
+// from the turnover per elapsed month. Returns nil when no threshold is set
+func TrackVAT(months [12]money.Money, year int, now time.Time, cfg *config.TaxConfig) *VATStatus {
+	if cfg.VATThreshold <= 0 {
+		return nil
+	}
+
+	s := &VATStatus{Year: year, Threshold: money.FromFloat(cfg.VATThreshold)}
+	for i, v := range months {
+		s.Turnover += v
+		if s.CrossingMonth == 0 && s.Turnover > s.Threshold {
+			s.CrossingMonth = time.Month(i + 1)
+		}
+	}
+	s.UsedPercent = s.Turnover.Float64() / s.Threshold.Float64() * 100
+	s.Exceeded = s.Turnover > s.Threshold
+	if !s.Exceeded {
+		s.Headroom = s.Threshold - s.Turnover
+	}
+	s.Warning = s.Headroom <= s.Threshold.Percent(cfg.VATWarningPercent)
+
+	if s.Exceeded || year != now.Year() {
+		return s
+	}
+
+	// The current month counts for the part already elapsed
+	daysInMonth := time.Date(year, now.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	elapsed := float64(now.Month()-1) + float64(now.Day())/float64(daysInMonth)
+	perMonth := s.Turnover.Float64() / elapsed
+	if perMonth <= 0 {
+		return s
+	}
+	for mo := now.Month(); mo <= time.December; mo++ {
+		if s.Turnover+money.FromFloat(perMonth*(float64(mo)-elapsed)) > s.Threshold {
+			s.CrossingMonth = mo
+			s.Projected = true
+			break
+		}
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+package taxes
+
+import (
+	"testing"
+	"time"
+
+	"solo-cli/money"
+)
+
+// 35000 a month for the first half of 2026 is 210000: on 30 June that is
+// 35000 per elapsed month, 385000 by the end of November and past 395000 in
+// December
+func TestTrackVATProjection(t *testing.T) {
+	cfg := defaultCfg()
+	var months [12]money.Money
+	for i := 0; i < 6; i++ {
+		months[i] = 35000 * money.Lei
+	}
+
+	s := TrackVAT(months, 2026, time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC), cfg)
+
+	if s.Turnover != 210000*money.Lei || s.Headroom != 185000*money.Lei || s.Exceeded || s.Warning {
+		t.Fatalf("status = %+v, want turnover 210000, headroom 185000, no warning", s)
+	}
+	if !s.Projected || s.CrossingMonth != time.December {
+		t.Errorf("crossing = %v (projected %v), want projected December", s.CrossingMonth, s.Projected)
+	}
+
+	// At 30000 a month it reaches 360000 and stays under for the year
+	for i := 0; i < 6; i++ {
+		months[i] = 30000 * money.Lei
+	}
+	if s := TrackVAT(months, 2026, time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC), cfg); s.CrossingMonth != 0 {
+		t.Errorf("crossing = %v, want none", s.CrossingMonth)
+	}
+}
+
+func TestTrackVATWarningAndExceeded(t *testing.T) {
+	cfg := defaultCfg()
+	now := time.Date(2027, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	// 360000 leaves 35000, under 10% of the threshold
+	months := [12]money.Money{0: 200000 * money.Lei, 4: 160000 * money.Lei}
+	s := TrackVAT(months, 2026, now, cfg)
+	if !s.Warning || s.Exceeded || s.Projected || s.CrossingMonth != 0 {
+		t.Errorf("status = %+v, want a warning and no projection for a past year", s)
+	}
+
+	months[7] = 50000 * money.Lei
+	s = TrackVAT(months, 2026, now, cfg)
+	if !s.Exceeded || s.Headroom != 0 || s.CrossingMonth != time.August || s.Projected {
+		t.Errorf("status = %+v, want exceeded in August", s)
+	}
+}
+
+func TestTrackVATDisabled(t *testing.T) {
+	cfg := defaultCfg()
+	cfg.VATThreshold = 0
+	if s := TrackVAT([12]money.Money{0: money.Lei}, 2026, time.Now(), cfg); s != nil {
+		t.Errorf("status = %+v, want nil without a threshold", s)
+	}
+}
@@ -1,11 +1,16 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 
 	"solo-cli/client"
+	"solo-cli/config"
+	"solo-cli/money"
+	"solo-cli/report"
+	"solo-cli/taxes"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -144,6 +149,129 @@ func TestTaxesViewportUsesFullHeight(t *testing.T) {
 	}
 }
 
+// The Taxes tab lists the next occurrence of every deadline rule, so the
+// monthly e-Factura reminder cannot push out the D212 payment
+func TestTaxesDeadlinesPanel(t *testing.T) {
+	m := NewDemoModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = updated.(Model)
+
+	content := stripANSI(m.renderTaxes())
+	if !strings.Contains(content, "Upcoming Deadlines") {
+		t.Fatal("deadlines panel missing")
+	}
+	if got := strings.Count(content, "e-Factura: verificare"); got != 1 {
+		t.Errorf("monthly rule listed %d times, want once", got)
+	}
+	if !strings.Contains(content, "Plată impozit pe venit și CAS/CASS") {
+		t.Error("D212 payment deadline missing")
+	}
+	if !strings.Contains(content, fmt.Sprintf("%.2f RON", m.taxBreakdown.TotalTaxes)) {
+		t.Error("amount due missing from the payment deadline")
+	}
+}
+
+// The optimizer panel recommends dropping a bracket only when it pays off
+func TestTaxesOptimizerPanel(t *testing.T) {
+	m := NewDemoModel()
+	cfg := m.taxConfig
+	smb := money.FromFloat(cfg.SalariuMinimBrut)
+
+	// Just over 12 salarii: dropping under the CAS threshold pays off
+	m.optimization = taxes.Optimize(12*smb+1000*money.Lei, 0, cfg)
+	content := stripANSI(m.renderTaxes())
+	if !strings.Contains(content, "Best move: spend 1001.00 RON") {
+		t.Errorf("optimizer panel missing the CAS drop:\n%s", content)
+	}
+	if !strings.Contains(content, "Marginal rate now:") {
+		t.Error("marginal rate line missing")
+	}
+
+	m.optimization = taxes.Optimize(30000*money.Lei, 0, cfg)
+	if content := stripANSI(m.renderTaxes()); !strings.Contains(content, "No extra deductible expense pays for itself") {
+		t.Errorf("optimizer panel should not recommend spending:\n%s", content)
+	}
+}
+
+// c toggles the Taxes tab between the breakdown and the tax curve chart,
+// which marks every CAS/CASS threshold and the current position
+func TestTaxesChartToggle(t *testing.T) {
+	const width = 100
+
+	m := NewDemoModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: 60})
+	m = updated.(Model)
+	m.activeTab = TabTaxes
+
+	updated, _ = m.Update(keyMsg("c"))
+	m = updated.(Model)
+	view := stripANSI(m.View())
+	if !strings.Contains(view, "Tax Curve (0-100 salarii") {
+		t.Fatalf("chart not shown after c:\n%s", view)
+	}
+	for _, want := range []string{" 12.0 SMB", " 24.0 SMB", " 72.0 SMB", "▲ CAS ", "▲ CASS", "◀ you", "100.0 SMB"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("chart missing %q", want)
+		}
+	}
+	you := fmt.Sprintf("%5.1f SMB", m.taxBreakdown.SalariesCount)
+	for _, line := range strings.Split(view, "\n") {
+		if strings.Contains(line, "◀ you") && !strings.Contains(line, "your net income") && !strings.Contains(line, you) {
+			t.Errorf("current position marked on the wrong row: %q", line)
+		}
+		if w := lipgloss.Width(line); w > width {
+			t.Errorf("line exceeds width %d (got %d): %q", width, w, line)
+		}
+	}
+
+	updated, _ = m.Update(keyMsg("c"))
+	m = updated.(Model)
+	if view := stripANSI(m.View()); !strings.Contains(view, "Tax Breakdown") {
+		t.Error("second c did not return to the breakdown")
+	}
+}
+
+// Declared non-PFA income gets its own box with the CASS on other income
+func TestTaxesOtherIncome(t *testing.T) {
+	m := NewDemoModel()
+	extra := []config.ExtraIncome{{Year: m.summary.Year, Category: "dividends", Amount: 50000 * money.Lei}}
+	m.taxBreakdown = taxes.CalculateWithIncome(m.summary.TotalRevenues, m.summary.TotalDeductibleExpenses, extra, m.taxConfig)
+
+	content := stripANSI(m.renderTaxes())
+	for _, want := range []string{"Other Income (50000.00 RON)", "Dividende: 50000.00 RON", "(withheld)", "CASS pe 12 salarii", "Withheld at source: 8000.00 RON"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("taxes tab missing %q:\n%s", want, content)
+		}
+	}
+}
+
+// An invalid taxes.json lists its problems on the Taxes tab and is flagged
+// on the Dashboard
+func TestTaxesInvalidConfig(t *testing.T) {
+	m := NewDemoModel()
+	m.taxConfig, m.taxBreakdown = nil, nil
+	m.taxConfigErr = fmt.Errorf("taxes.json: %w", &config.ValidationError{Problems: []string{"salariu_minim_brut must be positive, got 0"}})
+
+	content := stripANSI(m.renderTaxes())
+	for _, want := range []string{"taxes.json is invalid", "• salariu_minim_brut must be positive, got 0", "solo-cli taxes config validate"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("taxes tab missing %q:\n%s", want, content)
+		}
+	}
+	if dashboard := stripANSI(m.renderDashboard()); !strings.Contains(dashboard, "taxes.json is invalid, see the Taxes tab") {
+		t.Errorf("dashboard does not flag the invalid config:\n%s", dashboard)
+	}
+}
+
+// An unreadable rates.json is flagged on the Dashboard
+func TestRatesLoadError(t *testing.T) {
+	m := NewDemoModel()
+	m.ratesErr = errors.New("rates.json: unexpected end of JSON input")
+	if dashboard := stripANSI(m.renderDashboard()); !strings.Contains(dashboard, "rates.json could not be loaded") {
+		t.Errorf("dashboard does not flag the rates error:\n%s", dashboard)
+	}
+}
+
 func keyMsg(s string) tea.KeyMsg {
 	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
 }
@@ -256,7 +384,7 @@ func TestYearSwitcherFullFlow(t *testing.T) {
 	m.activeTab = TabDashboard
 	m.year, m.maxYear = 0, 0
 
-	updated, _ := m.Update(summaryMsg(&client.Summary{Year: 2026, TotalRevenues: 1000}))
+	updated, _ := m.Update(summaryMsg(&client.Summary{Year: 2026, TotalRevenues: 1000 * money.Lei}))
 	m = updated.(Model)
 
 	updated, cmd := m.Update(keyMsg("["))
@@ -297,7 +425,7 @@ func TestSummarySetsYearBounds(t *testing.T) {
 	m.summary = nil
 	m.year, m.maxYear = 0, 0
 
-	updated, _ := m.Update(summaryMsg(&client.Summary{Year: 2026, TotalRevenues: 1000}))
+	updated, _ := m.Update(summaryMsg(&client.Summary{Year: 2026, TotalRevenues: 1000 * money.Lei}))
 	m = updated.(Model)
 	if m.year != 2026 || m.maxYear != 2026 {
 		t.Fatalf("after first summary: year %d maxYear %d, want 2026/2026", m.year, m.maxYear)
@@ -305,7 +433,7 @@ func TestSummarySetsYearBounds(t *testing.T) {
 
 	// A response for the selected year lands (the user switched to 2024)
 	m.year = 2024
-	updated, _ = m.Update(summaryMsg(&client.Summary{Year: 2024, TotalRevenues: 500}))
+	updated, _ = m.Update(summaryMsg(&client.Summary{Year: 2024, TotalRevenues: 500 * money.Lei}))
 	m = updated.(Model)
 	if m.year != 2024 || m.maxYear != 2026 {
 		t.Errorf("after year switch: year %d maxYear %d, want 2024/2026", m.year, m.maxYear)
@@ -313,7 +441,7 @@ func TestSummarySetsYearBounds(t *testing.T) {
 
 	// A stale response for a year no longer selected is dropped: rapid
 	// [ [ presses must not let the slower older fetch win
-	updated, _ = m.Update(summaryMsg(&client.Summary{Year: 2025, TotalRevenues: 777}))
+	updated, _ = m.Update(summaryMsg(&client.Summary{Year: 2025, TotalRevenues: 777 * money.Lei}))
 	m = updated.(Model)
 	if m.summary.Year != 2024 {
 		t.Errorf("stale summary for %d replaced the selected year 2024", m.summary.Year)
@@ -605,7 +733,7 @@ func TestDashboardShowsCompanyDetails(t *testing.T) {
 		"CAEN principal: 6201",
 		"CAEN secundare: 6202, 6311",
 		"Net Income:",
-		fmt.Sprintf("%.2f", demoNet),
+		demoNet.String(),
 	} {
 		if !strings.Contains(view, want) {
 			t.Errorf("dashboard missing %q", want)
@@ -613,6 +741,38 @@ func TestDashboardShowsCompanyDetails(t *testing.T) {
 	}
 }
 
+// The demo invoices (321362.50 RON, all in January, the EUR and USD ones at
+// the demo BNR rates) sit beside the summary box against the VAT threshold,
+// and crossing it turns into a warning
+func TestDashboardVATThreshold(t *testing.T) {
+	m := NewDemoModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	m.activeTab = TabDashboard
+
+	view := stripANSI(m.View())
+	for _, want := range []string{"VAT Threshold", "Turnover: 321362.50 / 395000 (81.4%)", "Headroom: 73637.50 RON"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("dashboard missing %q\n%s", want, view)
+		}
+	}
+	beside := false
+	for _, line := range strings.Split(view, "\n") {
+		beside = beside || strings.Contains(line, "Year:") && strings.Contains(line, "VAT Threshold")
+	}
+	if !beside {
+		t.Error("VAT box not beside the summary box")
+	}
+
+	cfg := *m.taxConfig
+	cfg.VATThreshold = 100000
+	m.taxConfig = &cfg
+	view = stripANSI(m.View())
+	if !strings.Contains(view, fmt.Sprintf("Exceeded in Ian %d, register for VAT", m.summary.Year)) {
+		t.Errorf("exceeded threshold not flagged:\n%s", view)
+	}
+}
+
 // Enter opens the detail modal for the selected row, navigation browses
 // items while open, esc and clicks close it
 func TestDetailModal(t *testing.T) {
@@ -700,23 +860,27 @@ func TestChartTab(t *testing.T) {
 	if !strings.Contains(view, "Total: ") {
 		t.Error("total line missing")
 	}
+	// Demo invoices are in RON, EUR and USD
+	if !strings.Contains(view, "By currency: ") || !strings.Contains(view, "EUR ") {
+		t.Error("currency split missing")
+	}
 
 	// Aggregation math: foreign currency uses the local RON amount
 	year := m.summary.Year
 	m.revenues.Items = m.revenues.Items[:0]
-	local := client.LocalAmount{Total: 5000}
+	local := client.LocalAmount{Total: 5000 * money.Lei}
 	m.revenues.Items = append(m.revenues.Items,
-		client.Revenue{IssueDate: fmt.Sprintf("%d-03-10T00:00:00+02:00", year), Total: 1000, InvoiceLocalAmount: &local},
-		client.Revenue{IssueDate: fmt.Sprintf("%d-03-20", year), Total: 200},
-		client.Revenue{IssueDate: fmt.Sprintf("%d-03-20", year-1), Total: 99999}, // other year, excluded
+		client.Revenue{IssueDate: fmt.Sprintf("%d-03-10T00:00:00+02:00", year), Total: 1000 * money.Lei, InvoiceLocalAmount: &local},
+		client.Revenue{IssueDate: fmt.Sprintf("%d-03-20", year), Total: 200 * money.Lei},
+		client.Revenue{IssueDate: fmt.Sprintf("%d-03-20", year-1), Total: 99999 * money.Lei}, // other year, excluded
 	)
 	months := m.monthlyRevenues(year)
-	if months[2] != 5200 {
-		t.Errorf("March total = %.2f, want 5200 (local RON amount + plain total)", months[2])
+	if months[2] != 5200*money.Lei {
+		t.Errorf("March total = %v, want 5200 (local RON amount + plain total)", months[2])
 	}
 	for i, v := range months {
 		if i != 2 && v != 0 {
-			t.Errorf("month %d has %f, want 0", i+1, v)
+			t.Errorf("month %d has %v, want 0", i+1, v)
 		}
 	}
 
@@ -727,6 +891,197 @@ func TestChartTab(t *testing.T) {
 	}
 }
 
+// p on the Chart tab swaps the bars for the profit and loss table, b
+// switches it between months and quarters
+func TestChartPnLToggle(t *testing.T) {
+	m := NewDemoModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 28})
+	m = updated.(Model)
+	m.activeTab = TabChart
+
+	year := m.summary.Year
+	m.revenues.Items = []client.Revenue{
+		{IssueDate: fmt.Sprintf("%d-02-10", year), Total: 1000 * money.Lei},
+		{IssueDate: fmt.Sprintf("%d-05-10", year), Total: 500 * money.Lei, Status: &client.InvoiceStatus{IsCancelled: true}},
+	}
+	m.expenses.Items = []client.Expense{
+		{PurchaseDate: fmt.Sprintf("%d-02-01", year), Total: 300 * money.Lei, Deductibility: "50%"},
+		{PurchaseDate: fmt.Sprintf("%d-04-01", year), Total: 200 * money.Lei},
+	}
+
+	updated, _ = m.Update(keyMsg("p"))
+	m = updated.(Model)
+	view := m.View()
+	for _, want := range []string{"Profit and Loss", "by month", "Ian", "Dec", "Total", "b month/quarter"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+	if lines := strings.Split(view, "\n"); len(lines) != 28 {
+		t.Errorf("view has %d lines, want 28", len(lines))
+	}
+
+	updated, _ = m.Update(keyMsg("b"))
+	m = updated.(Model)
+	p := m.pnl(year)
+	if p.By != report.ByQuarter || len(p.Rows) != 4 {
+		t.Fatalf("b did not switch to quarters: %+v", p)
+	}
+	// Q1: 1000 revenue, 150 of the 300 expense deductible. Q2: the
+	// cancelled invoice is left out, the 200 expense is fully deductible
+	if q1 := p.Rows[0]; q1.Revenue != 1000*money.Lei || q1.Deductible != 150*money.Lei || q1.NonDeductible != 150*money.Lei || q1.Net != 850*money.Lei {
+		t.Errorf("Q1 = %+v", q1)
+	}
+	if q2 := p.Rows[1]; q2.Net != -200*money.Lei || q2.YTD != 650*money.Lei {
+		t.Errorf("Q2 = %+v", q2)
+	}
+	if !strings.Contains(m.View(), "Q4") {
+		t.Error("quarter rows missing")
+	}
+
+	updated, _ = m.Update(keyMsg("p"))
+	m = updated.(Model)
+	if !strings.Contains(m.View(), "Monthly Revenues") {
+		t.Error("p did not return to the bar chart")
+	}
+}
+
+// g on the Expenses tab cycles the list through the breakdown bars by
+// category, supplier and deductibility
+func TestExpenseBreakdownToggle(t *testing.T) {
+	m := NewDemoModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 28})
+	m = updated.(Model)
+	m.activeTab = TabExpenses
+
+	year := m.summary.Year
+	m.expenses.Items = []client.Expense{
+		{PurchaseDate: fmt.Sprintf("%d-02-01", year), SupplierName: "Hosting SRL", Category: "Servicii", Total: 300 * money.Lei},
+		{PurchaseDate: fmt.Sprintf("%d-03-01", year), SupplierName: "Restaurant", Category: "Protocol", Total: 100 * money.Lei, Deductibility: "0%"},
+		{PurchaseDate: fmt.Sprintf("%d-03-01", year-1), SupplierName: "Hosting SRL", Category: "Servicii", Total: 200 * money.Lei},
+	}
+	for i := range 40 {
+		m.expenses.Items = append(m.expenses.Items, client.Expense{
+			PurchaseDate: fmt.Sprintf("%d-05-01", year), SupplierName: fmt.Sprintf("Supplier %02d", i), Total: money.Lei,
+		})
+	}
+
+	updated, _ = m.Update(keyMsg("g"))
+	m = updated.(Model)
+	if m.isListTab() {
+		t.Error("the breakdown must not behave as a list")
+	}
+	view := m.View()
+	for _, want := range []string{"Expenses by category", "Servicii", "█", "+50%", "Non-deductible: ", "g next grouping"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("category view missing %q", want)
+		}
+	}
+
+	updated, _ = m.Update(keyMsg("g"))
+	m = updated.(Model)
+	view = m.View()
+	// 42 suppliers do not fit: the rest are combined and the help bar stays
+	// on screen
+	if !strings.Contains(view, "Expenses by supplier") || !strings.Contains(view, "Other (") {
+		t.Errorf("supplier view:\n%s", view)
+	}
+	if lines := strings.Split(view, "\n"); len(lines) != 28 || !strings.Contains(lines[27], "quit") {
+		t.Errorf("view has %d lines, want 28 ending in the help bar", len(lines))
+	}
+
+	updated, _ = m.Update(keyMsg("g"))
+	m = updated.(Model)
+	if view := m.View(); !strings.Contains(view, "Expenses by deductibility") || !strings.Contains(view, "Non-deductible") {
+		t.Errorf("deductibility view:\n%s", view)
+	}
+
+	updated, _ = m.Update(keyMsg("g"))
+	m = updated.(Model)
+	if m.expenseGroup != "" || !m.isListTab() || !strings.Contains(m.View(), "g breakdown") {
+		t.Error("g did not cycle back to the list")
+	}
+}
+
+// c on the Chart tab shows the revenue per client with the concentration
+func TestChartClientsPanel(t *testing.T) {
+	m := NewDemoModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 28})
+	m = updated.(Model)
+	m.activeTab = TabChart
+
+	updated, _ = m.Update(keyMsg("c"))
+	m = updated.(Model)
+	view := m.View()
+	for _, want := range []string{"Revenue by Client", "TechStart Solutions", "█", "Concentration: HHI ", "Clients: ", "c chart"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+
+	// Clients that do not fit are combined and the help bar stays on screen
+	year := m.summary.Year
+	for i := range 40 {
+		m.revenues.Items = append(m.revenues.Items, client.Revenue{
+			ClientName: fmt.Sprintf("Client %02d", i), IssueDate: fmt.Sprintf("%d-03-01", year), Total: money.Lei,
+		})
+	}
+	view = m.View()
+	if lines := strings.Split(view, "\n"); len(lines) != 28 || !strings.Contains(lines[27], "quit") {
+		t.Errorf("view has %d lines, want 28 ending in the help bar", len(lines))
+	}
+	if !strings.Contains(view, "Other (") {
+		t.Error("Other row missing")
+	}
+
+	// p switches straight to the profit and loss, c again back to the bars
+	updated, _ = m.Update(keyMsg("p"))
+	m = updated.(Model)
+	if !strings.Contains(m.View(), "Profit and Loss") {
+		t.Error("p did not switch to the profit and loss")
+	}
+	updated, _ = m.Update(keyMsg("c"))
+	m = updated.(Model)
+	updated, _ = m.Update(keyMsg("c"))
+	m = updated.(Model)
+	if !strings.Contains(m.View(), "Monthly Revenues") {
+		t.Error("c did not return to the monthly bars")
+	}
+}
+
+func TestReceivablesTab(t *testing.T) {
+	m := NewDemoModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 28})
+	m = updated.(Model)
+	m.activeTab = TabReceivables
+
+	// Demo data: three unpaid invoices, one of them in USD
+	view := m.View()
+	for _, want := range []string{"Receivables (as of", "DataFlow Analytics", "owed 12750.00 USD", "Quantum Labs SRL", "in 3 invoice(s)", "90+:"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+	if strings.Contains(view, "Cloud Services Inc") {
+		t.Error("fully paid client listed")
+	}
+
+	// Clients that do not fit are summarized instead of pushing the help
+	// bar off screen
+	for i := range 30 {
+		m.revenues.Items = append(m.revenues.Items, client.Revenue{
+			ClientName: fmt.Sprintf("Client %02d", i), IssueDate: "2026-01-10", Total: money.Lei,
+		})
+	}
+	view = m.View()
+	if lines := strings.Split(view, "\n"); len(lines) != 28 {
+		t.Errorf("view has %d lines, want 28", len(lines))
+	}
+	if !strings.Contains(view, "more") {
+		t.Error("overflow note missing")
+	}
+}
+
 // The CAEN principal line marquees on the dashboard when it overflows
 func TestDashboardCAENMarquees(t *testing.T) {
 	m := NewDemoModel()
@@ -885,3 +1240,72 @@ func TestPadTruncate(t *testing.T) {
 		t.Errorf("diacritics width = %d, want 12 (%q)", lipgloss.Width(got), got)
 	}
 }
+
+func TestYearComparison(t *testing.T) {
+	m := NewDemoModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 28})
+	m = updated.(Model)
+	m.activeTab = TabDashboard
+
+	updated, cmd := m.Update(keyMsg("y"))
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("y did not fetch the comparison years")
+	}
+	if !strings.Contains(m.View(), "Loading summaries...") {
+		t.Error("comparison not loading")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+
+	year := m.summary.Year
+	view := m.View()
+	for _, want := range []string{
+		fmt.Sprintf("Year over Year (%d-%d)", year-4, year),
+		fmt.Sprintf("%d", year-4), "+14.9%", "Revenue growth: ", "y single year",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+	if lines := strings.Split(view, "\n"); len(lines) != 28 || !strings.Contains(lines[27], "quit") {
+		t.Errorf("view has %d lines, want 28 ending in the help bar", len(lines))
+	}
+
+	// A comparison for another year is dropped
+	updated, _ = m.Update(yearSummariesMsg{end: year - 1})
+	m = updated.(Model)
+	if m.comparison.end != year {
+		t.Error("stale comparison replaced the current one")
+	}
+
+	updated, _ = m.Update(keyMsg("y"))
+	m = updated.(Model)
+	if strings.Contains(m.View(), "Year over Year") {
+		t.Error("y did not return to the single year")
+	}
+}
+
+func TestChartPreviousYearOverlay(t *testing.T) {
+	m := NewDemoModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 28})
+	m = updated.(Model)
+	m.activeTab = TabChart
+
+	year := m.summary.Year
+	if strings.Contains(m.View(), fmt.Sprintf("▒ %d", year-1)) {
+		t.Error("overlay shown without invoices the year before")
+	}
+	m.revenues.Items = append(m.revenues.Items, client.Revenue{
+		ClientName: "Old Client", IssueDate: fmt.Sprintf("%d-01-10", year-1), Total: 1000 * money.Lei,
+	})
+	view := m.View()
+	for _, want := range []string{fmt.Sprintf("Monthly Revenues (%d, ▒ %d)", year, year-1), fmt.Sprintf(" · %d: ", year-1)} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+	if lines := strings.Split(view, "\n"); len(lines) != 28 {
+		t.Errorf("view has %d lines, want 28", len(lines))
+	}
+}
@@ -1,6 +1,9 @@
 package tui
 
 import (
+	"time"
+
+	"solo-cli/calendar"
 	"solo-cli/client"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,6 +17,41 @@ func (m Model) fetchSummary() tea.Msg {
 	return summaryMsg(summary)
 }
 
+// compareYearCount is how many years the Dashboard comparison shows
+const compareYearCount = 5
+
+// fetchYearSummaries loads the summaries of the comparison years, ending
+// at the selected year, concurrently
+func (m Model) fetchYearSummaries() tea.Msg {
+	end := m.year
+	if end == 0 && m.summary != nil {
+		end = m.summary.Year
+	}
+	years := make([]int, compareYearCount)
+	for i := range years {
+		years[i] = end - compareYearCount + 1 + i
+	}
+
+	if m.demoMode {
+		summaries := make([]*client.Summary, len(years))
+		for i, y := range years {
+			summaries[i] = client.GetDemoSummaryForYear(y)
+		}
+		return yearSummariesMsg{end: end, summaries: summaries}
+	}
+	summaries, err := m.client.GetSummariesForYears(years)
+	return yearSummariesMsg{end: end, summaries: summaries, err: err}
+}
+
+// fetchYear refetches what shows the selected year: the summary and, in
+// comparison mode, the comparison years
+func (m Model) fetchYear() tea.Cmd {
+	if m.compareYears {
+		return tea.Batch(m.fetchSummary, m.fetchYearSummaries)
+	}
+	return m.fetchSummary
+}
+
 func (m Model) fetchCompany() tea.Msg {
 	if m.client.CompanyID == "" {
 		return companyMsg(nil)
@@ -38,6 +76,21 @@ func (m Model) fetchCAEN() tea.Msg {
 	return caenMsg(codes)
 }
 
+// deadlineMonths is how far ahead the Taxes tab lists fiscal deadlines
+const deadlineMonths = 12
+
+// fetchDeadlines expands the calendar rules and computes the amounts due
+// from each income year's summary. Deadlines are optional, failures only
+// leave amounts blank
+func (m Model) fetchDeadlines() tea.Msg {
+	if m.calendarCfg == nil || m.taxConfig == nil {
+		return deadlinesMsg(nil)
+	}
+	breakdownFor := calendar.YearBreakdowns(m.client.GetSummaryForYear, m.taxConfig, m.extraIncome)
+	deadlines := calendar.Upcoming(m.calendarCfg.Rules, time.Now(), deadlineMonths, breakdownFor)
+	return deadlinesMsg(deadlines)
+}
+
 // searchFor returns the active search query when tab is the searched tab.
 // The query only ever applies to the tab it was typed on
 func (m Model) searchFor(tab Tab) string {
@@ -132,6 +185,13 @@ func (m Model) loadedAndTotal() (int, int) {
 	return loaded, loaded
 }
 
+// needsAllRevenues reports whether the active tab aggregates the complete
+// invoice list: the chart by month, the dashboard for the VAT threshold and
+// the receivables aging
+func (m Model) needsAllRevenues() bool {
+	return m.activeTab == TabChart || m.activeTab == TabDashboard || m.activeTab == TabReceivables
+}
+
 // fetchRestOfRevenues loads the next revenue page unconditionally. The
 // Chart tab needs the complete invoice list to aggregate by month, so it
 // chains this until everything is loaded
@@ -155,6 +215,49 @@ func (m *Model) fetchRestOfRevenues() tea.Cmd {
 	}
 }
 
+// needsAllExpenses reports whether the active view aggregates the complete
+// expense list: the profit and loss table of the Chart tab and the expense
+// breakdown
+func (m Model) needsAllExpenses() bool {
+	return (m.activeTab == TabChart && m.chartView == chartPnL) || (m.activeTab == TabExpenses && m.expenseGroup != "")
+}
+
+// fetchRestOfExpenses loads the next expense page unconditionally, like
+// fetchRestOfRevenues
+func (m *Model) fetchRestOfExpenses() tea.Cmd {
+	if m.demoMode || m.fetchingMore || m.expenses == nil || m.expenses.TotalResults == nil {
+		return nil
+	}
+	loaded := len(m.expenses.Items)
+	if loaded >= *m.expenses.TotalResults {
+		return nil
+	}
+	m.fetchingMore = true
+
+	offset, pageSize, c, gen := loaded, m.pageSize, m.client, m.listGen
+	return func() tea.Msg {
+		resp, err := c.ListExpenses(offset, pageSize, "")
+		if err != nil {
+			return errMsg(err)
+		}
+		return expensesPageMsg{resp, gen}
+	}
+}
+
+// fetchRestForTab chains the page fetches the active tab aggregates:
+// revenues first, then expenses. One page is in flight at a time
+func (m *Model) fetchRestForTab() tea.Cmd {
+	if m.needsAllRevenues() {
+		if cmd := m.fetchRestOfRevenues(); cmd != nil {
+			return cmd
+		}
+	}
+	if m.needsAllExpenses() {
+		return m.fetchRestOfExpenses()
+	}
+	return nil
+}
+
 // maybeFetchMore starts a next-page fetch when the cursor gets within one
 // viewport of the end of the loaded items and the server has more
 func (m *Model) maybeFetchMore() tea.Cmd {
@@ -2,9 +2,13 @@ package tui
 
 import (
 	"os"
+	"time"
 
+	"solo-cli/calendar"
 	"solo-cli/client"
 	"solo-cli/config"
+	"solo-cli/rates"
+	"solo-cli/report"
 	"solo-cli/taxes"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -22,9 +26,19 @@ const (
 	TabQueue
 	TabTaxes
 	TabChart
+	TabReceivables
 )
 
-const tabCount = 7
+const tabCount = 8
+
+// chartView is what the Chart tab shows
+type chartView int
+
+const (
+	chartMonthly chartView = iota // Revenue bars by month
+	chartPnL                      // Profit and loss table
+	chartClients                  // Revenue bars by client
+)
 
 func (t Tab) String() string {
 	switch t {
@@ -42,6 +56,8 @@ func (t Tab) String() string {
 		return "Taxes"
 	case TabChart:
 		return "Chart"
+	case TabReceivables:
+		return "Receivables"
 	default:
 		return "Unknown"
 	}
@@ -66,25 +82,38 @@ type Model struct {
 	queue        *client.QueuedExpenseResponse
 	efactura     *client.EFacturaListResponse
 	taxBreakdown *taxes.TaxBreakdown
+	optimization *taxes.Optimization
 	taxConfig    *config.TaxConfig
+	taxConfigErr error // why taxes.json could not be loaded, nil when taxConfig is set
+	calendarCfg  *config.CalendarConfig
+	extraIncome  []config.ExtraIncome // Declared non-PFA income, all years
+	rates        *rates.Store         // BNR rates for invoices without a local amount, may be nil
+	ratesErr     error                // why rates.json could not be loaded, foreign amounts then stay unconverted
+	deadlines    []calendar.Deadline
+	comparison   *yearSummariesMsg // Summaries of the comparison years, nil until loaded
 
 	// UI state
 	loading        bool
 	err            error
 	spinner        spinner.Model
 	cursor         int
-	detailOpen     bool   // Detail modal for the selected row
-	searching      bool   // Typing in the search input
-	searchInput    string // Text being typed
-	searchQuery    string // Applied server-side filter for the active list tab
-	searchSeq      int    // Debounce sequence, only the latest tick applies
-	marqueeOffset  int    // Scroll position of the focused row's marquee
-	viewportOffset int // First visible item index
-	viewportSize   int // Number of visible items
-	taxesScroll    int  // Scroll offset for taxes tab
-	taxesLines     int  // Total line count of taxes content
-	fetchingMore   bool // A next-page fetch is in flight
-	listGen        int  // List generation, stale page fetches are dropped
+	detailOpen     bool           // Detail modal for the selected row
+	searching      bool           // Typing in the search input
+	searchInput    string         // Text being typed
+	searchQuery    string         // Applied server-side filter for the active list tab
+	searchSeq      int            // Debounce sequence, only the latest tick applies
+	marqueeOffset  int            // Scroll position of the focused row's marquee
+	viewportOffset int            // First visible item index
+	viewportSize   int            // Number of visible items
+	taxesScroll    int            // Scroll offset for taxes tab
+	taxesLines     int            // Total line count of taxes content
+	taxesChart     bool           // Taxes tab shows the tax curve chart
+	chartView      chartView      // What the Chart tab shows
+	pnlQuarterly   bool           // Profit and loss by quarter instead of month
+	expenseGroup   report.GroupBy // Expenses tab shows the breakdown by this grouping, "" for the list
+	compareYears   bool           // Dashboard shows the multi-year comparison
+	fetchingMore   bool           // A next-page fetch is in flight
+	listGen        int            // List generation, stale page fetches are dropped
 	demoMode       bool
 	debugMouse     bool   // SOLO_MOUSE_DEBUG=1, show raw mouse events
 	lastMouse      string // Last mouse event, for the debug overlay
@@ -103,6 +132,15 @@ type queueMsg *client.QueuedExpenseResponse
 type efacturaMsg *client.EFacturaListResponse
 type errMsg error
 type deleteSuccessMsg struct{}
+type deadlinesMsg []calendar.Deadline
+
+// yearSummariesMsg carries the summaries of the comparison years ending at
+// end. A failure only affects the comparison, so it travels in err
+type yearSummariesMsg struct {
+	end       int
+	summaries []*client.Summary
+	err       error
+}
 
 // Page messages append to the already loaded list instead of replacing it.
 // gen ties the page to the list generation it was fetched for, so a slow
@@ -138,8 +176,11 @@ func NewModel(c *client.Client, pageSize int) Model {
 		pageSize = 100 // Default
 	}
 
-	// Load tax config (non-fatal if it fails)
-	taxCfg, _ := config.LoadTaxes()
+	// Load tax, calendar and declared income config (non-fatal if they fail)
+	taxCfg, taxErr := config.LoadTaxes()
+	calendarCfg, _ := config.LoadCalendar()
+	extraIncome, _ := config.LoadExtraIncome()
+	rateStore, ratesErr := rates.Load()
 
 	return Model{
 		client:       c,
@@ -149,6 +190,11 @@ func NewModel(c *client.Client, pageSize int) Model {
 		pageSize:     pageSize,
 		viewportSize: 10, // Fallback until the first WindowSizeMsg arrives
 		taxConfig:    taxCfg,
+		taxConfigErr: taxErr,
+		calendarCfg:  calendarCfg,
+		extraIncome:  extraIncome,
+		rates:        rateStore,
+		ratesErr:     ratesErr,
 		debugMouse:   os.Getenv("SOLO_MOUSE_DEBUG") != "",
 	}
 }
@@ -158,6 +204,11 @@ func NewDemoModel() Model {
 	demoSummary := client.GetDemoSummary()
 	taxCfg := config.DefaultTaxConfig()
 	taxBreakdown := taxes.Calculate(demoSummary.TotalRevenues, demoSummary.TotalDeductibleExpenses, taxCfg)
+	calendarCfg := config.DefaultCalendarConfig()
+	// Demo amounts due reuse the demo year's breakdown for every income year
+	deadlines := calendar.Upcoming(calendarCfg.Rules, time.Now(), deadlineMonths, func(int) *taxes.TaxBreakdown {
+		return taxBreakdown
+	})
 
 	return Model{
 		activeTab:    TabDashboard,
@@ -171,14 +222,18 @@ func NewDemoModel() Model {
 		debugMouse:   os.Getenv("SOLO_MOUSE_DEBUG") != "",
 		taxConfig:    taxCfg,
 		taxBreakdown: taxBreakdown,
+		optimization: taxes.Optimize(demoSummary.TotalRevenues, demoSummary.TotalDeductibleExpenses, taxCfg),
+		calendarCfg:  calendarCfg,
+		deadlines:    deadlines,
+		rates:        rates.Demo(demoSummary.Year),
 		// Pre-populate with demo data
 		summary:   demoSummary,
 		company:   client.GetDemoCompany(),
 		caenCodes: client.GetDemoCAENCodes(),
-		revenues: client.GetDemoRevenues(),
-		expenses: client.GetDemoExpenses(),
-		rejected: client.GetDemoRejectedExpenses(),
-		queue:    client.GetDemoQueue(),
-		efactura: client.GetDemoEFactura(),
+		revenues:  client.GetDemoRevenues(),
+		expenses:  client.GetDemoExpenses(),
+		rejected:  client.GetDemoRejectedExpenses(),
+		queue:     client.GetDemoQueue(),
+		efactura:  client.GetDemoEFactura(),
 	}
 }
@@ -81,7 +81,7 @@ func (m *Model) clickYear(x, y int) tea.Cmd {
 			if yr != m.year {
 				m.year = yr
 				m.taxesScroll = 0
-				return m.fetchSummary
+				return m.fetchYear()
 			}
 			return nil
 		}
@@ -132,9 +132,7 @@ func (m *Model) clickTab(x int) tea.Cmd {
 
 // clickRow moves the cursor to the clicked table row on list tabs
 func (m *Model) clickRow(y int) {
-	switch m.activeTab {
-	case TabRevenues, TabExpenses, TabEFactura, TabQueue:
-	default:
+	if !m.isListTab() {
 		return
 	}
 
@@ -2,46 +2,24 @@ package tui
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
+	"solo-cli/client"
+	"solo-cli/money"
+	"solo-cli/report"
 	"solo-cli/taxes"
 )
 
 var monthLabels = [12]string{"Ian", "Feb", "Mar", "Apr", "Mai", "Iun", "Iul", "Aug", "Sep", "Oct", "Noi", "Dec"}
 
-// revenueRON returns the invoice value in RON, preferring the local amount
-// for foreign currency invoices
-func revenueRON(total float64, localTotal *float64) float64 {
-	if localTotal != nil {
-		return *localTotal
-	}
-	return total
-}
-
 // monthlyRevenues aggregates the loaded invoices of the given year by issue
-// month. IssueDate is ISO formatted so the year and month are a prefix
-func (m Model) monthlyRevenues(year int) [12]float64 {
-	var months [12]float64
+// month in RON, valuing foreign invoices without a local amount at the BNR
+// rate of their issue date
+func (m Model) monthlyRevenues(year int) [12]money.Money {
 	if m.revenues == nil {
-		return months
-	}
-	prefix := strconv.Itoa(year) + "-"
-	for _, r := range m.revenues.Items {
-		if !strings.HasPrefix(r.IssueDate, prefix) || len(r.IssueDate) < 7 {
-			continue
-		}
-		mo, err := strconv.Atoi(r.IssueDate[5:7])
-		if err != nil || mo < 1 || mo > 12 {
-			continue
-		}
-		var local *float64
-		if r.InvoiceLocalAmount != nil {
-			local = &r.InvoiceLocalAmount.Total
-		}
-		months[mo-1] += revenueRON(r.Total, local)
+		return [12]money.Money{}
 	}
-	return months
+	return client.MonthlyRevenuesBy(m.revenues.Items, year, m.rates.RevenueRON)
 }
 
 func (m Model) renderChart() string {
@@ -52,7 +30,17 @@ func (m Model) renderChart() string {
 		year = m.summary.Year
 	}
 
-	b.WriteString(TitleStyle.Render(fmt.Sprintf("Monthly Revenues (%d)", year)))
+	// Last year's months are overlaid when there were any
+	months, prev := m.monthlyRevenues(year), m.monthlyRevenues(year-1)
+	var prevTotal money.Money
+	for _, v := range prev {
+		prevTotal += v
+	}
+	title := fmt.Sprintf("Monthly Revenues (%d)", year)
+	if prevTotal > 0 {
+		title = fmt.Sprintf("Monthly Revenues (%d, ▒ %d)", year, year-1)
+	}
+	b.WriteString(TitleStyle.Render(title))
 	b.WriteString("\n")
 
 	loaded, available := m.chartCoverage()
@@ -61,13 +49,9 @@ func (m Model) renderChart() string {
 		b.WriteString("\n\n")
 	}
 
-	months := m.monthlyRevenues(year)
-	maxVal := 0.0
-	total := 0.0
-	for _, v := range months {
-		if v > maxVal {
-			maxVal = v
-		}
+	var maxVal, total money.Money
+	for i, v := range months {
+		maxVal = max(maxVal, v, prev[i])
 		total += v
 	}
 
@@ -81,15 +65,10 @@ func (m Model) renderChart() string {
 	barWidth := m.fillWidth(4+1+valueWidth, 20)
 
 	for i, v := range months {
-		filled := 0
-		if maxVal > 0 {
-			filled = int(v / maxVal * float64(barWidth))
+		bar := renderBar(v, maxVal, barWidth)
+		if prevTotal > 0 {
+			bar = renderOverlayBar(v, prev[i], maxVal, barWidth)
 		}
-		if v > 0 && filled == 0 {
-			filled = 1 // Non-zero months always show something
-		}
-
-		bar := secondaryStyle.Render(strings.Repeat("█", filled)) + SummaryLabelStyle.Render(strings.Repeat("░", barWidth-filled))
 		b.WriteString(fmt.Sprintf("%s %s %s\n",
 			SummaryLabelStyle.Render(fmt.Sprintf("%-3s", monthLabels[i])),
 			bar,
@@ -100,10 +79,73 @@ func (m Model) renderChart() string {
 	b.WriteString("\n")
 	b.WriteString(SummaryLabelStyle.Render("Total: "))
 	b.WriteString(SummaryValueStyle.Render(taxes.FormatRON(total)))
+	if prevTotal > 0 {
+		b.WriteString(SummaryLabelStyle.Render(fmt.Sprintf(" · %d: ", year-1)))
+		b.WriteString(SummaryValueStyle.Render(taxes.FormatRON(prevTotal)))
+		b.WriteString(SummaryLabelStyle.Render(fmt.Sprintf(" (%s)", report.FormatGrowth(report.Growth(total, prevTotal)))))
+	}
+	if split := m.currencySplit(year); split != "" {
+		b.WriteString("\n")
+		b.WriteString(SummaryLabelStyle.Render("By currency: "))
+		b.WriteString(SummaryValueStyle.Render(split))
+	}
 
 	return b.String()
 }
 
+// barCells is how many of width cells v fills scaled to maxVal
+func barCells(v, maxVal money.Money, width int) int {
+	filled := 0
+	if maxVal > 0 {
+		filled = min(int(v.Float64()/maxVal.Float64()*float64(width)), width)
+	}
+	if v > 0 && filled == 0 {
+		filled = 1 // Non-zero values always show something
+	}
+	return filled
+}
+
+// renderBar draws v as a horizontal bar of width cells scaled to maxVal
+func renderBar(v, maxVal money.Money, width int) string {
+	filled := barCells(v, maxVal, width)
+	return secondaryStyle.Render(strings.Repeat("█", filled)) + SummaryLabelStyle.Render(strings.Repeat("░", width-filled))
+}
+
+// renderOverlayBar draws v like renderBar with prev overlaid: shaded past
+// the end of a shorter v bar, a marker inside a longer one
+func renderOverlayBar(v, prev, maxVal money.Money, width int) string {
+	filled, prevFilled := barCells(v, maxVal, width), barCells(prev, maxVal, width)
+	if prevFilled > filled {
+		return secondaryStyle.Render(strings.Repeat("█", filled)) +
+			warningStyle.Render(strings.Repeat("▒", prevFilled-filled)) +
+			SummaryLabelStyle.Render(strings.Repeat("░", width-prevFilled))
+	}
+	bar := secondaryStyle.Render(strings.Repeat("█", filled))
+	if prevFilled > 0 && prevFilled < filled {
+		bar = secondaryStyle.Render(strings.Repeat("█", prevFilled-1)) + warningStyle.Render("┃") +
+			secondaryStyle.Render(strings.Repeat("█", filled-prevFilled))
+	}
+	return bar + SummaryLabelStyle.Render(strings.Repeat("░", width-filled))
+}
+
+// currencySplit is the share of the year's invoicing per currency, empty
+// when everything is invoiced in one currency
+func (m Model) currencySplit(year int) string {
+	exp := m.rates.CurrencyExposure(m.revenues.Items, year, "RON")
+	if len(exp.Rows) < 2 {
+		return ""
+	}
+	parts := make([]string, 0, len(exp.Rows))
+	for _, row := range exp.Rows {
+		if row.Missing == row.Invoices {
+			parts = append(parts, row.Currency+" n/a")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %.0f%%", row.Currency, row.Share))
+	}
+	return strings.Join(parts, " · ")
+}
+
 // chartCoverage reports how many invoices are loaded vs available
 func (m Model) chartCoverage() (int, int) {
 	if m.revenues == nil {
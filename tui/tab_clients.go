@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"solo-cli/client"
+	"solo-cli/money"
+	"solo-cli/report"
+	"solo-cli/taxes"
+)
+
+// clientReport totals the loaded invoices of the given year per client,
+// keeping the largest top clients
+func (m Model) clientReport(year, top int) *report.ClientReport {
+	var items []client.Revenue
+	if m.revenues != nil {
+		items = m.revenues.Items
+	}
+	return report.BuildClientReport(items, year, top, m.rates.RevenueRON)
+}
+
+func (m Model) renderClients() string {
+	var b strings.Builder
+
+	year := m.year
+	if year == 0 && m.summary != nil {
+		year = m.summary.Year
+	}
+
+	b.WriteString(TitleStyle.Render(fmt.Sprintf("Revenue by Client (%d)", year)))
+	b.WriteString("\n")
+	chrome := 5 // title with its margin (2), blank, concentration and client count lines
+
+	loaded, available := m.chartCoverage()
+	if loaded < available {
+		b.WriteString(LoadingStyle.Render(fmt.Sprintf("Loading invoices... %d of %d", loaded, available)))
+		b.WriteString("\n\n")
+		chrome += 2
+	}
+
+	// The clients that do not fit are combined into one Other row
+	space := max(m.bodyHeight()-chrome, 2)
+	rep := m.clientReport(year, space)
+	if rep.Other != nil {
+		rep = m.clientReport(year, space-1)
+	}
+	if rep.Invoices == 0 {
+		b.WriteString(SummaryLabelStyle.Render(fmt.Sprintf("No invoices issued in %d", year)))
+		return b.String()
+	}
+
+	rows := rep.Clients
+	if rep.Other != nil {
+		rows = append(rows, *rep.Other)
+	}
+	var maxVal money.Money
+	for _, c := range rows {
+		maxVal = max(maxVal, c.Revenue)
+	}
+
+	// Layout: name (22) + bar + revenue (12) + share (6) + new marker (3)
+	// with separators. The bar flexes
+	nameWidth := 22
+	barWidth := m.fillWidth(nameWidth+1+1+12+1+6+1+3, 10)
+	for _, c := range rows {
+		marker := "   "
+		if c.New {
+			marker = "new"
+		}
+		b.WriteString(fmt.Sprintf("%s %s %s %s %s\n",
+			SummaryLabelStyle.Render(padTruncate(c.Name, nameWidth)),
+			renderBar(c.Revenue, maxVal, barWidth),
+			SummaryValueStyle.Render(fmt.Sprintf("%12.2f", c.Revenue)),
+			SummaryValueStyle.Render(fmt.Sprintf("%5.1f%%", c.Share)),
+			secondaryStyle.Render(marker),
+		))
+	}
+
+	b.WriteString("\n")
+	hhi := fmt.Sprintf("HHI %.0f, %s", rep.HHI, rep.Concentration())
+	b.WriteString(SummaryLabelStyle.Render("Concentration: "))
+	switch {
+	case rep.HHI > 2500:
+		b.WriteString(dangerStyle.Render(hhi))
+	case rep.HHI >= 1500:
+		b.WriteString(warningStyle.Render(hhi))
+	default:
+		b.WriteString(SummaryValueStyle.Render(hhi))
+	}
+	b.WriteString(SummaryLabelStyle.Render(fmt.Sprintf(" · largest client %.0f%%", rep.TopShare)))
+	b.WriteString("\n")
+	this := rep.ThisYear()
+	b.WriteString(SummaryLabelStyle.Render("Clients: "))
+	b.WriteString(SummaryValueStyle.Render(fmt.Sprintf("%d (%d new, %d returning)", this.Clients, this.New, this.Returning)))
+	b.WriteString(SummaryLabelStyle.Render(" · Avg invoice: "))
+	b.WriteString(SummaryValueStyle.Render(taxes.FormatRON(rep.AvgInvoice)))
+
+	return b.String()
+}
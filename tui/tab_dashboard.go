@@ -3,6 +3,11 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"solo-cli/taxes"
 )
 
 func (m Model) renderDashboard() string {
@@ -60,7 +65,27 @@ func (m Model) renderDashboard() string {
 		)
 	}
 
-	b.WriteString(SummaryBoxStyle.Render(summaryContent))
+	// The VAT tracker sits beside the summary when the terminal is wide
+	// enough, the dashboard does not scroll
+	summaryBox := SummaryBoxStyle.Render(summaryContent)
+	if vat := m.renderVAT(); vat != "" {
+		vatBox := SummaryBoxStyle.Render(vat)
+		if lipgloss.Width(summaryBox)+1+lipgloss.Width(vatBox) <= m.width {
+			summaryBox = lipgloss.JoinHorizontal(lipgloss.Top, summaryBox, " ", vatBox)
+		} else {
+			summaryBox += "\n" + vatBox
+		}
+	}
+	b.WriteString(summaryBox)
+
+	if m.taxConfigErr != nil {
+		b.WriteString("\n")
+		b.WriteString(ErrorStyle.Render("‼️  taxes.json is invalid, see the Taxes tab"))
+	}
+	if m.ratesErr != nil {
+		b.WriteString("\n")
+		b.WriteString(ErrorStyle.Render("‼️  rates.json could not be loaded, foreign amounts are not converted to RON"))
+	}
 
 	// Show pending review info if any
 	if m.queue != nil && len(m.queue.Items) > 0 {
@@ -128,3 +153,58 @@ func (m Model) renderCAENLines(width int) string {
 	}
 	return b.String()
 }
+
+// vatBarWidth is the width of the VAT threshold usage bar
+const vatBarWidth = 30
+
+// renderVAT tracks the displayed year's invoiced turnover against the VAT
+// registration threshold in five lines, "" without a threshold. Needs the
+// complete invoice list, which the dashboard keeps loading like the Chart tab
+func (m Model) renderVAT() string {
+	if m.taxConfig == nil {
+		return ""
+	}
+	vat := taxes.TrackVAT(m.monthlyRevenues(m.summary.Year), m.summary.Year, time.Now(), m.taxConfig)
+	if vat == nil {
+		return ""
+	}
+
+	valueStyle := SummaryValueStyle
+	if vat.Exceeded {
+		valueStyle = dangerStyle
+	} else if vat.Warning {
+		valueStyle = warningStyle
+	}
+
+	filled := min(int(vat.UsedPercent/100*vatBarWidth), vatBarWidth)
+	lines := []string{
+		SummaryLabelStyle.Render("VAT Threshold"),
+		fmt.Sprintf("%s %s / %.0f (%.1f%%)",
+			SummaryLabelStyle.Render("Turnover:"),
+			valueStyle.Render(fmt.Sprintf("%.2f", vat.Turnover)),
+			vat.Threshold,
+			vat.UsedPercent,
+		),
+		valueStyle.Render(strings.Repeat("█", filled)) + SummaryLabelStyle.Render(strings.Repeat("░", vatBarWidth-filled)),
+		fmt.Sprintf("%s %s", SummaryLabelStyle.Render("Headroom:"), valueStyle.Render(taxes.FormatRON(vat.Headroom))),
+	}
+
+	when := ""
+	if vat.CrossingMonth != 0 {
+		when = fmt.Sprintf("%s %d", monthLabels[vat.CrossingMonth-1], vat.Year)
+	}
+	loaded, available := m.chartCoverage()
+	switch {
+	case vat.Exceeded:
+		lines = append(lines, dangerStyle.Render(fmt.Sprintf("⚠️  Exceeded in %s, register for VAT", when)))
+	case loaded < available:
+		lines = append(lines, LoadingStyle.Render(fmt.Sprintf("Loading invoices... %d of %d", loaded, available)))
+	case vat.Projected:
+		lines = append(lines, fmt.Sprintf("%s %s", SummaryLabelStyle.Render("Projected crossing:"), valueStyle.Render(when)))
+	case vat.Warning:
+		lines = append(lines, warningStyle.Render(fmt.Sprintf("⚠️  Within %.0f%% of the threshold", m.taxConfig.VATWarningPercent)))
+	default:
+		lines = append(lines, SummaryLabelStyle.Render(fmt.Sprintf("No crossing projected for %d", vat.Year)))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -3,6 +3,11 @@ package tui
 import (
 	"fmt"
 	"strings"
+
+	"solo-cli/client"
+	"solo-cli/money"
+	"solo-cli/report"
+	"solo-cli/taxes"
 )
 
 func (m Model) renderExpenses() string {
@@ -43,3 +48,84 @@ func (m Model) renderExpenses() string {
 
 	return b.String()
 }
+
+// expenseBreakdown groups the loaded expenses of the given year, keeping
+// the largest top groups
+func (m Model) expenseBreakdown(year, top int) *report.ExpenseBreakdown {
+	var items []client.Expense
+	if m.expenses != nil {
+		items = m.expenses.Items
+	}
+	return report.BuildExpenseBreakdown(items, year, m.expenseGroup, top, m.rates.ExpenseRON)
+}
+
+func (m Model) renderExpenseBreakdown() string {
+	var b strings.Builder
+
+	year := m.year
+	if year == 0 && m.summary != nil {
+		year = m.summary.Year
+	}
+
+	b.WriteString(TitleStyle.Render(fmt.Sprintf("Expenses by %s (%d)", m.expenseGroup, year)))
+	b.WriteString("\n")
+	chrome := 5 // title with its margin (2), blank, total and non-deductible lines
+
+	loaded, available := m.expensesCoverage()
+	if loaded < available {
+		b.WriteString(LoadingStyle.Render(fmt.Sprintf("Loading expenses... %d of %d", loaded, available)))
+		b.WriteString("\n\n")
+		chrome += 2
+	}
+
+	// The groups that do not fit are combined into one Other row
+	space := max(m.bodyHeight()-chrome, 2)
+	bd := m.expenseBreakdown(year, space)
+	if bd.Other != nil {
+		bd = m.expenseBreakdown(year, space-1)
+	}
+	if bd.Total.Count == 0 {
+		b.WriteString(SummaryLabelStyle.Render(fmt.Sprintf("No expenses in %d", year)))
+		return b.String()
+	}
+
+	rows := bd.Groups
+	if bd.Other != nil {
+		rows = append(rows, *bd.Other)
+	}
+	var maxVal money.Money
+	for _, g := range rows {
+		maxVal = max(maxVal, g.Amount)
+	}
+
+	// Layout: name (22) + bar + amount (12) + share (6) + change vs last
+	// year (6) with separators. The bar flexes
+	nameWidth := 22
+	barWidth := m.fillWidth(nameWidth+1+1+12+1+6+1+6, 10)
+	for _, g := range rows {
+		b.WriteString(fmt.Sprintf("%s %s %s %s %s\n",
+			SummaryLabelStyle.Render(padTruncate(g.Name, nameWidth)),
+			renderBar(g.Amount, maxVal, barWidth),
+			SummaryValueStyle.Render(fmt.Sprintf("%12.2f", g.Amount)),
+			SummaryValueStyle.Render(fmt.Sprintf("%5.1f%%", g.Share)),
+			SummaryLabelStyle.Render(fmt.Sprintf("%6s", report.FormatChange(g.Change))),
+		))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(SummaryLabelStyle.Render("Total: "))
+	b.WriteString(SummaryValueStyle.Render(taxes.FormatRON(bd.Total.Amount)))
+	if bd.Total.Change != nil {
+		b.WriteString(SummaryLabelStyle.Render(fmt.Sprintf(" (%s vs %d)", report.FormatChange(bd.Total.Change), year-1)))
+	}
+	b.WriteString("\n")
+	nonDeductible := fmt.Sprintf("%s (%.1f%%)", taxes.FormatRON(bd.Total.NonDeductible), bd.NonDeductibleShare())
+	b.WriteString(SummaryLabelStyle.Render("Non-deductible: "))
+	if bd.Total.NonDeductible > 0 {
+		b.WriteString(warningStyle.Render(nonDeductible))
+	} else {
+		b.WriteString(SummaryValueStyle.Render(nonDeductible))
+	}
+
+	return b.String()
+}
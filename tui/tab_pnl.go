@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"solo-cli/client"
+	"solo-cli/report"
+)
+
+// pnl aggregates the loaded invoices and expenses of the given year into
+// the profit and loss per month or quarter
+func (m Model) pnl(year int) *report.PnL {
+	var revenues []client.Revenue
+	var expenses []client.Expense
+	if m.revenues != nil {
+		revenues = m.revenues.Items
+	}
+	if m.expenses != nil {
+		expenses = m.expenses.Items
+	}
+	by := report.ByMonth
+	if m.pnlQuarterly {
+		by = report.ByQuarter
+	}
+	return report.BuildPnL(revenues, expenses, year, by, m.rates.RevenueRON, m.rates.ExpenseRON)
+}
+
+// expensesCoverage reports how many expenses are loaded vs available
+func (m Model) expensesCoverage() (int, int) {
+	if m.expenses == nil {
+		return 0, 0
+	}
+	loaded := len(m.expenses.Items)
+	if m.expenses.TotalResults != nil && *m.expenses.TotalResults > loaded {
+		return loaded, *m.expenses.TotalResults
+	}
+	return loaded, loaded
+}
+
+func (m Model) renderPnL() string {
+	var b strings.Builder
+
+	year := m.year
+	if year == 0 && m.summary != nil {
+		year = m.summary.Year
+	}
+	p := m.pnl(year)
+
+	b.WriteString(TitleStyle.Render(fmt.Sprintf("Profit and Loss (%d, by %s)", year, p.By)))
+	b.WriteString("\n")
+
+	revLoaded, revAvailable := m.chartCoverage()
+	expLoaded, expAvailable := m.expensesCoverage()
+	if revLoaded < revAvailable || expLoaded < expAvailable {
+		b.WriteString(LoadingStyle.Render(fmt.Sprintf("Loading... %d of %d invoices, %d of %d expenses",
+			revLoaded, revAvailable, expLoaded, expAvailable)))
+		b.WriteString("\n\n")
+	}
+
+	// Period (7) + five amounts (13) with separators
+	header := fmt.Sprintf("%-7s %13s %13s %13s %13s %13s", "Period", "Revenue", "Deductible", "Non-deduct.", "Net income", "Net YTD")
+	b.WriteString(TableHeaderStyle.Render(header))
+	b.WriteString("\n")
+
+	for i, row := range append(p.Rows, p.Total) {
+		label := "Total"
+		if i < len(p.Rows) {
+			label = fmt.Sprintf("Q%d", i+1)
+			if p.By == report.ByMonth {
+				label = monthLabels[i]
+			}
+		}
+		line := fmt.Sprintf("%-7s %13.2f %13.2f %13.2f ", label, row.Revenue, row.Deductible, row.NonDeductible)
+		net := fmt.Sprintf("%13.2f", row.Net)
+		ytd := fmt.Sprintf(" %13.2f", row.YTD)
+		if row.Net < 0 {
+			net = dangerStyle.Render(net)
+		}
+		if i == len(p.Rows) {
+			b.WriteString(SummaryValueStyle.Render(line) + net + SummaryValueStyle.Render(ytd))
+			break
+		}
+		b.WriteString(TableRowStyle.Render(line) + net + TableRowStyle.Render(ytd))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"solo-cli/client"
+	"solo-cli/report"
+	"solo-cli/taxes"
+)
+
+// receivables ages the loaded unpaid invoices, valuing foreign invoices
+// without a local amount at the BNR rate of their issue date
+func (m Model) receivables() *report.Receivables {
+	var items []client.Revenue
+	if m.revenues != nil {
+		items = m.revenues.Items
+	}
+	return report.AgeReceivables(items, time.Now(), m.rates.RevenueRON)
+}
+
+func (m Model) renderReceivables() string {
+	var b strings.Builder
+	rep := m.receivables()
+
+	b.WriteString(TitleStyle.Render(fmt.Sprintf("Receivables (as of %s)", rep.AsOf.Format("2006-01-02"))))
+	b.WriteString("\n")
+	chrome := 7 // title with its margin (2), header (2), blank, totals (2)
+
+	loaded, available := m.chartCoverage()
+	if loaded < available {
+		b.WriteString(LoadingStyle.Render(fmt.Sprintf("Loading invoices... %d of %d", loaded, available)))
+		b.WriteString("\n\n")
+		chrome += 2
+	}
+
+	if len(rep.Clients) == 0 {
+		b.WriteString(SummaryLabelStyle.Render("No unpaid invoices"))
+		return b.String()
+	}
+
+	// Fixed columns: Inv(4) + 4 buckets(11) + Total(12) + Pays in(7) + separators
+	names := report.AgingBuckets
+	clientWidth := m.fillWidth(74, 16)
+	header := fmt.Sprintf("%s %4s %11s %11s %11s %11s %12s %7s", padTruncate("Client", clientWidth),
+		"Inv", names[0], names[1], names[2], names[3], "Total RON", "Pays in")
+	b.WriteString(TableHeaderStyle.Render(header))
+	b.WriteString("\n")
+
+	space := m.bodyHeight() - chrome
+	for i, cr := range rep.Clients {
+		// A client with foreign currency invoices gets a second line with
+		// the amounts in the invoice currencies
+		lines := 1
+		foreign := len(cr.ByCurrency) > 1 || cr.ByCurrency["RON"] == 0
+		if foreign {
+			lines++
+		}
+		// Keep a line for the "more" note unless this is the last client
+		need := lines
+		if i < len(rep.Clients)-1 {
+			need++
+		}
+		if need > space {
+			b.WriteString(SummaryLabelStyle.Render(fmt.Sprintf("… and %d more", len(rep.Clients)-i)))
+			b.WriteString("\n")
+			break
+		}
+		space -= lines
+
+		paysIn := "n/a"
+		if cr.PaidInvoices > 0 {
+			paysIn = fmt.Sprintf("%.0fd", cr.AvgDaysToPay)
+		}
+		overdue := fmt.Sprintf("%11.2f", cr.Buckets[3])
+		if cr.Buckets[3] > 0 {
+			overdue = dangerStyle.Render(overdue)
+		}
+		b.WriteString(TableRowStyle.Render(fmt.Sprintf("%s %4d %11.2f %11.2f %11.2f ",
+			padTruncate(cr.Client, clientWidth), cr.Invoices, cr.Buckets[0], cr.Buckets[1], cr.Buckets[2])))
+		b.WriteString(overdue)
+		b.WriteString(TableRowStyle.Render(fmt.Sprintf(" %12.2f %7s", cr.Total, paysIn)))
+		b.WriteString("\n")
+		if foreign {
+			b.WriteString(SummaryLabelStyle.Render(truncate("  owed "+client.FormatCurrencies(cr.ByCurrency), m.fillWidth(0, 20))))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(SummaryLabelStyle.Render("Total: "))
+	b.WriteString(SummaryValueStyle.Render(taxes.FormatRON(rep.Total)))
+	b.WriteString(SummaryLabelStyle.Render(truncate(fmt.Sprintf(" in %d invoice(s) • %s", rep.Invoices, client.FormatCurrencies(rep.ByCurrency)), m.fillWidth(30, 10))))
+	b.WriteString("\n")
+
+	buckets := make([]string, len(names))
+	for i, name := range names {
+		style := SummaryValueStyle
+		if i == len(names)-1 && rep.Buckets[i] > 0 {
+			style = dangerStyle
+		}
+		buckets[i] = SummaryLabelStyle.Render(name+": ") + style.Render(fmt.Sprintf("%.2f", rep.Buckets[i]))
+	}
+	b.WriteString(strings.Join(buckets, SummaryLabelStyle.Render(" • ")))
+
+	return b.String()
+}
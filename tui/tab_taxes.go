@@ -1,16 +1,18 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
+	"solo-cli/config"
 	"solo-cli/taxes"
 )
 
-// renderTaxesViewport wraps renderTaxes in a manual scroll window sized to
+// renderTaxesViewport wraps taxesContent in a manual scroll window sized to
 // the terminal height
 func (m Model) renderTaxesViewport() string {
-	content := m.renderTaxes()
+	content := m.taxesContent()
 	lines := strings.Split(content, "\n")
 	// The scroll hint line is the taxes tab's only chrome inside the body
 	availHeight := m.bodyHeight() - 1
@@ -40,7 +42,7 @@ func (m Model) renderTaxesViewport() string {
 func (m Model) renderTaxes() string {
 	if m.taxBreakdown == nil {
 		if m.taxConfig == nil {
-			return ErrorStyle.Render("Could not load taxes.json config")
+			return m.renderTaxConfigError()
 		}
 		return LoadingStyle.Render("Loading tax data...")
 	}
@@ -120,6 +122,36 @@ func (m Model) renderTaxes() string {
 	b.WriteString(CompactBoxStyle.Render(itContent))
 	b.WriteString("\n")
 
+	// Other (non-PFA) income
+	if len(t.OtherIncome) > 0 {
+		var lines []string
+		for _, o := range t.OtherIncome {
+			withheld := ""
+			if o.Category.WithheldAtSource {
+				withheld = SummaryLabelStyle.Render(" (withheld)")
+			}
+			lines = append(lines, fmt.Sprintf("%s %s → %s %s%s",
+				SummaryLabelStyle.Render(o.Category.Label+":"),
+				SummaryValueStyle.Render(taxes.FormatRON(o.Amount)),
+				SummaryLabelStyle.Render(fmt.Sprintf("Tax %.0f%%:", o.Category.IncomeTaxPercent)),
+				SummaryValueStyle.Render(taxes.FormatRON(o.Tax)),
+				withheld,
+			))
+		}
+		lines = append(lines, fmt.Sprintf("%s %s\n%s %s → %s %s",
+			SummaryLabelStyle.Render("CASS Bracket:"),
+			t.OtherCASS.Label,
+			SummaryLabelStyle.Render("Base:"),
+			SummaryValueStyle.Render(taxes.FormatRON(t.OtherCASS.Base)),
+			SummaryLabelStyle.Render("Amount:"),
+			SummaryValueStyle.Render(taxes.FormatRON(t.OtherCASS.Amount)),
+		))
+		b.WriteString(SummaryLabelStyle.Render(fmt.Sprintf("Other Income (%s)", taxes.FormatRON(t.OtherIncomeTotal))))
+		b.WriteString("\n")
+		b.WriteString(CompactBoxStyle.Render(strings.Join(lines, "\n") + renderThresholdHint(t.OtherCASS)))
+		b.WriteString("\n")
+	}
+
 	// Totals
 	totalsContent := fmt.Sprintf(
 		"%s %s\n%s %s\n%s %.1f%%",
@@ -130,11 +162,96 @@ func (m Model) renderTaxes() string {
 		SummaryLabelStyle.Render("Effective Tax Rate:"),
 		t.EffectiveRate,
 	)
+	if t.WithheldTax > 0 {
+		totalsContent += fmt.Sprintf("\n%s %s",
+			SummaryLabelStyle.Render("Withheld at source:"),
+			SummaryValueStyle.Render(taxes.FormatRON(t.WithheldTax)),
+		)
+	}
 	b.WriteString(CompactBoxStyle.Render(totalsContent))
 
+	if optimizer := m.renderOptimizer(); optimizer != "" {
+		b.WriteString("\n")
+		b.WriteString(SummaryLabelStyle.Render("Expense Optimizer"))
+		b.WriteString("\n")
+		b.WriteString(CompactBoxStyle.Render(optimizer))
+	}
+
+	if deadlines := m.renderDeadlines(); deadlines != "" {
+		b.WriteString("\n")
+		b.WriteString(SummaryLabelStyle.Render("Upcoming Deadlines"))
+		b.WriteString("\n")
+		b.WriteString(CompactBoxStyle.Render(deadlines))
+	}
+
 	return b.String()
 }
 
+// renderOptimizer shows the extra deductible spending that maximizes net
+// after tax across CAS, CASS and income tax combined, and the marginal rate
+// at the current net income
+func (m Model) renderOptimizer() string {
+	if m.optimization == nil {
+		return ""
+	}
+	best := m.optimization.Best
+
+	var b strings.Builder
+	if best.ExtraExpenses > 0 {
+		b.WriteString(fmt.Sprintf("%s %s → %s %s\n%s %s / %s",
+			SummaryLabelStyle.Render("Best move: spend"),
+			warningStyle.Render(taxes.FormatRON(best.ExtraExpenses)),
+			SummaryLabelStyle.Render("net after tax"),
+			secondaryStyle.Render("+"+taxes.FormatRON(best.Gain)),
+			SummaryLabelStyle.Render("Lands in:"),
+			best.CASLabel,
+			best.CASSLabel,
+		))
+	} else {
+		b.WriteString(SummaryLabelStyle.Render("No extra deductible expense pays for itself"))
+	}
+
+	if curve := m.optimization.Curve; len(curve) > 0 {
+		b.WriteString(fmt.Sprintf("\n%s %.1f%%",
+			SummaryLabelStyle.Render("Marginal rate now:"),
+			curve[len(curve)-1].MarginalRate,
+		))
+	}
+	return b.String()
+}
+
+// maxDeadlines caps the deadline panel length
+const maxDeadlines = 6
+
+// renderDeadlines lists the next occurrence of each fiscal deadline rule
+// from calendar.json with the amount due, "" when there are none. Only the
+// next occurrence counts so monthly rules do not push out the yearly ones
+func (m Model) renderDeadlines() string {
+	// Box border and padding (6) + date (10) + amount column (16) + gaps
+	titleWidth := min(m.fillWidth(6+10+16+2, 20), 50)
+	seen := map[string]bool{}
+	var lines []string
+	for _, d := range m.deadlines {
+		if len(lines) == maxDeadlines {
+			break
+		}
+		if seen[d.Rule.ID] {
+			continue
+		}
+		seen[d.Rule.ID] = true
+		amount := ""
+		if d.HasAmount {
+			amount = taxes.FormatRON(d.Amount)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			SummaryLabelStyle.Render(d.Date.Format("2006-01-02")),
+			padTruncate(d.Rule.Title, titleWidth),
+			SummaryValueStyle.Render(fmt.Sprintf("%16s", amount)),
+		))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // renderThresholdHint shows a "buffer to next" line if still in the lowest
 // bracket, or an actionable "add expenses to drop a bracket" line once a
 // threshold has been crossed. Returns "" if no hint applies.
@@ -167,3 +284,26 @@ func renderThresholdHint(t taxes.ThresholdResult) string {
 	}
 	return ""
 }
+
+// renderTaxConfigError explains why taxes.json could not be loaded, listing
+// every validation problem on its own line
+func (m Model) renderTaxConfigError() string {
+	var invalid *config.ValidationError
+	if !errors.As(m.taxConfigErr, &invalid) {
+		msg := "Could not load taxes.json config"
+		if m.taxConfigErr != nil {
+			msg += ": " + m.taxConfigErr.Error()
+		}
+		return ErrorStyle.Render(msg)
+	}
+
+	var b strings.Builder
+	b.WriteString(ErrorStyle.Render("‼️  taxes.json is invalid, taxes are not calculated"))
+	b.WriteString("\n")
+	for _, p := range invalid.Problems {
+		b.WriteString(warningStyle.Render("  • " + p))
+		b.WriteString("\n")
+	}
+	b.WriteString(SummaryLabelStyle.Render("Fix it and restart, check with: solo-cli taxes config validate"))
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"solo-cli/money"
+	"solo-cli/taxes"
+)
+
+// taxChartMaxSalaries is the net income range of the tax chart in SMB
+const taxChartMaxSalaries = 100
+
+// taxChartStep is the regular row spacing of the tax chart in SMB
+const taxChartStep = 5
+
+// taxesContent returns what the Taxes tab scrolls through: the breakdown
+// boxes or, toggled with c, the tax curve chart
+func (m Model) taxesContent() string {
+	if m.taxesChart {
+		return m.renderTaxChart()
+	}
+	return m.renderTaxes()
+}
+
+// taxChartRow is one net income sample of the tax chart
+type taxChartRow struct {
+	salaries float64
+	marker   string // threshold or current position marker, "" for plain rows
+	current  bool
+}
+
+// taxChartRows samples 0-100 SMB every taxChartStep salaries plus every
+// CAS/CASS threshold (evaluated at the threshold, after the cliff) and the
+// current net income, sorted by income
+func (m Model) taxChartRows() []taxChartRow {
+	markers := map[float64][]string{}
+	for s := 0.0; s <= taxChartMaxSalaries; s += taxChartStep {
+		markers[s] = nil
+	}
+	for _, t := range m.taxConfig.CASThresholds {
+		if t.MinSalaries > 0 && t.MinSalaries <= taxChartMaxSalaries {
+			markers[t.MinSalaries] = append(markers[t.MinSalaries], "CAS")
+		}
+	}
+	for _, t := range m.taxConfig.CASSThresholds {
+		if t.MinSalaries > 0 && t.MinSalaries <= taxChartMaxSalaries {
+			markers[t.MinSalaries] = append(markers[t.MinSalaries], "CASS")
+		}
+	}
+
+	current := -1.0
+	if m.taxBreakdown != nil {
+		current = m.taxBreakdown.SalariesCount
+		if _, ok := markers[current]; !ok {
+			markers[current] = nil
+		}
+	}
+
+	rows := make([]taxChartRow, 0, len(markers))
+	for s, labels := range markers {
+		row := taxChartRow{salaries: s, current: s == current}
+		if len(labels) > 0 {
+			row.marker = "▲ " + strings.Join(labels, "/")
+		}
+		if row.current {
+			row.marker = "◀ you"
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].salaries < rows[j].salaries })
+	return rows
+}
+
+// renderTaxChart plots total taxes (bars) and the effective rate against net
+// income from 0 to 100 SMB. Threshold rows are marked so the CAS/CASS
+// cliffs stand out, the current position is highlighted
+func (m Model) renderTaxChart() string {
+	if m.taxConfig == nil {
+		return m.renderTaxConfigError()
+	}
+
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render(fmt.Sprintf("Tax Curve (0-%d salarii @ %.0f RON)", taxChartMaxSalaries, m.taxConfig.SalariuMinimBrut)))
+	b.WriteString("\n")
+
+	rows := m.taxChartRows()
+	results := make([]*taxes.TaxBreakdown, len(rows))
+	var maxTax money.Money
+	for i, row := range rows {
+		results[i] = taxes.Calculate(money.FromFloat(row.salaries*m.taxConfig.SalariuMinimBrut), 0, m.taxConfig)
+		if results[i].TotalTaxes > maxTax {
+			maxTax = results[i].TotalTaxes
+		}
+	}
+
+	// Layout: salaries (9) + bar + taxes (12) + rate (6) + marker (10), the
+	// bar flexes
+	const valueWidth, rateWidth, markerWidth = 12, 6, 10
+	barWidth := m.fillWidth(9+1+1+valueWidth+1+rateWidth+1+markerWidth, 20)
+
+	b.WriteString(SummaryLabelStyle.Render(fmt.Sprintf("%9s %s %*s %*s", "Net", padTruncate("Total taxes", barWidth), valueWidth, "RON", rateWidth, "Rate")))
+	b.WriteString("\n")
+
+	for i, row := range rows {
+		r := results[i]
+		filled := 0
+		if maxTax > 0 {
+			filled = int(r.TotalTaxes.Float64() / maxTax.Float64() * float64(barWidth))
+		}
+
+		barStyle := secondaryStyle
+		if row.current {
+			barStyle = warningStyle
+		}
+		bar := barStyle.Render(strings.Repeat("█", filled)) + SummaryLabelStyle.Render(strings.Repeat("░", barWidth-filled))
+
+		marker := SummaryLabelStyle.Render(padTruncate(row.marker, markerWidth))
+		if row.current {
+			marker = warningStyle.Render(padTruncate(row.marker, markerWidth))
+		} else if row.marker != "" {
+			marker = dangerStyle.Render(padTruncate(row.marker, markerWidth))
+		}
+
+		b.WriteString(fmt.Sprintf("%s %s %s %s %s\n",
+			SummaryLabelStyle.Render(fmt.Sprintf("%5.1f SMB", row.salaries)),
+			bar,
+			SummaryValueStyle.Render(fmt.Sprintf("%*.2f", valueWidth, r.TotalTaxes)),
+			SummaryValueStyle.Render(fmt.Sprintf("%*.1f%%", rateWidth-1, r.EffectiveRate)),
+			marker,
+		))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(SummaryLabelStyle.Render("▲ threshold (taxes at the threshold, after the cliff) • ◀ your net income"))
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"solo-cli/money"
+	"solo-cli/report"
+)
+
+// renderYearComparison lines up the comparison years with the growth from
+// one year to the next
+func (m Model) renderYearComparison() string {
+	var b strings.Builder
+
+	end := m.year
+	if end == 0 && m.summary != nil {
+		end = m.summary.Year
+	}
+	b.WriteString(TitleStyle.Render(fmt.Sprintf("Year over Year (%d-%d)", end-compareYearCount+1, end)))
+	b.WriteString("\n")
+
+	switch {
+	case m.comparison == nil || m.comparison.end != end:
+		b.WriteString(LoadingStyle.Render("Loading summaries..."))
+		return b.String()
+	case m.comparison.err != nil:
+		b.WriteString(ErrorStyle.Render(fmt.Sprintf("Could not load the summaries: %v", m.comparison.err)))
+		return b.String()
+	}
+	years := report.CompareYears(m.comparison.summaries, m.taxConfig, m.extraIncome)
+
+	// Year (4) + four amounts (10) each with its growth (7)
+	header := fmt.Sprintf("%-4s %10s %7s %10s %7s %10s %7s %10s %7s",
+		"Year", "Revenues", "", "Deductible", "", "Net income", "", "Taxes", "")
+	b.WriteString(TableHeaderStyle.Render(header))
+	b.WriteString("\n")
+
+	// Falling revenue or net income stands out
+	cell := func(v money.Money, g *float64, flagDrop bool) string {
+		amount := TableRowStyle.Render(fmt.Sprintf(" %10.0f ", v))
+		growth := SummaryLabelStyle.Render(fmt.Sprintf("%7s", report.FormatGrowth(g)))
+		if flagDrop && g != nil && *g < 0 {
+			growth = dangerStyle.Render(fmt.Sprintf("%7s", report.FormatGrowth(g)))
+		}
+		return amount + growth
+	}
+	for _, row := range years.Rows {
+		taxes := cell(row.Taxes, row.TaxesGrowth, false)
+		if m.taxConfig == nil {
+			taxes = TableRowStyle.Render(fmt.Sprintf(" %10s ", "n/a")) + strings.Repeat(" ", 7)
+		}
+		b.WriteString(TableRowStyle.Render(fmt.Sprintf("%-4d", row.Year)) +
+			cell(row.Revenue, row.RevenueGrowth, true) +
+			cell(row.Deductible, row.DeductibleGrowth, false) +
+			cell(row.Net, row.NetGrowth, true) + taxes)
+		b.WriteString("\n")
+	}
+
+	if years.RevenueCAGR != nil {
+		b.WriteString("\n")
+		b.WriteString(SummaryLabelStyle.Render("Revenue growth: "))
+		b.WriteString(SummaryValueStyle.Render(report.FormatGrowth(years.RevenueCAGR)))
+		b.WriteString(SummaryLabelStyle.Render(" per year on average"))
+	}
+	return b.String()
+}
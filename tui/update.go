@@ -2,9 +2,12 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"solo-cli/config"
+	"solo-cli/report"
 	"solo-cli/taxes"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -64,6 +67,7 @@ func (m Model) fetchAll() tea.Cmd {
 		m.fetchRejected,
 		m.fetchQueue,
 		m.fetchEFactura,
+		m.fetchDeadlines,
 	)
 }
 
@@ -141,23 +145,55 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.searchInput = ""
 				return m, m.applySearch()
 			}
+		case "c":
+			switch m.activeTab {
+			case TabTaxes:
+				m.taxesChart = !m.taxesChart
+				m.taxesScroll = 0
+				m.taxesLines = len(strings.Split(m.taxesContent(), "\n"))
+			case TabChart:
+				m.toggleChartView(chartClients)
+			}
+		case "g":
+			if m.activeTab == TabExpenses {
+				return m, m.nextExpenseGroup()
+			}
+		case "y":
+			if m.activeTab == TabDashboard {
+				m.compareYears = !m.compareYears
+				if m.compareYears {
+					return m, m.fetchYearSummaries
+				}
+			}
+		case "p":
+			if m.activeTab == TabChart {
+				m.toggleChartView(chartPnL)
+				return m, m.fetchRestForTab()
+			}
+		case "b":
+			if m.activeTab == TabChart && m.chartView == chartPnL {
+				m.pnlQuarterly = !m.pnlQuarterly
+			}
 		case "[":
 			if m.canSwitchYear() && m.year > 2015 {
 				m.year--
 				m.taxesScroll = 0
-				return m, m.fetchSummary
+				return m, m.fetchYear()
 			}
 		case "]":
 			if m.canSwitchYear() && m.year < m.maxYear {
 				m.year++
 				m.taxesScroll = 0
-				return m, m.fetchSummary
+				return m, m.fetchYear()
 			}
 		case "r":
 			// Refresh
 			m.loading = true
 			m.listGen++
 			m.fetchingMore = false
+			if m.compareYears {
+				return m, tea.Batch(m.fetchAll(), m.fetchYearSummaries)
+			}
 			return m, m.fetchAll()
 		}
 
@@ -187,7 +223,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		if m.taxBreakdown != nil && m.taxesLines == 0 {
-			m.taxesLines = len(strings.Split(m.renderTaxes(), "\n"))
+			m.taxesLines = len(strings.Split(m.taxesContent(), "\n"))
 		}
 
 	case summaryMsg:
@@ -206,12 +242,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.year = m.summary.Year
 			if m.taxConfig != nil {
-				m.taxBreakdown = taxes.Calculate(m.summary.TotalRevenues, m.summary.TotalDeductibleExpenses, m.taxConfig)
-				m.taxesLines = len(strings.Split(m.renderTaxes(), "\n"))
+				extra := config.ExtraIncomeForYear(m.extraIncome, m.summary.Year)
+				m.taxBreakdown = taxes.CalculateWithIncome(m.summary.TotalRevenues, m.summary.TotalDeductibleExpenses, extra, m.taxConfig)
+				m.optimization = taxes.Optimize(m.summary.TotalRevenues, m.summary.TotalDeductibleExpenses, m.taxConfig)
+				m.taxesLines = len(strings.Split(m.taxesContent(), "\n"))
 			}
 		}
 		m.checkLoadingDone()
 
+	case yearSummariesMsg:
+		// Like summaryMsg, only the comparison for the selected year lands
+		if m.year != 0 && msg.end != m.year {
+			return m, nil
+		}
+		m.comparison = &msg
+
 	case companyMsg:
 		m.company = msg
 		// Company is optional, don't block loading
@@ -220,13 +265,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.caenCodes = msg
 		// CAEN codes are optional, don't block loading
 
+	case deadlinesMsg:
+		m.deadlines = msg
+		// Deadlines are optional, don't block loading
+		if m.taxBreakdown != nil {
+			m.taxesLines = len(strings.Split(m.taxesContent(), "\n"))
+		}
+
 	case revenuesMsg:
 		m.revenues = msg
 		m.checkLoadingDone()
+		return m, m.fetchRestForTab()
 
 	case expensesMsg:
 		m.expenses = msg
 		m.checkLoadingDone()
+		return m, m.fetchRestForTab()
 
 	case rejectedMsg:
 		m.rejected = msg
@@ -249,10 +303,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.revenues.TotalResults = msg.resp.TotalResults
 		}
 		m.fetchingMore = false
-		// Keep loading until complete while the chart is visible
-		if m.activeTab == TabChart {
-			return m, m.fetchRestOfRevenues()
-		}
+		// Keep loading until complete while the chart, the VAT tracker or
+		// the receivables are visible
+		return m, m.fetchRestForTab()
 
 	case expensesPageMsg:
 		if msg.gen != m.listGen {
@@ -263,6 +316,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.expenses.TotalResults = msg.resp.TotalResults
 		}
 		m.fetchingMore = false
+		// The profit and loss table needs every expense
+		return m, m.fetchRestForTab()
 
 	case queuePageMsg:
 		if msg.gen != m.listGen {
@@ -332,19 +387,55 @@ func (m Model) canSwitchYear() bool {
 	switch m.activeTab {
 	case TabDashboard, TabTaxes, TabChart:
 		return !m.demoMode && m.year > 0
+	case TabExpenses:
+		return m.expenseGroup != "" && !m.demoMode && m.year > 0
 	}
 	return false
 }
 
-// isListTab reports whether the active tab shows a navigable list
+// isListTab reports whether the active tab shows a navigable list. The
+// Expenses tab does not while it shows the breakdown
 func (m Model) isListTab() bool {
 	switch m.activeTab {
-	case TabRevenues, TabExpenses, TabEFactura, TabQueue:
+	case TabRevenues, TabEFactura, TabQueue:
 		return true
+	case TabExpenses:
+		return m.expenseGroup == ""
 	}
 	return false
 }
 
+// toggleChartView switches the Chart tab between the monthly bars and v
+func (m *Model) toggleChartView(v chartView) {
+	if m.chartView == v {
+		m.chartView = chartMonthly
+	} else {
+		m.chartView = v
+	}
+}
+
+// nextExpenseGroup cycles the Expenses tab through the list and the
+// breakdown by each grouping. The breakdown covers every expense, so an
+// applied search is cleared first
+func (m *Model) nextExpenseGroup() tea.Cmd {
+	next := report.GroupBys[0]
+	if i := slices.Index(report.GroupBys, m.expenseGroup); i >= 0 {
+		next = ""
+		if i+1 < len(report.GroupBys) {
+			next = report.GroupBys[i+1]
+		}
+	}
+	m.expenseGroup = next
+	m.cursor = 0
+	m.viewportOffset = 0
+	m.detailOpen = false
+	if next != "" && m.searchQuery != "" {
+		m.searchInput = ""
+		return m.applySearch()
+	}
+	return m.fetchRestForTab()
+}
+
 // setTab switches the active tab and resets per-tab navigation state.
 // An active search belongs to the tab it was typed on, so it is cleared
 // and the list is refetched unfiltered
@@ -365,11 +456,7 @@ func (m *Model) setTab(t Tab) tea.Cmd {
 	if hadQuery && !m.demoMode {
 		return m.fetchAll()
 	}
-	if t == TabChart {
-		// The chart aggregates the complete invoice list
-		return m.fetchRestOfRevenues()
-	}
-	return nil
+	return m.fetchRestForTab()
 }
 
 func (m *Model) scrollUp() {
@@ -40,10 +40,14 @@ func (m Model) View() string {
 		switch {
 		case m.detailOpen && m.isListTab():
 			b.WriteString(m.renderDetail())
+		case m.activeTab == TabDashboard && m.compareYears:
+			b.WriteString(m.renderYearComparison())
 		case m.activeTab == TabDashboard:
 			b.WriteString(m.renderDashboard())
 		case m.activeTab == TabRevenues:
 			b.WriteString(m.renderRevenues())
+		case m.activeTab == TabExpenses && m.expenseGroup != "":
+			b.WriteString(m.renderExpenseBreakdown())
 		case m.activeTab == TabExpenses:
 			b.WriteString(m.renderExpenses())
 		case m.activeTab == TabQueue:
@@ -52,8 +56,14 @@ func (m Model) View() string {
 			b.WriteString(m.renderEFactura())
 		case m.activeTab == TabTaxes:
 			b.WriteString(m.renderTaxesViewport())
+		case m.activeTab == TabChart && m.chartView == chartPnL:
+			b.WriteString(m.renderPnL())
+		case m.activeTab == TabChart && m.chartView == chartClients:
+			b.WriteString(m.renderClients())
 		case m.activeTab == TabChart:
 			b.WriteString(m.renderChart())
+		case m.activeTab == TabReceivables:
+			b.WriteString(m.renderReceivables())
 		}
 	}
 
@@ -66,10 +76,24 @@ func (m Model) View() string {
 		helpText = "type to filter live • enter done • esc clear"
 	case m.activeTab == TabQueue:
 		helpText = "←/→ tabs • ↑/↓ navigate • enter details • / search • d delete • r refresh • q quit"
-	case m.activeTab == TabDashboard, m.activeTab == TabChart:
-		helpText = "←/→ tabs • [ and ] switch year • r refresh • q quit"
+	case m.activeTab == TabExpenses && m.expenseGroup != "":
+		helpText = "←/→ tabs • g next grouping • [ and ] switch year • r refresh • q quit"
+	case m.activeTab == TabExpenses:
+		helpText = "←/→ tabs • ↑/↓ navigate • enter details • / search • g breakdown • r refresh • q quit"
+	case m.activeTab == TabReceivables:
+		helpText = "←/→ tabs • r refresh • q quit"
+	case m.activeTab == TabChart && m.chartView == chartPnL:
+		helpText = "←/→ tabs • p chart • b month/quarter • c clients • [ and ] switch year • r refresh • q quit"
+	case m.activeTab == TabChart && m.chartView == chartClients:
+		helpText = "←/→ tabs • c chart • p profit and loss • [ and ] switch year • r refresh • q quit"
+	case m.activeTab == TabChart:
+		helpText = "←/→ tabs • p profit and loss • c clients • [ and ] switch year • r refresh • q quit"
+	case m.activeTab == TabDashboard && m.compareYears:
+		helpText = "←/→ tabs • y single year • [ and ] switch year • r refresh • q quit"
+	case m.activeTab == TabDashboard:
+		helpText = "←/→ tabs • y compare years • [ and ] switch year • r refresh • q quit"
 	case m.activeTab == TabTaxes:
-		helpText = "←/→ tabs • ↑/↓ scroll • [ and ] switch year • r refresh • q quit"
+		helpText = "←/→ tabs • ↑/↓ scroll • c chart • [ and ] switch year • r refresh • q quit"
 	}
 	if m.debugMouse && m.lastMouse != "" {
 		helpText = m.lastMouse
@@ -91,7 +115,7 @@ func (m Model) View() string {
 }
 
 // tabOrder is the display order of the tab bar, shared with click handling
-var tabOrder = []Tab{TabDashboard, TabRevenues, TabExpenses, TabEFactura, TabQueue, TabTaxes, TabChart}
+var tabOrder = []Tab{TabDashboard, TabRevenues, TabExpenses, TabEFactura, TabQueue, TabTaxes, TabChart, TabReceivables}
 
 func (m Model) renderTabs() string {
 	var parts []string